@@ -0,0 +1,81 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// AddressResult is the outcome of a check against one of the addresses its
+// host resolves to.
+type AddressResult struct {
+	IP       string `json:"ip"`
+	IPFamily string `json:"ipFamily"`
+	Error    string `json:"error,omitempty"`
+	Latency  int64  `json:"latency"`
+	Status   int    `json:"status,omitempty"`
+}
+
+// ProbeAddresses resolves host to all its A and AAAA records, restricted to
+// the IP family, and runs probe against each of them concurrently, so a dead
+// backend behind round-robin DNS cannot hide behind its healthy siblings.
+func ProbeAddresses(ctx context.Context, host, family string, probe func(ctx context.Context, ip netip.Addr) AddressResult) ([]AddressResult, error) {
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, lookupNetwork(family), host)
+	if err != nil {
+		return nil, &ClassifiedError{Class: ErrorClassDNS, Err: fmt.Errorf("unable to resolve %s: %w", host, err)}
+	}
+
+	results := make([]AddressResult, len(ips))
+	var wg sync.WaitGroup
+	for i, ip := range ips {
+		wg.Go(func() {
+			ip = ip.Unmap()
+			res := probe(ctx, ip)
+			res.IP = ip.String()
+			res.IPFamily = request.IPFamilyIPv6
+			if ip.Is4() {
+				res.IPFamily = request.IPFamilyIPv4
+			}
+			results[i] = res
+		})
+	}
+	wg.Wait()
+
+	return results, nil
+}
+
+// FailedAddress returns an error naming the first address whose probe
+// failed, nil when they all succeeded.
+func FailedAddress(results []AddressResult) error {
+	for _, r := range results {
+		if r.Error != "" {
+			return fmt.Errorf("address %s: %s", r.IP, r.Error)
+		}
+	}
+
+	return nil
+}
+
+// DialTo returns a dial function connecting to address whatever the address
+// it is asked for, so the Host header and the SNI still name the target.
+// address is a host or IP, with the port of the original address, or a
+// host:port.
+func DialTo(dial func(ctx context.Context, network, addr string) (net.Conn, error), address string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if _, _, err := net.SplitHostPort(address); err == nil {
+			return dial(ctx, network, address)
+		}
+
+		_, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		return dial(ctx, network, net.JoinHostPort(strings.Trim(address, "[]"), port))
+	}
+}
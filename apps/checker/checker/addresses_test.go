@@ -0,0 +1,52 @@
+package checker_test
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestProbeAddresses(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	var d net.Dialer
+	results, err := checker.ProbeAddresses(t.Context(), "localhost", request.IPFamilyIPv4, func(ctx context.Context, ip netip.Addr) checker.AddressResult {
+		// The dial is pinned to ip whatever the name.
+		conn, err := checker.DialTo(d.DialContext, ip.String())(ctx, "tcp", "example.com:"+port)
+		if err != nil {
+			return checker.AddressResult{Error: err.Error()}
+		}
+		conn.Close()
+
+		return checker.AddressResult{}
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []checker.AddressResult{{IP: "127.0.0.1", IPFamily: request.IPFamilyIPv4}}, results)
+	assert.NoError(t, checker.FailedAddress(results))
+
+	results = append(results, checker.AddressResult{IP: "127.0.0.2", Error: "connection refused"})
+	assert.EqualError(t, checker.FailedAddress(results), "address 127.0.0.2: connection refused")
+}
+
+func TestDialTo(t *testing.T) {
+	var dialed []string
+	dial := func(_ context.Context, _, addr string) (net.Conn, error) {
+		dialed = append(dialed, addr)
+		return nil, nil
+	}
+
+	for _, address := range []string{"10.0.0.1", "origin.openstat.us", "::1", "[::1]", "10.0.0.1:8443"} {
+		_, _ = checker.DialTo(dial, address)(t.Context(), "tcp", "openstat.us:443")
+	}
+	assert.Equal(t, []string{"10.0.0.1:443", "origin.openstat.us:443", "[::1]:443", "[::1]:443", "10.0.0.1:8443"}, dialed)
+}
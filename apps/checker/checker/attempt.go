@@ -0,0 +1,30 @@
+package checker
+
+import "time"
+
+// Attempt describes a single try of a check, the first one included, so
+// users can tell a slow first try from a success after a few retries.
+type Attempt struct {
+	Error     string     `json:"error,omitempty"`
+	Class     ErrorClass `json:"class,omitempty"`
+	Code      ErrorCode  `json:"code,omitempty"`
+	Attempt   int        `json:"attempt"`
+	Timestamp int64      `json:"timestamp"`
+	Latency   int64      `json:"latency"`
+}
+
+// NewAttempt records the outcome of the n-th try, started at start.
+func NewAttempt(n int, start time.Time, err error) Attempt {
+	attempt := Attempt{
+		Attempt:   n,
+		Timestamp: start.UTC().UnixMilli(),
+		Latency:   time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+		attempt.Class = ClassifyError(err)
+		attempt.Code = ClassifyCode(err)
+	}
+
+	return attempt
+}
@@ -0,0 +1,77 @@
+package checker
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultBodyCaptureBytes is how much of a response body is captured when
+// the check does not set its own limit.
+const DefaultBodyCaptureBytes = 4 << 10
+
+// MaxBodyCaptureBytes caps the body captured in an event.
+const MaxBodyCaptureBytes = 64 << 10
+
+// CaptureBody returns the first limit bytes of a response body for the event
+// of a check, DefaultBodyCaptureBytes when limit is 0. Text is cut on a rune
+// boundary and a truncated body ends with a marker. Binary bodies are
+// captured as a base64 data URL, the format of the binary request bodies.
+func CaptureBody(body, contentType string, limit int) string {
+	if limit <= 0 {
+		limit = DefaultBodyCaptureBytes
+	}
+	limit = min(limit, MaxBodyCaptureBytes)
+
+	if isBinary(body, contentType) {
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType == "" {
+			mediaType = http.DetectContentType([]byte(body))
+		}
+		prefix := fmt.Sprintf("data:%s;base64,", mediaType)
+		// base64 takes 4 bytes for every 3.
+		n := max(0, (limit-len(prefix))/4*3)
+		if len(body) <= n {
+			return prefix + base64.StdEncoding.EncodeToString([]byte(body))
+		}
+
+		return prefix + base64.StdEncoding.EncodeToString([]byte(body[:n]))
+	}
+
+	if len(body) <= limit {
+		return body
+	}
+
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+
+	return body[:cut] + fmt.Sprintf("…[truncated %d bytes]", len(body)-cut)
+}
+
+func isBinary(body, contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "json"), strings.HasSuffix(mediaType, "xml"),
+		mediaType == "application/javascript",
+		mediaType == "application/x-www-form-urlencoded":
+		return false
+	case strings.HasPrefix(mediaType, "image/"), strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"), mediaType == "application/octet-stream":
+		return true
+	}
+
+	// Unknown or missing content type, sniff the start of the body. A rune
+	// cut by the end of the sample does not make the body binary.
+	sample := body[:min(len(body), 512)]
+	for i := 1; i < utf8.UTFMax && len(sample) < len(body) && !utf8.ValidString(sample); i++ {
+		sample = sample[:len(sample)-1]
+	}
+
+	return !utf8.ValidString(sample) || strings.IndexByte(sample, 0) >= 0
+}
@@ -0,0 +1,36 @@
+package checker_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+)
+
+func TestCaptureBody(t *testing.T) {
+	t.Run("short text", func(t *testing.T) {
+		assert.Equal(t, `{"ok":false}`, checker.CaptureBody(`{"ok":false}`, "application/json", 0))
+	})
+
+	t.Run("truncated on a rune boundary", func(t *testing.T) {
+		got := checker.CaptureBody("aé"+strings.Repeat("b", 10), "text/plain; charset=utf-8", 2)
+		assert.Equal(t, "a…[truncated 12 bytes]", got)
+	})
+
+	t.Run("default limit", func(t *testing.T) {
+		got := checker.CaptureBody(strings.Repeat("a", checker.DefaultBodyCaptureBytes+1), "", 0)
+		assert.True(t, strings.HasSuffix(got, "…[truncated 1 bytes]"))
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		assert.Equal(t, "data:image/png;base64,iVBORw==", checker.CaptureBody("\x89PNG", "image/png", 0))
+		assert.Equal(t, "data:application/octet-stream;base64,AAEC", checker.CaptureBody("\x00\x01\x02", "", 0))
+	})
+
+	t.Run("binary truncated", func(t *testing.T) {
+		got := checker.CaptureBody(strings.Repeat("\x00", 100), "application/octet-stream", 45)
+		assert.Equal(t, "data:application/octet-stream;base64,AAAAAAAA", got)
+	})
+}
@@ -0,0 +1,69 @@
+package checker
+
+import (
+	"strconv"
+	"strings"
+)
+
+// The normalized cache statuses of the CDN in front of an HTTP target.
+const (
+	CacheHit         = "HIT"
+	CacheMiss        = "MISS"
+	CacheStale       = "STALE"
+	CacheExpired     = "EXPIRED"
+	CacheRevalidated = "REVALIDATED"
+	CacheBypass      = "BYPASS"
+	CacheDynamic     = "DYNAMIC"
+)
+
+// CacheStatus reads the cache status of a response from the headers of the
+// common CDNs: cf-cache-status (Cloudflare), x-vercel-cache (Vercel) and
+// x-cache (CloudFront, Fastly, Akamai, Varnish), and falls back on a
+// positive Age meaning a hit. It is empty when the response says nothing.
+func CacheStatus(headers map[string]string) string {
+	for _, key := range []string{"Cf-Cache-Status", "X-Vercel-Cache", "X-Cache"} {
+		value, ok := headers[key]
+		if !ok {
+			continue
+		}
+		// Fastly lists the status of every cache layer, the last one is
+		// the edge the check hit.
+		if i := strings.LastIndex(value, ","); i >= 0 {
+			value = value[i+1:]
+		}
+		if status := normalizeCacheStatus(value); status != "" {
+			return status
+		}
+	}
+
+	if age, err := strconv.Atoi(strings.TrimSpace(headers["Age"])); err == nil && age > 0 {
+		return CacheHit
+	}
+
+	return ""
+}
+
+func normalizeCacheStatus(value string) string {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	switch {
+	case value == "":
+		return ""
+	// CloudFront answers "Hit from cloudfront", Akamai "TCP_HIT".
+	case strings.Contains(value, "REFRESH_HIT"), strings.Contains(value, "REVALIDATED"):
+		return CacheRevalidated
+	case strings.Contains(value, "STALE"), value == "UPDATING":
+		return CacheStale
+	case strings.Contains(value, "HIT"):
+		return CacheHit
+	case strings.Contains(value, "EXPIRED"):
+		return CacheExpired
+	case strings.Contains(value, "MISS"), value == "PRERENDER":
+		return CacheMiss
+	case strings.Contains(value, "BYPASS"), value == "PASS":
+		return CacheBypass
+	case value == "DYNAMIC", value == "NONE", value == "UNKNOWN":
+		return CacheDynamic
+	}
+
+	return value
+}
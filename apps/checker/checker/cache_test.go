@@ -0,0 +1,34 @@
+package checker_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+)
+
+func TestCacheStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{name: "cloudflare", headers: map[string]string{"Cf-Cache-Status": "HIT"}, want: checker.CacheHit},
+		{name: "cloudflare dynamic", headers: map[string]string{"Cf-Cache-Status": "DYNAMIC"}, want: checker.CacheDynamic},
+		{name: "cloudfront", headers: map[string]string{"X-Cache": "Miss from cloudfront"}, want: checker.CacheMiss},
+		{name: "fastly edge", headers: map[string]string{"X-Cache": "MISS, HIT"}, want: checker.CacheHit},
+		{name: "akamai", headers: map[string]string{"X-Cache": "TCP_REFRESH_HIT from a23-1-2-3"}, want: checker.CacheRevalidated},
+		{name: "vercel", headers: map[string]string{"X-Vercel-Cache": "STALE"}, want: checker.CacheStale},
+		{name: "cloudflare wins", headers: map[string]string{"Cf-Cache-Status": "EXPIRED", "X-Cache": "HIT"}, want: checker.CacheExpired},
+		{name: "age", headers: map[string]string{"Age": "120"}, want: checker.CacheHit},
+		{name: "fresh age", headers: map[string]string{"Age": "0"}, want: ""},
+		{name: "no cdn", headers: map[string]string{"Content-Type": "text/html"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, checker.CacheStatus(tt.headers))
+		})
+	}
+}
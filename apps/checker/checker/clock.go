@@ -0,0 +1,20 @@
+package checker
+
+import "time"
+
+// clock timestamps the phases of a check. The timestamps are wall-clock Unix
+// milliseconds, but only the start is read from the wall clock: the rest is
+// advanced by the monotonic clock, so an NTP adjustment during the check
+// cannot make a phase last a negative or absurd time.
+type clock struct {
+	start time.Time
+}
+
+func newClock() clock {
+	return clock{start: time.Now()}
+}
+
+// now returns the Unix milliseconds of the present.
+func (c clock) now() int64 {
+	return c.start.UnixMilli() + time.Since(c.start).Milliseconds()
+}
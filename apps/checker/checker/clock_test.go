@@ -0,0 +1,18 @@
+package checker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock(t *testing.T) {
+	clk := newClock()
+	assert.Equal(t, clk.start.UnixMilli(), clk.now())
+
+	first := clk.now()
+	time.Sleep(5 * time.Millisecond)
+	second := clk.now()
+	assert.GreaterOrEqual(t, second-first, int64(5))
+}
@@ -0,0 +1,63 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// ConditionalResult is the outcome of the revalidation of a response.
+type ConditionalResult struct {
+	// IfNoneMatch and IfModifiedSince are the validators sent, the ETag and
+	// Last-Modified of the response.
+	IfNoneMatch     string `json:"ifNoneMatch,omitempty"`
+	IfModifiedSince string `json:"ifModifiedSince,omitempty"`
+	Status          int    `json:"status"`
+	Latency         int64  `json:"latency"`
+	// Changed tells the resource changed in between, the replay answering
+	// with another ETag.
+	Changed bool `json:"changed,omitempty"`
+}
+
+// Revalidate replays req with the validators of res, its response, in
+// If-None-Match and If-Modified-Since, and fails unless the server answers
+// 304 Not Modified or the resource changed in between.
+func Revalidate(ctx context.Context, client *http.Client, req request.HttpCheckerRequest, res Response) (*ConditionalResult, error) {
+	result := &ConditionalResult{IfNoneMatch: res.Headers["Etag"], IfModifiedSince: res.Headers["Last-Modified"]}
+	if result.IfNoneMatch == "" && result.IfModifiedSince == "" {
+		return result, &ClassifiedError{Class: ErrorClassAssertion, Err: errors.New("the response has no ETag nor Last-Modified to revalidate")}
+	}
+
+	// The validators of the check replace the ones it may send.
+	req.Headers = slices.DeleteFunc(slices.Clone(req.Headers), func(h struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}) bool {
+		return strings.EqualFold(h.Key, "If-None-Match") || strings.EqualFold(h.Key, "If-Modified-Since")
+	})
+	for _, h := range [][2]string{{"If-None-Match", result.IfNoneMatch}, {"If-Modified-Since", result.IfModifiedSince}} {
+		if h[1] != "" {
+			req.Headers = append(req.Headers, struct {
+				Key   string `json:"key"`
+				Value string `json:"value"`
+			}{h[0], h[1]})
+		}
+	}
+
+	replay, err := Http(ctx, client, req)
+	if err != nil {
+		return result, fmt.Errorf("unable to revalidate: %w", err)
+	}
+	result.Status, result.Latency = replay.Status, replay.Latency
+	result.Changed = replay.Status == http.StatusOK && result.IfNoneMatch != "" && replay.Headers["Etag"] != result.IfNoneMatch
+	if replay.Status != http.StatusNotModified && !result.Changed {
+		return result, &ClassifiedError{Class: ErrorClassAssertion, Err: fmt.Errorf("revalidation answered %d, expected 304", replay.Status)}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,63 @@
+package checker_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestRevalidate(t *testing.T) {
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var version int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cached":
+			w.Header().Set("ETag", `"v1"`)
+			http.ServeContent(w, r, "index.html", modified, strings.NewReader("<html></html>"))
+		case "/uncached":
+			w.Header().Set("ETag", `"v1"`)
+			_, _ = w.Write([]byte("<html></html>"))
+		case "/changing":
+			version++
+			w.Header().Set("ETag", `"v`+strconv.Itoa(version)+`"`)
+			_, _ = w.Write([]byte("<html></html>"))
+		default:
+			_, _ = w.Write([]byte("<html></html>"))
+		}
+	}))
+	defer server.Close()
+
+	revalidate := func(path string) (*checker.ConditionalResult, error) {
+		req := request.HttpCheckerRequest{URL: server.URL + path, Method: http.MethodGet}
+		res, err := checker.Http(t.Context(), server.Client(), req)
+		require.NoError(t, err)
+		return checker.Revalidate(t.Context(), server.Client(), req, res)
+	}
+
+	res, err := revalidate("/cached")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotModified, res.Status)
+	assert.Equal(t, `"v1"`, res.IfNoneMatch)
+	assert.Equal(t, modified.Format(http.TimeFormat), res.IfModifiedSince)
+
+	res, err = revalidate("/uncached")
+	assert.ErrorContains(t, err, "revalidation answered 200, expected 304")
+	assert.Equal(t, checker.ErrorCodeAssertionFailed, checker.ClassifyCode(err))
+	assert.Equal(t, http.StatusOK, res.Status)
+
+	res, err = revalidate("/changing")
+	require.NoError(t, err)
+	assert.True(t, res.Changed)
+
+	_, err = revalidate("/")
+	assert.ErrorContains(t, err, "no ETag nor Last-Modified")
+}
@@ -22,7 +22,7 @@ type DnsResponse struct {
 func Dns(ctx context.Context, host string) (*DnsResponse, error) {
 	logger:= log.Ctx(ctx).With().Str("monitor", host).Logger()
 
-	ips, err := net.LookupIP(host)
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
 	if err != nil {
 		logger.Error().Err(err).Msg("DNS IP lookup failed")
 		return nil, fmt.Errorf("failed to lookup IPs: %w", err)
@@ -38,19 +38,19 @@ func Dns(ctx context.Context, host string) (*DnsResponse, error) {
 			AAAA = append(AAAA, ip.String())
 		}
 	}
-	CNAME,err := lookupCNAME(host)
+	CNAME,err := lookupCNAME(ctx, host)
 	if err != nil {
 		logger.Error().Err(err).Msg("DNS CNAME record lookup failed")
 		return nil, fmt.Errorf("failed to lookup CNAME record: %w", err)
 	}
-	MXRecords := lookupMX(host)
+	MXRecords := lookupMX(ctx, host)
 
-	NS,err  := lookupNS(host)
+	NS,err  := lookupNS(ctx, host)
 	if err != nil {
 		logger.Error().Err(err).Msg("DNS NS record lookup failed")
 		return nil, fmt.Errorf("failed to lookup NS record: %w", err)
 	}
-	TXT := lookupTXT(host)
+	TXT := lookupTXT(ctx, host)
 
 
 	response := &DnsResponse{
@@ -67,8 +67,8 @@ func Dns(ctx context.Context, host string) (*DnsResponse, error) {
 
 
 
-func lookupCNAME(domain string) (string, error) {
-	cname, err := net.LookupCNAME(domain)
+func lookupCNAME(ctx context.Context, domain string) (string, error) {
+	cname, err := net.DefaultResolver.LookupCNAME(ctx, domain)
 	if err != nil {
 		return "", err
 	}
@@ -76,9 +76,9 @@ func lookupCNAME(domain string) (string, error) {
 	return cname, nil
 }
 
-func lookupMX(domain string) ([]string) {
+func lookupMX(ctx context.Context, domain string) ([]string) {
 	mx := []string{}
-	mxRecords,_ := net.LookupMX(domain)
+	mxRecords,_ := net.DefaultResolver.LookupMX(ctx, domain)
 
 
 	for _, r := range mxRecords {
@@ -87,14 +87,14 @@ func lookupMX(domain string) ([]string) {
 	return mx
 }
 
-func lookupNS(domain string) ([]string, error) {
+func lookupNS(ctx context.Context, domain string) ([]string, error) {
 
 	hosts := []string{}
 	isSubdomain := isSubdomain(domain)
 	if isSubdomain {
 		return hosts, nil
 	}
-	nsRecords, err := net.LookupNS(domain)
+	nsRecords, err := net.DefaultResolver.LookupNS(ctx, domain)
 	if err != nil {
 		return nil, err
 	}
@@ -105,9 +105,9 @@ func lookupNS(domain string) ([]string, error) {
 	return hosts, nil
 }
 
-func lookupTXT(domain string) ([]string) {
+func lookupTXT(ctx context.Context, domain string) ([]string) {
 	records := []string{}
-	txtRecords, err := net.LookupTXT(domain)
+	txtRecords, err := net.DefaultResolver.LookupTXT(ctx, domain)
 	if err != nil {
 		return nil
 	}
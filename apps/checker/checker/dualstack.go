@@ -0,0 +1,95 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// FamilyAttempt is the connection of a dual-stack race over one IP family,
+// to the first address of the host in that family. A family without any
+// address has no IP.
+type FamilyAttempt struct {
+	IP      string `json:"ip,omitempty"`
+	Latency int64  `json:"latency"`
+	Error   string `json:"error,omitempty"`
+}
+
+// DualStackResult is the outcome of racing IPv4 and IPv6 connections to a
+// host.
+type DualStackResult struct {
+	IPv4 FamilyAttempt `json:"ipv4"`
+	IPv6 FamilyAttempt `json:"ipv6"`
+	// Winner is the family connected first, empty when neither did.
+	Winner string `json:"winner,omitempty"`
+}
+
+// Err returns an error naming the family of the host which could not be
+// connected to, nil when the families with an address all were.
+func (r *DualStackResult) Err() error {
+	for _, a := range []struct {
+		family  string
+		attempt FamilyAttempt
+	}{{request.IPFamilyIPv6, r.IPv6}, {request.IPFamilyIPv4, r.IPv4}} {
+		if a.attempt.IP != "" && a.attempt.Error != "" {
+			return &ClassifiedError{Class: ErrorClassAssertion, Err: fmt.Errorf("%s address %s: %s", a.family, a.attempt.IP, a.attempt.Error)}
+		}
+	}
+
+	return nil
+}
+
+// RaceFamilies connects to port of host over IPv4 and IPv6 at once, each
+// within the timeout of dialer, unlike Happy Eyeballs which gives up on the
+// slower family and hides its failure. It fails when host has no address.
+func RaceFamilies(ctx context.Context, dialer *net.Dialer, host, port string) (*DualStackResult, error) {
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, &ClassifiedError{Class: ErrorClassDNS, Err: fmt.Errorf("unable to resolve %s: %w", host, err)}
+	}
+
+	res := &DualStackResult{IPv4: FamilyAttempt{Error: "no address"}, IPv6: FamilyAttempt{Error: "no address"}}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, family := range []string{request.IPFamilyIPv4, request.IPFamilyIPv6} {
+		attempt := &res.IPv4
+		if family == request.IPFamilyIPv6 {
+			attempt = &res.IPv6
+		}
+		for _, ip := range ips {
+			ip = ip.Unmap()
+			if ip.Is4() != (family == request.IPFamilyIPv4) {
+				continue
+			}
+			*attempt = FamilyAttempt{IP: ip.String()}
+			wg.Go(func() {
+				start := time.Now()
+				conn, err := dialer.DialContext(ctx, Network(family), net.JoinHostPort(attempt.IP, port))
+				attempt.Latency = time.Since(start).Milliseconds()
+				if err != nil {
+					attempt.Error = err.Error()
+					return
+				}
+				conn.Close()
+
+				mu.Lock()
+				defer mu.Unlock()
+				if res.Winner == "" {
+					res.Winner = family
+				}
+			})
+			break
+		}
+	}
+	wg.Wait()
+
+	return res, nil
+}
@@ -0,0 +1,45 @@
+package checker_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestRaceFamilies(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	res, err := checker.RaceFamilies(t.Context(), &net.Dialer{Timeout: time.Second}, "127.0.0.1", port)
+	require.NoError(t, err)
+	assert.Equal(t, request.IPFamilyIPv4, res.Winner)
+	assert.Equal(t, "127.0.0.1", res.IPv4.IP)
+	assert.Empty(t, res.IPv4.Error)
+	assert.Equal(t, checker.FamilyAttempt{Error: "no address"}, res.IPv6)
+	assert.NoError(t, res.Err(), "a family without address is not a failure")
+
+	ln.Close()
+	res, err = checker.RaceFamilies(t.Context(), &net.Dialer{Timeout: time.Second}, "127.0.0.1", port)
+	require.NoError(t, err)
+	assert.Empty(t, res.Winner)
+	assert.NotEmpty(t, res.IPv4.Error)
+	assert.ErrorContains(t, res.Err(), "ipv4 address 127.0.0.1")
+	assert.Equal(t, checker.ErrorCodeAssertionFailed, checker.ClassifyCode(res.Err()))
+}
@@ -0,0 +1,246 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"syscall"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/ssrf"
+)
+
+// ErrorClass groups check failures by cause so callers can decide which ones
+// are worth retrying.
+type ErrorClass string
+
+const (
+	ErrorClassDNS               ErrorClass = "dns"
+	ErrorClassConnectionRefused ErrorClass = "connection_refused"
+	ErrorClassTimeout           ErrorClass = "timeout"
+	ErrorClassTLS               ErrorClass = "tls"
+	ErrorClassHTTP5xx           ErrorClass = "http_5xx"
+	ErrorClassHTTP4xx           ErrorClass = "http_4xx"
+	ErrorClassAssertion         ErrorClass = "assertion"
+	ErrorClassBlocked           ErrorClass = "blocked"
+	ErrorClassUnknown           ErrorClass = "unknown"
+)
+
+// ErrorCode is the stable code of a check failure, finer than its class, so
+// failures can be aggregated without parsing their message.
+type ErrorCode string
+
+const (
+	ErrorCodeDNSNXDomain         ErrorCode = "DNS_NXDOMAIN"
+	ErrorCodeDNSTimeout          ErrorCode = "DNS_TIMEOUT"
+	ErrorCodeDNSFailure          ErrorCode = "DNS_FAILURE"
+	ErrorCodeConnRefused         ErrorCode = "CONN_REFUSED"
+	ErrorCodeConnReset           ErrorCode = "CONN_RESET"
+	ErrorCodeConnTimeout         ErrorCode = "CONN_TIMEOUT"
+	ErrorCodeHostUnreachable     ErrorCode = "HOST_UNREACHABLE"
+	ErrorCodeTLSExpired          ErrorCode = "TLS_EXPIRED"
+	ErrorCodeTLSUntrusted        ErrorCode = "TLS_UNTRUSTED"
+	ErrorCodeTLSHostnameMismatch ErrorCode = "TLS_HOSTNAME_MISMATCH"
+	ErrorCodeTLSRevoked          ErrorCode = "TLS_REVOKED"
+	ErrorCodeTLSTimeout          ErrorCode = "TLS_TIMEOUT"
+	ErrorCodeTLS                 ErrorCode = "TLS_ERROR"
+	ErrorCodeHTTP4xx             ErrorCode = "HTTP_4XX"
+	ErrorCodeHTTP5xx             ErrorCode = "HTTP_5XX"
+	ErrorCodeAssertionFailed     ErrorCode = "ASSERTION_FAILED"
+	ErrorCodeTimeout             ErrorCode = "TIMEOUT"
+	ErrorCodeBlocked             ErrorCode = "TARGET_BLOCKED"
+	ErrorCodeUnknown             ErrorCode = "UNKNOWN"
+)
+
+// classCodes are the codes of the failures known by their class only.
+var classCodes = map[ErrorClass]ErrorCode{
+	ErrorClassDNS:               ErrorCodeDNSFailure,
+	ErrorClassConnectionRefused: ErrorCodeConnRefused,
+	ErrorClassTimeout:           ErrorCodeTimeout,
+	ErrorClassTLS:               ErrorCodeTLS,
+	ErrorClassHTTP5xx:           ErrorCodeHTTP5xx,
+	ErrorClassHTTP4xx:           ErrorCodeHTTP4xx,
+	ErrorClassAssertion:         ErrorCodeAssertionFailed,
+	ErrorClassBlocked:           ErrorCodeBlocked,
+	ErrorClassUnknown:           ErrorCodeUnknown,
+}
+
+// Code returns the code of a failure of the class, when nothing finer is
+// known.
+func (c ErrorClass) Code() ErrorCode {
+	if c == "" {
+		return ""
+	}
+	if code, ok := classCodes[c]; ok {
+		return code
+	}
+
+	return ErrorCodeUnknown
+}
+
+// ClassifiedError is returned by the checks when the original error is
+// replaced by a friendlier message, so the class is not lost. Code is set
+// when the friendlier message hides a finer cause than the class.
+type ClassifiedError struct {
+	Err   error
+	Class ErrorClass
+	Code  ErrorCode
+}
+
+func (e *ClassifiedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ClassifiedError) Unwrap() error {
+	return e.Err
+}
+
+// ClassifyError returns the class of an error returned by a check.
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ""
+	}
+
+	var classified *ClassifiedError
+	if errors.As(err, &classified) {
+		return classified.Class
+	}
+
+	if errors.Is(err, ssrf.ErrBlocked) {
+		return ErrorClassBlocked
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsTimeout {
+			return ErrorClassTimeout
+		}
+		return ErrorClassDNS
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrorClassConnectionRefused
+	}
+
+	var (
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		verifyErr    *tls.CertificateVerificationError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+	if errors.As(err, &recordErr) || errors.As(err, &alertErr) || errors.As(err, &verifyErr) ||
+		errors.As(err, &authorityErr) || errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return ErrorClassTLS
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrorClassTimeout
+	}
+
+	return ErrorClassUnknown
+}
+
+// ClassifyCode returns the code of an error returned by a check, the one of
+// its class when its cause is not known more precisely.
+func ClassifyCode(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+
+	var classified *ClassifiedError
+	if errors.As(err, &classified) && classified.Code != "" {
+		return classified.Code
+	}
+
+	if errors.Is(err, ssrf.ErrBlocked) {
+		return ErrorCodeBlocked
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		switch {
+		case dnsErr.IsNotFound:
+			return ErrorCodeDNSNXDomain
+		case dnsErr.IsTimeout:
+			return ErrorCodeDNSTimeout
+		default:
+			return ErrorCodeDNSFailure
+		}
+	}
+
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorCodeConnRefused
+	case errors.Is(err, syscall.ECONNRESET):
+		return ErrorCodeConnReset
+	case errors.Is(err, syscall.EHOSTUNREACH), errors.Is(err, syscall.ENETUNREACH):
+		return ErrorCodeHostUnreachable
+	}
+
+	var (
+		invalidErr   x509.CertificateInvalidError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+	)
+	switch {
+	case errors.As(err, &invalidErr) && invalidErr.Reason == x509.Expired:
+		return ErrorCodeTLSExpired
+	case errors.As(err, &authorityErr):
+		return ErrorCodeTLSUntrusted
+	case errors.As(err, &hostnameErr):
+		return ErrorCodeTLSHostnameMismatch
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" && opErr.Timeout() {
+		return ErrorCodeConnTimeout
+	}
+
+	return ClassifyError(err).Code()
+}
+
+// ClassifyStatus returns the class of an unsuccessful HTTP status code, or
+// an empty class for 1xx-3xx.
+func ClassifyStatus(status int) ErrorClass {
+	switch {
+	case status >= 500:
+		return ErrorClassHTTP5xx
+	case status >= 400:
+		return ErrorClassHTTP4xx
+	default:
+		return ""
+	}
+}
+
+// ErrorClass returns the class of an unsuccessful response, the one of its
+// Failure when set. A response without status code comes from a timed out
+// request, and a failing response with a successful status code failed one
+// of its assertions.
+func (r Response) ErrorClass() ErrorClass {
+	if r.Failure != nil {
+		return ClassifyError(r.Failure)
+	}
+
+	if r.Status == 0 && r.Error != "" {
+		return ErrorClassTimeout
+	}
+
+	if class := ClassifyStatus(r.Status); class != "" {
+		return class
+	}
+
+	return ErrorClassAssertion
+}
+
+// ErrorCode returns the code of an unsuccessful response.
+func (r Response) ErrorCode() ErrorCode {
+	if r.Failure != nil {
+		return ClassifyCode(r.Failure)
+	}
+
+	return r.ErrorClass().Code()
+}
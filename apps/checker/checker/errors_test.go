@@ -0,0 +1,80 @@
+package checker_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		name string
+		want checker.ErrorClass
+	}{
+		{name: "nil", err: nil, want: ""},
+		{name: "dns", err: &url.Error{Op: "Get", Err: &net.DNSError{Err: "no such host", Name: "example.invalid"}}, want: checker.ErrorClassDNS},
+		{name: "refused", err: &net.OpError{Op: "dial", Err: fmt.Errorf("connect: %w", syscall.ECONNREFUSED)}, want: checker.ErrorClassConnectionRefused},
+		{name: "deadline", err: fmt.Errorf("unable to ping: %w", context.DeadlineExceeded), want: checker.ErrorClassTimeout},
+		{name: "certificate", err: &url.Error{Op: "Get", Err: x509.UnknownAuthorityError{}}, want: checker.ErrorClassTLS},
+		{name: "classified", err: fmt.Errorf("unable to check tcp %w", &checker.ClassifiedError{Class: checker.ErrorClassTimeout, Err: errors.New("timeout")}), want: checker.ErrorClassTimeout},
+		{name: "unknown", err: errors.New("boom"), want: checker.ErrorClassUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, checker.ClassifyError(tt.err))
+		})
+	}
+}
+
+func TestResponseErrorClass(t *testing.T) {
+	assert.Equal(t, checker.ErrorClassTimeout, checker.Response{Error: "Timeout after 10 ms"}.ErrorClass())
+	assert.Equal(t, checker.ErrorClassHTTP5xx, checker.Response{Status: 503}.ErrorClass())
+	assert.Equal(t, checker.ErrorClassHTTP4xx, checker.Response{Status: 404}.ErrorClass())
+	assert.Equal(t, checker.ErrorClassAssertion, checker.Response{Status: 200}.ErrorClass())
+
+	revoked := checker.Response{Status: 200, Failure: (&checker.Revocation{Status: checker.RevocationRevoked}).Err()}
+	assert.Equal(t, checker.ErrorClassTLS, revoked.ErrorClass())
+	assert.Equal(t, checker.ErrorCodeTLSRevoked, revoked.ErrorCode())
+}
+
+func TestClassifyCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		name string
+		want checker.ErrorCode
+	}{
+		{name: "nil", err: nil, want: ""},
+		{name: "nxdomain", err: &url.Error{Op: "Get", Err: &net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}}, want: checker.ErrorCodeDNSNXDomain},
+		{name: "dns timeout", err: &net.DNSError{Err: "i/o timeout", IsTimeout: true}, want: checker.ErrorCodeDNSTimeout},
+		{name: "servfail", err: &net.DNSError{Err: "server misbehaving"}, want: checker.ErrorCodeDNSFailure},
+		{name: "refused", err: &net.OpError{Op: "dial", Err: fmt.Errorf("connect: %w", syscall.ECONNREFUSED)}, want: checker.ErrorCodeConnRefused},
+		{name: "reset", err: &net.OpError{Op: "read", Err: fmt.Errorf("read: %w", syscall.ECONNRESET)}, want: checker.ErrorCodeConnReset},
+		{name: "expired", err: &url.Error{Op: "Get", Err: &tls.CertificateVerificationError{Err: x509.CertificateInvalidError{Reason: x509.Expired}}}, want: checker.ErrorCodeTLSExpired},
+		{name: "untrusted", err: &url.Error{Op: "Get", Err: x509.UnknownAuthorityError{}}, want: checker.ErrorCodeTLSUntrusted},
+		{name: "hostname", err: x509.HostnameError{Host: "openstat.us"}, want: checker.ErrorCodeTLSHostnameMismatch},
+		{name: "deadline", err: fmt.Errorf("unable to ping: %w", context.DeadlineExceeded), want: checker.ErrorCodeTimeout},
+		{name: "classified", err: &checker.ClassifiedError{Class: checker.ErrorClassTimeout, Code: checker.ErrorCodeConnTimeout, Err: errors.New("timeout after 10 ms")}, want: checker.ErrorCodeConnTimeout},
+		{name: "class only", err: &checker.ClassifiedError{Class: checker.ErrorClassAssertion, Err: errors.New("assertion failed")}, want: checker.ErrorCodeAssertionFailed},
+		{name: "unknown", err: errors.New("boom"), want: checker.ErrorCodeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, checker.ClassifyCode(tt.err))
+		})
+	}
+
+	assert.Equal(t, checker.ErrorCodeHTTP5xx, checker.Response{Status: 503}.ErrorCode())
+}
@@ -0,0 +1,183 @@
+package checker
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// HAR is an HTTP Archive 1.2 log of an HTTP check, which browser devtools
+// and HAR analyzers import as is.
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HAREntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	Time            int64       `json:"time"`
+}
+
+type HARRequest struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	HTTPVersion string      `json:"httpVersion"`
+	PostData    *HARContent `json:"postData,omitempty"`
+	Headers     []HARPair   `json:"headers"`
+	QueryString []HARPair   `json:"queryString"`
+	Cookies     []HARPair   `json:"cookies"`
+	HeadersSize int64       `json:"headersSize"`
+	BodySize    int64       `json:"bodySize"`
+}
+
+type HARResponse struct {
+	StatusText  string     `json:"statusText"`
+	HTTPVersion string     `json:"httpVersion"`
+	RedirectURL string     `json:"redirectURL"`
+	Content     HARContent `json:"content"`
+	Headers     []HARPair  `json:"headers"`
+	Cookies     []HARPair  `json:"cookies"`
+	Status      int        `json:"status"`
+	HeadersSize int64      `json:"headersSize"`
+	BodySize    int64      `json:"bodySize"`
+}
+
+type HARPair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARContent struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Size     int64  `json:"size"`
+}
+
+// HARTimings are in milliseconds, -1 for the phases the check did not go
+// through. Connect includes SSL as the spec requires.
+type HARTimings struct {
+	Blocked int64 `json:"blocked"`
+	DNS     int64 `json:"dns"`
+	Connect int64 `json:"connect"`
+	SSL     int64 `json:"ssl"`
+	Send    int64 `json:"send"`
+	Wait    int64 `json:"wait"`
+	Receive int64 `json:"receive"`
+}
+
+// NewHAR records the check of input that answered res. The sizes of the
+// headers are unknown, -1 as the spec requires.
+func NewHAR(input request.HttpCheckerRequest, res Response) *HAR {
+	entry := HAREntry{
+		StartedDateTime: time.UnixMilli(res.Timestamp).UTC().Format(time.RFC3339Nano),
+		ServerIPAddress: res.RemoteIP,
+		Time:            res.Latency,
+		Request: HARRequest{
+			Method:      input.Method,
+			URL:         input.URL,
+			HTTPVersion: "HTTP/1.1",
+			Headers:     []HARPair{},
+			QueryString: []HARPair{},
+			Cookies:     []HARPair{},
+			HeadersSize: -1,
+			BodySize:    int64(len(input.Body)),
+		},
+		Response: HARResponse{
+			Status:      res.Status,
+			HTTPVersion: "HTTP/1.1",
+			Headers:     []HARPair{},
+			Cookies:     []HARPair{},
+			HeadersSize: -1,
+			BodySize:    int64(len(res.Body)),
+			RedirectURL: res.Headers["Location"],
+			Content: HARContent{
+				MimeType: res.Headers["Content-Type"],
+				Text:     res.Body,
+				Size:     int64(len(res.Body)),
+			},
+		},
+		Timings: HARTimings{
+			Blocked: -1,
+			DNS:     phase(res.Timing.DnsStart, res.Timing.DnsDone),
+			Connect: phase(res.Timing.ConnectStart, max(res.Timing.ConnectDone, res.Timing.TlsHandshakeDone)),
+			SSL:     phase(res.Timing.TlsHandshakeStart, res.Timing.TlsHandshakeDone),
+			Wait:    max(0, res.Timing.FirstByteDone-res.Timing.FirstByteStart),
+			Receive: max(0, res.Timing.TransferDone-res.Timing.TransferStart),
+		},
+	}
+
+	if res.Proto != "" {
+		entry.Response.HTTPVersion = res.Proto
+		entry.Request.HTTPVersion = res.Proto
+	}
+	if res.Status != 0 {
+		entry.Response.StatusText = http.StatusText(res.Status)
+	}
+	for _, h := range input.Headers {
+		if h.Key != "" {
+			entry.Request.Headers = append(entry.Request.Headers, HARPair{Name: h.Key, Value: h.Value})
+		}
+	}
+	if u, err := url.Parse(input.URL); err == nil {
+		for key, values := range u.Query() {
+			for _, value := range values {
+				entry.Request.QueryString = append(entry.Request.QueryString, HARPair{Name: key, Value: value})
+			}
+		}
+		slices.SortFunc(entry.Request.QueryString, comparePairs)
+	}
+	if input.Body != "" {
+		entry.Request.PostData = &HARContent{Text: input.Body, Size: int64(len(input.Body))}
+		for _, h := range input.Headers {
+			if http.CanonicalHeaderKey(h.Key) == "Content-Type" {
+				entry.Request.PostData.MimeType = h.Value
+			}
+		}
+	}
+	for name, value := range res.Headers {
+		entry.Response.Headers = append(entry.Response.Headers, HARPair{Name: name, Value: value})
+	}
+	slices.SortFunc(entry.Response.Headers, comparePairs)
+
+	return &HAR{Log: HARLog{
+		Version: "1.2",
+		Creator: HARCreator{Name: "OpenStatus", Version: "1.0"},
+		Entries: []HAREntry{entry},
+	}}
+}
+
+// phase is the duration between two timestamps, -1 when it did not happen.
+func phase(start, done int64) int64 {
+	if start == 0 || done == 0 {
+		return -1
+	}
+
+	return max(0, done-start)
+}
+
+func comparePairs(a, b HARPair) int {
+	if a.Name != b.Name {
+		return strings.Compare(a.Name, b.Name)
+	}
+
+	return strings.Compare(a.Value, b.Value)
+}
@@ -0,0 +1,50 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+)
+
+// HoldResult is the outcome of holding an idle connection open.
+type HoldResult struct {
+	// HeldMs is how long the connection stayed open.
+	HeldMs  int64 `json:"heldMs"`
+	Dropped bool  `json:"dropped"`
+	// Reason is how the connection was dropped, closed by the peer or reset.
+	Reason string `json:"reason,omitempty"`
+}
+
+// holdConn reads from conn until it is dropped or d elapses, discarding
+// what the peer sends, e.g. a banner. It is aborted as soon as ctx is done.
+func holdConn(ctx context.Context, conn net.Conn, d time.Duration) (*HoldResult, error) {
+	start := time.Now()
+	_ = conn.SetDeadline(start.Add(d))
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	buf := make([]byte, 4096)
+	for {
+		_, err := conn.Read(buf)
+		if err == nil {
+			continue
+		}
+		held := time.Since(start).Milliseconds()
+		if cerr := context.Cause(ctx); cerr != nil {
+			return nil, fmt.Errorf("hold aborted: %w", cerr)
+		}
+
+		var netErr net.Error
+		switch {
+		case errors.As(err, &netErr) && netErr.Timeout():
+			return &HoldResult{HeldMs: held}, nil
+		case errors.Is(err, syscall.ECONNRESET):
+			return &HoldResult{HeldMs: held, Dropped: true, Reason: "reset"}, nil
+		default:
+			return &HoldResult{HeldMs: held, Dropped: true, Reason: "closed"}, nil
+		}
+	}
+}
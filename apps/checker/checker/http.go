@@ -3,17 +3,23 @@ package checker
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/http/httptrace"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/openstatushq/openstatus/apps/checker/pkg/geoip"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/templating"
 	"github.com/openstatushq/openstatus/apps/checker/request"
 	"github.com/rs/zerolog/log"
 )
@@ -29,6 +35,10 @@ type Timing struct {
 	FirstByteDone     int64 `json:"firstByteDone"`
 	TransferStart     int64 `json:"transferStart"`
 	TransferDone      int64 `json:"transferDone"`
+	// The proxy phase lasts from the connection to the proxy until the
+	// tunnel to the target is ready, only for proxied checks.
+	ProxyConnectStart int64 `json:"proxyConnectStart,omitempty"`
+	ProxyConnectDone  int64 `json:"proxyConnectDone,omitempty"`
 }
 
 type Response struct {
@@ -41,6 +51,88 @@ type Response struct {
 	Timestamp int64             `json:"timestamp"`
 	Status    int               `json:"status,omitempty"`
 	Timing    Timing            `json:"timing"`
+	Attempts  []Attempt         `json:"attempts,omitempty"`
+	// Addresses holds the result of each address of the host, when they
+	// are all checked.
+	Addresses []AddressResult `json:"addresses,omitempty"`
+	// DualStack is the race of IPv4 and IPv6 connections to the host, when
+	// asked for.
+	DualStack *DualStackResult `json:"dualStack,omitempty"`
+	// Conditional is the revalidation of the response, when asked for.
+	Conditional *ConditionalResult `json:"conditional,omitempty"`
+	// RemoteIP is the address the check connected to, of IPFamily. It is the
+	// address of the proxy for proxied checks.
+	RemoteIP string `json:"remoteIp,omitempty"`
+	IPFamily string `json:"ipFamily,omitempty"`
+	// Geo is the network and location of RemoteIP, when known.
+	Geo *geoip.Info `json:"geo,omitempty"`
+	// Redirects is the chain of responses from the URL of the check to the
+	// final one, when a redirect was followed.
+	Redirects []RedirectHop `json:"redirects,omitempty"`
+	// Proto is the protocol of the response, HTTP/1.1 or HTTP/2.0.
+	Proto string `json:"proto,omitempty"`
+	// HAR records the check when asked for with the har verbosity.
+	HAR *HAR `json:"har,omitempty"`
+	// TLS describes the connection of the final response, for HTTPS.
+	TLS *TLSInfo `json:"tls,omitempty"`
+	// CapturedHeaders are the headers of the response the check asked to
+	// keep, by lower case name, whatever the verbosity.
+	CapturedHeaders map[string]string `json:"capturedHeaders,omitempty"`
+	// CacheStatus is the normalized cache status of the CDN in front of the
+	// target, e.g. HIT or MISS, empty without one.
+	CacheStatus string `json:"cacheStatus,omitempty"`
+	// BodyHash is the hex SHA-256 of the body, hashed as it is read, to
+	// tell when the content of the target changes.
+	BodyHash string `json:"bodyHash,omitempty"`
+	// SecurityHeaders grades the security headers of the response, when
+	// asked for.
+	SecurityHeaders *SecurityHeaders `json:"securityHeaders,omitempty"`
+	// Truncated is set when the body was longer than the limit of the check,
+	// Body and BodyHash being those of its start.
+	Truncated bool `json:"truncated,omitempty"`
+	// Failure is the error of a check failing past its response, e.g. its
+	// certificate being revoked, whose class and code are those of Error.
+	Failure error `json:"-"`
+}
+
+// CaptureHeaders returns the headers named by names, by lower case name,
+// nil when none of them is in the response.
+func CaptureHeaders(headers map[string]string, names []string) map[string]string {
+	var captured map[string]string
+	for _, name := range names {
+		value, ok := headers[http.CanonicalHeaderKey(name)]
+		if !ok {
+			continue
+		}
+		if captured == nil {
+			captured = make(map[string]string, len(names))
+		}
+		captured[strings.ToLower(name)] = value
+	}
+
+	return captured
+}
+
+// render replaces the template variables of the URL, headers and body of a
+// check. It runs for every attempt, which each get fresh values.
+func render(input request.HttpCheckerRequest) (request.HttpCheckerRequest, error) {
+	vars := templating.New()
+
+	var err error
+	if input.URL, err = vars.Render(input.URL); err != nil {
+		return input, err
+	}
+	input.Headers = slices.Clone(input.Headers)
+	for i := range input.Headers {
+		if input.Headers[i].Value, err = vars.Render(input.Headers[i].Value); err != nil {
+			return input, err
+		}
+	}
+	if input.Body, err = vars.Render(input.Body); err != nil {
+		return input, err
+	}
+
+	return input, nil
 }
 
 // decodeBase64Body decodes a data URL base64 body if needed
@@ -56,6 +148,11 @@ func decodeBase64Body(body string) ([]byte, error) {
 func Http(ctx context.Context, client *http.Client, inputData request.HttpCheckerRequest) (Response, error) {
 	logger := log.Ctx(ctx).With().Str("monitor", inputData.URL).Logger()
 
+	inputData, err := render(inputData)
+	if err != nil {
+		return Response{}, fmt.Errorf("unable to render the check: %w", err)
+	}
+
 	var bodyBytes []byte
 	if inputData.Method == http.MethodPost {
 		contentType := ""
@@ -88,6 +185,14 @@ func Http(ctx context.Context, client *http.Client, inputData request.HttpChecke
 		if header.Key != "" {
 			req.Header.Set(header.Key, header.Value)
 		}
+		// net/http only sends req.Host, an override from the check wins over
+		// the host of the URL.
+		if strings.EqualFold(header.Key, "Host") {
+			req.Host = header.Value
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(inputData.Cookies)) {
+		req.AddCookie(&http.Cookie{Name: name, Value: inputData.Cookies[name]})
 	}
 
 	// Maybe we should remove the default post to application JSON
@@ -96,29 +201,51 @@ func Http(ctx context.Context, client *http.Client, inputData request.HttpChecke
 		req.Header.Set("Content-Type", "application/json")
 	}
 
+	clk := newClock()
 	timing := Timing{}
+	var remoteIP, ipFamily string
+
+	proxied := inputData.Proxy != nil
+	// tunnelReady ends the proxy phase at the TLS handshake with the target,
+	// or when the connection is handed over for plain HTTP targets.
+	tunnelReady := func() {
+		if proxied && timing.ProxyConnectDone == 0 {
+			timing.ProxyConnectDone = clk.now()
+		}
+	}
 
 	trace := &httptrace.ClientTrace{
-		DNSStart:          func(_ httptrace.DNSStartInfo) { timing.DnsStart = time.Now().UTC().UnixMilli() },
-		DNSDone:           func(_ httptrace.DNSDoneInfo) { timing.DnsDone = time.Now().UTC().UnixMilli() },
-		ConnectStart:      func(_, _ string) { timing.ConnectStart = time.Now().UTC().UnixMilli() },
-		ConnectDone:       func(_, _ string, _ error) { timing.ConnectDone = time.Now().UTC().UnixMilli() },
-		TLSHandshakeStart: func() { timing.TlsHandshakeStart = time.Now().UTC().UnixMilli() },
-		TLSHandshakeDone:  func(_ tls.ConnectionState, _ error) { timing.TlsHandshakeDone = time.Now().UTC().UnixMilli() },
-		GotConn: func(_ httptrace.GotConnInfo) {
-			timing.FirstByteStart = time.Now().UTC().UnixMilli()
+		DNSStart:     func(_ httptrace.DNSStartInfo) { timing.DnsStart = clk.now() },
+		DNSDone:      func(_ httptrace.DNSDoneInfo) { timing.DnsDone = clk.now() },
+		ConnectStart: func(_, _ string) { timing.ConnectStart = clk.now() },
+		ConnectDone: func(_, _ string, _ error) {
+			timing.ConnectDone = clk.now()
+			if proxied {
+				timing.ProxyConnectStart = timing.ConnectDone
+			}
+		},
+		TLSHandshakeStart: func() {
+			tunnelReady()
+			timing.TlsHandshakeStart = clk.now()
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, _ error) { timing.TlsHandshakeDone = clk.now() },
+		GotConn: func(info httptrace.GotConnInfo) {
+			remoteIP, ipFamily = RemoteIP(info.Conn.RemoteAddr())
+			tunnelReady()
+			timing.FirstByteStart = clk.now()
 		},
 		GotFirstResponseByte: func() {
-			timing.FirstByteDone = time.Now().UTC().UnixMilli()
-			timing.TransferStart = time.Now().UTC().UnixMilli()
+			timing.FirstByteDone = clk.now()
+			timing.TransferStart = clk.now()
 		},
 	}
 
 	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
 
-	start := time.Now()
+	start := clk.start
 
-	response, err := client.Do(req)
+	redirects := &redirectChain{hopStart: start}
+	response, err := redirects.follow(client).Do(req)
 	latency := time.Since(start).Milliseconds()
 
 	if err != nil {
@@ -130,6 +257,8 @@ func Http(ctx context.Context, client *http.Client, inputData request.HttpChecke
 				Timing:    timing,
 				Timestamp: start.UTC().UnixMilli(),
 				Error:     fmt.Sprintf("Timeout after %d ms", latency),
+				RemoteIP:  remoteIP,
+				IPFamily:  ipFamily,
 			}, nil
 		}
 
@@ -140,9 +269,17 @@ func Http(ctx context.Context, client *http.Client, inputData request.HttpChecke
 
 	defer response.Body.Close()
 
-	body, err := io.ReadAll(response.Body)
+	hash := sha256.New()
+	body, err := io.ReadAll(io.TeeReader(io.LimitReader(response.Body, request.BodyLimit(inputData.MaxBodyBytes)), hash))
+	truncated := false
+	if err == nil {
+		// One more byte tells a body of exactly the limit from a longer one.
+		var b [1]byte
+		n, _ := io.ReadFull(response.Body, b[:])
+		truncated = n > 0
+	}
 
-	timing.TransferDone = time.Now().UTC().UnixMilli()
+	timing.TransferDone = clk.now()
 
 	if err != nil {
 		return Response{
@@ -153,18 +290,34 @@ func Http(ctx context.Context, client *http.Client, inputData request.HttpChecke
 		}, err
 	}
 
+	var tlsInfo *TLSInfo
+	if response.TLS != nil {
+		tlsInfo = NewTLSInfo(*response.TLS)
+		if inputData.Revocation != "" {
+			tlsInfo.Revocation = CheckRevocation(ctx, client, *response.TLS)
+		}
+	}
+
 	headers := make(map[string]string)
 	for key := range response.Header {
 		headers[key] = response.Header.Get(key)
 	}
 
 	return Response{
-		Timestamp: start.UTC().UnixMilli(),
-		Status:    response.StatusCode,
-		Headers:   headers,
-		Timing:    timing,
-		Latency:   latency,
-		Body:      string(body),
+		Timestamp:   start.UTC().UnixMilli(),
+		Status:      response.StatusCode,
+		Headers:     headers,
+		Timing:      timing,
+		Latency:     latency,
+		Body:        string(body),
+		RemoteIP:    remoteIP,
+		IPFamily:    ipFamily,
+		Redirects:   redirects.end(response),
+		Proto:       response.Proto,
+		TLS:         tlsInfo,
+		CacheStatus: CacheStatus(headers),
+		BodyHash:    hex.EncodeToString(hash.Sum(nil)),
+		Truncated:   truncated,
 	}, nil
 
 }
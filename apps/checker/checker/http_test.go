@@ -3,11 +3,14 @@ package checker_test
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/openstatushq/openstatus/apps/checker/checker"
 	"github.com/openstatushq/openstatus/apps/checker/request"
@@ -94,3 +97,175 @@ func Test_ping(t *testing.T) {
 		})
 	}
 }
+
+func TestHttp_Proxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "http://openstat.us/", r.URL.String(), "the proxy receives the absolute URL")
+		assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("user:pass")), r.Header.Get("Proxy-Authorization"))
+		_, _ = w.Write([]byte("proxied"))
+	}))
+	defer proxy.Close()
+
+	p := &request.Proxy{URL: proxy.URL, Username: "user", Password: "pass"}
+	proxyURL, err := p.ProxyURL()
+	require.NoError(t, err)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	got, err := checker.Http(t.Context(), client, request.HttpCheckerRequest{URL: "http://openstat.us/", Method: http.MethodGet, Proxy: p})
+	require.NoError(t, err)
+	assert.Equal(t, "proxied", got.Body)
+	assert.Equal(t, "b09f17ea1b5ca77b7a01a3ed62c84b38578817eddb34f827666c898a27504f67", got.BodyHash, "the SHA-256 of the body")
+	assert.NotZero(t, got.Timing.ProxyConnectStart)
+	assert.NotZero(t, got.Timing.ProxyConnectDone)
+	assert.Equal(t, "127.0.0.1", got.RemoteIP, "the address of the proxy")
+	assert.Equal(t, request.IPFamilyIPv4, got.IPFamily)
+}
+
+func TestHttp_Redirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			http.Redirect(w, r, "/b", http.StatusMovedPermanently)
+		case "/b":
+			http.Redirect(w, r, "/c", http.StatusFound)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	check := func(path string, follow bool, max int) checker.Response {
+		client := &http.Client{CheckRedirect: checker.CheckRedirect(follow, max)}
+		got, err := checker.Http(t.Context(), client, request.HttpCheckerRequest{URL: server.URL + path, Method: http.MethodGet})
+		require.NoError(t, err)
+		return got
+	}
+
+	t.Run("chain", func(t *testing.T) {
+		got := check("/a", true, 0)
+		assert.Equal(t, http.StatusOK, got.Status)
+		if assert.Len(t, got.Redirects, 3) {
+			assert.Equal(t, server.URL+"/a", got.Redirects[0].URL)
+			assert.Equal(t, http.StatusMovedPermanently, got.Redirects[0].Status)
+			assert.Equal(t, http.StatusFound, got.Redirects[1].Status)
+			assert.Equal(t, server.URL+"/c", got.Redirects[2].URL)
+			assert.Equal(t, http.StatusOK, got.Redirects[2].Status)
+		}
+	})
+
+	t.Run("not followed", func(t *testing.T) {
+		got := check("/a", false, 0)
+		assert.Equal(t, http.StatusMovedPermanently, got.Status)
+		assert.Empty(t, got.Redirects)
+	})
+
+	t.Run("loop", func(t *testing.T) {
+		got := check("/loop", true, 3)
+		assert.Equal(t, http.StatusFound, got.Status)
+		assert.Len(t, got.Redirects, 4, "3 redirects followed and the response kept")
+	})
+}
+
+func TestNewHAR(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	input := request.HttpCheckerRequest{URL: server.URL + "/?b=2&a=1", Method: http.MethodPost, Body: "{}"}
+	input.Headers = append(input.Headers, struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	}{Key: "Content-Type", Value: "application/json"})
+
+	res, err := checker.Http(t.Context(), &http.Client{}, input)
+	require.NoError(t, err)
+
+	har := checker.NewHAR(input, res)
+	require.Len(t, har.Log.Entries, 1)
+	entry := har.Log.Entries[0]
+	assert.Equal(t, "1.2", har.Log.Version)
+	assert.Equal(t, "127.0.0.1", entry.ServerIPAddress)
+	assert.Equal(t, []checker.HARPair{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}}, entry.Request.QueryString)
+	if assert.NotNil(t, entry.Request.PostData) {
+		assert.Equal(t, "application/json", entry.Request.PostData.MimeType)
+	}
+	assert.Equal(t, http.StatusOK, entry.Response.Status)
+	assert.Equal(t, "OK", entry.Response.StatusText)
+	assert.Equal(t, "HTTP/1.1", entry.Response.HTTPVersion)
+	assert.Equal(t, `{"ok":true}`, entry.Response.Content.Text)
+	assert.Equal(t, "application/json", entry.Response.Content.MimeType)
+	assert.Equal(t, int64(-1), entry.Timings.SSL, "plain HTTP has no TLS handshake")
+	assert.GreaterOrEqual(t, entry.Timings.Connect, int64(0))
+}
+
+func TestHttp_Cookies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			if c, err := r.Cookie("consent"); err != nil || c.Value != "yes" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+			http.Redirect(w, r, "/home", http.StatusFound)
+		case "/home":
+			if c, err := r.Cookie("session"); err != nil || c.Value != "abc" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{CheckRedirect: checker.CheckRedirect(true, 0)}
+	got, err := checker.Http(t.Context(), client, request.HttpCheckerRequest{
+		URL: server.URL + "/login", Method: http.MethodGet, Cookies: map[string]string{"consent": "yes"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, got.Status, "the session cookie is kept across the redirect")
+}
+
+func TestHttp_MaxBodyBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(bytes.Repeat([]byte("a"), 2048))
+	}))
+	defer server.Close()
+
+	got, err := checker.Http(t.Context(), server.Client(), request.HttpCheckerRequest{URL: server.URL, Method: http.MethodGet, MaxBodyBytes: 1024})
+	require.NoError(t, err)
+	assert.Len(t, got.Body, 1024)
+	assert.True(t, got.Truncated)
+
+	got, err = checker.Http(t.Context(), server.Client(), request.HttpCheckerRequest{URL: server.URL, Method: http.MethodGet, MaxBodyBytes: 2048})
+	require.NoError(t, err)
+	assert.Len(t, got.Body, 2048)
+	assert.False(t, got.Truncated, "a body of exactly the limit is read whole")
+}
+
+func TestHttp_TLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	got, err := checker.Http(t.Context(), server.Client(), request.HttpCheckerRequest{URL: server.URL, Method: http.MethodGet})
+	require.NoError(t, err)
+	if assert.NotNil(t, got.TLS) {
+		assert.Equal(t, "TLS 1.3", got.TLS.Version)
+		assert.NotEmpty(t, got.TLS.CipherSuite)
+		assert.Equal(t, "h2", got.TLS.ALPN)
+	}
+}
+
+func TestCaptureHeaders(t *testing.T) {
+	headers := map[string]string{"Cf-Ray": "8a1b2c3d4e5f-CDG", "X-Request-Id": "42", "Content-Type": "text/plain"}
+
+	got := checker.CaptureHeaders(headers, []string{"x-request-id", "CF-RAY", "x-vercel-id"})
+	assert.Equal(t, map[string]string{"x-request-id": "42", "cf-ray": "8a1b2c3d4e5f-CDG"}, got)
+	assert.Nil(t, checker.CaptureHeaders(headers, []string{"x-vercel-id"}))
+}
@@ -0,0 +1,52 @@
+package checker
+
+import (
+	"net"
+	"net/netip"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// Network returns the network to dial for the IP family of a check.
+func Network(family string) string {
+	switch family {
+	case request.IPFamilyIPv4:
+		return "tcp4"
+	case request.IPFamilyIPv6:
+		return "tcp6"
+	default:
+		return "tcp"
+	}
+}
+
+// lookupNetwork returns the network to resolve for the IP family of a check.
+func lookupNetwork(family string) string {
+	switch family {
+	case request.IPFamilyIPv4:
+		return "ip4"
+	case request.IPFamilyIPv6:
+		return "ip6"
+	default:
+		return "ip"
+	}
+}
+
+// RemoteIP returns the literal IP of the address a check connected to, and
+// its family.
+func RemoteIP(addr net.Addr) (string, string) {
+	if addr == nil {
+		return "", ""
+	}
+
+	addrPort, err := netip.ParseAddrPort(addr.String())
+	if err != nil {
+		return "", ""
+	}
+
+	ip := addrPort.Addr().Unmap()
+	if ip.Is4() {
+		return ip.String(), request.IPFamilyIPv4
+	}
+
+	return ip.String(), request.IPFamilyIPv6
+}
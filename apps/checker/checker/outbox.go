@@ -0,0 +1,276 @@
+package checker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// DroppedUpdates counts the status updates given up on, served with the other
+// expvar metrics.
+var DroppedUpdates = expvar.NewInt("status_updates_dropped")
+
+// outboxMaxAge is how long an update is retried before it is dropped, an
+// alert that late being of little use.
+const outboxMaxAge = 24 * time.Hour
+
+type queuedUpdate struct {
+	Queued time.Time  `json:"queued"`
+	Data   UpdateData `json:"data"`
+	file   string
+}
+
+// Outbox delivers the status updates in order, retrying each of them with
+// backoff until the control plane accepts it, since a lost error transition
+// is a missed alert. Updates are kept in memory, and in dir when set so they
+// survive a restart. Once maxSize are pending the oldest one is dropped.
+type Outbox struct {
+	send            func(context.Context, UpdateData) error
+	now             func() time.Time
+	wake            chan struct{}
+	dir             string
+	pending         []*queuedUpdate
+	maxSize         int
+	initialInterval time.Duration
+	mu              sync.Mutex
+}
+
+// NewOutbox returns an outbox of at most maxSize updates, loading the ones
+// left in dir by a previous run. An empty dir keeps the updates in memory
+// only.
+func NewOutbox(dir string, maxSize int) (*Outbox, error) {
+	o := &Outbox{
+		send:            UpdateStatus,
+		now:             time.Now,
+		wake:            make(chan struct{}, 1),
+		dir:             dir,
+		maxSize:         maxSize,
+		initialInterval: backoff.DefaultInitialInterval,
+	}
+	if dir == "" {
+		return o, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("unable to create status queue: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read status queue: %w", err)
+	}
+	// The names sort in queue order.
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		file := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read status queue: %w", err)
+		}
+		u := &queuedUpdate{file: file}
+		if err := json.Unmarshal(data, u); err != nil {
+			log.Warn().Err(err).Str("file", file).Msg("dropping unreadable status update")
+			_ = os.Remove(file)
+			DroppedUpdates.Add(1)
+			continue
+		}
+		o.pending = append(o.pending, u)
+	}
+	for len(o.pending) > o.maxSize {
+		o.drop(o.pending[0])
+	}
+
+	return o, nil
+}
+
+// SendWith replaces how the updates are delivered, UpdateStatus by default,
+// e.g. with the in-memory stub of development. It must be called before Run.
+func (o *Outbox) SendWith(send func(context.Context, UpdateData) error) {
+	o.send = send
+}
+
+// Add queues an update, its delivery being retried by Run. It replaces the
+// pending update of the same monitor and region, only the last status of a
+// monitor mattering once the control plane is reachable again, so an outage
+// does not pile up an update per monitor and tick.
+func (o *Outbox) Add(data UpdateData) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// The head may be being delivered.
+	for i := len(o.pending) - 1; i > 0; i-- {
+		if u := o.pending[i]; u.Data.MonitorId == data.MonitorId && u.Data.Region == data.Region {
+			o.remove(u)
+		}
+	}
+
+	if len(o.pending) >= o.maxSize {
+		log.Warn().Str("monitorId", o.pending[0].Data.MonitorId).Msg("status queue full, dropping its oldest update")
+		o.drop(o.pending[0])
+	}
+
+	u := &queuedUpdate{Queued: o.now(), Data: data}
+	if o.dir != "" {
+		if err := o.persist(u); err != nil {
+			log.Error().Err(err).Msg("failed to persist status update, keeping it in memory")
+		}
+	}
+	o.pending = append(o.pending, u)
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// persist writes u to a new file of dir, o.mu must be held.
+func (o *Outbox) persist(u *queuedUpdate) error {
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+
+	// Add may be called twice within the same nanosecond.
+	name := fmt.Sprintf("%020d", u.Queued.UnixNano())
+	for i := 0; ; i++ {
+		file := filepath.Join(o.dir, fmt.Sprintf("%s-%04d.json", name, i))
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			_ = os.Remove(file)
+			return err
+		}
+		u.file = file
+
+		return nil
+	}
+}
+
+// drop gives up on u, o.mu must be held.
+func (o *Outbox) drop(u *queuedUpdate) {
+	o.remove(u)
+	DroppedUpdates.Add(1)
+}
+
+// remove takes u off the queue, o.mu must be held.
+func (o *Outbox) remove(u *queuedUpdate) {
+	i := slices.Index(o.pending, u)
+	if i < 0 {
+		return
+	}
+	o.pending = slices.Delete(o.pending, i, i+1)
+	if u.file != "" {
+		_ = os.Remove(u.file)
+	}
+}
+
+func (o *Outbox) head() (*queuedUpdate, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.pending) == 0 {
+		return nil, false
+	}
+
+	return o.pending[0], true
+}
+
+// Len returns the number of updates not delivered yet.
+func (o *Outbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return len(o.pending)
+}
+
+// Flush delivers the queued updates once Run returned, e.g. on shutdown,
+// until one fails or ctx is done. The undelivered ones are left in the queue.
+func (o *Outbox) Flush(ctx context.Context) error {
+	for ctx.Err() == nil {
+		u, ok := o.head()
+		if !ok {
+			return nil
+		}
+		if err := o.send(ctx, u.Data); err != nil {
+			return err
+		}
+
+		o.mu.Lock()
+		o.remove(u)
+		o.mu.Unlock()
+	}
+
+	return ctx.Err()
+}
+
+// Run delivers the queued updates until ctx is done.
+func (o *Outbox) Run(ctx context.Context) {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = o.initialInterval
+	b.MaxInterval = time.Minute
+
+	for {
+		u, ok := o.head()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-o.wake:
+				continue
+			}
+		}
+
+		if o.now().Sub(u.Queued) > outboxMaxAge {
+			log.Ctx(ctx).Error().Str("monitorId", u.Data.MonitorId).Msg("status update undelivered for too long, dropping it")
+			o.mu.Lock()
+			o.drop(u)
+			o.mu.Unlock()
+			continue
+		}
+
+		var permanent *backoff.PermanentError
+		err := o.send(ctx, u.Data)
+		if errors.As(err, &permanent) {
+			log.Ctx(ctx).Error().Err(err).Str("monitorId", u.Data.MonitorId).Msg("status update rejected, dropping it")
+			o.mu.Lock()
+			o.drop(u)
+			o.mu.Unlock()
+			continue
+		}
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("monitorId", u.Data.MonitorId).Msg("failed to deliver status update, retrying")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(b.NextBackOff()):
+			}
+			continue
+		}
+
+		b.Reset()
+		o.mu.Lock()
+		o.remove(u)
+		o.mu.Unlock()
+	}
+}
@@ -0,0 +1,153 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutbox(t *testing.T) {
+	t.Run("retries until delivered", func(t *testing.T) {
+		o, err := NewOutbox("", 10)
+		require.NoError(t, err)
+		o.initialInterval = time.Millisecond
+
+		var mu sync.Mutex
+		var delivered []string
+		failures := 2
+		o.send = func(_ context.Context, data UpdateData) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if failures > 0 {
+				failures--
+				return errors.New("unavailable")
+			}
+			delivered = append(delivered, data.MonitorId)
+			return nil
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go o.Run(ctx)
+
+		o.Add(UpdateData{MonitorId: "1", Status: "error"})
+		o.Add(UpdateData{MonitorId: "2", Status: "error"})
+
+		assert.Eventually(t, func() bool { return o.Len() == 0 }, time.Second, time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, []string{"1", "2"}, delivered)
+	})
+
+	t.Run("survives a restart", func(t *testing.T) {
+		dir := t.TempDir()
+		o, err := NewOutbox(dir, 10)
+		require.NoError(t, err)
+		o.Add(UpdateData{MonitorId: "1", Status: "error"})
+		o.Add(UpdateData{MonitorId: "2", Status: "active"})
+
+		restarted, err := NewOutbox(dir, 10)
+		require.NoError(t, err)
+		require.Equal(t, 2, restarted.Len())
+
+		var delivered []UpdateData
+		restarted.send = func(_ context.Context, data UpdateData) error {
+			delivered = append(delivered, data)
+			return nil
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go restarted.Run(ctx)
+
+		assert.Eventually(t, func() bool { return restarted.Len() == 0 }, time.Second, time.Millisecond)
+		assert.Equal(t, []UpdateData{{MonitorId: "1", Status: "error"}, {MonitorId: "2", Status: "active"}}, delivered)
+
+		empty, err := NewOutbox(dir, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 0, empty.Len())
+	})
+
+	t.Run("keeps the last update of a monitor", func(t *testing.T) {
+		dir := t.TempDir()
+		o, err := NewOutbox(dir, 10)
+		require.NoError(t, err)
+
+		o.Add(UpdateData{MonitorId: "1", Region: "ams", Status: "error"})
+		o.Add(UpdateData{MonitorId: "1", Region: "ams", Status: "active"})
+		o.Add(UpdateData{MonitorId: "1", Region: "iad", Status: "error"})
+		o.Add(UpdateData{MonitorId: "2", Region: "ams", Status: "error"})
+		o.Add(UpdateData{MonitorId: "1", Region: "ams", Status: "degraded"})
+		o.Add(UpdateData{MonitorId: "1", Region: "ams", Status: "error"})
+
+		var statuses []string
+		for _, u := range o.pending {
+			statuses = append(statuses, u.Data.MonitorId+"/"+u.Data.Region+"/"+u.Data.Status)
+		}
+		// The first update is kept, it may be being delivered.
+		assert.Equal(t, []string{"1/ams/error", "1/iad/error", "2/ams/error", "1/ams/error"}, statuses)
+
+		restarted, err := NewOutbox(dir, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 4, restarted.Len(), "the replaced updates are removed from the queue directory")
+	})
+
+	t.Run("drops the oldest update when full", func(t *testing.T) {
+		dropped := DroppedUpdates.Value()
+		o, err := NewOutbox(t.TempDir(), 2)
+		require.NoError(t, err)
+
+		o.Add(UpdateData{MonitorId: "1"})
+		o.Add(UpdateData{MonitorId: "2"})
+		o.Add(UpdateData{MonitorId: "3"})
+
+		require.Equal(t, 2, o.Len())
+		assert.Equal(t, "2", o.pending[0].Data.MonitorId)
+		assert.Equal(t, dropped+1, DroppedUpdates.Value())
+	})
+
+	t.Run("drops stale updates", func(t *testing.T) {
+		dropped := DroppedUpdates.Value()
+		o, err := NewOutbox("", 10)
+		require.NoError(t, err)
+		o.send = func(context.Context, UpdateData) error {
+			t.Error("stale update sent")
+			return nil
+		}
+
+		o.Add(UpdateData{MonitorId: "1"})
+		o.now = func() time.Time { return time.Now().Add(outboxMaxAge + time.Minute) }
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go o.Run(ctx)
+
+		assert.Eventually(t, func() bool { return o.Len() == 0 }, time.Second, time.Millisecond)
+		assert.Equal(t, dropped+1, DroppedUpdates.Value())
+	})
+
+	t.Run("flushes on shutdown", func(t *testing.T) {
+		o, err := NewOutbox("", 10)
+		require.NoError(t, err)
+		o.Add(UpdateData{MonitorId: "1"})
+		o.Add(UpdateData{MonitorId: "2"})
+		o.Add(UpdateData{MonitorId: "3"})
+
+		var delivered []string
+		o.send = func(_ context.Context, data UpdateData) error {
+			if data.MonitorId == "3" {
+				return errors.New("unavailable")
+			}
+			delivered = append(delivered, data.MonitorId)
+			return nil
+		}
+
+		assert.EqualError(t, o.Flush(t.Context()), "unavailable")
+		assert.Equal(t, []string{"1", "2"}, delivered)
+		assert.Equal(t, 1, o.Len(), "undelivered updates are kept")
+	})
+}
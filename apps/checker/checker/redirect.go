@@ -0,0 +1,96 @@
+package checker
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"time"
+)
+
+// DefaultMaxRedirects is the number of redirects followed by a check that
+// does not set its own limit, the default of net/http.
+const DefaultMaxRedirects = 10
+
+// RedirectHop is a response of the redirect chain of an HTTP check, its
+// Latency runs from the request to the response of the hop.
+type RedirectHop struct {
+	URL     string `json:"url"`
+	Status  int    `json:"status"`
+	Latency int64  `json:"latency"`
+}
+
+// CheckRedirect returns the redirect policy of an HTTP check. Without follow
+// the first response is kept, otherwise up to max redirects are followed,
+// DefaultMaxRedirects when max is 0, and the last response is kept.
+func CheckRedirect(follow bool, max int) func(*http.Request, []*http.Request) error {
+	if max <= 0 {
+		max = DefaultMaxRedirects
+	}
+
+	return func(_ *http.Request, via []*http.Request) error {
+		if !follow || len(via) > max {
+			return http.ErrUseLastResponse
+		}
+
+		return nil
+	}
+}
+
+// defaultCheckRedirect is the policy of a client without CheckRedirect.
+func defaultCheckRedirect(_ *http.Request, via []*http.Request) error {
+	if len(via) >= DefaultMaxRedirects {
+		return fmt.Errorf("stopped after %d redirects", DefaultMaxRedirects)
+	}
+
+	return nil
+}
+
+// redirectChain records the hops followed by client, the hop of the final
+// response is added by end.
+type redirectChain struct {
+	hopStart time.Time
+	hops     []RedirectHop
+}
+
+// follow returns a copy of client recording the redirects its policy follows.
+// Without a jar of its own, the copy keeps the cookies set along the chain
+// like a browser would, as login flows depend on them.
+func (r *redirectChain) follow(client *http.Client) *http.Client {
+	policy := client.CheckRedirect
+	if policy == nil {
+		policy = defaultCheckRedirect
+	}
+
+	c := *client
+	if c.Jar == nil {
+		// cookiejar.New only fails on options it is not given.
+		c.Jar, _ = cookiejar.New(nil)
+	}
+	c.CheckRedirect = func(next *http.Request, via []*http.Request) error {
+		err := policy(next, via)
+		if err == nil {
+			r.add(via[len(via)-1].URL.String(), next.Response.StatusCode)
+		}
+
+		return err
+	}
+
+	return &c
+}
+
+func (r *redirectChain) add(url string, status int) {
+	now := time.Now()
+	r.hops = append(r.hops, RedirectHop{URL: url, Status: status, Latency: now.Sub(r.hopStart).Milliseconds()})
+	r.hopStart = now
+}
+
+// end returns the chain ending with response, nil when no redirect was
+// followed.
+func (r *redirectChain) end(response *http.Response) []RedirectHop {
+	if len(r.hops) == 0 {
+		return nil
+	}
+	r.add(response.Request.URL.String(), response.StatusCode)
+
+	return r.hops
+}
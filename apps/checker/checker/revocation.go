@@ -0,0 +1,170 @@
+package checker
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// The OCSP statuses of a certificate.
+const (
+	RevocationGood    = "good"
+	RevocationRevoked = "revoked"
+	RevocationUnknown = "unknown"
+)
+
+// Revocation is the revocation status of the certificate of a TLS connection,
+// from the OCSP response stapled by the server or else from the responder of
+// the certificate.
+type Revocation struct {
+	// Status is one of the Revocation statuses, empty when it could not be
+	// checked, with the reason in Error.
+	Status string `json:"status,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// RevokedAt is when the certificate was revoked, in milliseconds.
+	RevokedAt int64 `json:"revokedAt,omitempty"`
+	Stapled   bool  `json:"stapled"`
+	// MustStaple is set when the certificate requires a stapled response.
+	MustStaple bool `json:"mustStaple,omitempty"`
+}
+
+// Err returns why the certificate should not be trusted: it is revoked, or
+// it requires a stapled response the server did not send.
+func (r *Revocation) Err() error {
+	switch {
+	case r == nil:
+		return nil
+	case r.Status == RevocationRevoked:
+		return &ClassifiedError{Class: ErrorClassTLS, Code: ErrorCodeTLSRevoked, Err: fmt.Errorf("certificate revoked: %s", r.Reason)}
+	case r.MustStaple && !r.Stapled:
+		return &ClassifiedError{Class: ErrorClassTLS, Err: errors.New("certificate requires OCSP stapling, none stapled")}
+	}
+
+	return nil
+}
+
+// maxOCSPResponse caps the body of an OCSP responder.
+const maxOCSPResponse = 64 << 10
+
+// oidTLSFeature is the TLS feature extension of RFC 7633, whose
+// status_request (5) feature is OCSP must-staple.
+var oidTLSFeature = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 24}
+
+// CheckRevocation checks the revocation status of the certificate of state,
+// asking its OCSP responder with client when the server stapled no response.
+func CheckRevocation(ctx context.Context, client *http.Client, state tls.ConnectionState) *Revocation {
+	if len(state.PeerCertificates) < 2 {
+		return &Revocation{Error: "no issuer certificate"}
+	}
+	leaf, issuer := state.PeerCertificates[0], state.PeerCertificates[1]
+	if len(state.VerifiedChains) > 0 && len(state.VerifiedChains[0]) > 1 {
+		issuer = state.VerifiedChains[0][1]
+	}
+
+	r := &Revocation{Stapled: len(state.OCSPResponse) > 0, MustStaple: mustStaple(leaf)}
+	raw := state.OCSPResponse
+	if !r.Stapled {
+		var err error
+		if raw, err = queryOCSP(ctx, client, leaf, issuer); err != nil {
+			r.Error = err.Error()
+			return r
+		}
+	}
+
+	res, err := ocsp.ParseResponseForCert(raw, leaf, issuer)
+	if err != nil {
+		r.Error = fmt.Sprintf("invalid OCSP response: %s", err)
+		return r
+	}
+	switch res.Status {
+	case ocsp.Good:
+		r.Status = RevocationGood
+	case ocsp.Revoked:
+		r.Status = RevocationRevoked
+		r.RevokedAt = res.RevokedAt.UTC().UnixMilli()
+		r.Reason = revocationReason(res.RevocationReason)
+	default:
+		r.Status = RevocationUnknown
+	}
+
+	return r
+}
+
+func queryOCSP(ctx context.Context, client *http.Client, leaf, issuer *x509.Certificate) ([]byte, error) {
+	if len(leaf.OCSPServer) == 0 {
+		return nil, errors.New("no OCSP responder")
+	}
+	body, err := ocsp.CreateRequest(leaf, issuer, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, leaf.OCSPServer[0], bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/ocsp-request")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("OCSP responder: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OCSP responder answered %d", res.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(res.Body, maxOCSPResponse))
+}
+
+func mustStaple(cert *x509.Certificate) bool {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidTLSFeature) {
+			continue
+		}
+		var features []int
+		if _, err := asn1.Unmarshal(ext.Value, &features); err != nil {
+			return false
+		}
+		for _, f := range features {
+			if f == 5 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+var revocationReasons = map[int]string{
+	ocsp.Unspecified:          "unspecified",
+	ocsp.KeyCompromise:        "key compromise",
+	ocsp.CACompromise:         "CA compromise",
+	ocsp.AffiliationChanged:   "affiliation changed",
+	ocsp.Superseded:           "superseded",
+	ocsp.CessationOfOperation: "cessation of operation",
+	ocsp.CertificateHold:      "certificate hold",
+	ocsp.RemoveFromCRL:        "remove from CRL",
+	ocsp.PrivilegeWithdrawn:   "privilege withdrawn",
+	ocsp.AACompromise:         "AA compromise",
+}
+
+func revocationReason(code int) string {
+	if reason, ok := revocationReasons[code]; ok {
+		return reason
+	}
+
+	return "unspecified"
+}
@@ -0,0 +1,143 @@
+package checker_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+)
+
+type pki struct {
+	issuer    *x509.Certificate
+	issuerKey crypto.Signer
+	leaf      *x509.Certificate
+}
+
+// newPKI issues a leaf certificate whose OCSP responder is responder, must
+// staple when mustStaple is set.
+func newPKI(t *testing.T, responder string, mustStaple bool) pki {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+
+	leafKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	leafTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "openstat.us"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		OCSPServer:   []string{responder},
+	}
+	if mustStaple {
+		value, err := asn1.Marshal([]int{5})
+		require.NoError(t, err)
+		leafTemplate.ExtraExtensions = []pkix.Extension{{Id: asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 24}, Value: value}}
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTemplate, ca, &leafKey.PublicKey, caKey)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(leafDER)
+	require.NoError(t, err)
+
+	return pki{issuer: ca, issuerKey: caKey, leaf: leaf}
+}
+
+func (p pki) response(t *testing.T, status int) []byte {
+	t.Helper()
+
+	res, err := ocsp.CreateResponse(p.issuer, p.issuer, ocsp.Response{
+		Status:           status,
+		SerialNumber:     p.leaf.SerialNumber,
+		ThisUpdate:       time.Now().Add(-time.Minute),
+		NextUpdate:       time.Now().Add(time.Hour),
+		RevokedAt:        time.Now().Add(-time.Minute),
+		RevocationReason: ocsp.KeyCompromise,
+	}, p.issuerKey)
+	require.NoError(t, err)
+
+	return res
+}
+
+func (p pki) state(stapled []byte) tls.ConnectionState {
+	return tls.ConnectionState{PeerCertificates: []*x509.Certificate{p.leaf, p.issuer}, OCSPResponse: stapled}
+}
+
+func TestCheckRevocation_Stapled(t *testing.T) {
+	p := newPKI(t, "http://ocsp.invalid", true)
+
+	r := checker.CheckRevocation(context.Background(), http.DefaultClient, p.state(p.response(t, ocsp.Good)))
+
+	assert.Equal(t, &checker.Revocation{Status: checker.RevocationGood, Stapled: true, MustStaple: true}, r)
+	assert.NoError(t, r.Err())
+}
+
+func TestCheckRevocation_Responder(t *testing.T) {
+	var p pki
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		req, err := ocsp.ParseRequest(body)
+		if assert.NoError(t, err) {
+			assert.Equal(t, p.leaf.SerialNumber, req.SerialNumber)
+		}
+		_, _ = w.Write(p.response(t, ocsp.Revoked))
+	}))
+	defer responder.Close()
+	p = newPKI(t, responder.URL, false)
+
+	r := checker.CheckRevocation(context.Background(), responder.Client(), p.state(nil))
+
+	assert.Equal(t, checker.RevocationRevoked, r.Status)
+	assert.Equal(t, "key compromise", r.Reason)
+	assert.NotZero(t, r.RevokedAt)
+	assert.False(t, r.Stapled)
+	assert.EqualError(t, r.Err(), "certificate revoked: key compromise")
+	assert.Equal(t, checker.ErrorClassTLS, checker.ClassifyError(r.Err()))
+}
+
+func TestCheckRevocation_MustStaple(t *testing.T) {
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer responder.Close()
+	p := newPKI(t, responder.URL, true)
+
+	r := checker.CheckRevocation(context.Background(), responder.Client(), p.state(nil))
+
+	assert.Empty(t, r.Status)
+	assert.Equal(t, "OCSP responder answered 500", r.Error)
+	assert.EqualError(t, r.Err(), "certificate requires OCSP stapling, none stapled")
+
+	var none *checker.Revocation
+	assert.NoError(t, none.Err())
+}
@@ -0,0 +1,109 @@
+package checker
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// The security headers graded by GradeSecurityHeaders, by the name of their
+// check.
+const (
+	SecurityHSTS               = "strict-transport-security"
+	SecurityCSP                = "content-security-policy"
+	SecurityContentTypeOptions = "x-content-type-options"
+	SecurityFrameOptions       = "x-frame-options"
+	SecurityReferrerPolicy     = "referrer-policy"
+	SecurityPermissionsPolicy  = "permissions-policy"
+)
+
+// SecurityHeaders grades the security headers of a response.
+type SecurityHeaders struct {
+	// Checks tells whether each security header is set as recommended.
+	Checks map[string]bool `json:"checks"`
+	// Grade is one of request.SecurityGrades, from the Score out of 100.
+	Grade string `json:"grade"`
+	Score int    `json:"score"`
+}
+
+// minHSTSMaxAge is the max-age an HSTS header must hold, 180 days.
+const minHSTSMaxAge = 180 * 24 * 60 * 60
+
+var securityWeights = map[string]int{
+	SecurityHSTS:               25,
+	SecurityCSP:                25,
+	SecurityContentTypeOptions: 15,
+	SecurityFrameOptions:       15,
+	SecurityReferrerPolicy:     10,
+	SecurityPermissionsPolicy:  10,
+}
+
+// safeReferrerPolicies are the policies not leaking the full URL to other
+// origins.
+var safeReferrerPolicies = []string{
+	"no-referrer", "same-origin", "strict-origin", "strict-origin-when-cross-origin", "origin", "origin-when-cross-origin",
+}
+
+// GradeSecurityHeaders grades the security headers of a response, by
+// canonical name as in Response. HSTS only counts over HTTPS, and a CSP
+// frame-ancestors directive stands for X-Frame-Options.
+func GradeSecurityHeaders(headers map[string]string, https bool) *SecurityHeaders {
+	csp := strings.ToLower(headers["Content-Security-Policy"])
+	frameOptions := strings.ToUpper(strings.TrimSpace(headers["X-Frame-Options"]))
+
+	checks := map[string]bool{
+		SecurityHSTS:               https && hstsMaxAge(headers["Strict-Transport-Security"]) >= minHSTSMaxAge,
+		SecurityCSP:                strings.TrimSpace(csp) != "",
+		SecurityContentTypeOptions: strings.EqualFold(strings.TrimSpace(headers["X-Content-Type-Options"]), "nosniff"),
+		SecurityFrameOptions:       frameOptions == "DENY" || frameOptions == "SAMEORIGIN" || strings.Contains(csp, "frame-ancestors"),
+		SecurityReferrerPolicy:     referrerPolicySafe(headers["Referrer-Policy"]),
+		SecurityPermissionsPolicy:  strings.TrimSpace(headers["Permissions-Policy"]) != "",
+	}
+
+	score := 0
+	for name, ok := range checks {
+		if ok {
+			score += securityWeights[name]
+		}
+	}
+
+	return &SecurityHeaders{Checks: checks, Score: score, Grade: securityGrade(score)}
+}
+
+func securityGrade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 75:
+		return "B"
+	case score >= 60:
+		return "C"
+	case score >= 45:
+		return "D"
+	case score >= 30:
+		return "E"
+	default:
+		return "F"
+	}
+}
+
+func hstsMaxAge(value string) int {
+	for directive := range strings.SplitSeq(value, ";") {
+		name, v, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if strings.EqualFold(name, "max-age") {
+			age, _ := strconv.Atoi(strings.Trim(strings.TrimSpace(v), `"`))
+			return age
+		}
+	}
+
+	return 0
+}
+
+// referrerPolicySafe reports whether the last policy of value, the one
+// browsers apply, is safe.
+func referrerPolicySafe(value string) bool {
+	policies := strings.Split(value, ",")
+	policy := strings.ToLower(strings.TrimSpace(policies[len(policies)-1]))
+
+	return slices.Contains(safeReferrerPolicies, policy)
+}
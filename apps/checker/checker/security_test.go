@@ -0,0 +1,70 @@
+package checker_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+)
+
+func TestGradeSecurityHeaders(t *testing.T) {
+	hardened := map[string]string{
+		"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+		"Content-Security-Policy":   "default-src 'self'; frame-ancestors 'none'",
+		"X-Content-Type-Options":    "nosniff",
+		"Referrer-Policy":           "no-referrer-when-downgrade, strict-origin-when-cross-origin",
+		"Permissions-Policy":        "camera=()",
+	}
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		https   bool
+		grade   string
+		score   int
+		failed  []string
+	}{
+		{name: "hardened", headers: hardened, https: true, grade: "A", score: 100},
+		{name: "over http", headers: hardened, grade: "B", score: 75, failed: []string{checker.SecurityHSTS}},
+		{
+			name:    "short hsts and unsafe referrer",
+			headers: map[string]string{"Strict-Transport-Security": "max-age=300", "X-Frame-Options": "sameorigin", "X-Content-Type-Options": "nosniff", "Referrer-Policy": "unsafe-url"},
+			https:   true,
+			grade:   "E",
+			score:   30,
+			failed:  []string{checker.SecurityHSTS, checker.SecurityCSP, checker.SecurityReferrerPolicy, checker.SecurityPermissionsPolicy},
+		},
+		{
+			name:    "none",
+			headers: map[string]string{"Content-Type": "text/html"},
+			https:   true,
+			grade:   "F",
+			score:   0,
+			failed: []string{
+				checker.SecurityHSTS, checker.SecurityCSP, checker.SecurityContentTypeOptions,
+				checker.SecurityFrameOptions, checker.SecurityReferrerPolicy, checker.SecurityPermissionsPolicy,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checker.GradeSecurityHeaders(tt.headers, tt.https)
+
+			assert.Equal(t, tt.grade, got.Grade)
+			assert.Equal(t, tt.score, got.Score)
+			assert.Len(t, got.Checks, 6)
+			var failed []string
+			for _, name := range []string{
+				checker.SecurityHSTS, checker.SecurityCSP, checker.SecurityContentTypeOptions,
+				checker.SecurityFrameOptions, checker.SecurityReferrerPolicy, checker.SecurityPermissionsPolicy,
+			} {
+				if !got.Checks[name] {
+					failed = append(failed, name)
+				}
+			}
+			assert.Equal(t, tt.failed, failed)
+		})
+	}
+}
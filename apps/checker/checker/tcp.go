@@ -1,10 +1,17 @@
 package checker
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
+	"net/http"
+	"net/netip"
 	"strings"
 	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/geoip"
 )
 
 type TCPData struct {
@@ -13,9 +20,18 @@ type TCPData struct {
 	Timestamp   int64  `json:"timestamp"`
 }
 
+// TCPResponseTiming holds the phases of a TCP check. TCPStart and TCPDone
+// span the DNS lookup and the connection, which are also timed on their own.
+// Hosts given as an IP have no DNS phase.
 type TCPResponseTiming struct {
-	TCPStart int64 `json:"tcpStart"`
-	TCPDone  int64 `json:"tcpDone"`
+	TCPStart     int64 `json:"tcpStart"`
+	TCPDone      int64 `json:"tcpDone"`
+	DNSStart     int64 `json:"dnsStart,omitempty"`
+	DNSDone      int64 `json:"dnsDone,omitempty"`
+	ConnectStart int64 `json:"connectStart,omitempty"`
+	ConnectDone  int64 `json:"connectDone,omitempty"`
+	TLSStart     int64 `json:"tlsStart,omitempty"`
+	TLSDone      int64 `json:"tlsDone,omitempty"`
 }
 
 type TCPResponse struct {
@@ -28,24 +44,170 @@ type TCPResponse struct {
 	Timestamp    int64             `json:"timestamp"`
 	Latency      int64             `json:"latency"`
 	Timing       TCPResponseTiming `json:"timing"`
-	Error        uint8             `json:"error,omitempty"`
+	Attempts     []Attempt         `json:"attempts,omitempty"`
+	// Addresses holds the result of each address of the host, when they
+	// are all checked.
+	Addresses []AddressResult `json:"addresses,omitempty"`
+	// DualStack is the one of Response.
+	DualStack *DualStackResult `json:"dualStack,omitempty"`
+	// RemoteIP is the address the check connected to, of IPFamily.
+	RemoteIP string `json:"remoteIp,omitempty"`
+	IPFamily string `json:"ipFamily,omitempty"`
+	// Geo is the network and location of RemoteIP, when known.
+	Geo *geoip.Info `json:"geo,omitempty"`
+	// TLS describes the connection of checks with tls.
+	TLS *TLSInfo `json:"tls,omitempty"`
+	// Hold is how long the connection of checks with hold stayed open.
+	Hold  *HoldResult `json:"hold,omitempty"`
+	Error uint8       `json:"error,omitempty"`
+}
+
+// PingTCP dials url within timeout. The dial is aborted as soon as ctx is
+// done.
+func PingTCP(ctx context.Context, timeout time.Duration, url string) (TCPResponseTiming, error) {
+	res, err := DialTCP(ctx, url, TCPOptions{Dialer: &net.Dialer{Timeout: timeout}})
+
+	return res.Timing, err
+}
+
+// TCPOptions tune DialTCP.
+type TCPOptions struct {
+	// Dialer may restrict the addresses it connects to. Its timeout applies
+	// to the TLS handshake too.
+	Dialer *net.Dialer
+	// TLSConfig enables a TLS handshake once connected.
+	TLSConfig *tls.Config
+	// IPFamily is one of the request.IPFamily values, any when empty.
+	IPFamily string
+	// Revocation asks the OCSP responder of the certificate with its client,
+	// nil to leave the revocation status unchecked.
+	Revocation *http.Client
+	// Hold keeps the connection open and idle this long once established,
+	// TLS handshake included, watching for it to be dropped.
+	Hold time.Duration
+}
+
+// TCPResult describes a successful dial.
+type TCPResult struct {
+	TLS      *TLSInfo
+	Hold     *HoldResult
+	RemoteIP string
+	IPFamily string
+	Timing   TCPResponseTiming
 }
 
-func PingTCP(timeout int, url string) (TCPResponseTiming, error) {
-	start := time.Now().UTC().UnixMilli()
-	conn, err := net.DialTimeout("tcp", url, time.Duration(timeout)*time.Second)
-	stop := time.Now().UTC().UnixMilli()
+// DialTCP is PingTCP with options. The dial is aborted as soon as ctx is done.
+func DialTCP(ctx context.Context, url string, opts TCPOptions) (TCPResult, error) {
+	dialer := opts.Dialer
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+
+	clk := newClock()
+	timing := TCPResponseTiming{TCPStart: clk.now()}
+	conn, err := dial(ctx, dialer, opts.IPFamily, url, clk, &timing)
+	timing.TCPDone = clk.now()
 
 	if err != nil {
-		if e := err.(*net.OpError).Timeout(); e {
-			return TCPResponseTiming{}, fmt.Errorf("timeout after %d ms", timeout*1000)
+		if cerr := context.Cause(ctx); cerr != nil {
+			return TCPResult{}, fmt.Errorf("dial aborted: %w", cerr)
+		}
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Timeout() {
+			return TCPResult{}, &ClassifiedError{Class: ErrorClassTimeout, Code: ErrorCodeConnTimeout, Err: fmt.Errorf("timeout after %d ms", dialer.Timeout.Milliseconds())}
 		}
 		if strings.Contains(err.Error(), "connection refused") {
-			return TCPResponseTiming{}, fmt.Errorf("connection refused")
+			return TCPResult{}, &ClassifiedError{Class: ErrorClassConnectionRefused, Err: fmt.Errorf("connection refused")}
 		}
-		return TCPResponseTiming{}, fmt.Errorf("dial error: %w", err)
+		return TCPResult{}, fmt.Errorf("dial error: %w", err)
 	}
 	defer conn.Close()
 
-	return TCPResponseTiming{TCPStart: start, TCPDone: stop}, nil
+	res := TCPResult{Timing: timing}
+	res.RemoteIP, res.IPFamily = RemoteIP(conn.RemoteAddr())
+	if opts.TLSConfig == nil {
+		if opts.Hold > 0 {
+			res.Hold, err = holdConn(ctx, conn, opts.Hold)
+			if err != nil {
+				return TCPResult{}, err
+			}
+		}
+		return res, nil
+	}
+
+	cfg := opts.TLSConfig.Clone()
+	if cfg.ServerName == "" {
+		cfg.ServerName, _, _ = net.SplitHostPort(url)
+	}
+	if dialer.Timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(dialer.Timeout))
+	}
+
+	res.Timing.TLSStart = clk.now()
+	tlsConn := tls.Client(conn, cfg)
+	err = tlsConn.HandshakeContext(ctx)
+	res.Timing.TLSDone = clk.now()
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return TCPResult{}, &ClassifiedError{Class: ErrorClassTimeout, Code: ErrorCodeTLSTimeout, Err: fmt.Errorf("tls handshake timeout after %d ms", dialer.Timeout.Milliseconds())}
+		}
+		return TCPResult{}, &ClassifiedError{Class: ErrorClassTLS, Err: fmt.Errorf("tls handshake error: %w", err)}
+	}
+	res.TLS = NewTLSInfo(tlsConn.ConnectionState())
+	if opts.Revocation != nil {
+		res.TLS.Revocation = CheckRevocation(ctx, opts.Revocation, tlsConn.ConnectionState())
+	}
+	if opts.Hold > 0 {
+		res.Hold, err = holdConn(ctx, tlsConn, opts.Hold)
+		if err != nil {
+			return TCPResult{}, err
+		}
+	}
+
+	return res, nil
+}
+
+// dial resolves the host of address and connects to its addresses in turn
+// until one accepts, within the timeout of dialer, timing both phases.
+func dial(ctx context.Context, dialer *net.Dialer, family, address string, clk clock, timing *TCPResponseTiming) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if dialer.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, dialer.Timeout)
+		defer cancel()
+	}
+
+	network := Network(family)
+	ips := []netip.Addr{}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		ips = append(ips, ip)
+	} else {
+		timing.DNSStart = clk.now()
+		ips, err = net.DefaultResolver.LookupNetIP(ctx, lookupNetwork(family), host)
+		timing.DNSDone = clk.now()
+		if err == nil && len(ips) == 0 {
+			err = &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		if err != nil {
+			return nil, &net.OpError{Op: "dial", Net: network, Err: err}
+		}
+	}
+
+	timing.ConnectStart = clk.now()
+	defer func() { timing.ConnectDone = clk.now() }()
+
+	var conn net.Conn
+	for _, ip := range ips {
+		conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(ip.Unmap().String(), port))
+		if err == nil || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return conn, err
 }
@@ -1,15 +1,29 @@
 package checker_test
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"math/big"
+	"net"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/request"
 )
 
 func TestPingTcp(t *testing.T) {
 	type args struct {
 		url     string
-		timeout int
+		timeout time.Duration
 	}
 	tests := []struct {
 		name    string
@@ -17,12 +31,12 @@ func TestPingTcp(t *testing.T) {
 		want    checker.TCPResponseTiming
 		wantErr bool
 	}{
-		{name: "will failed", args: args{url: "error", timeout: 60}, wantErr: true},
-		{name: "will be ok", args: args{url: "openstat.us:443", timeout: 60}, wantErr: false},
+		{name: "will failed", args: args{url: "error", timeout: time.Minute}, wantErr: true},
+		{name: "will be ok", args: args{url: "openstat.us:443", timeout: time.Minute}, wantErr: false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := checker.PingTCP(tt.args.timeout, tt.args.url)
+			got, err := checker.PingTCP(t.Context(), tt.args.timeout, tt.args.url)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("PingTcp() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -39,3 +53,139 @@ func TestPingTcp(t *testing.T) {
 		})
 	}
 }
+
+func TestPingTcpCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, err := checker.PingTCP(ctx, time.Minute, "openstat.us:443")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("PingTcp() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestDialTCP_TLS(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+
+	clientCerts := make(chan int, 1)
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequestClientCert,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			clientCerts <- len(cs.PeerCertificates)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			_ = conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	res, err := checker.DialTCP(t.Context(), ln.Addr().String(), checker.TCPOptions{
+		Dialer: &net.Dialer{Timeout: time.Second},
+		TLSConfig: &tls.Config{
+			InsecureSkipVerify: true,
+			Certificates:       []tls.Certificate{cert},
+		},
+	})
+	require.NoError(t, err)
+	assert.NotZero(t, res.Timing.TLSDone)
+	if assert.NotNil(t, res.TLS) {
+		assert.Equal(t, "TLS 1.3", res.TLS.Version)
+		assert.NotEmpty(t, res.TLS.CipherSuite)
+	}
+	assert.Equal(t, 1, <-clientCerts, "the client certificate is presented")
+}
+
+func TestDialTCP_Hold(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				// The first connection is dropped after 50ms, the second held.
+				_, _ = conn.Write([]byte("220 ready\r\n"))
+				time.Sleep(50 * time.Millisecond)
+				conn.Close()
+			}()
+			conn, err = ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	opts := checker.TCPOptions{Dialer: &net.Dialer{Timeout: time.Second}, Hold: 500 * time.Millisecond}
+	res, err := checker.DialTCP(t.Context(), ln.Addr().String(), opts)
+	require.NoError(t, err)
+	require.NotNil(t, res.Hold)
+	assert.True(t, res.Hold.Dropped)
+	assert.Equal(t, "closed", res.Hold.Reason)
+	assert.Less(t, res.Hold.HeldMs, int64(500))
+
+	opts.Hold = 100 * time.Millisecond
+	res, err = checker.DialTCP(t.Context(), ln.Addr().String(), opts)
+	require.NoError(t, err)
+	require.NotNil(t, res.Hold)
+	assert.False(t, res.Hold.Dropped)
+	assert.GreaterOrEqual(t, res.Hold.HeldMs, int64(100))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	opts.Hold = time.Minute
+	_, err = checker.DialTCP(ctx, ln.Addr().String(), opts)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestDialTCP_IPFamily(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	res, err := checker.DialTCP(t.Context(), ln.Addr().String(), checker.TCPOptions{IPFamily: request.IPFamilyIPv4})
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", res.RemoteIP)
+	assert.Equal(t, request.IPFamilyIPv4, res.IPFamily)
+
+	// An IPv4 address cannot be reached over IPv6.
+	_, err = checker.DialTCP(t.Context(), ln.Addr().String(), checker.TCPOptions{IPFamily: request.IPFamilyIPv6})
+	assert.Error(t, err)
+}
+
+func TestDialTCP_Phases(t *testing.T) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	res, err := checker.DialTCP(t.Context(), net.JoinHostPort("localhost", port), checker.TCPOptions{IPFamily: request.IPFamilyIPv4})
+	require.NoError(t, err)
+	timing := res.Timing
+	assert.NotZero(t, timing.DNSStart)
+	assert.NotZero(t, timing.ConnectStart)
+	assert.LessOrEqual(t, timing.TCPStart, timing.DNSStart)
+	assert.LessOrEqual(t, timing.DNSDone, timing.ConnectStart)
+	assert.LessOrEqual(t, timing.ConnectDone, timing.TCPDone)
+
+	// An IP needs no lookup.
+	res, err = checker.DialTCP(t.Context(), ln.Addr().String(), checker.TCPOptions{})
+	require.NoError(t, err)
+	assert.Zero(t, res.Timing.DNSStart)
+	assert.NotZero(t, res.Timing.ConnectStart)
+}
@@ -0,0 +1,23 @@
+package checker
+
+import "crypto/tls"
+
+// TLSInfo describes the TLS connection of a check: the negotiated version,
+// e.g. "TLS 1.3", cipher suite and ALPN protocol.
+type TLSInfo struct {
+	Version     string `json:"version"`
+	CipherSuite string `json:"cipherSuite"`
+	ALPN        string `json:"alpn,omitempty"`
+	// Revocation is the OCSP status of the certificate, when checked.
+	Revocation *Revocation `json:"revocation,omitempty"`
+	// Scan lists the versions and weak cipher suites accepted, when scanned.
+	Scan *TLSScan `json:"scan,omitempty"`
+}
+
+func NewTLSInfo(state tls.ConnectionState) *TLSInfo {
+	return &TLSInfo{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ALPN:        state.NegotiatedProtocol,
+	}
+}
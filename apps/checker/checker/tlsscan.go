@@ -0,0 +1,116 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// ScanTLSVersions are the TLS versions a TLS scan tries, oldest first.
+var ScanTLSVersions = []uint16{tls.VersionTLS10, tls.VersionTLS11, tls.VersionTLS12, tls.VersionTLS13}
+
+// WeakCipherSuites are the cipher suites a TLS scan tries: the RC4 and 3DES
+// ones, which are broken. The other insecure suites of crypto/tls, CBC or
+// without forward secrecy, are merely dated and left out.
+var WeakCipherSuites = []uint16{
+	tls.TLS_RSA_WITH_RC4_128_SHA,
+	tls.TLS_ECDHE_ECDSA_WITH_RC4_128_SHA,
+	tls.TLS_ECDHE_RSA_WITH_RC4_128_SHA,
+	tls.TLS_RSA_WITH_3DES_EDE_CBC_SHA,
+	tls.TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA,
+}
+
+// TLSScan lists the TLS versions and weak cipher suites a target accepts.
+type TLSScan struct {
+	Versions    []string `json:"versions"`
+	WeakCiphers []string `json:"weakCiphers,omitempty"`
+}
+
+// ScanTLS performs one handshake with address per TLS version and per weak
+// cipher suite, concurrently, each within the timeout of dialer. A refused
+// handshake means the version or suite is not accepted, the failure of a
+// dial is returned.
+func ScanTLS(ctx context.Context, dialer *net.Dialer, address string, config *tls.Config) (*TLSScan, error) {
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+
+	configs := make([]*tls.Config, 0, len(ScanTLSVersions)+len(WeakCipherSuites))
+	for _, version := range ScanTLSVersions {
+		cfg := scanConfig(config)
+		cfg.MinVersion, cfg.MaxVersion = version, version
+		configs = append(configs, cfg)
+	}
+	for _, suite := range WeakCipherSuites {
+		cfg := scanConfig(config)
+		cfg.MinVersion, cfg.MaxVersion = tls.VersionTLS10, tls.VersionTLS12
+		cfg.CipherSuites = []uint16{suite}
+		configs = append(configs, cfg)
+	}
+
+	accepted := make([]bool, len(configs))
+	errs := make([]error, len(configs))
+	var wg sync.WaitGroup
+	for i, cfg := range configs {
+		wg.Go(func() {
+			accepted[i], errs[i] = handshake(ctx, dialer, address, cfg)
+		})
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("tls scan error: %w", err)
+	}
+
+	scan := &TLSScan{Versions: []string{}}
+	for i, version := range ScanTLSVersions {
+		if accepted[i] {
+			scan.Versions = append(scan.Versions, tls.VersionName(version))
+		}
+	}
+	for i, suite := range WeakCipherSuites {
+		if accepted[len(ScanTLSVersions)+i] {
+			scan.WeakCiphers = append(scan.WeakCiphers, tls.CipherSuiteName(suite))
+		}
+	}
+
+	return scan, nil
+}
+
+// scanConfig returns a copy of config for a handshake of a scan. The
+// certificate is not verified: it was by the handshake of the check, the
+// scan only tells which versions and suites are accepted.
+func scanConfig(config *tls.Config) *tls.Config {
+	cfg := &tls.Config{}
+	if config != nil {
+		cfg = config.Clone()
+	}
+	cfg.InsecureSkipVerify = true
+
+	return cfg
+}
+
+// handshake reports whether address accepts a handshake with cfg.
+func handshake(ctx context.Context, dialer *net.Dialer, address string, cfg *tls.Config) (bool, error) {
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	if dialer.Timeout > 0 {
+		handshakeCtx, cancel := context.WithTimeout(ctx, dialer.Timeout)
+		defer cancel()
+		ctx = handshakeCtx
+	}
+	if err := tls.Client(conn, cfg).HandshakeContext(ctx); err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		return false, nil
+	}
+
+	return true, nil
+}
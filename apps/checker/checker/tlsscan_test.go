@@ -0,0 +1,55 @@
+package checker_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+)
+
+func TestScanTLS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		MinVersion:   tls.VersionTLS10,
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA, tls.TLS_RSA_WITH_RC4_128_SHA},
+	})
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_ = conn.(*tls.Conn).Handshake()
+				conn.Close()
+			}()
+		}
+	}()
+
+	scan, err := checker.ScanTLS(t.Context(), &net.Dialer{Timeout: time.Second}, ln.Addr().String(), &tls.Config{ServerName: "openstat.us"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TLS 1.0", "TLS 1.1", "TLS 1.2"}, scan.Versions)
+	assert.Equal(t, []string{"TLS_RSA_WITH_RC4_128_SHA"}, scan.WeakCiphers)
+
+	ln.Close()
+	_, err = checker.ScanTLS(t.Context(), &net.Dialer{Timeout: time.Second}, ln.Addr().String(), nil)
+	assert.ErrorContains(t, err, "tls scan error")
+}
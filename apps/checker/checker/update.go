@@ -5,15 +5,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/cenkalti/backoff/v5"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/api/option"
 
 	"cloud.google.com/go/auth"
 	cloudtasks "cloud.google.com/go/cloudtasks/apiv2"
 	taskspb "cloud.google.com/go/cloudtasks/apiv2/cloudtaskspb"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/geoip"
 )
 
 type UpdateData struct {
@@ -24,13 +30,101 @@ type UpdateData struct {
 	CronTimestamp int64  `json:"cronTimestamp"`
 	StatusCode    int    `json:"statusCode,omitempty"`
 	Latency       int64  `json:"latency,omitempty"`
+	// Evidence details why the check failed, for error updates.
+	Evidence *Evidence `json:"evidence,omitempty"`
 }
 
-func UpdateStatus(ctx context.Context, updateData UpdateData) error {
+// Evidence is what the alert of a failed check shows besides its message.
+type Evidence struct {
+	// Assertion is the JSON of the first assertion that failed.
+	Assertion string `json:"assertion,omitempty"`
+	// Snippet is the start of the response body.
+	Snippet string `json:"snippet,omitempty"`
+	// ResolvedIP is the address the check connected to.
+	ResolvedIP string `json:"resolvedIp,omitempty"`
+	// Geo is the network and location of ResolvedIP, when known.
+	Geo *geoip.Info `json:"geo,omitempty"`
+}
+
+// defaultUpdateStatusURL receives the status updates of the openstatus fleet.
+const defaultUpdateStatusURL = "https://openstatus-workflows.fly.dev/updateStatus"
+
+// updateStatusURL is the endpoint of the status updates, STATUS_UPDATE_URL
+// pointing self-hosted and staging checkers at their own control plane.
+func updateStatusURL() string {
+	if u := os.Getenv("STATUS_UPDATE_URL"); u != "" {
+		return strings.TrimSuffix(u, "/")
+	}
+
+	return defaultUpdateStatusURL
+}
+
+// updateStatusAuthorization is the Authorization header of the status
+// updates, STATUS_UPDATE_AUTHORIZATION (e.g. "Bearer <token>") or Basic with
+// CRON_SECRET.
+func updateStatusAuthorization() string {
+	if a := os.Getenv("STATUS_UPDATE_AUTHORIZATION"); a != "" {
+		return a
+	}
+
+	return "Basic " + os.Getenv("CRON_SECRET")
+}
 
-	url := "https://openstatus-workflows.fly.dev/updateStatus"
-	basic := "Basic " + os.Getenv("CRON_SECRET")
+func UpdateStatus(ctx context.Context, updateData UpdateData) error {
 	payloadBuf := new(bytes.Buffer)
+	if err := json.NewEncoder(payloadBuf).Encode(updateData); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("error while updating status")
+		return err
+	}
+
+	return postStatus(ctx, updateStatusURL(), payloadBuf.Bytes())
+}
+
+// postStatus sends body to url through the alerting queue of Cloud Tasks, or
+// directly when GCP_PROJECT_ID is not set, as on self-hosted checkers.
+func postStatus(ctx context.Context, url string, body []byte) error {
+	if os.Getenv("GCP_PROJECT_ID") == "" {
+		return post(ctx, url, body)
+	}
+
+	return createTask(ctx, url, body)
+}
+
+// post sends body to url. An update rejected with a 4xx other than 408 and
+// 429, e.g. an unknown monitor or region, fails with a
+// *backoff.PermanentError, retrying it being pointless.
+func post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+	req.Header.Set("Authorization", updateStatusAuthorization())
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := statusClient.Do(req)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("error while sending the status update")
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		err := fmt.Errorf("status update answered %d", resp.StatusCode)
+		if resp.StatusCode < http.StatusInternalServerError && resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+			return backoff.Permanent(err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// statusClient sends the status updates not going through Cloud Tasks.
+var statusClient = &http.Client{Timeout: 30 * time.Second}
+
+// createTask queues the POST of body to url on the alerting queue.
+func createTask(ctx context.Context, url string, body []byte) error {
 	c := os.Getenv("GCP_PRIVATE_KEY")
 	c = strings.ReplaceAll(c, "\\n", "\n")
 	opts := &auth.Options2LO{
@@ -56,14 +150,10 @@ func UpdateStatus(ctx context.Context, updateData UpdateData) error {
 	client, err := cloudtasks.NewClient(ctx, option.WithAuthCredentials(creds))
 	if err != nil {
 		log.Ctx(ctx).Error().Err(err).Msg("error while creating cloud tasks client")
-
+		return err
 	}
 	defer client.Close()
 
-	if err := json.NewEncoder(payloadBuf).Encode(updateData); err != nil {
-		log.Ctx(ctx).Error().Err(err).Msg("error while updating status")
-		return err
-	}
 	projectID := os.Getenv("GCP_PROJECT_ID")
 	queuePath := fmt.Sprintf("projects/%s/locations/europe-west1/queues/alerting", projectID)
 	req := &taskspb.CreateTaskRequest{
@@ -74,14 +164,14 @@ func UpdateStatus(ctx context.Context, updateData UpdateData) error {
 				HttpRequest: &taskspb.HttpRequest{
 					HttpMethod: taskspb.HttpMethod_POST,
 					Url:        url,
-					Headers:    map[string]string{"Authorization": basic, "Content-Type": "application/json"},
+					Headers:    map[string]string{"Authorization": updateStatusAuthorization(), "Content-Type": "application/json"},
 				},
 			},
 		},
 	}
 
 	// Add a payload message if one is present.
-	req.Task.GetHttpRequest().Body = payloadBuf.Bytes()
+	req.Task.GetHttpRequest().Body = body
 
 	_, err = client.CreateTask(ctx, req)
 	if err != nil {
@@ -0,0 +1,57 @@
+package checker_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+)
+
+func TestUpdateStatus(t *testing.T) {
+	var received checker.UpdateData
+	var authorization, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization, path = r.Header.Get("Authorization"), r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	t.Setenv("GCP_PROJECT_ID", "")
+	t.Setenv("STATUS_UPDATE_URL", server.URL+"/status/")
+	t.Setenv("STATUS_UPDATE_AUTHORIZATION", "Bearer staging")
+
+	data := checker.UpdateData{MonitorId: "1", Status: "error", Region: "ams", CronTimestamp: 1}
+	require.NoError(t, checker.UpdateStatus(context.Background(), data))
+	assert.Equal(t, data, received)
+	assert.Equal(t, "Bearer staging", authorization)
+	assert.Equal(t, "/status", path)
+
+	t.Setenv("STATUS_UPDATE_AUTHORIZATION", "")
+	t.Setenv("CRON_SECRET", "secret")
+	require.NoError(t, checker.UpdateStatus(context.Background(), data))
+	assert.Equal(t, "Basic secret", authorization)
+
+	var permanent *backoff.PermanentError
+	for status, rejected := range map[int]bool{
+		http.StatusServiceUnavailable:  false,
+		http.StatusTooManyRequests:     false,
+		http.StatusRequestTimeout:      false,
+		http.StatusUnprocessableEntity: true,
+		http.StatusUnauthorized:        true,
+	} {
+		server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		})
+		err := checker.UpdateStatus(context.Background(), data)
+		assert.Error(t, err)
+		assert.Equal(t, rejected, errors.As(err, &permanent), "status %d", status)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/bench"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func main() {
+	checkerURL := flag.String("checker", "http://localhost:8080", "base URL of the checker")
+	checkType := flag.String("type", "http", "type of the checks: http, tcp or dns")
+	target := flag.String("target", "", "URL, host:port or domain checked by the jobs")
+	rate := flag.Float64("rate", 10, "jobs sent per second")
+	duration := flag.Duration("duration", time.Minute, "duration of the run")
+	concurrency := flag.Int("concurrency", 100, "jobs in flight at most, the others being dropped")
+	monitors := flag.Int("monitors", 1000, "distinct monitor IDs the jobs cycle through")
+	dryRun := flag.Bool("dry-run", true, "run the checks without sending their events and status updates")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	if *target == "" {
+		fmt.Fprintln(os.Stderr, "-target is required")
+		os.Exit(2)
+	}
+	endpoint, err := url.JoinPath(*checkerURL, "v2/checker", *checkType)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid -checker: %v\n", err)
+		os.Exit(2)
+	}
+	if *dryRun {
+		endpoint += "?dryRun=true"
+	}
+	job, err := jobs(*checkType, *target, *monitors)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	report, err := bench.Run(ctx, bench.Config{
+		URL: endpoint,
+		// CRON_SECRET authenticates the jobs like the dispatcher does.
+		Authorization: "Basic " + os.Getenv("CRON_SECRET"),
+		Job:           job,
+		Rate:          *rate,
+		Duration:      *duration,
+		Concurrency:   *concurrency,
+		Client:        &http.Client{Timeout: 2 * time.Minute},
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if *asJSON {
+		_ = json.NewEncoder(os.Stdout).Encode(report)
+		return
+	}
+	fmt.Println(report)
+}
+
+// jobs returns the synthetic jobs of checkType, each of one of monitors
+// monitor IDs.
+func jobs(checkType, target string, monitors int) (func(n int) []byte, error) {
+	if monitors <= 0 {
+		return nil, fmt.Errorf("-monitors must be positive")
+	}
+
+	var job func(monitorID string) any
+	switch checkType {
+	case "http":
+		job = func(monitorID string) any {
+			return request.HttpCheckerRequest{WorkspaceID: "1", MonitorID: monitorID, URL: target, Method: http.MethodGet, Status: "active", Timeout: 30000}
+		}
+	case "tcp":
+		job = func(monitorID string) any {
+			return request.TCPCheckerRequest{WorkspaceID: "1", MonitorID: monitorID, URI: target, Status: "active", Timeout: 30000}
+		}
+	case "dns":
+		job = func(monitorID string) any {
+			return request.DNSCheckerRequest{WorkspaceID: "1", MonitorID: monitorID, URI: target, Status: "active", Timeout: 30000}
+		}
+	default:
+		return nil, fmt.Errorf("unknown -type %q", checkType)
+	}
+
+	return func(n int) []byte {
+		body, _ := json.Marshal(job(strconv.Itoa(n%monitors + 1)))
+		return body
+	}, nil
+}
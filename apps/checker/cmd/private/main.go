@@ -2,16 +2,20 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strconv"
 	"syscall"
 	"time"
 
 	"connectrpc.com/connect"
 	"github.com/madflojo/tasks"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/agent"
 	"github.com/openstatushq/openstatus/apps/checker/pkg/job"
 	"github.com/openstatushq/openstatus/apps/checker/pkg/scheduler"
 
@@ -20,8 +24,16 @@ import (
 
 const (
 	configRefreshInterval = 10 * time.Minute
+	// drainTimeout bounds the wait for running checks before a restart.
+	drainTimeout = 30 * time.Second
 )
 
+// version is set at build time with -ldflags "-X main.version=...".
+var version = "dev"
+
+// capabilities are the check types the agent runs.
+var capabilities = []string{"http", "tcp", "dns"}
+
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -38,27 +50,168 @@ func main() {
 	defer s.Stop()
 
 	apiKey := getEnv("OPENSTATUS_KEY", "")
+	client := getClient(apiKey)
 
 	monitorManager := scheduler.MonitorManager{
-		Client:    getClient(apiKey),
+		Client:    client,
 		JobRunner: job.NewJobRunner(),
 		Scheduler: s,
 	}
+
+	// Register the agent and report its liveness in the background, when
+	// enabled, checks of the monitors already assigned to it run in the
+	// meantime.
+	var fetcher *agent.ConfigFetcher
+	if agentClient, reg, err := newAgent(client); err != nil {
+		fmt.Printf("Agent not registered: %v\n", err)
+	} else if agentClient != nil {
+		go register(ctx, agentClient, reg)
+		go heartbeat(ctx, agentClient, reg, &monitorManager)
+		fetcher = newConfigFetcher(agentClient)
+	}
+	// updated receives the path of the updated binary once installed.
+	updated := make(chan string, 1)
+	if updater := newUpdater(); updater != nil {
+		go selfUpdate(ctx, updater, updated)
+	}
 	configTicker := time.NewTicker(configRefreshInterval)
 	defer configTicker.Stop()
 
-	monitorManager.UpdateMonitors(ctx)
+	refresh := func() {
+		if fetcher == nil {
+			monitorManager.UpdateMonitors(ctx)
+			return
+		}
+		if interval := fetchConfig(ctx, fetcher, &monitorManager); interval > 0 {
+			configTicker.Reset(interval)
+		}
+	}
+
+	refresh()
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-configTicker.C:
 			fmt.Println("fetching monitors")
-			monitorManager.UpdateMonitors(ctx)
+			refresh()
+		case path := <-updated:
+			restart(path, &monitorManager)
+			return
 		}
 	}
 }
 
+// newUpdater returns the updater of the agent, nil when self-update is not
+// enabled with both the manifest URL and the release key.
+func newUpdater() *agent.Updater {
+	manifestURL := getEnv("OPENSTATUS_AGENT_UPDATE_URL", "")
+	releaseKey := getEnv("OPENSTATUS_AGENT_UPDATE_KEY", "")
+	if manifestURL == "" || releaseKey == "" {
+		return nil
+	}
+	if version == "dev" {
+		fmt.Println("Development build without a version, self-update disabled")
+		return nil
+	}
+	key, err := agent.ParsePublicKey(releaseKey)
+	if err != nil {
+		fmt.Printf("Invalid OPENSTATUS_AGENT_UPDATE_KEY, self-update disabled: %v\n", err)
+		return nil
+	}
+
+	return &agent.Updater{ManifestURL: manifestURL, PublicKey: key, Current: version}
+}
+
+// selfUpdate checks for a new release of the agent until one is installed,
+// and sends the path of the updated binary on updated.
+func selfUpdate(ctx context.Context, updater *agent.Updater, updated chan<- string) {
+	interval, err := time.ParseDuration(getEnv("OPENSTATUS_AGENT_UPDATE_INTERVAL", "1h"))
+	if err != nil || interval <= 0 {
+		fmt.Printf("Invalid OPENSTATUS_AGENT_UPDATE_INTERVAL, using 1h: %v\n", err)
+		interval = time.Hour
+	}
+	path, err := agent.Executable()
+	if err != nil {
+		fmt.Printf("Self-update disabled: %v\n", err)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		binary, release, err := updater.Check(ctx)
+		switch {
+		case errors.Is(err, agent.ErrNoUpdate):
+		case err != nil:
+			fmt.Printf("Failed to check for updates: %v\n", err)
+		default:
+			if err := updater.Install(ctx, binary, path); err != nil {
+				fmt.Printf("Failed to install release %s: %v\n", release, err)
+				break
+			}
+			fmt.Printf("Installed release %s\n", release)
+			updated <- path
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// restart stops scheduling checks, waits for the running ones to finish, so
+// none is lost, and replaces the agent with the updated binary at path.
+func restart(path string, mm *scheduler.MonitorManager) {
+	mm.Scheduler.Stop()
+	deadline := time.Now().Add(drainTimeout)
+	for mm.Stats().Running > 0 && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	fmt.Println("Restarting on the updated release")
+	if err := agent.Restart(path); err != nil {
+		// The agent exits, its supervisor starts the updated binary.
+		fmt.Printf("Unable to restart: %v\n", err)
+	}
+}
+
+// newConfigFetcher returns the fetcher of the signed config of the agent,
+// nil to fetch the monitors alone when no control plane key is set.
+func newConfigFetcher(client *agent.Client) *agent.ConfigFetcher {
+	serverKey := getEnv("OPENSTATUS_CONTROL_PLANE_KEY", "")
+	if serverKey == "" {
+		return nil
+	}
+	key, err := agent.ParsePublicKey(serverKey)
+	if err != nil {
+		fmt.Printf("Invalid OPENSTATUS_CONTROL_PLANE_KEY, remote config disabled: %v\n", err)
+		return nil
+	}
+
+	return &agent.ConfigFetcher{Client: client, ServerKey: key}
+}
+
+// fetchConfig applies the config of the agent when it changed, and returns
+// its refresh interval, 0 to keep the current one.
+func fetchConfig(ctx context.Context, fetcher *agent.ConfigFetcher, mm *scheduler.MonitorManager) time.Duration {
+	config, err := fetcher.Fetch(ctx)
+	if err != nil {
+		fmt.Printf("Failed to fetch agent config: %v\n", err)
+		return 0
+	}
+	if config == nil {
+		return 0
+	}
+
+	mm.Apply(config.Monitors)
+
+	return config.Settings.Refresh()
+}
+
 func getEnv(key, fallback string) string {
 	if value, ok := os.LookupEnv(key); ok {
 		return value
@@ -66,8 +219,102 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+func ingestURL() string {
+	return getEnv("OPENSTATUS_INGEST_URL", "https://openstatus-private-location.fly.dev")
+}
+
+// newAgent returns the client of the agent RPCs of the control plane, and
+// the registration of the agent: its region name, labels, capabilities and
+// public key. Agents only register when OPENSTATUS_AGENT_REGISTER is true,
+// the client is nil otherwise.
+func newAgent(client v1.PrivateLocationServiceClient) (*agent.Client, *v1.RegisterRequest, error) {
+	if enabled, _ := strconv.ParseBool(getEnv("OPENSTATUS_AGENT_REGISTER", "false")); !enabled {
+		return nil, nil, nil
+	}
+
+	path, err := agentKeyPath()
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := agent.LoadKey(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	labels, err := agent.ParseLabels(getEnv("OPENSTATUS_AGENT_LABELS", ""))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid OPENSTATUS_AGENT_LABELS: %w", err)
+	}
+	region := getEnv("OPENSTATUS_AGENT_REGION", "")
+	if region == "" {
+		region, _ = os.Hostname()
+	}
+
+	agentClient := &agent.Client{RPC: client, Key: key}
+
+	return agentClient, &v1.RegisterRequest{
+		Region:       region,
+		Labels:       labels,
+		Capabilities: capabilities,
+		PublicKey:    agentClient.PublicKey(),
+		Version:      version,
+	}, nil
+}
+
+// agentKeyPath is where the key of the agent is kept across restarts:
+// OPENSTATUS_AGENT_KEY_FILE, or openstatus/agent.key in the config directory
+// of the user.
+func agentKeyPath() (string, error) {
+	if path := getEnv("OPENSTATUS_AGENT_KEY_FILE", ""); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("set OPENSTATUS_AGENT_KEY_FILE to keep the agent key: %w", err)
+	}
+
+	return filepath.Join(dir, "openstatus", "agent.key"), nil
+}
+
+// register announces the agent to the control plane, so it appears as a
+// private location.
+func register(ctx context.Context, client *agent.Client, reg *v1.RegisterRequest) {
+	location, err := client.RegisterUntilDone(ctx, reg)
+	if err != nil {
+		fmt.Printf("Unable to register agent: %v\n", err)
+		return
+	}
+	fmt.Printf("Registered agent %s as private location %s\n", reg.GetRegion(), location)
+}
+
+// heartbeat reports the liveness of the agent until ctx is done, so the
+// control plane stops scheduling on a stale or wedged one.
+func heartbeat(ctx context.Context, client *agent.Client, reg *v1.RegisterRequest, mm *scheduler.MonitorManager) {
+	interval, err := time.ParseDuration(getEnv("OPENSTATUS_AGENT_HEARTBEAT_INTERVAL", "30s"))
+	if err != nil || interval <= 0 {
+		fmt.Printf("Invalid OPENSTATUS_AGENT_HEARTBEAT_INTERVAL, using 30s: %v\n", err)
+		interval = 30 * time.Second
+	}
+
+	started := time.Now()
+	client.Beat(ctx, interval, func() *v1.HeartbeatRequest {
+		stats := mm.Stats()
+		hb := &v1.HeartbeatRequest{
+			Region:        reg.GetRegion(),
+			Version:       version,
+			UptimeSeconds: int64(time.Since(started).Seconds()),
+			QueueDepth:    int64(stats.Running),
+			Monitors:      int64(stats.Monitors),
+		}
+		if !stats.LastIngest.IsZero() {
+			hb.LastIngest = stats.LastIngest.UnixMilli()
+		}
+
+		return hb
+	})
+}
+
 func getClient(apiKey string) v1.PrivateLocationServiceClient {
-	ingestUrl := getEnv("OPENSTATUS_INGEST_URL", "https://openstatus-private-location.fly.dev")
+	ingestUrl := ingestURL()
 
 	client := v1.NewPrivateLocationServiceClient(
 		http.DefaultClient,
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/content"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/dev"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/mailbox"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/oauth2"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+const runUsage = `usage: checker run <type> <target> [flags]
+
+Runs a single check locally, without reporting it, and prints its result as
+the /check endpoint answers it. The type is one of http, tcp, dns, content,
+crawl, grpc, smtp, email, ports, oidc or download, the target the URL,
+host:port, domain, host or issuer checked. The smtp checks need the mailbox of
+MAIL_CHECK_IMAP and MAIL_CHECK_ADDRESS.
+
+flags:
+`
+
+// listFlag collects the values of a flag repeated on the command line.
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ", ") }
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// run executes a single check locally, e.g.
+//
+//	checker run http https://openstat.us --assert status=200
+//
+// and prints its envelope, the result of the /check endpoint, on stdout.
+// It returns the exit code: 0 when the target is up, even degraded, 1 when
+// the check failed and 2 when it could not run.
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, runUsage)
+		fs.PrintDefaults()
+	}
+	var asserts, headers, selectors, openPorts, closedPorts, keyIDs listFlag
+	fs.Var(&asserts, "assert", "assertion as <subject><op><target>, e.g. status=200 or header.content-type~json, repeatable")
+	fs.Var(&headers, "header", `request header as "Key: Value", repeatable`)
+	fs.Var(&selectors, "dkim", "DKIM selector of the email checks, repeatable")
+	fs.Var(&openPorts, "open", "port or range of ports, e.g. 8000-8010, expected open by the ports checks, repeatable")
+	fs.Var(&closedPorts, "closed", "port or range of ports expected closed by the ports checks, repeatable")
+	fs.Var(&keyIDs, "kid", "id of a key expected in the JWKS of the oidc checks, repeatable")
+	method := fs.String("method", "", "HTTP method, GET by default, or the package.Service/Method of the gRPC checks")
+	body := fs.String("body", "", "body of the HTTP request, or the JSON of the gRPC request message")
+	selector := fs.String("selector", "", "CSS selector of the content checks")
+	from := fs.String("from", "", "sender of the message of the smtp checks")
+	sha := fs.String("sha256", "", "SHA-256 expected of the file of the download checks")
+	timeout := fs.Duration("timeout", 0, "timeout of the check, the default of its type when unset")
+	requestFile := fs.String("request", "", "JSON file of the request of the check, as sent to /check, the flags overriding its fields")
+
+	if len(args) < 2 || strings.HasPrefix(args[0], "-") || strings.HasPrefix(args[1], "-") {
+		fs.Usage()
+		return 2
+	}
+	checkType, target := args[0], args[1]
+	if err := fs.Parse(args[2:]); err != nil {
+		return 2
+	}
+
+	check := map[string]any{}
+	if *requestFile != "" {
+		data, err := os.ReadFile(*requestFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "unable to read -request: %v\n", err)
+			return 2
+		}
+		if err := json.Unmarshal(data, &check); err != nil {
+			fmt.Fprintf(stderr, "invalid -request: %v\n", err)
+			return 2
+		}
+	}
+	check["type"] = checkType
+	// The checks of monitors need their IDs, a local run has none.
+	for key, value := range map[string]string{"status": "active", "workspaceId": "0", "monitorId": "0"} {
+		if _, ok := check[key]; !ok {
+			check[key] = value
+		}
+	}
+	switch checkType {
+	case "tcp", "dns", "grpc", "smtp", "email", "ports":
+		check["uri"] = target
+	default:
+		check["url"] = target
+	}
+	if checkType == "http" {
+		if *method != "" {
+			check["method"] = *method
+		} else if _, ok := check["method"]; !ok {
+			check["method"] = http.MethodGet
+		}
+		if *body != "" {
+			check["body"] = *body
+		}
+	}
+	if checkType == "grpc" {
+		if *method != "" {
+			check["method"] = *method
+		}
+		if *body != "" {
+			if !json.Valid([]byte(*body)) {
+				fmt.Fprintln(stderr, "invalid -body, expected the JSON of the request message")
+				return 2
+			}
+			check["body"] = json.RawMessage(*body)
+		}
+	}
+	if *selector != "" {
+		check["selector"] = *selector
+	}
+	if *from != "" {
+		check["from"] = *from
+	}
+	if len(selectors) > 0 {
+		check["selectors"] = []string(selectors)
+	}
+	if len(openPorts) > 0 {
+		check["open"] = []string(openPorts)
+	}
+	if len(closedPorts) > 0 {
+		check["closed"] = []string(closedPorts)
+	}
+	if *sha != "" {
+		check["sha256"] = *sha
+	}
+	if len(keyIDs) > 0 {
+		check["keyIds"] = []string(keyIDs)
+	}
+	if *timeout > 0 {
+		check["timeout"] = timeout.Milliseconds()
+	}
+	if len(headers) > 0 {
+		var list []map[string]string
+		for _, header := range headers {
+			key, value, ok := strings.Cut(header, ":")
+			if !ok {
+				fmt.Fprintf(stderr, "invalid -header %q, expected \"Key: Value\"\n", header)
+				return 2
+			}
+			list = append(list, map[string]string{"key": strings.TrimSpace(key), "value": strings.TrimSpace(value)})
+		}
+		check["headers"] = list
+	}
+	if len(asserts) > 0 {
+		var list []json.RawMessage
+		for _, a := range asserts {
+			assertion, err := request.ParseAssertion(a)
+			if err != nil {
+				fmt.Fprintln(stderr, err)
+				return 2
+			}
+			list = append(list, assertion)
+		}
+		check["assertions"] = list
+	}
+
+	var inbox *mailbox.Mailbox
+	if rawURL := env("MAIL_CHECK_IMAP", ""); rawURL != "" {
+		var err error
+		if inbox, err = mailbox.Parse(rawURL, env("MAIL_CHECK_ADDRESS", "")); err != nil {
+			fmt.Fprintf(stderr, "invalid MAIL_CHECK_IMAP: %v\n", err)
+			return 2
+		}
+	}
+
+	code, answer := runCheck(check, inbox)
+	var envelope handlers.Envelope
+	if err := json.Unmarshal(answer, &envelope); err != nil {
+		fmt.Fprintf(stderr, "unexpected answer: %s\n", bytes.TrimSpace(answer))
+		return 2
+	}
+	var out bytes.Buffer
+	_ = json.Indent(&out, answer, "", "  ")
+	fmt.Fprintln(stdout, strings.TrimSpace(out.String()))
+
+	switch {
+	case code != http.StatusOK:
+		return 2
+	case envelope.Status == handlers.StatusError:
+		return 1
+	}
+
+	return 0
+}
+
+// runCheck runs check through the /check endpoint of a checker reporting
+// nothing, as a dry run, and returns the status code and body of its answer.
+// The SMTP checks are delivered to inbox, rejected when nil.
+func runCheck(check map[string]any, inbox *mailbox.Mailbox) (int, []byte) {
+	body, _ := json.Marshal(check)
+
+	gin.SetMode(gin.ReleaseMode)
+	h := &handlers.Handler{
+		Secret:        "local",
+		CloudProvider: "local",
+		Region:        env("REGION", "local"),
+		Version:       version,
+		TbClient:      &dev.Sink{},
+		Tokens:        oauth2.NewCache(),
+		Contents:      content.NewStore(),
+		Mailbox:       inbox,
+	}
+	router := gin.New()
+	router.POST("/check", h.CheckHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/check?dryRun=true", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Basic "+h.Secret)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	return w.Code, w.Body.Bytes()
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/probes"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// probeHTTPAddresses runs the check against every address of its host. The
+// requests still carry the Host header and SNI of the URL.
+func (h Handler) probeHTTPAddresses(ctx context.Context, req request.HttpCheckerRequest, client *http.Client) ([]checker.AddressResult, error) {
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	return checker.ProbeAddresses(ctx, u.Hostname(), req.IPFamily, func(ctx context.Context, ip netip.Addr) checker.AddressResult {
+		t := h.transport(probes.HTTPConnection(req))
+		t.DialContext = checker.DialTo(t.DialContext, ip.String())
+		defer t.CloseIdleConnections()
+
+		pinned := *client
+		pinned.Transport = h.authenticate(t, req.Auth, req.URL)
+
+		res, err := checker.Http(ctx, &pinned, req)
+		if err != nil {
+			return checker.AddressResult{Error: err.Error()}
+		}
+
+		result := checker.AddressResult{Latency: res.Latency, Status: res.Status, Error: res.Error}
+		headers, _ := json.Marshal(res.Headers)
+		ok, err := EvaluateHTTPAssertions(req.RawAssertions, PingData{Headers: string(headers), Body: res.Body}, res)
+		if result.Error == "" && (err != nil || !ok) {
+			result.Error = fmt.Sprintf("assertion failed with status code %d", res.Status)
+		}
+
+		return result
+	})
+}
+
+// probeTCPAddresses dials every address of the host of the check, and fails
+// when one of them cannot be reached.
+func (h Handler) probeTCPAddresses(ctx context.Context, req request.TCPCheckerRequest) ([]checker.AddressResult, error) {
+	host, port, err := net.SplitHostPort(req.Address())
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := checker.ProbeAddresses(ctx, host, req.IPFamily, func(ctx context.Context, ip netip.Addr) checker.AddressResult {
+		opts := h.prober().TCPOptions(req)
+		if opts.TLSConfig != nil && opts.TLSConfig.ServerName == "" {
+			opts.TLSConfig.ServerName = host
+		}
+
+		res, err := checker.DialTCP(ctx, net.JoinHostPort(ip.String(), port), opts)
+		if err != nil {
+			return checker.AddressResult{Error: err.Error()}
+		}
+
+		return checker.AddressResult{Latency: res.Timing.TCPDone - res.Timing.TCPStart}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, checker.FailedAddress(results)
+}
+
+// raceFamilies races the IPv4 and IPv6 connections to address, a host:port,
+// each within timeout, failing when a family of the host cannot connect.
+func (h Handler) raceFamilies(ctx context.Context, address string, timeout time.Duration) (*checker.DualStackResult, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := checker.RaceFamilies(ctx, h.Guard.Dialer(timeout), host, port)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, res.Err()
+}
+
+// httpAddress returns the host:port an HTTP check connects to.
+func httpAddress(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	port := u.Port()
+	if port == "" {
+		port = request.DefaultPorts[u.Scheme]
+	}
+
+	return net.JoinHostPort(u.Hostname(), port)
+}
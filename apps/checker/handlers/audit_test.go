@@ -0,0 +1,102 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/audit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/statsd"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/tinybird"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+type auditSink []audit.Entry
+
+func (s *auditSink) Record(_ context.Context, e audit.Entry) error {
+	*s = append(*s, e)
+	return nil
+}
+
+func TestHandler_Audit(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	tbClient := tinybird.NewClient(&http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(""))}
+	})}, "apiKey")
+
+	var sink auditSink
+	h := handlers.Handler{TbClient: tbClient, Secret: "test", CloudProvider: "local", Region: "local", Audit: &sink}
+	router := gin.New()
+	router.POST("/checker/http", h.HTTPCheckerHandler)
+
+	body, _ := json.Marshal(request.HttpCheckerRequest{
+		URL: target.URL, Method: http.MethodGet, Status: "active", Timeout: 1000,
+		WorkspaceID: "1", MonitorID: "2",
+	})
+	req, _ := http.NewRequest(http.MethodPost, "/checker/http", strings.NewReader(string(body)))
+	req.Header.Set("Authorization", "Basic test")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.Len(t, sink, 1)
+	assert.Equal(t, "cron", sink[0].Trigger)
+	assert.Equal(t, "1", sink[0].WorkspaceID)
+	assert.Equal(t, "2", sink[0].MonitorID)
+	assert.Equal(t, target.URL, sink[0].Target)
+	assert.Equal(t, "http", sink[0].Type)
+	assert.Equal(t, handlers.StatusSuccess, sink[0].Outcome)
+}
+
+func TestHandler_MetricsWithoutAudit(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+	client, err := statsd.New(conn.LocalAddr().String(), "openstatus")
+	require.NoError(t, err)
+	defer client.Close()
+
+	tbClient := tinybird.NewClient(&http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(""))}
+	})}, "apiKey")
+
+	h := handlers.Handler{TbClient: tbClient, Secret: "test", CloudProvider: "local", Region: "local", StatsD: client}
+	router := gin.New()
+	router.POST("/checker/http", h.HTTPCheckerHandler)
+
+	body, _ := json.Marshal(request.HttpCheckerRequest{
+		URL: target.URL, Method: http.MethodGet, Status: "active", Timeout: 1000,
+		WorkspaceID: "1", MonitorID: "2",
+	})
+	req, _ := http.NewRequest(http.MethodPost, "/checker/http", strings.NewReader(string(body)))
+	req.Header.Set("Authorization", "Basic test")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	buf := make([]byte, 1024)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(buf[:n]), "openstatus.check.latency:"), string(buf[:n]))
+}
@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// NDJSONContentType is the Accept header of a batch streaming its results,
+// one JSON object per line.
+const NDJSONContentType = "application/x-ndjson"
+
+// batchConcurrency bounds the checks of a batch running at once.
+const batchConcurrency = 50
+
+// The bounds of the pages of the results of a batch.
+const (
+	defaultBatchPage = 100
+	maxBatchPage     = 1000
+)
+
+// BatchResult is the envelope of the check at Index in the checks of its
+// batch.
+type BatchResult struct {
+	Index  int             `json:"index"`
+	Result json.RawMessage `json:"result"`
+}
+
+// BatchPage is a page of the results of a batch, by index. NextCursor reads
+// the next page from /v2/batch/:id, and is unset on the last page.
+type BatchPage struct {
+	ID         string        `json:"id,omitempty"`
+	Results    []BatchResult `json:"results"`
+	Total      int           `json:"total"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+}
+
+// Batches keeps the results of the batches larger than a page, for their
+// next pages to be read until ttl after the batch completed.
+type Batches struct {
+	now       func() time.Time
+	entries   map[string]*batchEntry
+	nextSweep time.Time
+	ttl       time.Duration
+	mu        sync.Mutex
+}
+
+type batchEntry struct {
+	expires time.Time
+	// owner is the hash of the credentials of the batch, so its results
+	// cannot be read by someone else.
+	owner   string
+	results []BatchResult
+}
+
+// NewBatches returns the store of the results of the batches, or nil when
+// ttl is not positive, every batch then answering all its results at once.
+func NewBatches(ttl time.Duration) *Batches {
+	if ttl <= 0 {
+		return nil
+	}
+
+	return &Batches{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*batchEntry),
+	}
+}
+
+func (b *Batches) add(owner string, results []BatchResult) (string, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if now.After(b.nextSweep) {
+		for k, e := range b.entries {
+			if now.After(e.expires) {
+				delete(b.entries, k)
+			}
+		}
+		b.nextSweep = now.Add(b.ttl)
+	}
+	b.entries[id.String()] = &batchEntry{expires: now.Add(b.ttl), owner: owner, results: results}
+
+	return id.String(), nil
+}
+
+func (b *Batches) get(id, owner string) ([]BatchResult, bool) {
+	if b == nil {
+		return nil, false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e, ok := b.entries[id]
+	if !ok || e.owner != owner || b.now().After(e.expires) {
+		return nil, false
+	}
+
+	return e.results, true
+}
+
+// BatchHandler runs the checks of a batch, each as /check would, in
+// parallel. With an Accept of NDJSONContentType, their results are streamed
+// as they complete, one BatchResult per line. Otherwise the batch answers
+// the first page of its results once they all completed, the next ones
+// being read from /v2/batch/:id.
+func (h Handler) BatchHandler(c *gin.Context) {
+	c.Set(v2Key, true)
+
+	if !h.authorized(c) {
+
+		return
+	}
+
+	var req request.BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalid(c, err)
+
+		return
+	}
+	if err := req.Validate(); err != nil {
+		invalid(c, err)
+
+		return
+	}
+	limit, err := batchLimit(c)
+	if err != nil {
+		invalid(c, err)
+
+		return
+	}
+
+	results := make(chan BatchResult)
+	go h.runBatch(c, req.Checks, results)
+
+	if strings.Contains(c.GetHeader("Accept"), NDJSONContentType) {
+		c.Header("Content-Type", NDJSONContentType)
+		c.Status(http.StatusOK)
+
+		enc := json.NewEncoder(c.Writer)
+		var err error
+		// Drained even once the client is gone, for the checks to return.
+		for result := range results {
+			if err == nil {
+				err = enc.Encode(result)
+				c.Writer.Flush()
+			}
+		}
+
+		return
+	}
+
+	all := make([]BatchResult, len(req.Checks))
+	for result := range results {
+		all[result.Index] = result
+	}
+
+	page := BatchPage{Results: all, Total: len(all)}
+	if h.Batches != nil && len(all) > limit {
+		page.ID, err = h.Batches.add(batchOwner(c), all)
+		if err != nil {
+			log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to keep batch results")
+		} else {
+			page.Results, page.NextCursor = batchPage(all, 0, limit)
+		}
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// BatchResultsHandler answers the page of the results of a batch starting
+// at its cursor.
+func (h Handler) BatchResultsHandler(c *gin.Context) {
+	c.Set(v2Key, true)
+
+	if !h.authorized(c) {
+
+		return
+	}
+
+	results, ok := h.Batches.get(c.Param("id"), batchOwner(c))
+	if !ok {
+		fail(c, http.StatusNotFound, ErrCodeNotFound, "unknown or expired batch")
+
+		return
+	}
+	limit, err := batchLimit(c)
+	if err != nil {
+		invalid(c, err)
+
+		return
+	}
+	offset := 0
+	if cursor := c.Query("cursor"); cursor != "" {
+		offset, err = strconv.Atoi(cursor)
+		if err != nil || offset < 0 || offset > len(results) {
+			invalid(c, request.ValidationError{{Field: "cursor", Reason: "is not a cursor of the batch", Value: cursor}})
+
+			return
+		}
+	}
+
+	page := BatchPage{ID: c.Param("id"), Total: len(results)}
+	page.Results, page.NextCursor = batchPage(results, offset, limit)
+	c.JSON(http.StatusOK, page)
+}
+
+// runBatch runs checks through CheckHandler, batchConcurrency at once, and
+// sends their results as they complete, closing results once they all did.
+func (h Handler) runBatch(c *gin.Context, checks []json.RawMessage, results chan<- BatchResult) {
+	defer close(results)
+
+	router := gin.New()
+	router.Use(gin.Recovery())
+	// The batch itself was authenticated.
+	router.POST("/check", func(c *gin.Context) {
+		c.Set(authenticatedKey, true)
+		h.CheckHandler(c)
+	})
+	path := "/check"
+	if dryRun(c) {
+		path += "?dryRun=true"
+	}
+
+	sem := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+
+			results <- BatchResult{Index: i, Result: runBatchCheck(c, router, path, check)}
+		})
+	}
+	wg.Wait()
+}
+
+func runBatchCheck(c *gin.Context, router *gin.Engine, path string, check []byte) json.RawMessage {
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, path, bytes.NewReader(check))
+	if err != nil {
+		return batchError(err.Error())
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if body := bytes.TrimSpace(w.Body.Bytes()); json.Valid(body) {
+		return body
+	}
+
+	return batchError("check answered " + strconv.Itoa(w.Code))
+}
+
+// batchError is the envelope of a check of a batch which did not answer
+// one.
+func batchError(message string) json.RawMessage {
+	data, _ := json.Marshal(Envelope{
+		Status:   StatusError,
+		Error:    &EnvelopeError{Code: ErrCodeInternal, Message: message},
+		Attempts: []checker.Attempt{},
+	})
+
+	return data
+}
+
+// batchPage returns the results from offset on, at most limit of them, and
+// the cursor of the next page, if any.
+func batchPage(results []BatchResult, offset, limit int) ([]BatchResult, string) {
+	end := min(offset+limit, len(results))
+	if end == len(results) {
+		return results[offset:end], ""
+	}
+
+	return results[offset:end], strconv.Itoa(end)
+}
+
+func batchLimit(c *gin.Context) (int, error) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return defaultBatchPage, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 || limit > maxBatchPage {
+		return 0, request.ValidationError{{Field: "limit", Reason: "must be between 1 and " + strconv.Itoa(maxBatchPage), Value: raw}}
+	}
+
+	return limit, nil
+}
+
+func batchOwner(c *gin.Context) string {
+	sum := sha256.Sum256([]byte(c.GetHeader("Authorization")))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,120 @@
+package handlers_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/tinybird"
+)
+
+func TestHandler_Batch(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	tbClient := tinybird.NewClient(&http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(""))}
+	})}, "apiKey")
+
+	h := handlers.Handler{TbClient: tbClient, Secret: "test", CloudProvider: "local", Region: "local", Batches: handlers.NewBatches(time.Minute)}
+	router := gin.New()
+	v2 := router.Group("/v2", handlers.V2())
+	v2.POST("/batch", h.BatchHandler)
+	v2.GET("/batch/:id", h.BatchResultsHandler)
+
+	var checks []string
+	for i := range 3 {
+		checks = append(checks, fmt.Sprintf(`{"type":"http","url":%q,"method":"GET","status":"active","workspaceId":"1","monitorId":"%d","timeout":1000}`, target.URL, i+1))
+	}
+	// The last check is invalid, and answers its error.
+	checks = append(checks, `{"type":"smtp"}`)
+	body := `{"checks":[` + strings.Join(checks, ",") + `]}`
+
+	do := func(method, path, body, accept string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Basic test")
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	status := func(t *testing.T, result handlers.BatchResult) string {
+		var env handlers.Envelope
+		require.NoError(t, json.Unmarshal(result.Result, &env))
+		return env.Status
+	}
+
+	t.Run("paginated", func(t *testing.T) {
+		w := do(http.MethodPost, "/v2/batch?dryRun=true&limit=3", body, "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var page handlers.BatchPage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.Equal(t, 4, page.Total)
+		require.Len(t, page.Results, 3)
+		for i, result := range page.Results {
+			assert.Equal(t, i, result.Index)
+			assert.Equal(t, handlers.StatusSuccess, status(t, result))
+		}
+		require.NotEmpty(t, page.ID)
+		require.NotEmpty(t, page.NextCursor)
+
+		w = do(http.MethodGet, "/v2/batch/"+page.ID+"?limit=3&cursor="+page.NextCursor, "", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var next handlers.BatchPage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &next))
+		require.Len(t, next.Results, 1)
+		assert.Equal(t, 3, next.Results[0].Index)
+		assert.Equal(t, handlers.StatusError, status(t, next.Results[0]))
+		assert.Empty(t, next.NextCursor)
+	})
+
+	t.Run("one page", func(t *testing.T) {
+		w := do(http.MethodPost, "/v2/batch?dryRun=true", body, "")
+		var page handlers.BatchPage
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.Len(t, page.Results, 4)
+		assert.Empty(t, page.ID)
+		assert.Empty(t, page.NextCursor)
+	})
+
+	t.Run("streamed", func(t *testing.T) {
+		w := do(http.MethodPost, "/v2/batch?dryRun=true", body, handlers.NDJSONContentType)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, handlers.NDJSONContentType, w.Header().Get("Content-Type"))
+
+		seen := map[int]bool{}
+		scanner := bufio.NewScanner(w.Body)
+		for scanner.Scan() {
+			var result handlers.BatchResult
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &result))
+			seen[result.Index] = true
+		}
+		assert.Len(t, seen, 4)
+	})
+
+	t.Run("unknown batch", func(t *testing.T) {
+		w := do(http.MethodGet, "/v2/batch/unknown", "", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		w := do(http.MethodPost, "/v2/batch", `{"checks":[]}`, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
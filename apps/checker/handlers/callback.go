@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// callbackKey is the key of the callback of a check, posted its envelope by
+// respond.
+const callbackKey = "callback"
+
+// Callback is the body posted to the callbackUrl of a check once it
+// completed, its retries included.
+type Callback struct {
+	MonitorID   string   `json:"monitorId"`
+	WorkspaceID string   `json:"workspaceId"`
+	Result      Envelope `json:"result"`
+}
+
+// callbackTo registers url as the callback of the check, and reports
+// whether the request was answered because the callback is not allowed.
+func (h Handler) callbackTo(c *gin.Context, url, workspaceID, monitorID string) bool {
+	if url == "" {
+		return false
+	}
+	if h.Callbacks == nil {
+		invalid(c, request.ValidationError{{Field: "callbackUrl", Reason: "callbacks are not enabled on this checker", Value: url}})
+
+		return true
+	}
+	if h.blockedTarget(c, "callbackUrl", url, h.Guard.CheckURL) {
+		return true
+	}
+
+	c.Set(callbackKey, func(env Envelope) {
+		h.Callbacks.Send(c.Request.Context(), url, Callback{MonitorID: monitorID, WorkspaceID: workspaceID, Result: env})
+	})
+
+	return false
+}
+
+// notify posts env to the callback of the check, if any.
+func notify(c *gin.Context, env Envelope) {
+	value, ok := c.Get(callbackKey)
+	if !ok {
+		return
+	}
+	if callback, ok := value.(func(Envelope)); ok {
+		callback(env)
+	}
+}
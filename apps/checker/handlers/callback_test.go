@@ -0,0 +1,107 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/auth"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/callback"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/tinybird"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestHandler_Callback(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	verifier := auth.NewHMAC([]string{"callback"}, time.Minute)
+	received := make(chan handlers.Callback, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := verifier.Authenticate(r); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var cb handlers.Callback
+		_ = json.NewDecoder(r.Body).Decode(&cb)
+		received <- cb
+	}))
+	defer receiver.Close()
+
+	tbClient := tinybird.NewClient(&http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(""))}
+	})}, "apiKey")
+
+	// do checks with h, each monitor posting its own callback: the same one
+	// posted twice within a second is rejected as replayed.
+	do := func(h handlers.Handler, query, monitorID string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.POST("/checker/http", h.HTTPCheckerHandler)
+
+		body, _ := json.Marshal(request.HttpCheckerRequest{
+			URL: target.URL, Method: http.MethodGet, Status: "active", Timeout: 1000,
+			WorkspaceID: "1", MonitorID: monitorID, CallbackURL: receiver.URL,
+		})
+		req, _ := http.NewRequest(http.MethodPost, "/checker/http"+query, strings.NewReader(string(body)))
+		req.Header.Set("Authorization", "Basic test")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("posted", func(t *testing.T) {
+		sender := &callback.Sender{Secret: "callback"}
+		w := do(handlers.Handler{TbClient: tbClient, Secret: "test", CloudProvider: "local", Region: "local", Callbacks: sender}, "", "2")
+		require.Equal(t, http.StatusOK, w.Code)
+		sender.Wait()
+
+		select {
+		case cb := <-received:
+			assert.Equal(t, "2", cb.MonitorID)
+			assert.Equal(t, "1", cb.WorkspaceID)
+			assert.Equal(t, handlers.StatusSuccess, cb.Result.Status)
+		default:
+			t.Fatal("callback not posted")
+		}
+	})
+
+	t.Run("whole result whatever the verbosity", func(t *testing.T) {
+		sender := &callback.Sender{Secret: "callback"}
+		w := do(handlers.Handler{TbClient: tbClient, Secret: "test", CloudProvider: "local", Region: "local", Callbacks: sender}, "?verbosity=timing", "3")
+		require.Equal(t, http.StatusOK, w.Code)
+		sender.Wait()
+
+		var res checker.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		assert.Nil(t, res.Headers)
+		assert.Nil(t, res.Attempts)
+
+		select {
+		case cb := <-received:
+			assert.Equal(t, "3", cb.MonitorID)
+			require.NotNil(t, cb.Result.HTTP)
+			assert.NotEmpty(t, cb.Result.HTTP.Headers)
+			assert.NotEmpty(t, cb.Result.Attempts)
+		default:
+			t.Fatal("callback not posted")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		w := do(handlers.Handler{TbClient: tbClient, Secret: "test", CloudProvider: "local", Region: "local"}, "", "2")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"field":"callbackUrl"`)
+	})
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// checkHandlers maps each of the checkTypes to the handler of its scheduled
+// checks.
+func (h Handler) checkHandlers() map[string]gin.HandlerFunc {
+	handlers := map[string]gin.HandlerFunc{
+		"http":     h.HTTPCheckerHandler,
+		"tcp":      h.TCPHandler,
+		"dns":      h.DNSHandler,
+		"content":  h.ContentHandler,
+		"crawl":    h.CrawlHandler,
+		"grpc":     h.GRPCHandler,
+		"email":    h.EmailHandler,
+		"ports":    h.PortsHandler,
+		"oidc":     h.OIDCHandler,
+		"download": h.DownloadHandler,
+	}
+	if h.Mailbox != nil {
+		handlers["smtp"] = h.SMTPHandler
+	}
+
+	return handlers
+}
+
+// CheckHandler runs the scheduled check of any type, read from the type field
+// of the body, and answers its envelope. The control plane posts every check
+// to the same route, and a new check type needs no route of its own.
+func (h Handler) CheckHandler(c *gin.Context) {
+	c.Set(v2Key, true)
+
+	if !h.authorized(c) {
+
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		invalid(c, err)
+
+		return
+	}
+	// The handler of the type decodes the body again.
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var req request.CheckRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		invalid(c, err)
+
+		return
+	}
+
+	handler, ok := h.checkHandlers()[req.Type]
+	if !ok {
+		invalid(c, request.ValidationError{{Field: "type", Reason: "must be one of " + strings.Join(h.checkTypes(), ", "), Value: req.Type}})
+
+		return
+	}
+
+	handler(c)
+}
@@ -0,0 +1,74 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/tinybird"
+)
+
+func TestHandler_CheckHandler(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	tbClient := tinybird.NewClient(&http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(""))}
+	})}, "apiKey")
+
+	h := handlers.Handler{TbClient: tbClient, Secret: "test", CloudProvider: "local", Region: "local"}
+	router := gin.New()
+	router.POST("/check", h.CheckHandler)
+
+	check := func(body string) (*httptest.ResponseRecorder, handlers.Envelope) {
+		req, _ := http.NewRequest(http.MethodPost, "/check", strings.NewReader(body))
+		req.Header.Set("Authorization", "Basic test")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var env handlers.Envelope
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+		return w, env
+	}
+
+	t.Run("dispatches on the type", func(t *testing.T) {
+		w, env := check(`{"type":"http","url":"` + target.URL + `","method":"GET","status":"active","timeout":1000}`)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, handlers.StatusSuccess, env.Status)
+		assert.Equal(t, "http", env.Type)
+	})
+
+	t.Run("request of the type validated", func(t *testing.T) {
+		w, env := check(`{"type":"http","url":"ftp://openstat.us"}`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.NotNil(t, env.Error)
+		assert.Equal(t, handlers.ErrCodeInvalidRequest, env.Error.Code)
+		assert.Equal(t, "url", env.Error.Fields[0].Field)
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		w, env := check(`{"type":"icmp","host":"openstat.us"}`)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.NotNil(t, env.Error)
+		require.Len(t, env.Error.Fields, 1)
+		assert.Equal(t, "type", env.Error.Fields[0].Field)
+		assert.Equal(t, "must be one of http, tcp, dns, content, crawl, grpc, email, ports, oidc, download", env.Error.Fields[0].Reason)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPost, "/check", strings.NewReader(`{"type":"http"}`))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
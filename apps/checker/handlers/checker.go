@@ -1,19 +1,25 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
 
-	"github.com/cenkalti/backoff/v4"
+	"github.com/cenkalti/backoff/v5"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 
 	"github.com/openstatushq/openstatus/apps/checker/checker"
-	"github.com/openstatushq/openstatus/apps/checker/pkg/assertions"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/audit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/dependency"
 	otelOS "github.com/openstatushq/openstatus/apps/checker/pkg/otel"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/probes"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/retry"
 	"github.com/openstatushq/openstatus/apps/checker/request"
 )
 
@@ -24,74 +30,98 @@ func (s statusCode) IsSuccessful() bool {
 }
 
 type PingData struct {
-	ID            string `json:"id"`
-	WorkspaceID   string `json:"workspaceId"`
-	MonitorID     string `json:"monitorId"`
-	URL           string `json:"url"`
-	Method        string `json:"method"`
-	Region        string `json:"region"`
-	Message       string `json:"message,omitempty"`
-	Timing        string `json:"timing,omitempty"`
-	Headers       string `json:"headers,omitempty"`
-	Assertions    string `json:"assertions"`
-	Body          string `json:"body,omitempty"`
-	Trigger       string `json:"trigger,omitempty"`
-	RequestStatus string `json:"requestStatus,omitempty"`
-	Latency       int64  `json:"latency"`
-	CronTimestamp int64  `json:"cronTimestamp"`
-	Timestamp     int64  `json:"timestamp"`
-	StatusCode    int    `json:"statusCode,omitempty"`
-	Error         uint8  `json:"error"`
+	ID              string `json:"id"`
+	WorkspaceID     string `json:"workspaceId"`
+	MonitorID       string `json:"monitorId"`
+	URL             string `json:"url"`
+	Method          string `json:"method"`
+	Region          string `json:"region"`
+	Message         string `json:"message,omitempty"`
+	Timing          string `json:"timing,omitempty"`
+	Headers         string `json:"headers,omitempty"`
+	Assertions      string `json:"assertions"`
+	ErrorCode       string `json:"errorCode,omitempty"`
+	Body            string `json:"body,omitempty"`
+	BodyHash        string `json:"bodyHash,omitempty"`
+	SecurityHeaders string `json:"securityHeaders,omitempty"`
+	CapturedHeaders string `json:"capturedHeaders,omitempty"`
+	Trigger         string `json:"trigger,omitempty"`
+	RequestStatus   string `json:"requestStatus,omitempty"`
+	Labels          string `json:"labels,omitempty"`
+	RemoteIP        string `json:"remoteIp,omitempty"`
+	ASOrganization  string `json:"asOrganization,omitempty"`
+	Country         string `json:"country,omitempty"`
+	ASN             uint   `json:"asn,omitempty"`
+	Latency         int64  `json:"latency"`
+	CronTimestamp   int64  `json:"cronTimestamp"`
+	ScheduleOffset  int64  `json:"scheduleOffset,omitempty"`
+	Timestamp       int64  `json:"timestamp"`
+	StatusCode      int    `json:"statusCode,omitempty"`
+	Attempts        int    `json:"attempts"`
+	Error           uint8  `json:"error"`
 }
 
 func (h Handler) HTTPCheckerHandler(c *gin.Context) {
 	ctx := c.Request.Context()
-	const defaultRetry = 3
 	dataSourceName := "ping_response__v8"
 
-	if c.GetHeader("Authorization") != fmt.Sprintf("Basic %s", h.Secret) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	if !h.authorized(c) {
 
 		return
 	}
 
-	if h.CloudProvider == "fly" {
-		// if the request has been routed to a wrong region, we forward it to the correct one.
-		region := c.GetHeader("fly-prefer-region")
-		if region != "" && region != h.Region {
-			c.Header("fly-replay", fmt.Sprintf("region=%s", region))
-			c.String(http.StatusAccepted, "Forwarding request to %s", region)
-
-			return
-		}
+	// if the request has been routed to a wrong region, we forward it to the correct one.
+	if h.replayed(c, "") {
+		return
 	}
 
 	var req request.HttpCheckerRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
 		log.Ctx(ctx).Error().Err(err).Msg("failed to decode checker request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		invalid(c, err)
 
 		return
 	}
+	if err := req.Validate(); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid checker request")
+		invalid(c, err)
+
+		return
+	}
+	if h.blockedTarget(c, "url", req.URL, h.Guard.CheckURL) {
+		return
+	}
+	if proxyURL, _ := req.Proxy.ProxyURL(); proxyURL != nil && h.blockedTarget(c, "proxy.url", proxyURL.Redacted(), h.Guard.CheckURL) {
+		return
+	}
+	if req.ConnectTo != "" && h.blockedTarget(c, "connectTo", req.ConnectTo, h.Guard.CheckAddress) {
+		return
+	}
+	if req.Auth != nil && h.blockedTarget(c, "auth.tokenUrl", req.Auth.TokenURL, h.Guard.CheckURL) {
+		return
+	}
+
+	v, err := parseVerbosity(c)
+	if err != nil {
+		invalid(c, err)
+
+		return
+	}
+
+	if h.callbackTo(c, req.CallbackURL, req.WorkspaceID, req.MonitorID) {
+		return
+	}
+	if h.circuitOpen(c, req.MonitorID) {
+		return
+	}
+
 	//  We need a new client for each request to avoid connection reuse.
 	requestClient := &http.Client{
-		Timeout: time.Duration(req.Timeout) * time.Millisecond,
+		Timeout:   request.Timeout("http", req.Timeout),
+		Transport: h.Recorder.Wrap(h.authenticate(h.transport(probes.HTTPConnection(req)), req.Auth, req.URL)),
 	}
 
-	// Configure redirect policy based on FollowRedirects setting
-	if !req.FollowRedirects {
-		requestClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		}
-	} else {
-		// Explicitly limit the number of redirects to 10 (Go's default)
-		requestClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-			if len(via) >= 10 {
-				return http.ErrUseLastResponse
-			}
-			return nil
-		}
-	}
+	requestClient.CheckRedirect = checker.CheckRedirect(req.FollowRedirects, req.MaxRedirects)
 	defer requestClient.CloseIdleConnections()
 
 	// Might be a more efficient way to do it
@@ -109,36 +139,61 @@ func (h Handler) HTTPCheckerHandler(c *gin.Context) {
 	}
 
 	var called int
+	var attempts []checker.Attempt
+	var skipped bool
+	// degraded is set when the check left its monitor degraded.
+	var degraded bool
+	offset := scheduleOffset(req.ScheduledAt)
 
-	var result checker.Response
+	policy := retry.FromRequest(req.RetryPolicy, req.Retry)
 
-	retry := defaultRetry
-	if req.Retry != 0 {
-		retry = int(req.Retry)
-	}
+	checkCtx, cancel := retry.WithDeadline(ctx, req.TotalDeadline)
+	defer cancel()
 
-	op := func() error {
+	op := func() (checker.Response, error) {
 		called++
-		res, err := checker.Http(ctx, requestClient, req)
+		start := time.Now()
+		var res checker.Response
+		err := h.Chaos.Inject(checkCtx, req.MonitorID)
+		if err == nil {
+			res, err = checker.Http(checkCtx, requestClient, req)
+		}
+		attempt := checker.NewAttempt(called, start, err)
+
+		// The caller went away, drop the result instead of writing stale events.
+		if ctx.Err() != nil {
+			return checker.Response{}, backoff.Permanent(context.Cause(ctx))
+		}
 
 		if err != nil {
-			return fmt.Errorf("unable to ping: %w", err)
+			attempts = append(attempts, attempt)
+			return checker.Response{}, policy.Wrap(fmt.Errorf("unable to ping: %w", err))
 		}
 
 		// In TB we need to store them as string
 		timingAsString, err := json.Marshal(res.Timing)
 		if err != nil {
-			return fmt.Errorf("error while parsing timing data %s: %w", req.URL, err)
+			return checker.Response{}, fmt.Errorf("error while parsing timing data %s: %w", req.URL, err)
 		}
 
 		headersAsString, err := json.Marshal(res.Headers)
 		if err != nil {
-			return fmt.Errorf("error while parsing headers %s: %w", req.URL, err)
+			return checker.Response{}, fmt.Errorf("error while parsing headers %s: %w", req.URL, err)
+		}
+
+		res.CapturedHeaders = checker.CaptureHeaders(res.Headers, req.CaptureHeaders)
+		if req.SecurityHeaders {
+			res.SecurityHeaders = checker.GradeSecurityHeaders(res.Headers, res.TLS != nil)
+		}
+		res.Geo = h.GeoIP.Lookup(res.RemoteIP)
+		var capturedHeaders []byte
+		if res.CapturedHeaders != nil {
+			capturedHeaders, _ = json.Marshal(res.CapturedHeaders)
 		}
 
 		id, err := uuid.NewV7()
 		if err != nil {
-			return fmt.Errorf("error while generating uuid %w", err)
+			return checker.Response{}, fmt.Errorf("error while generating uuid %w", err)
 		}
 
 		var requestStatus = ""
@@ -152,35 +207,101 @@ func (h Handler) HTTPCheckerHandler(c *gin.Context) {
 		}
 
 		data := PingData{
-			ID:            id.String(),
-			Latency:       res.Latency,
-			StatusCode:    res.Status,
-			MonitorID:     req.MonitorID,
-			Region:        h.Region,
-			WorkspaceID:   req.WorkspaceID,
-			Timestamp:     res.Timestamp,
-			CronTimestamp: req.CronTimestamp,
-			URL:           req.URL,
-			Method:        req.Method,
-			Timing:        string(timingAsString),
-			Headers:       string(headersAsString),
-			Body:          string(res.Body),
-			Trigger:       trigger,
-			RequestStatus: requestStatus,
+			ID:              id.String(),
+			Latency:         res.Latency,
+			StatusCode:      res.Status,
+			MonitorID:       req.MonitorID,
+			Region:          h.Region,
+			WorkspaceID:     req.WorkspaceID,
+			Timestamp:       res.Timestamp,
+			CronTimestamp:   req.CronTimestamp,
+			ScheduleOffset:  offset,
+			URL:             req.URL,
+			Method:          req.Method,
+			Timing:          string(timingAsString),
+			Headers:         string(headersAsString),
+			Body:            string(res.Body),
+			BodyHash:        res.BodyHash,
+			SecurityHeaders: securityHeadersJSON(res.SecurityHeaders),
+			CapturedHeaders: string(capturedHeaders),
+			Trigger:         trigger,
+			RequestStatus:   requestStatus,
+			Labels:          labelsJSON(req.Labels),
+			Attempts:        called,
+			RemoteIP:        res.RemoteIP,
+		}
+		if res.Geo != nil {
+			data.ASN, data.ASOrganization, data.Country = res.Geo.ASN, res.Geo.Organization, res.Geo.Country
 		}
 
 		var isSuccessfull bool = true
 		isSuccessfull, err = EvaluateHTTPAssertions(req.RawAssertions, data, res)
 		if err != nil {
-			return err
+			return checker.Response{}, err
+		}
+		var evidence *checker.Evidence
+		if !isSuccessfull {
+			evidence = httpEvidence(req.RawAssertions, data, res)
+		}
+
+		if req.AllAddresses {
+			addresses, addrErr := h.probeHTTPAddresses(checkCtx, req, requestClient)
+			if addrErr == nil {
+				addrErr = checker.FailedAddress(addresses)
+			}
+			res.Addresses = addresses
+			if addrErr != nil && isSuccessfull {
+				isSuccessfull = false
+				res.Error = addrErr.Error()
+				res.Failure = addrErr
+				evidence = httpEvidence(nil, data, res)
+			}
+		}
+
+		if req.DualStack {
+			dualStack, raceErr := h.raceFamilies(checkCtx, httpAddress(req.URL), request.Timeout("http", req.Timeout))
+			res.DualStack = dualStack
+			if raceErr != nil && isSuccessfull {
+				isSuccessfull = false
+				res.Error = raceErr.Error()
+				res.Failure = raceErr
+				evidence = httpEvidence(nil, data, res)
+			}
+		}
+
+		if req.Conditional && isSuccessfull {
+			conditional, revalidateErr := checker.Revalidate(checkCtx, requestClient, req, res)
+			res.Conditional = conditional
+			if revalidateErr != nil {
+				isSuccessfull = false
+				res.Error = revalidateErr.Error()
+				res.Failure = revalidateErr
+				evidence = httpEvidence(nil, data, res)
+			}
 		}
 
+		if req.Revocation == request.RevocationFail && res.TLS != nil && isSuccessfull {
+			if revErr := res.TLS.Revocation.Err(); revErr != nil {
+				isSuccessfull = false
+				res.Error = revErr.Error()
+				res.Failure = revErr
+				evidence = httpEvidence(nil, data, res)
+			}
+		}
+
+		if !isSuccessfull {
+			attempt.Error = res.Error
+			attempt.Class = res.ErrorClass()
+			attempt.Code = res.ErrorCode()
+		}
+		attempts = append(attempts, attempt)
+
 		// let's retry at least once if the status code is not successful.
-		if !isSuccessfull && called < retry {
-			return fmt.Errorf("unable to ping: %v with status %v", res, res.Status)
+		if !isSuccessfull && !policy.IsLastAttempt(called) && policy.Retryable(res.ErrorClass()) {
+			return checker.Response{}, fmt.Errorf("unable to ping: %v with status %v", res, res.Status)
 		}
 
-		result = res
+		result := res
 		result.Region = h.Region
 		result.JobType = "http"
 
@@ -197,14 +318,26 @@ func (h Handler) HTTPCheckerHandler(c *gin.Context) {
 			// Small trick to avoid sending the body at the moment to TB
 		} else {
 			data.Error = 1
+			data.ErrorCode = string(res.ErrorCode())
 			result.Error = "Error"
 		}
 
 		data.Assertions = assertionAsString
 
-		if !isSuccessfull && req.Status != "error" {
+		if capture := req.CaptureBody; capture != nil {
+			data.Body = ""
+			if !isSuccessfull || capture.Always || (req.DegradedAfter != 0 && res.Latency > req.DegradedAfter) {
+				data.Body = checker.CaptureBody(res.Body, res.Headers["Content-Type"], capture.MaxBytes)
+			}
+		}
+
+		skipped = !isSuccessfull && h.skippedDependency(c, req.DependsOn, req.CronTimestamp)
+		if skipped {
+			data.RequestStatus = dependency.StatusSkipped
+		}
+		if !isSuccessfull && !skipped && req.Status != "error" && h.confirmed(c, req.Confirm, h.confirmHTTP(checkCtx, requestClient, req)) && h.quorumReached(c, req.Quorum, "http") {
 			// Q: Why here we do not check if the status was previously active?
-			checker.UpdateStatus(ctx, checker.UpdateData{
+			h.updateStatus(c, checker.UpdateData{
 				MonitorId:     req.MonitorID,
 				Status:        "error",
 				StatusCode:    res.Status,
@@ -212,12 +345,16 @@ func (h Handler) HTTPCheckerHandler(c *gin.Context) {
 				Message:       res.Error,
 				CronTimestamp: req.CronTimestamp,
 				Latency:       res.Latency,
+				Evidence:      evidence,
 			})
 			data.RequestStatus = "error"
 		}
+		degraded = isSuccessfull && h.degraded(c, req.MonitorID, req.Status, res.Latency, req.DegradedAfter, req.RecoverBelow, req.DegradedWindow)
+		slow := slowPhases(httpTiming(res), req.DegradedPhases)
+		degraded = degraded || (isSuccessfull && len(slow) > 0)
 		// it's degraded
-		if isSuccessfull && req.DegradedAfter > 0 && res.Latency > req.DegradedAfter && req.Status != "degraded" {
-			checker.UpdateStatus(ctx, checker.UpdateData{
+		if degraded && req.Status != "degraded" {
+			h.updateStatus(c, checker.UpdateData{
 				MonitorId:     req.MonitorID,
 				Status:        "degraded",
 				Region:        h.Region,
@@ -228,20 +365,8 @@ func (h Handler) HTTPCheckerHandler(c *gin.Context) {
 			data.RequestStatus = "degraded"
 		}
 		// it's active
-		if isSuccessfull && req.DegradedAfter == 0 && req.Status != "active" {
-			checker.UpdateStatus(ctx, checker.UpdateData{
-				MonitorId:     req.MonitorID,
-				Status:        "active",
-				Region:        h.Region,
-				StatusCode:    res.Status,
-				CronTimestamp: req.CronTimestamp,
-				Latency:       res.Latency,
-			})
-			data.RequestStatus = "success"
-		}
-		// it's active
-		if isSuccessfull && res.Latency < req.DegradedAfter && req.DegradedAfter != 0 && req.Status != "active" {
-			checker.UpdateStatus(ctx, checker.UpdateData{
+		if isSuccessfull && !degraded && req.Status != "active" {
+			h.updateStatus(c, checker.UpdateData{
 				MonitorId:     req.MonitorID,
 				Status:        "active",
 				Region:        h.Region,
@@ -252,7 +377,7 @@ func (h Handler) HTTPCheckerHandler(c *gin.Context) {
 			data.RequestStatus = "success"
 		}
 
-		if err := h.TbClient.SendEvent(ctx, data, dataSourceName); err != nil {
+		if err := h.events(c).SendEvent(ctx, data, dataSourceName); err != nil {
 			log.Ctx(ctx).Error().Err(err).Msg("failed to send event to tinybird")
 		}
 
@@ -270,39 +395,57 @@ func (h Handler) HTTPCheckerHandler(c *gin.Context) {
 			c.Set("event", t)
 		}
 
-		return nil
+		return result, nil
+	}
+
+	result, err := backoff.Retry(checkCtx, op, policy.Options()...)
+	result.Attempts = attempts
+
+	if h.cancelled(c, req.MonitorID) {
+		return
 	}
+	h.recordOutcome(c, req.MonitorID, req.CronTimestamp, err != nil || result.Error != "")
 
-	if err := backoff.Retry(op, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), uint64(retry))); err != nil {
+	if err != nil {
 		id, e := uuid.NewV7()
 		if e != nil {
 			log.Ctx(ctx).Error().Err(e).Msg("failed to send event to tinybird")
 			return
 		}
 
+		skipped = h.skippedDependency(c, req.DependsOn, req.CronTimestamp)
+		requestStatus := "error"
+		if skipped {
+			requestStatus = dependency.StatusSkipped
+		}
+
 		data := PingData{
-			ID:            id.String(),
-			URL:           req.URL,
-			Method:        req.Method,
-			Region:        h.Region,
-			Message:       err.Error(),
-			CronTimestamp: req.CronTimestamp,
-			Timestamp:     req.CronTimestamp,
-			MonitorID:     req.MonitorID,
-			WorkspaceID:   req.WorkspaceID,
-			Error:         1,
-			Assertions:    assertionAsString,
-			Body:          "",
-			Trigger:       trigger,
-			RequestStatus: "error",
-		}
-
-		if err := h.TbClient.SendEvent(ctx, data, dataSourceName); err != nil {
+			ID:             id.String(),
+			URL:            req.URL,
+			Method:         req.Method,
+			Region:         h.Region,
+			Message:        err.Error(),
+			CronTimestamp:  req.CronTimestamp,
+			ScheduleOffset: offset,
+			Timestamp:      req.CronTimestamp,
+			MonitorID:      req.MonitorID,
+			WorkspaceID:    req.WorkspaceID,
+			Error:          1,
+			ErrorCode:      string(checker.ClassifyCode(err)),
+			Assertions:     assertionAsString,
+			Body:           "",
+			Trigger:        trigger,
+			RequestStatus:  requestStatus,
+			Labels:         labelsJSON(req.Labels),
+			Attempts:       called,
+		}
+
+		if err := h.events(c).SendEvent(ctx, data, dataSourceName); err != nil {
 			log.Ctx(ctx).Error().Err(err).Msg("failed to send event to tinybird")
 		}
 
-		if req.Status != "error" {
-			checker.UpdateStatus(ctx, checker.UpdateData{
+		if !skipped && req.Status != "error" && h.confirmed(c, req.Confirm, h.confirmHTTP(checkCtx, requestClient, req)) && h.quorumReached(c, req.Quorum, "http") {
+			h.updateStatus(c, checker.UpdateData{
 				MonitorId:     req.MonitorID,
 				Status:        "error",
 				Message:       err.Error(),
@@ -312,61 +455,89 @@ func (h Handler) HTTPCheckerHandler(c *gin.Context) {
 		}
 	}
 
-	if req.OtelConfig.Endpoint != "" {
+	if req.OtelConfig.Endpoint != "" && !dryRun(c) {
 		otelOS.RecordHTTPMetrics(ctx, req, result, h.Region)
 	}
 
-	returnData := c.Query("data")
-	if returnData == "true" {
-
-		if len(result.Body) > 1024 {
-			result.Body = result.Body[:1000]
-		}
+	if len(result.Body) > 1024 {
+		result.Body = result.Body[:1000]
+	}
+	env := httpEnvelope(h.Region, result, err, degraded)
+	env.slowPhases(req.DegradedPhases)
+	env.hostNames(req.URL)
+	if skipped {
+		env.Status = dependency.StatusSkipped
+	}
+	h.finish(c, audit.Entry{
+		Trigger:     trigger,
+		WorkspaceID: req.WorkspaceID,
+		MonitorID:   req.MonitorID,
+		Target:      req.URL,
+	}, env)
 
-		c.JSON(http.StatusOK, result)
+	if v.set {
+		respond(c, v.http(result), env)
 
 		return
 	}
 
-	c.JSON(http.StatusOK, nil)
+	respond(c, nil, env)
 }
 
+// EvaluateHTTPAssertions evaluates the assertions of an HTTP check against
+// the headers and body of data, see probes.HTTPAssertions.
 func EvaluateHTTPAssertions(raw []json.RawMessage, data PingData, res checker.Response) (bool, error) {
-	statusCode := statusCode(res.Status)
-	if len(raw) == 0 {
-		return statusCode.IsSuccessful(), nil
+	res.Headers = nil
+	_ = json.Unmarshal([]byte(data.Headers), &res.Headers)
+	res.Body = data.Body
+
+	return probes.HTTPAssertions(raw, res)
+}
+
+// confirmHTTP runs the check of req once more, for its confirmation.
+func (h Handler) confirmHTTP(ctx context.Context, client *http.Client, req request.HttpCheckerRequest) func() bool {
+	return func() bool {
+		if h.Chaos.Inject(ctx, req.MonitorID) != nil {
+			return true
+		}
+		res, err := checker.Http(ctx, client, req)
+		if err != nil {
+			return true
+		}
+		headers, _ := json.Marshal(res.Headers)
+		ok, err := EvaluateHTTPAssertions(req.RawAssertions, PingData{Headers: string(headers), Body: res.Body}, res)
+
+		return err != nil || !ok
+	}
+}
+
+// evidenceSnippetBytes is how much of the body is sent with a failed check.
+const evidenceSnippetBytes = 512
+
+// httpEvidence details the failure of an HTTP check for its status update:
+// the first of raw that failed, the start of the body and the address checked.
+func httpEvidence(raw []json.RawMessage, data PingData, res checker.Response) *checker.Evidence {
+	e := &checker.Evidence{ResolvedIP: res.RemoteIP, Geo: res.Geo}
+	if res.Body != "" {
+		e.Snippet = checker.CaptureBody(res.Body, res.Headers["Content-Type"], evidenceSnippetBytes)
 	}
-	isSuccessful := true
 	for _, a := range raw {
-		var assert request.Assertion
-		if err := json.Unmarshal(a, &assert); err != nil {
-			return false, fmt.Errorf("unable to unmarshal assertion: %w", err)
-		}
-		switch assert.AssertionType {
-		case request.AssertionHeader:
-			var target assertions.HeaderTarget
-			if err := json.Unmarshal(a, &target); err != nil {
-				return false, fmt.Errorf("unable to unmarshal HeaderTarget: %w", err)
-			}
-			isSuccessful = isSuccessful && target.HeaderEvaluate(data.Headers)
-		case request.AssertionTextBody:
-			var target assertions.StringTargetType
-			if err := json.Unmarshal(a, &target); err != nil {
-				return false, fmt.Errorf("unable to unmarshal StringTargetType: %w", err)
-			}
-			isSuccessful = isSuccessful && target.StringEvaluate(data.Body)
-		case request.AssertionStatus:
-			var target assertions.StatusTarget
-			if err := json.Unmarshal(a, &target); err != nil {
-				return false, fmt.Errorf("unable to unmarshal StatusTarget: %w", err)
-			}
-			isSuccessful = isSuccessful && target.StatusEvaluate(int64(res.Status))
-		case request.AssertionJsonBody:
-			// TODO: Implement JSON body assertion
-		default:
-			fmt.Println("unknown assertion type: ", assert.AssertionType)
-			// TODO: Handle unknown assertion type
+		if ok, err := EvaluateHTTPAssertions([]json.RawMessage{a}, data, res); err == nil && !ok {
+			e.Assertion = string(a)
+			break
 		}
 	}
-	return isSuccessful, nil
+
+	return e
+}
+
+// securityHeadersJSON encodes the grade of the security headers for the
+// Tinybird event, empty when they were not graded.
+func securityHeadersJSON(grade *checker.SecurityHeaders) string {
+	if grade == nil {
+		return ""
+	}
+	b, _ := json.Marshal(grade)
+
+	return string(b)
 }
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/latency"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestHTTPEvidence(t *testing.T) {
+	raw := []json.RawMessage{
+		json.RawMessage(`{"type":"status","compare":"eq","target":503}`),
+		json.RawMessage(`{"type":"textBody","compare":"contains","target":"ok"}`),
+	}
+	res := checker.Response{
+		Status:   503,
+		Body:     "upstream unavailable",
+		Headers:  map[string]string{"Content-Type": "text/plain"},
+		RemoteIP: "192.0.2.1",
+	}
+	data := PingData{Body: res.Body}
+
+	e := httpEvidence(raw, data, res)
+
+	assert.Equal(t, &checker.Evidence{
+		Assertion:  `{"type":"textBody","compare":"contains","target":"ok"}`,
+		Snippet:    "upstream unavailable",
+		ResolvedIP: "192.0.2.1",
+	}, e)
+}
+
+func TestConfirmed(t *testing.T) {
+	probes := func(outcomes ...bool) (func() bool, *int) {
+		runs := 0
+		return func() bool {
+			failed := outcomes[runs]
+			runs++
+			return failed
+		}, &runs
+	}
+
+	tests := []struct {
+		name      string
+		confirm   *request.Confirm
+		outcomes  []bool
+		confirmed bool
+		runs      int
+	}{
+		{"without confirm", nil, nil, true, 0},
+		{"every run fails", &request.Confirm{Checks: 3}, []bool{true, true}, true, 2},
+		{"a run succeeds", &request.Confirm{Checks: 3}, []bool{false}, false, 1},
+		{"enough runs fail", &request.Confirm{Checks: 3, Failures: 2}, []bool{false, true}, true, 2},
+		{"stops once confirmed", &request.Confirm{Checks: 5, Failures: 2}, []bool{true}, true, 1},
+		{"stops once impossible", &request.Confirm{Checks: 4, Failures: 3}, []bool{false, false}, false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+			probe, runs := probes(tt.outcomes...)
+
+			assert.Equal(t, tt.confirmed, Handler{}.confirmed(c, tt.confirm, probe))
+			assert.Equal(t, tt.runs, *runs)
+		})
+	}
+}
+
+func TestQuorumReached(t *testing.T) {
+	peer := func(status int, body string) *url.URL {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw, _ := io.ReadAll(r.Body)
+			if r.URL.Path != "/v2/checker/http" || r.URL.Query().Get("dryRun") != "true" || strings.Contains(string(raw), "quorum") {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(body))
+		}))
+		t.Cleanup(server.Close)
+		u, _ := url.Parse(server.URL)
+		return u
+	}
+	h := Handler{
+		Region: "ams",
+		FanOut: &FanOut{
+			Client: http.DefaultClient,
+			Peers: map[string]*url.URL{
+				"iad": peer(http.StatusOK, `{"status":"error"}`),
+				"fra": peer(http.StatusOK, `{"status":"error"}`),
+				"syd": peer(http.StatusOK, `{"status":"success"}`),
+				"gru": peer(http.StatusUnauthorized, `{"status":"error","error":{"code":"unauthorized"}}`),
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		quorum  *request.Quorum
+		reached bool
+	}{
+		{"without quorum", nil, true},
+		{"majority fails", &request.Quorum{Regions: []string{"iad", "syd"}}, true},
+		{"majority succeeds", &request.Quorum{Regions: []string{"syd", "gru"}}, false},
+		{"enough regions fail", &request.Quorum{Regions: []string{"iad", "fra", "syd"}, Failures: 3}, true},
+		{"unavailable regions do not vote", &request.Quorum{Regions: []string{"gru", "syd", "lhr"}, Failures: 2}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+			c.Set(gin.BodyBytesKey, []byte(`{"url":"https://openstat.us","quorum":{"regions":["iad"]}}`))
+			c.Set("event", map[string]any{})
+
+			assert.Equal(t, tt.reached, h.quorumReached(c, tt.quorum, "http"))
+			if tt.quorum != nil {
+				event := c.MustGet("event").(map[string]any)
+				assert.Equal(t, tt.quorum.Required(), event[quorumKey].(map[string]any)["required"])
+			}
+		})
+	}
+}
+
+func TestDegraded(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+
+	h := Handler{Latencies: latency.New()}
+	assert.False(t, h.degraded(c, "1", "active", 1000, 0, 0, 0))
+	assert.True(t, h.degraded(c, "1", "active", 401, 400, 0, 0))
+	assert.False(t, h.degraded(c, "1", "active", 400, 400, 0, 0))
+
+	// Hysteresis: degraded above 400ms, active again below 300ms.
+	assert.True(t, h.degraded(c, "1", "degraded", 350, 400, 300, 0))
+	assert.False(t, h.degraded(c, "1", "degraded", 299, 400, 300, 0))
+
+	// A single slow check does not move the average of the window.
+	assert.False(t, h.degraded(c, "2", "active", 200, 400, 300, 3))
+	assert.False(t, h.degraded(c, "2", "active", 200, 400, 300, 3))
+	assert.False(t, h.degraded(c, "2", "active", 700, 400, 300, 3))
+	assert.True(t, h.degraded(c, "2", "active", 700, 400, 300, 3))
+}
+
+func TestHTTPEnvelope_Revoked(t *testing.T) {
+	revErr := (&checker.Revocation{Status: checker.RevocationRevoked, Reason: "keyCompromise"}).Err()
+	res := checker.Response{Status: 200, Error: revErr.Error(), Failure: revErr}
+
+	env := httpEnvelope("ams", res, nil, false)
+	require.NotNil(t, env.Error)
+	assert.Equal(t, string(checker.ErrorClassTLS), env.Error.Code)
+	assert.Equal(t, checker.ErrorCodeTLSRevoked, env.Error.ErrorCode)
+}
+
+func TestEnvelopeHostNames(t *testing.T) {
+	for _, target := range []string{"https://bücher.example/shop", "bücher.example:443", "bücher.example"} {
+		var env Envelope
+		env.hostNames(target)
+		assert.Equal(t, "bücher.example", env.Host, target)
+		assert.Equal(t, "xn--bcher-kva.example", env.ASCIIHost, target)
+	}
+
+	var env Envelope
+	env.hostNames("https://openstat.us")
+	assert.Empty(t, env.Host)
+	assert.Empty(t, env.ASCIIHost)
+}
+
+func TestCheckHandlers(t *testing.T) {
+	handlers := Handler{}.checkHandlers()
+	assert.Len(t, handlers, len(CheckTypes))
+	for _, checkType := range CheckTypes {
+		assert.Contains(t, handlers, checkType, "every check type is served by /check")
+	}
+}
+
+func TestSlowPhases(t *testing.T) {
+	timing := EnvelopeTiming{DNSMs: 20, ConnectMs: 30, TLSMs: 400, FirstByteMs: 100, TotalMs: 600}
+
+	assert.Nil(t, slowPhases(timing, nil))
+	assert.Equal(t, []string{"tls"}, slowPhases(timing, &request.PhaseThresholds{DNS: 50, TLS: 200, Total: 1000}))
+
+	env := Envelope{Status: StatusSuccess, Timing: timing}
+	env.slowPhases(&request.PhaseThresholds{TLS: 200, FirstByte: 50})
+	assert.Equal(t, StatusDegraded, env.Status, "degraded although the total is fine")
+	assert.Equal(t, []string{"tls", "firstByte"}, env.SlowPhases)
+
+	env = Envelope{Status: StatusError, Timing: timing}
+	env.slowPhases(&request.PhaseThresholds{TLS: 200})
+	assert.Equal(t, StatusError, env.Status, "a failure stays a failure")
+}
@@ -74,7 +74,8 @@ func TestHandler_HTTPCheckerHandler(t *testing.T) {
 		router.ServeHTTP(w, req)
 
 		assert.Equal(t, 400, w.Code)
-		assert.Contains(t, w.Body.String(), "{\"error\":\"invalid request\"}")
+		assert.Contains(t, w.Body.String(), "\"error\":\"invalid request\"")
+		assert.Contains(t, w.Body.String(), "\"field\":\"workspaceId\"")
 	})
 
 	t.Run("it should return 200 if the payload is not ok", func(t *testing.T) {
@@ -260,3 +261,48 @@ func TestEvaluateAssertions_raw(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestHandler_HTTPCheckerHandler_Attempts(t *testing.T) {
+	var hits int
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits++
+		if hits == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	tbClient := tinybird.NewClient(&http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(""))}
+	})}, "apiKey")
+
+	h := handlers.Handler{TbClient: tbClient, Secret: "test", CloudProvider: "local", Region: "local"}
+	router := gin.New()
+	router.POST("/checker", h.HTTPCheckerHandler)
+
+	data := request.HttpCheckerRequest{
+		URL:         target.URL,
+		Method:      http.MethodGet,
+		Status:      "active",
+		Timeout:     1000,
+		RetryPolicy: &request.RetryPolicy{InitialInterval: 1},
+	}
+	dataJson, _ := json.Marshal(data)
+	req, _ := http.NewRequest(http.MethodPost, "/checker?data=true", strings.NewReader(string(dataJson)))
+	req.Header.Set("Authorization", "Basic test")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var res checker.Response
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	assert.Equal(t, http.StatusOK, res.Status)
+	if assert.Len(t, res.Attempts, 2) {
+		assert.Equal(t, checker.ErrorClassHTTP5xx, res.Attempts[0].Class)
+		assert.Equal(t, 2, res.Attempts[1].Attempt)
+		assert.Empty(t, res.Attempts[1].Error)
+	}
+}
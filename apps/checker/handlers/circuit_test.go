@@ -0,0 +1,50 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/circuit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/tinybird"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestHandler_CancelledTrial(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	tbClient := tinybird.NewClient(&http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(""))}
+	})}, "apiKey")
+
+	// Without cooldown, the circuit is half open as soon as it opens.
+	breaker := circuit.New(1, 0)
+	breaker.Failure("2")
+	h := handlers.Handler{TbClient: tbClient, Secret: "test", CloudProvider: "local", Region: "local", Breaker: breaker}
+	router := gin.New()
+	router.POST("/checker/http", h.HTTPCheckerHandler)
+
+	body, _ := json.Marshal(request.HttpCheckerRequest{
+		URL: target.URL, Method: http.MethodGet, Status: "active", Timeout: 1000,
+		WorkspaceID: "1", MonitorID: "2", CronTimestamp: 1000,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/checker/http", strings.NewReader(string(body)))
+	req.Header.Set("Authorization", "Basic test")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	allowed, _ := breaker.Allow("2")
+	assert.True(t, allowed, "the cancelled trial is released for the next check")
+}
@@ -0,0 +1,70 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/tinybird"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestHandler_ConnectTo(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "www.openstat.us", r.Host, "the Host header names the production hostname")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+	origin, err := url.Parse(target.URL)
+	require.NoError(t, err)
+
+	tbClient := tinybird.NewClient(&http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(""))}
+	})}, "apiKey")
+
+	h := handlers.Handler{TbClient: tbClient, Secret: "test", CloudProvider: "local", Region: "local"}
+	router := gin.New()
+	router.Group("/v2", handlers.V2()).POST("/checker/http", h.HTTPCheckerHandler)
+
+	do := func(data request.HttpCheckerRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(data)
+		req, _ := http.NewRequest(http.MethodPost, "/v2/checker/http?dryRun=true", strings.NewReader(string(body)))
+		req.Header.Set("Authorization", "Basic test")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("origin", func(t *testing.T) {
+		w := do(request.HttpCheckerRequest{
+			URL: "http://www.openstat.us/", Method: http.MethodGet, Timeout: 1000, Retry: 1,
+			ConnectTo: origin.Host,
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var env handlers.Envelope
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+		assert.Equal(t, handlers.StatusSuccess, env.Status)
+		if assert.NotNil(t, env.HTTP) {
+			assert.Equal(t, http.StatusOK, env.HTTP.StatusCode)
+		}
+	})
+
+	t.Run("with a proxy", func(t *testing.T) {
+		w := do(request.HttpCheckerRequest{
+			URL: "http://www.openstat.us/", Method: http.MethodGet,
+			ConnectTo: origin.Host, Proxy: &request.Proxy{URL: "http://proxy.openstat.us:3128"},
+		})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"field":"connectTo"`)
+	})
+}
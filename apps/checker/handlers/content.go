@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/audit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/content"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/probes"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// ContentResponse is the event of a content check. Content is the text
+// extracted, for the control plane to send it back as the previous content of
+// the next run, and Diff the JSON of the content.Diff when it changed.
+type ContentResponse struct {
+	ID            string `json:"id"`
+	ErrorMessage  string `json:"errorMessage"`
+	ErrorCode     string `json:"errorCode,omitempty"`
+	Region        string `json:"region"`
+	Trigger       string `json:"trigger"`
+	URL           string `json:"url"`
+	Selector      string `json:"selector"`
+	RequestStatus string `json:"requestStatus,omitempty"`
+	Hash          string `json:"hash"`
+	PreviousHash  string `json:"previousHash"`
+	Diff          string `json:"diff,omitempty"`
+	Content       string `json:"content"`
+	Labels        string `json:"labels,omitempty"`
+
+	WorkspaceID   int64 `json:"workspaceId"`
+	MonitorID     int64 `json:"monitorId"`
+	Timestamp     int64 `json:"timestamp"`
+	Latency       int64 `json:"latency"`
+	CronTimestamp int64 `json:"cronTimestamp"`
+	StatusCode    int   `json:"statusCode,omitempty"`
+
+	Changed bool  `json:"changed"`
+	Error   uint8 `json:"error"`
+}
+
+// ContentResult is the outcome of a content check in the envelope.
+type ContentResult struct {
+	Hash         string        `json:"hash"`
+	PreviousHash string        `json:"previousHash,omitempty"`
+	Diff         *content.Diff `json:"diff,omitempty"`
+	Changed      bool          `json:"changed"`
+}
+
+// ContentHandler fetches the page of a content check, extracts the text of
+// its selector and compares it with the previous run, reporting the lines
+// added and removed when it changed. A change is not a failure, only a page
+// that cannot be fetched or parsed is.
+func (h Handler) ContentHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	dataSourceName := "content_response__v0"
+
+	if !h.authorized(c) {
+		return
+	}
+
+	if h.replayed(c, "") {
+		return
+	}
+
+	var req request.ContentCheckerRequest
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to decode checker request")
+		invalid(c, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid checker request")
+		invalid(c, err)
+		return
+	}
+	if h.blockedTarget(c, "url", req.URL, h.Guard.CheckURL) {
+		return
+	}
+
+	workspaceId, monitorId := monitorIDs(req.WorkspaceID, req.MonitorID)
+	// The selector has been validated above.
+	sel, _ := content.ParseSelector(req.Selector)
+
+	if h.callbackTo(c, req.CallbackURL, req.WorkspaceID, req.MonitorID) {
+		return
+	}
+	if h.circuitOpen(c, req.MonitorID) {
+		return
+	}
+
+	trigger := req.Trigger
+	if trigger == "" {
+		trigger = "cron"
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to generate UUID")
+		return
+	}
+
+	client := &http.Client{
+		Timeout:   request.Timeout("content", req.Timeout),
+		Transport: h.Recorder.Wrap(h.transport(probes.Connection{})),
+	}
+	defer client.CloseIdleConnections()
+
+	res, err := h.fetchContent(ctx, client, req)
+	if h.cancelled(c, req.MonitorID) {
+		return
+	}
+
+	data := ContentResponse{
+		ID:            id.String(),
+		Region:        h.Region,
+		Trigger:       trigger,
+		URL:           req.URL,
+		Selector:      req.Selector,
+		WorkspaceID:   workspaceId,
+		MonitorID:     monitorId,
+		CronTimestamp: req.CronTimestamp,
+		Labels:        labelsJSON(req.Labels),
+		Timestamp:     res.Timestamp,
+		Latency:       res.Latency,
+		StatusCode:    res.Status,
+		PreviousHash:  req.PreviousHash,
+	}
+
+	var (
+		text   string
+		result *ContentResult
+	)
+	if err == nil {
+		text, err = content.Extract(strings.NewReader(res.Body), sel)
+	}
+	if err == nil {
+		data.Content = text
+		data.Hash = content.Hash(text)
+		result = &ContentResult{Hash: data.Hash}
+
+		var diff *content.Diff
+		data.PreviousHash, data.Changed, diff = h.compareContent(c, req, text)
+		if diff != nil {
+			if j, err := json.Marshal(diff); err == nil {
+				data.Diff = string(j)
+			}
+		}
+		result.PreviousHash, result.Changed, result.Diff = data.PreviousHash, data.Changed, diff
+	}
+	h.recordOutcome(c, req.MonitorID, req.CronTimestamp, err != nil)
+
+	switch {
+	case err != nil:
+		data.RequestStatus = "error"
+		data.Error = 1
+		data.ErrorMessage = err.Error()
+		data.ErrorCode = string(checker.ClassifyCode(err))
+		if req.Status != "error" {
+			h.updateStatus(c, checker.UpdateData{
+				MonitorId:     req.MonitorID,
+				Status:        "error",
+				Region:        h.Region,
+				Message:       err.Error(),
+				CronTimestamp: req.CronTimestamp,
+				StatusCode:    res.Status,
+				Latency:       res.Latency,
+			})
+		}
+	case req.Status != "active":
+		h.updateStatus(c, checker.UpdateData{
+			MonitorId:     req.MonitorID,
+			Status:        "active",
+			Region:        h.Region,
+			CronTimestamp: req.CronTimestamp,
+			StatusCode:    res.Status,
+			Latency:       res.Latency,
+		})
+		data.RequestStatus = "success"
+	default:
+		data.RequestStatus = "success"
+	}
+
+	if data.Changed {
+		log.Ctx(ctx).Info().Str("monitor", req.MonitorID).Str("hash", data.Hash).Msg("content changed")
+	}
+	if err := h.events(c).SendEvent(ctx, data, dataSourceName); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to send event to tinybird")
+	}
+
+	event, f := c.Get("event")
+	if f {
+		t := event.(map[string]any)
+		t["checker"] = map[string]string{
+			"uri":          req.URL,
+			"workspace_id": req.WorkspaceID,
+			"monitor_id":   req.MonitorID,
+			"trigger":      trigger,
+			"type":         "content",
+		}
+		c.Set("event", t)
+	}
+
+	env := Envelope{
+		Type:      "content",
+		Region:    h.Region,
+		Timestamp: res.Timestamp,
+		Attempts:  res.Attempts,
+		Content:   result,
+		Timing:    EnvelopeTiming{TotalMs: res.Latency},
+	}
+	if res.Status != 0 {
+		env.HTTP = &HTTPResult{StatusCode: res.Status, BodyHash: res.BodyHash, BodyTruncated: res.Truncated}
+	}
+	env.hostNames(req.URL)
+	env.outcome(err, false)
+	h.finish(c, audit.Entry{
+		Trigger:     trigger,
+		WorkspaceID: req.WorkspaceID,
+		MonitorID:   req.MonitorID,
+		Target:      req.URL,
+	}, env)
+
+	respond(c, data, env)
+}
+
+// fetchContent fetches the page of a content check, an unsuccessful status
+// code being an error.
+func (h Handler) fetchContent(ctx context.Context, client *http.Client, req request.ContentCheckerRequest) (checker.Response, error) {
+	start := time.Now()
+
+	err := h.Chaos.Inject(ctx, req.MonitorID)
+	var res checker.Response
+	if err == nil {
+		res, err = checker.Http(ctx, client, request.HttpCheckerRequest{
+			Headers:      req.Headers,
+			URL:          req.URL,
+			MonitorID:    req.MonitorID,
+			Method:       http.MethodGet,
+			Timeout:      req.Timeout,
+			MaxBodyBytes: req.MaxBodyBytes,
+		})
+	}
+	if err == nil && !statusCode(res.Status).IsSuccessful() {
+		err = &checker.ClassifiedError{Class: checker.ClassifyStatus(res.Status), Err: fmt.Errorf("unexpected status code %d", res.Status)}
+	}
+	if res.Timestamp == 0 {
+		res.Timestamp = start.UTC().UnixMilli()
+	}
+	res.Attempts = []checker.Attempt{checker.NewAttempt(1, start, err)}
+
+	return res, err
+}
+
+// compareContent returns the hash of the previous content of the check and
+// whether text differs from it, with the diff of the two when the previous
+// text is known: sent with the request, or kept by this checker for the same
+// hash. The first run of a monitor has no previous hash and is no change.
+func (h Handler) compareContent(c *gin.Context, req request.ContentCheckerRequest, text string) (string, bool, *content.Diff) {
+	var (
+		stored string
+		kept   bool
+	)
+	// Dry runs leave the kept text alone.
+	if !dryRun(c) {
+		stored, kept = h.Contents.Swap(req.MonitorID, text)
+	}
+
+	previous, previousHash := req.PreviousContent, req.PreviousHash
+	known := previous != ""
+	switch {
+	case known && previousHash == "":
+		previousHash = content.Hash(previous)
+	case !known && kept && (previousHash == "" || previousHash == content.Hash(stored)):
+		previous, previousHash, known = stored, content.Hash(stored), true
+	}
+	if previousHash == "" || previousHash == content.Hash(text) {
+		return previousHash, false, nil
+	}
+	if !known {
+		return previousHash, true, nil
+	}
+	diff := content.Compare(previous, text)
+
+	return previousHash, true, &diff
+}
@@ -0,0 +1,111 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/content"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestHandler_ContentHandler(t *testing.T) {
+	updates := statusUpdates(t)
+
+	var price atomic.Int64
+	price.Store(10)
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<html><body><nav>Home</nav><main><h1>Pricing</h1><p>Pro: $%d</p><script>track()</script></main></body></html>`, price.Load())
+	}))
+	defer page.Close()
+
+	h := integrationHandler()
+	h.Contents = content.NewStore()
+	req := request.ContentCheckerRequest{
+		Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "1", Status: "active"},
+		URL:       page.URL,
+		Selector:  "main",
+		Timeout:   5000,
+	}
+	run := func() handlers.ContentResponse {
+		w := post(t, h.ContentHandler, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		var data handlers.ContentResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &data))
+		return data
+	}
+
+	first := run()
+	assert.Equal(t, "Pricing\nPro: $10", first.Content)
+	assert.Equal(t, content.Hash(first.Content), first.Hash)
+	assert.False(t, first.Changed, "the first run has nothing to compare with")
+
+	second := run()
+	assert.False(t, second.Changed)
+	assert.Equal(t, first.Hash, second.PreviousHash)
+
+	price.Store(12)
+	third := run()
+	assert.True(t, third.Changed)
+	assert.Equal(t, first.Hash, third.PreviousHash)
+	var diff content.Diff
+	require.NoError(t, json.Unmarshal([]byte(third.Diff), &diff))
+	assert.Equal(t, content.Diff{Added: []string{"Pro: $12"}, Removed: []string{"Pro: $10"}}, diff)
+	assert.Empty(t, updates(), "a change is not a failure")
+
+	t.Run("previous content of the request", func(t *testing.T) {
+		req := req
+		req.MonitorID = "2"
+		req.PreviousContent = "Pricing\nPro: $9"
+		w := post(t, h.ContentHandler, req)
+		var data handlers.ContentResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &data))
+		assert.True(t, data.Changed)
+		assert.Equal(t, content.Hash(req.PreviousContent), data.PreviousHash)
+		assert.JSONEq(t, `{"added":["Pro: $12"],"removed":["Pro: $9"]}`, data.Diff)
+	})
+
+	t.Run("previous hash only", func(t *testing.T) {
+		req := req
+		req.MonitorID = "3"
+		req.PreviousHash = content.Hash("something else")
+		w := post(t, h.ContentHandler, req)
+		var data handlers.ContentResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &data))
+		assert.True(t, data.Changed)
+		assert.Empty(t, data.Diff, "the previous text is unknown")
+	})
+
+	t.Run("unreachable page", func(t *testing.T) {
+		down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer down.Close()
+		req := req
+		req.MonitorID = "4"
+		req.URL = down.URL
+		w := post(t, h.ContentHandler, req)
+		var data handlers.ContentResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &data))
+		assert.Equal(t, uint8(1), data.Error)
+		assert.Equal(t, "error", data.RequestStatus)
+		if assert.Len(t, updates(), 1) {
+			assert.Equal(t, "error", updates()[0].Status)
+			assert.Equal(t, http.StatusBadGateway, updates()[0].StatusCode)
+		}
+	})
+
+	t.Run("invalid selector", func(t *testing.T) {
+		req := req
+		req.Selector = "main >"
+		w := post(t, h.ContentHandler, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
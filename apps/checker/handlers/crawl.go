@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/audit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/crawl"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/probes"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// CrawlResponse is the event of a crawl check. BrokenLinks is the JSON of the
+// broken crawl.Link, with their status or error, and MixedContent the one of
+// the crawl.MixedContent of the HTTPS pages.
+type CrawlResponse struct {
+	ID            string `json:"id"`
+	ErrorMessage  string `json:"errorMessage"`
+	ErrorCode     string `json:"errorCode,omitempty"`
+	Region        string `json:"region"`
+	Trigger       string `json:"trigger"`
+	URL           string `json:"url"`
+	RequestStatus string `json:"requestStatus,omitempty"`
+	BrokenLinks   string `json:"brokenLinks,omitempty"`
+	MixedContent  string `json:"mixedContent,omitempty"`
+	Labels        string `json:"labels,omitempty"`
+
+	WorkspaceID   int64 `json:"workspaceId"`
+	MonitorID     int64 `json:"monitorId"`
+	Timestamp     int64 `json:"timestamp"`
+	Latency       int64 `json:"latency"`
+	CronTimestamp int64 `json:"cronTimestamp"`
+	Checked       int   `json:"checked"`
+	Broken        int   `json:"broken"`
+
+	Truncated bool  `json:"truncated,omitempty"`
+	Error     uint8 `json:"error"`
+}
+
+// CrawlResult is the outcome of a crawl check in the envelope.
+type CrawlResult struct {
+	Broken    []crawl.Link `json:"broken"`
+	Checked   int          `json:"checked"`
+	Truncated bool         `json:"truncated,omitempty"`
+	// MixedContent lists the sub-resources of the HTTPS pages loaded over
+	// HTTP.
+	MixedContent []crawl.MixedContent `json:"mixedContent,omitempty"`
+}
+
+// CrawlHandler checks the links of a page, and of the pages of its host up to
+// the depth of the check, failing it when one of them is broken or when an
+// HTTPS page loads a sub-resource over HTTP.
+func (h Handler) CrawlHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	dataSourceName := "crawl_response__v0"
+
+	if !h.authorized(c) {
+		return
+	}
+
+	if h.replayed(c, "") {
+		return
+	}
+
+	var req request.CrawlCheckerRequest
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to decode checker request")
+		invalid(c, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid checker request")
+		invalid(c, err)
+		return
+	}
+	if h.blockedTarget(c, "url", req.URL, h.Guard.CheckURL) {
+		return
+	}
+
+	workspaceId, monitorId := monitorIDs(req.WorkspaceID, req.MonitorID)
+
+	if h.callbackTo(c, req.CallbackURL, req.WorkspaceID, req.MonitorID) {
+		return
+	}
+	if h.circuitOpen(c, req.MonitorID) {
+		return
+	}
+
+	trigger := req.Trigger
+	if trigger == "" {
+		trigger = "cron"
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to generate UUID")
+		return
+	}
+
+	// The transport of the guard keeps the links from reaching private
+	// addresses.
+	client := &http.Client{
+		Timeout:   request.Timeout("crawl", req.Timeout),
+		Transport: h.Recorder.Wrap(h.transport(probes.Connection{})),
+	}
+	defer client.CloseIdleConnections()
+
+	header := http.Header{}
+	for _, kv := range req.Headers {
+		header.Add(kv.Key, kv.Value)
+	}
+
+	start := time.Now()
+	err = h.Chaos.Inject(ctx, req.MonitorID)
+	var res crawl.Result
+	if err == nil {
+		res, err = crawl.Crawl(ctx, client, req.URL, crawl.Config{Depth: req.Depth, MaxLinks: req.MaxLinks, Header: header})
+	}
+	latency := time.Since(start).Milliseconds()
+	if h.cancelled(c, req.MonitorID) {
+		return
+	}
+
+	broken := res.Broken()
+	switch {
+	case err != nil:
+	case len(broken) > 0:
+		err = &checker.ClassifiedError{Class: checker.ErrorClassAssertion, Err: fmt.Errorf("%d broken links, first %s", len(broken), broken[0].URL)}
+	case len(res.MixedContent) > 0:
+		mixed := res.MixedContent[0]
+		err = &checker.ClassifiedError{Class: checker.ErrorClassAssertion, Err: fmt.Errorf("%d sub-resources loaded over HTTP, first %s on %s", len(res.MixedContent), mixed.URL, mixed.Page)}
+	}
+	h.recordOutcome(c, req.MonitorID, req.CronTimestamp, err != nil)
+
+	data := CrawlResponse{
+		ID:            id.String(),
+		Region:        h.Region,
+		Trigger:       trigger,
+		URL:           req.URL,
+		WorkspaceID:   workspaceId,
+		MonitorID:     monitorId,
+		CronTimestamp: req.CronTimestamp,
+		Labels:        labelsJSON(req.Labels),
+		Timestamp:     start.UTC().UnixMilli(),
+		Latency:       latency,
+		Checked:       len(res.Links),
+		Broken:        len(broken),
+		Truncated:     res.Truncated,
+	}
+	if len(broken) > 0 {
+		if j, err := json.Marshal(broken); err == nil {
+			data.BrokenLinks = string(j)
+		}
+	}
+	if len(res.MixedContent) > 0 {
+		if j, err := json.Marshal(res.MixedContent); err == nil {
+			data.MixedContent = string(j)
+		}
+	}
+
+	switch {
+	case err != nil:
+		data.RequestStatus = "error"
+		data.Error = 1
+		data.ErrorMessage = err.Error()
+		data.ErrorCode = string(checker.ClassifyCode(err))
+		if req.Status != "error" {
+			h.updateStatus(c, checker.UpdateData{
+				MonitorId:     req.MonitorID,
+				Status:        "error",
+				Region:        h.Region,
+				Message:       err.Error(),
+				CronTimestamp: req.CronTimestamp,
+				Latency:       latency,
+			})
+		}
+	case req.Status != "active":
+		h.updateStatus(c, checker.UpdateData{
+			MonitorId:     req.MonitorID,
+			Status:        "active",
+			Region:        h.Region,
+			CronTimestamp: req.CronTimestamp,
+			Latency:       latency,
+		})
+		data.RequestStatus = "success"
+	default:
+		data.RequestStatus = "success"
+	}
+
+	if err := h.events(c).SendEvent(ctx, data, dataSourceName); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to send event to tinybird")
+	}
+
+	event, f := c.Get("event")
+	if f {
+		t := event.(map[string]any)
+		t["checker"] = map[string]string{
+			"uri":          req.URL,
+			"workspace_id": req.WorkspaceID,
+			"monitor_id":   req.MonitorID,
+			"trigger":      trigger,
+			"type":         "crawl",
+		}
+		c.Set("event", t)
+	}
+
+	env := Envelope{
+		Type:      "crawl",
+		Region:    h.Region,
+		Timestamp: data.Timestamp,
+		Attempts:  []checker.Attempt{checker.NewAttempt(1, start, err)},
+		Crawl:     &CrawlResult{Broken: broken, Checked: len(res.Links), Truncated: res.Truncated, MixedContent: res.MixedContent},
+		Timing:    EnvelopeTiming{TotalMs: latency},
+	}
+	env.hostNames(req.URL)
+	env.outcome(err, false)
+	h.finish(c, audit.Entry{
+		Trigger:     trigger,
+		WorkspaceID: req.WorkspaceID,
+		MonitorID:   req.MonitorID,
+		Target:      req.URL,
+	}, env)
+
+	respond(c, data, env)
+}
@@ -0,0 +1,49 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/crawl"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestHandler_CrawlHandler(t *testing.T) {
+	updates := statusUpdates(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<a href="/ok">Ok</a> <a href="/broken">Broken</a>`)
+	})
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	site := httptest.NewServer(mux)
+	defer site.Close()
+
+	h := integrationHandler()
+	w := post(t, h.CrawlHandler, request.CrawlCheckerRequest{
+		Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "1", Status: "active"},
+		URL:       site.URL,
+		Timeout:   5000,
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var data handlers.CrawlResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &data))
+	assert.Equal(t, "error", data.RequestStatus)
+	assert.Equal(t, 2, data.Checked)
+	assert.Equal(t, 1, data.Broken)
+	var broken []crawl.Link
+	require.NoError(t, json.Unmarshal([]byte(data.BrokenLinks), &broken))
+	assert.Equal(t, []crawl.Link{{URL: site.URL + "/broken", Source: site.URL, Status: http.StatusNotFound, Depth: 1}}, broken)
+	if assert.Len(t, updates(), 1) {
+		assert.Equal(t, "error", updates()[0].Status)
+		assert.Contains(t, updates()[0].Message, "1 broken links")
+	}
+}
@@ -0,0 +1,67 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/dependency"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/tinybird"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestHandler_SkippedDependency(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer target.Close()
+
+	tbClient := tinybird.NewClient(&http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(""))}
+	})}, "apiKey")
+
+	check := func(t *testing.T, tracker *dependency.Tracker, status string) string {
+		var sink auditSink
+		h := handlers.Handler{TbClient: tbClient, Secret: "test", CloudProvider: "local", Region: "local", Audit: &sink, Dependencies: tracker}
+		router := gin.New()
+		router.POST("/checker/http", h.HTTPCheckerHandler)
+
+		body, _ := json.Marshal(request.HttpCheckerRequest{
+			URL: target.URL, Method: http.MethodGet, Status: status, Timeout: 1000,
+			WorkspaceID: "1", MonitorID: "2", CronTimestamp: 1000, DependsOn: []string{"1"},
+		})
+		req, _ := http.NewRequest(http.MethodPost, "/checker/http", strings.NewReader(string(body)))
+		req.Header.Set("Authorization", "Basic test")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Len(t, sink, 1)
+
+		return sink[0].Outcome
+	}
+
+	t.Run("parent down", func(t *testing.T) {
+		tracker := dependency.New(time.Second)
+		tracker.Record("1", 1000, true)
+
+		assert.Equal(t, dependency.StatusSkipped, check(t, tracker, "active"))
+		_, down := tracker.Down(t.Context(), []string{"2"}, 1000)
+		assert.True(t, down, "the children of a skipped check are skipped too")
+	})
+
+	t.Run("parent up", func(t *testing.T) {
+		tracker := dependency.New(time.Second)
+		tracker.Record("1", 1000, false)
+
+		assert.Equal(t, handlers.StatusError, check(t, tracker, "error"))
+	})
+}
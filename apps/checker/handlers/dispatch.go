@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/gin-gonic/gin"
+)
+
+// Dispatcher returns the function running the checks of the standalone
+// scheduler and of the job queue through the same handlers as the pushed
+// ones, behind middlewares. Its callers are trusted, the checks being read
+// from the configuration of the checker, so they skip the authentication of
+// the API. It returns the status of the monitor after the check: active,
+// degraded or error, empty when the check had no outcome, e.g. its circuit
+// being open or its parent down. A check answered with a 4xx other than 408
+// and 429, e.g. an invalid request, fails with a *backoff.PermanentError:
+// running it again would not change its answer.
+func (h Handler) Dispatcher(middlewares ...gin.HandlerFunc) func(ctx context.Context, checkType string, body []byte) (string, error) {
+	router := gin.New()
+	router.Use(middlewares...)
+	router.Use(func(c *gin.Context) {
+		c.Set(authenticatedKey, true)
+		// The envelope tells the status of the monitor whatever the type.
+		c.Set(v2Key, true)
+	})
+	for checkType, handler := range h.checkHandlers() {
+		router.POST("/checker/"+checkType, handler)
+	}
+
+	return func(ctx context.Context, checkType string, body []byte) (string, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/checker/"+checkType, bytes.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code < http.StatusBadRequest {
+			var env Envelope
+			// A cancelled check answers nothing.
+			_ = json.Unmarshal(w.Body.Bytes(), &env)
+
+			return monitorStatus[env.Status], nil
+		}
+
+		err = fmt.Errorf("check answered %d: %s", w.Code, strings.TrimSpace(w.Body.String()))
+		if w.Code < http.StatusInternalServerError && w.Code != http.StatusRequestTimeout && w.Code != http.StatusTooManyRequests {
+			return "", backoff.Permanent(err)
+		}
+
+		return "", err
+	}
+}
+
+// monitorStatus maps the status of an envelope to the one of its monitor,
+// the other ones leaving it unchanged.
+var monitorStatus = map[string]string{
+	StatusSuccess:  "active",
+	StatusDegraded: "degraded",
+	StatusError:    "error",
+}
@@ -0,0 +1,55 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/tinybird"
+)
+
+func TestHandler_Dispatcher(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	tbClient := tinybird.NewClient(&http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(""))}
+	})}, "apiKey")
+
+	h := handlers.Handler{TbClient: tbClient, Secret: "test", CloudProvider: "local", Region: "local"}
+	dispatch := h.Dispatcher()
+
+	t.Run("runs checks without credentials", func(t *testing.T) {
+		status, err := dispatch(context.Background(), "http", []byte(`{"workspaceId":"1","monitorId":"2","url":"`+target.URL+`","method":"GET","status":"error","timeout":1000}`))
+		assert.NoError(t, err)
+		assert.Equal(t, "active", status)
+	})
+
+	t.Run("status of failed check", func(t *testing.T) {
+		status, err := dispatch(context.Background(), "http", []byte(`{"workspaceId":"1","monitorId":"2","url":"`+target.URL+`/missing","method":"GET","status":"active","timeout":1000,"assertions":[{"type":"status","compare":"eq","target":201}]}`))
+		assert.NoError(t, err)
+		assert.Equal(t, "error", status)
+	})
+
+	t.Run("invalid check is permanent", func(t *testing.T) {
+		_, err := dispatch(context.Background(), "http", []byte(`{"url":"ftp://openstat.us"}`))
+		var permanent *backoff.PermanentError
+		assert.True(t, errors.As(err, &permanent))
+		assert.ErrorContains(t, err, "check answered 400")
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		_, err := dispatch(context.Background(), "icmp", []byte(`{}`))
+		assert.ErrorContains(t, err, "check answered 404")
+	})
+}
@@ -1,17 +1,21 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
-	"net/http"
+	"errors"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
 	"github.com/openstatushq/openstatus/apps/checker/checker"
-	"github.com/openstatushq/openstatus/apps/checker/pkg/assertions"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/audit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/dependency"
 	otelOS "github.com/openstatushq/openstatus/apps/checker/pkg/otel"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/probes"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/retry"
 	"github.com/openstatushq/openstatus/apps/checker/request"
 	"github.com/rs/zerolog/log"
 
@@ -21,11 +25,13 @@ import (
 type DNSResponse struct {
 	ID            string `json:"id"`
 	ErrorMessage  string `json:"errorMessage"`
+	ErrorCode     string `json:"errorCode,omitempty"`
 	Region        string `json:"region"`
 	Trigger       string `json:"trigger"`
 	URI           string `json:"uri"`
 	RequestStatus string `json:"requestStatus,omitempty"`
 	Assertions    string `json:"assertions"`
+	Labels        string `json:"labels,omitempty"`
 
 	Records map[string][]string `json:"records"`
 
@@ -35,6 +41,8 @@ type DNSResponse struct {
 	Timestamp     int64 `json:"timestamp"`
 	Latency       int64 `json:"latency"`
 	CronTimestamp int64 `json:"cronTimestamp"`
+	// ScheduleOffset is how late the check started, in milliseconds.
+	ScheduleOffset int64 `json:"scheduleOffset,omitempty"`
 
 	Error uint8 `json:"error"`
 }
@@ -56,44 +64,41 @@ func (d DNSResponse) tinybirdEvent() (dnsTinybirdEvent, error) {
 	return dnsTinybirdEvent{DNSResponse: d, Records: string(j)}, nil
 }
 
+var errDNSAssertion = &checker.ClassifiedError{Class: checker.ErrorClassAssertion, Err: errors.New("assertion failed")}
+
 func (h Handler) DNSHandler(c *gin.Context) {
 	ctx := c.Request.Context()
-	const defaultRetry = 3
 	dataSourceName := "dns_response__v0"
 
 	// Authorization check
-	if c.GetHeader("Authorization") != fmt.Sprintf("Basic %s", h.Secret) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	if !h.authorized(c) {
 		return
 	}
 
-	// Fly region forwarding
-	if h.CloudProvider == "fly" {
-		region := c.GetHeader("fly-prefer-region")
-		if region != "" && region != h.Region {
-			c.Header("fly-replay", fmt.Sprintf("region=%s", region))
-			c.String(http.StatusAccepted, "Forwarding request to %s", region)
-			return
-		}
+	// Region forwarding
+	if h.replayed(c, "") {
+		return
 	}
 
 	// Parse request
 	var req request.DNSCheckerRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
 		log.Ctx(ctx).Error().Err(err).Msg("failed to decode checker request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		invalid(c, err)
 		return
 	}
-
-	workspaceId, err := strconv.ParseInt(req.WorkspaceID, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid workspace id"})
+	if err := req.ValidateScheduled(); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid checker request")
+		invalid(c, err)
 		return
 	}
 
-	monitorId, err := strconv.ParseInt(req.MonitorID, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid monitor id"})
+	workspaceId, monitorId := monitorIDs(req.WorkspaceID, req.MonitorID)
+
+	if h.callbackTo(c, req.CallbackURL, req.WorkspaceID, req.MonitorID) {
+		return
+	}
+	if h.circuitOpen(c, req.MonitorID) {
 		return
 	}
 
@@ -102,10 +107,10 @@ func (h Handler) DNSHandler(c *gin.Context) {
 		trigger = "cron"
 	}
 
-	retry := defaultRetry
-	if req.Retry != 0 {
-		retry = int(req.Retry)
-	}
+	policy := retry.FromRequest(req.RetryPolicy, req.Retry)
+
+	checkCtx, cancel := retry.WithDeadline(ctx, req.TotalDeadline)
+	defer cancel()
 
 	id, e := uuid.NewV7()
 	if e != nil {
@@ -121,33 +126,42 @@ func (h Handler) DNSHandler(c *gin.Context) {
 	requestStatus := statusMap[req.Status]
 
 	data := DNSResponse{
-		ID:            id.String(),
-		Region:        h.Region,
-		Trigger:       trigger,
-		URI:           req.URI,
-		WorkspaceID:   workspaceId,
-		MonitorID:     monitorId,
-		CronTimestamp: req.CronTimestamp,
-		RequestStatus: requestStatus,
-		Timestamp:     time.Now().UTC().UnixMilli(),
+		ID:             id.String(),
+		Region:         h.Region,
+		Trigger:        trigger,
+		URI:            req.URI,
+		WorkspaceID:    workspaceId,
+		MonitorID:      monitorId,
+		CronTimestamp:  req.CronTimestamp,
+		ScheduleOffset: scheduleOffset(req.ScheduledAt),
+		RequestStatus:  requestStatus,
+		Labels:         labelsJSON(req.Labels),
+		Timestamp:      time.Now().UTC().UnixMilli(),
 	}
 
 	var (
 		latency      int64
 		isSuccessful = true
 		called       int
+		attempts     []checker.Attempt
 	)
 
 	op := func() (*checker.DnsResponse, error) {
 		called++
-		log.Ctx(ctx).Debug().Msgf("performing dns check for %s (attempt %d/%d)", req.URI, called, retry)
-		start := time.Now().UTC().UnixMilli()
-		response, err := checker.Dns(ctx, req.URI)
-		latency = time.Now().UTC().UnixMilli() - start
+		log.Ctx(ctx).Debug().Msgf("performing dns check for %s (attempt %d/%d)", req.URI, called, policy.MaxAttempts)
+		start := time.Now()
+		var response *checker.DnsResponse
+		err := h.Chaos.Inject(checkCtx, req.MonitorID)
+		if err == nil {
+			response, err = probes.LookupDNS(checkCtx, req)
+		}
+		latency = time.Since(start).Milliseconds()
+		attempt := checker.NewAttempt(called, start, err)
+		defer func() { attempts = append(attempts, attempt) }()
 
 		if err != nil {
 			log.Ctx(ctx).Error().Err(err).Msg("dns check failed")
-			return nil, err
+			return nil, policy.Wrap(err)
 		}
 		if len(req.RawAssertions) > 0 {
 			log.Ctx(ctx).Debug().Msgf("evaluating %d dns assertions", len(req.RawAssertions))
@@ -156,17 +170,26 @@ func (h Handler) DNSHandler(c *gin.Context) {
 				return response, backoff.Permanent(err)
 			}
 		}
-		if !isSuccessful && called < retry {
+		if !isSuccessful {
+			attempt.Class = checker.ErrorClassAssertion
+			attempt.Error = "assertion failed"
+		}
+		if !isSuccessful && !policy.IsLastAttempt(called) && policy.Retryable(checker.ErrorClassAssertion) {
 			return nil, backoff.RetryAfter(1)
 		}
 		if !isSuccessful {
 			log.Ctx(ctx).Debug().Msg("dns assertions failed")
-			return response, backoff.Permanent(fmt.Errorf("assertion failed"))
+			return response, backoff.Permanent(errDNSAssertion)
 		}
 		return response, nil
 	}
 
-	result, err := backoff.Retry(ctx, op, backoff.WithBackOff(backoff.NewExponentialBackOff()), backoff.WithMaxTries(uint(retry)))
+	result, err := backoff.Retry(checkCtx, op, policy.Options()...)
+
+	if h.cancelled(c, req.MonitorID) {
+		return
+	}
+	h.recordOutcome(c, req.MonitorID, req.CronTimestamp, err != nil || !isSuccessful)
 	data.Latency = latency
 	if result != nil {
 		data.Records = FormatDNSResult(result)
@@ -181,14 +204,31 @@ func (h Handler) DNSHandler(c *gin.Context) {
 	}
 
 	// Status update logic
+	var skipped bool
+	degraded := isSuccessful && h.degraded(c, req.MonitorID, req.Status, latency, req.DegradedAfter, req.RecoverBelow, req.DegradedWindow)
 	switch {
 	case !isSuccessful:
 		log.Ctx(ctx).Debug().Msg("DNS check failed assertions")
 		data.RequestStatus = "error"
 		data.Error = 1
 		data.ErrorMessage = err.Error()
-		if req.Status != "error" {
-			checker.UpdateStatus(ctx, checker.UpdateData{
+		data.ErrorCode = string(checker.ClassifyCode(err))
+		if skipped = h.skippedDependency(c, req.DependsOn, req.CronTimestamp); skipped {
+			data.RequestStatus = dependency.StatusSkipped
+		}
+		confirm := func() bool {
+			if h.Chaos.Inject(checkCtx, req.MonitorID) != nil {
+				return true
+			}
+			response, err := probes.LookupDNS(checkCtx, req)
+			if err != nil {
+				return true
+			}
+			ok, err := EvaluateDNSAssertions(req.RawAssertions, response)
+			return err != nil || (len(req.RawAssertions) > 0 && !ok)
+		}
+		if !skipped && req.Status != "error" && h.confirmed(c, req.Confirm, confirm) && h.quorumReached(c, req.Quorum, "dns") {
+			h.updateStatus(c, checker.UpdateData{
 				MonitorId:     req.MonitorID,
 				Status:        "error",
 				Region:        h.Region,
@@ -197,8 +237,8 @@ func (h Handler) DNSHandler(c *gin.Context) {
 				Latency:       latency,
 			})
 		}
-	case isSuccessful && req.DegradedAfter > 0 && latency > req.DegradedAfter && req.Status != "degraded":
-		checker.UpdateStatus(ctx, checker.UpdateData{
+	case degraded && req.Status != "degraded":
+		h.updateStatus(c, checker.UpdateData{
 			MonitorId:     req.MonitorID,
 			Status:        "degraded",
 			Region:        h.Region,
@@ -206,8 +246,8 @@ func (h Handler) DNSHandler(c *gin.Context) {
 			Latency:       latency,
 		})
 		data.RequestStatus = "degraded"
-	case isSuccessful && ((req.DegradedAfter == 0 && req.Status != "active") || (latency < req.DegradedAfter && req.DegradedAfter != 0 && req.Status != "active")):
-		checker.UpdateStatus(ctx, checker.UpdateData{
+	case isSuccessful && !degraded && req.Status != "active":
+		h.updateStatus(c, checker.UpdateData{
 			MonitorId:     req.MonitorID,
 			Status:        "active",
 			Region:        h.Region,
@@ -219,11 +259,11 @@ func (h Handler) DNSHandler(c *gin.Context) {
 
 	if tbEvent, err := data.tinybirdEvent(); err != nil {
 		log.Ctx(ctx).Error().Err(err).Msg("failed to marshal dns records")
-	} else if err := h.TbClient.SendEvent(ctx, tbEvent, dataSourceName); err != nil {
+	} else if err := h.events(c).SendEvent(ctx, tbEvent, dataSourceName); err != nil {
 		log.Ctx(ctx).Error().Err(err).Msg("failed to send event to tinybird")
 	}
 
-	if req.OtelConfig.Endpoint != "" {
+	if req.OtelConfig.Endpoint != "" && !dryRun(c) {
 		otelOS.RecordDNSMetrics(ctx, req, latency, err != nil || !isSuccessful, h.Region)
 	}
 
@@ -240,43 +280,55 @@ func (h Handler) DNSHandler(c *gin.Context) {
 		c.Set("event", t)
 	}
 
-	c.JSON(http.StatusOK, data)
+	env := dnsEnvelope(data, attempts, err, degraded)
+	if skipped {
+		env.Status = dependency.StatusSkipped
+	}
+	h.finish(c, audit.Entry{
+		Trigger:     trigger,
+		WorkspaceID: req.WorkspaceID,
+		MonitorID:   req.MonitorID,
+		Target:      req.URI,
+	}, env)
+
+	respond(c, data, env)
 }
 
 func (h Handler) DNSHandlerRegion(c *gin.Context) {
 	ctx := c.Request.Context()
 	dataSourceName := "check_dns_response__v0"
-	const defaultRetry = 3
 
 	// Authorization check
-	if c.GetHeader("Authorization") != fmt.Sprintf("Basic %s", h.Secret) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	if !h.authorized(c) {
 		return
 	}
 
-	// Fly region forwarding
-	if h.CloudProvider == "fly" {
-		region := c.GetHeader("fly-prefer-region")
-		if region != "" && region != h.Region {
-			c.Header("fly-replay", fmt.Sprintf("region=%s", region))
-			c.String(http.StatusAccepted, "Forwarding request to %s", region)
-			return
-		}
+	// Region forwarding
+	if h.replayed(c, "") {
+		return
 	}
 
 	// Parse request
 	var req request.DNSCheckerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Ctx(ctx).Error().Err(err).Msg("failed to decode checker request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		invalid(c, err)
 		return
 	}
-
-	retry := defaultRetry
-	if req.Retry != 0 {
-		retry = int(req.Retry)
+	if err := req.Validate(); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid checker request")
+		invalid(c, err)
+		return
+	}
+	if h.callbackTo(c, req.CallbackURL, req.WorkspaceID, req.MonitorID) {
+		return
 	}
 
+	policy := retry.FromRequest(req.RetryPolicy, req.Retry)
+
+	checkCtx, cancel := retry.WithDeadline(ctx, req.TotalDeadline)
+	defer cancel()
+
 	id, e := uuid.NewV7()
 	if e != nil {
 		log.Ctx(ctx).Error().Err(e).Msg("failed to generate UUID")
@@ -299,6 +351,7 @@ func (h Handler) DNSHandlerRegion(c *gin.Context) {
 		WorkspaceID:   int64(workspaceId),
 		CronTimestamp: req.CronTimestamp,
 		RequestStatus: requestStatus,
+		Labels:        labelsJSON(req.Labels),
 		Timestamp:     time.Now().UTC().UnixMilli(),
 	}
 
@@ -306,18 +359,21 @@ func (h Handler) DNSHandlerRegion(c *gin.Context) {
 		latency      int64
 		isSuccessful = true
 		called       int
+		attempts     []checker.Attempt
 	)
 
 	op := func() (*checker.DnsResponse, error) {
 		called++
-		log.Ctx(ctx).Debug().Msgf("performing dns check for %s (attempt %d/%d)", req.URI, called, retry)
-		start := time.Now().UTC().UnixMilli()
-		response, err := checker.Dns(ctx, req.URI)
-		latency = time.Now().UTC().UnixMilli() - start
+		log.Ctx(ctx).Debug().Msgf("performing dns check for %s (attempt %d/%d)", req.URI, called, policy.MaxAttempts)
+		start := time.Now()
+		response, err := probes.LookupDNS(checkCtx, req)
+		latency = time.Since(start).Milliseconds()
+		attempt := checker.NewAttempt(called, start, err)
+		defer func() { attempts = append(attempts, attempt) }()
 
 		if err != nil {
 			log.Ctx(ctx).Error().Err(err).Msg("dns check failed")
-			return nil, err
+			return nil, policy.Wrap(err)
 		}
 		if len(req.RawAssertions) > 0 {
 			log.Ctx(ctx).Debug().Msgf("evaluating %d dns assertions", len(req.RawAssertions))
@@ -326,17 +382,26 @@ func (h Handler) DNSHandlerRegion(c *gin.Context) {
 				return nil, backoff.Permanent(err)
 			}
 		}
-		if !isSuccessful && called < retry {
+		if !isSuccessful {
+			attempt.Class = checker.ErrorClassAssertion
+			attempt.Error = "assertion failed"
+		}
+		if !isSuccessful && !policy.IsLastAttempt(called) && policy.Retryable(checker.ErrorClassAssertion) {
 			return nil, backoff.RetryAfter(1)
 		}
 		if !isSuccessful {
 			log.Ctx(ctx).Debug().Msg("dns assertions failed")
-			return response, backoff.Permanent(fmt.Errorf("assertion failed"))
+			return response, backoff.Permanent(errDNSAssertion)
 		}
 		return response, nil
 	}
 
-	result, err := backoff.Retry(ctx, op, backoff.WithBackOff(backoff.NewExponentialBackOff()), backoff.WithMaxTries(uint(retry)))
+	result, err := backoff.Retry(checkCtx, op, policy.Options()...)
+
+	if ctx.Err() != nil {
+		log.Ctx(ctx).Warn().Err(context.Cause(ctx)).Msg("request cancelled, dropping check result")
+		return
+	}
 	data.Latency = latency
 
 	if len(req.RawAssertions) > 0 {
@@ -347,12 +412,22 @@ func (h Handler) DNSHandlerRegion(c *gin.Context) {
 		}
 	}
 
-	if req.OtelConfig.Endpoint != "" {
+	if req.OtelConfig.Endpoint != "" && !dryRun(c) {
 		otelOS.RecordDNSMetrics(ctx, req, latency, err != nil || !isSuccessful, h.Region)
 	}
 
+	entry := audit.Entry{
+		Trigger:     "api",
+		WorkspaceID: req.WorkspaceID,
+		MonitorID:   req.MonitorID,
+		RequestID:   req.RequestId,
+		Target:      req.URI,
+	}
+
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{"message": "uri not reachable"})
+		env := dnsEnvelope(data, attempts, err, false)
+		h.finish(c, entry, env)
+		respond(c, gin.H{"message": "uri not reachable"}, env)
 		return
 	}
 
@@ -360,75 +435,25 @@ func (h Handler) DNSHandlerRegion(c *gin.Context) {
 	if req.RequestId != 0 {
 		if tbEvent, err := data.tinybirdEvent(); err != nil {
 			log.Ctx(ctx).Error().Err(err).Msg("failed to marshal dns records")
-		} else if err := h.TbClient.SendEvent(ctx, tbEvent, dataSourceName); err != nil {
+		} else if err := h.events(c).SendEvent(ctx, tbEvent, dataSourceName); err != nil {
 			log.Ctx(ctx).Error().Err(err).Msg("failed to send event to tinybird")
 		}
 	}
 
-	c.JSON(http.StatusOK, data)
+	env := dnsEnvelope(data, attempts, err, false)
+	h.finish(c, entry, env)
 
+	respond(c, data, env)
 }
 
+// FormatDNSResult returns the records of result by type, see
+// probes.DNSRecords.
 func FormatDNSResult(result *checker.DnsResponse) map[string][]string {
-	r := make(map[string][]string)
-	a := make([]string, 0)
-	aaaa := make([]string, 0)
-	mx := make([]string, 0)
-	ns := make([]string, 0)
-	txt := make([]string, 0)
-
-	for _, v := range result.A {
-		a = append(a, v)
-	}
-	r["A"] = a
-
-	for _, v := range result.AAAA {
-		aaaa = append(aaaa, v)
-	}
-	r["AAAA"] = aaaa
-
-	r["CNAME"] = []string{result.CNAME}
-	for _, v := range result.MX {
-		mx = append(mx, v)
-	}
-	r["MX"] = mx
-	for _, v := range result.NS {
-		ns = append(ns, v)
-	}
-	r["NS"] = ns
-	for _, v := range result.TXT {
-		txt = append(txt, v)
-	}
-	r["TXT"] = txt
-	return r
+	return probes.DNSRecords(result)
 }
 
+// EvaluateDNSAssertions evaluates the dnsRecord assertions of a DNS check,
+// see probes.DNSAssertions.
 func EvaluateDNSAssertions(rawAssertions []json.RawMessage, response *checker.DnsResponse) (bool, error) {
-	for _, a := range rawAssertions {
-		var assert assertions.RecordTarget
-		if err := json.Unmarshal(a, &assert); err != nil {
-			return false, fmt.Errorf("unable to parse assertion: %w", err)
-		}
-		var isSuccessfull bool
-		switch assert.Key {
-		case request.RecordA:
-			isSuccessfull = assert.RecordEvaluate(response.A)
-		case request.RecordAAAA:
-			isSuccessfull = assert.RecordEvaluate(response.AAAA)
-		case request.RecordCNAME:
-			isSuccessfull = assert.RecordEvaluate([]string{response.CNAME})
-		case request.RecordMX:
-			isSuccessfull = assert.RecordEvaluate(response.MX)
-		case request.RecordNS:
-			isSuccessfull = assert.RecordEvaluate(response.NS)
-		case request.RecordTXT:
-			isSuccessfull = assert.RecordEvaluate(response.TXT)
-		default:
-			return false, fmt.Errorf("unknown record type in assertion: %s", assert.Key)
-		}
-		if !isSuccessfull {
-			return false, nil
-		}
-	}
-	return true, nil
+	return probes.DNSAssertions(rawAssertions, response)
 }
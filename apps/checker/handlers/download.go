@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/audit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/download"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/probes"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// DownloadResponse is the event of a download check, Throughput in bytes
+// per second.
+type DownloadResponse struct {
+	ID            string `json:"id"`
+	ErrorMessage  string `json:"errorMessage"`
+	ErrorCode     string `json:"errorCode,omitempty"`
+	Region        string `json:"region"`
+	Trigger       string `json:"trigger"`
+	URL           string `json:"url"`
+	SHA256        string `json:"sha256,omitempty"`
+	RequestStatus string `json:"requestStatus,omitempty"`
+	Labels        string `json:"labels,omitempty"`
+
+	WorkspaceID   int64 `json:"workspaceId"`
+	MonitorID     int64 `json:"monitorId"`
+	Timestamp     int64 `json:"timestamp"`
+	Latency       int64 `json:"latency"`
+	CronTimestamp int64 `json:"cronTimestamp"`
+	Bytes         int64 `json:"bytes"`
+	Throughput    int64 `json:"throughput"`
+	StatusCode    int   `json:"statusCode,omitempty"`
+
+	Error uint8 `json:"error"`
+}
+
+// DownloadHandler downloads a file, failing the check when its SHA-256 is
+// not the one expected or when it exceeds its size cap.
+func (h Handler) DownloadHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	dataSourceName := "download_response__v0"
+
+	if !h.authorized(c) {
+		return
+	}
+
+	if h.replayed(c, "") {
+		return
+	}
+
+	var req request.DownloadCheckerRequest
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to decode checker request")
+		invalid(c, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid checker request")
+		invalid(c, err)
+		return
+	}
+	if h.blockedTarget(c, "url", req.URL, h.Guard.CheckURL) {
+		return
+	}
+
+	workspaceId, monitorId := monitorIDs(req.WorkspaceID, req.MonitorID)
+
+	if h.callbackTo(c, req.CallbackURL, req.WorkspaceID, req.MonitorID) {
+		return
+	}
+	if h.circuitOpen(c, req.MonitorID) {
+		return
+	}
+
+	trigger := req.Trigger
+	if trigger == "" {
+		trigger = "cron"
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to generate UUID")
+		return
+	}
+
+	// The transport of the guard keeps the redirects from reaching private
+	// addresses. The fixture recorder is left out, reading the whole body.
+	client := &http.Client{
+		Timeout:   request.Timeout("download", req.Timeout),
+		Transport: h.transport(probes.Connection{}),
+	}
+	defer client.CloseIdleConnections()
+
+	header := http.Header{}
+	for _, kv := range req.Headers {
+		header.Add(kv.Key, kv.Value)
+	}
+
+	start := time.Now()
+	err = h.Chaos.Inject(ctx, req.MonitorID)
+	var res download.Result
+	if err == nil {
+		res, err = download.Download(ctx, client, req.URL, header, req.Cap())
+	}
+	latency := time.Since(start).Milliseconds()
+	if h.cancelled(c, req.MonitorID) {
+		return
+	}
+
+	if err == nil && !strings.EqualFold(res.SHA256, req.SHA256) {
+		err = &checker.ClassifiedError{Class: checker.ErrorClassAssertion, Err: fmt.Errorf("SHA-256 %s, expected %s", res.SHA256, strings.ToLower(req.SHA256))}
+	}
+	h.recordOutcome(c, req.MonitorID, req.CronTimestamp, err != nil)
+
+	data := DownloadResponse{
+		ID:            id.String(),
+		Region:        h.Region,
+		Trigger:       trigger,
+		URL:           req.URL,
+		SHA256:        res.SHA256,
+		WorkspaceID:   workspaceId,
+		MonitorID:     monitorId,
+		CronTimestamp: req.CronTimestamp,
+		Labels:        labelsJSON(req.Labels),
+		Timestamp:     start.UTC().UnixMilli(),
+		Latency:       latency,
+		Bytes:         res.Bytes,
+		Throughput:    res.Throughput,
+		StatusCode:    res.Status,
+	}
+
+	switch {
+	case err != nil:
+		data.RequestStatus = "error"
+		data.Error = 1
+		data.ErrorMessage = err.Error()
+		data.ErrorCode = string(checker.ClassifyCode(err))
+		if req.Status != "error" {
+			h.updateStatus(c, checker.UpdateData{
+				MonitorId:     req.MonitorID,
+				Status:        "error",
+				Region:        h.Region,
+				Message:       err.Error(),
+				CronTimestamp: req.CronTimestamp,
+				Latency:       latency,
+			})
+		}
+	case req.Status != "active":
+		h.updateStatus(c, checker.UpdateData{
+			MonitorId:     req.MonitorID,
+			Status:        "active",
+			Region:        h.Region,
+			CronTimestamp: req.CronTimestamp,
+			Latency:       latency,
+		})
+		data.RequestStatus = "success"
+	default:
+		data.RequestStatus = "success"
+	}
+
+	if err := h.events(c).SendEvent(ctx, data, dataSourceName); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to send event to tinybird")
+	}
+
+	event, f := c.Get("event")
+	if f {
+		t := event.(map[string]any)
+		t["checker"] = map[string]string{
+			"uri":          req.URL,
+			"workspace_id": req.WorkspaceID,
+			"monitor_id":   req.MonitorID,
+			"trigger":      trigger,
+			"type":         "download",
+		}
+		c.Set("event", t)
+	}
+
+	env := Envelope{
+		Type:      "download",
+		Region:    h.Region,
+		Timestamp: data.Timestamp,
+		Attempts:  []checker.Attempt{checker.NewAttempt(1, start, err)},
+		Timing:    EnvelopeTiming{FirstByteMs: res.FirstByteMs, TransferMs: res.TransferMs, TotalMs: latency},
+		Download:  &res,
+	}
+	env.hostNames(req.URL)
+	env.outcome(err, false)
+	h.finish(c, audit.Entry{
+		Trigger:     trigger,
+		WorkspaceID: req.WorkspaceID,
+		MonitorID:   req.MonitorID,
+		Target:      req.URL,
+	}, env)
+
+	respond(c, data, env)
+}
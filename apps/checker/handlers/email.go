@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/audit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/mailauth"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// EmailResponse is the event of an email check. BrokenRecords is the JSON of
+// the records with a problem.
+type EmailResponse struct {
+	ID            string `json:"id"`
+	ErrorMessage  string `json:"errorMessage"`
+	ErrorCode     string `json:"errorCode,omitempty"`
+	Region        string `json:"region"`
+	Trigger       string `json:"trigger"`
+	URI           string `json:"uri"`
+	SPF           string `json:"spf,omitempty"`
+	DMARC         string `json:"dmarc,omitempty"`
+	Policy        string `json:"policy,omitempty"`
+	BrokenRecords string `json:"brokenRecords,omitempty"`
+	RequestStatus string `json:"requestStatus,omitempty"`
+	Labels        string `json:"labels,omitempty"`
+
+	WorkspaceID   int64 `json:"workspaceId"`
+	MonitorID     int64 `json:"monitorId"`
+	Timestamp     int64 `json:"timestamp"`
+	Latency       int64 `json:"latency"`
+	CronTimestamp int64 `json:"cronTimestamp"`
+	SPFLookups    int   `json:"spfLookups"`
+	Broken        int   `json:"broken"`
+
+	Error uint8 `json:"error"`
+}
+
+// EmailHandler checks the SPF, DKIM and DMARC records of a domain, failing
+// the check when one of them is missing or broken.
+func (h Handler) EmailHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	dataSourceName := "email_response__v0"
+
+	if !h.authorized(c) {
+		return
+	}
+
+	if h.replayed(c, "") {
+		return
+	}
+
+	var req request.EmailCheckerRequest
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to decode checker request")
+		invalid(c, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid checker request")
+		invalid(c, err)
+		return
+	}
+
+	workspaceId, monitorId := monitorIDs(req.WorkspaceID, req.MonitorID)
+	// The domain has been validated above.
+	domain, _ := request.ASCIIHost(req.URI)
+
+	if h.callbackTo(c, req.CallbackURL, req.WorkspaceID, req.MonitorID) {
+		return
+	}
+	if h.circuitOpen(c, req.MonitorID) {
+		return
+	}
+
+	trigger := req.Trigger
+	if trigger == "" {
+		trigger = "cron"
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to generate UUID")
+		return
+	}
+
+	start := time.Now()
+	err = h.Chaos.Inject(ctx, req.MonitorID)
+	var report mailauth.Report
+	if err == nil {
+		lookupCtx, cancel := context.WithTimeout(ctx, request.Timeout("email", req.Timeout))
+		report, err = mailauth.Check(lookupCtx, net.DefaultResolver, domain, mailauth.Config{Selectors: req.Selectors, MinPolicy: req.MinPolicy})
+		cancel()
+	}
+	latency := time.Since(start).Milliseconds()
+	if h.cancelled(c, req.MonitorID) {
+		return
+	}
+
+	broken := report.Broken()
+	if err == nil && len(broken) > 0 {
+		err = &checker.ClassifiedError{Class: checker.ErrorClassAssertion, Err: fmt.Errorf("%d broken records, first %s: %s", len(broken), broken[0].Name, broken[0].Error)}
+	}
+	h.recordOutcome(c, req.MonitorID, req.CronTimestamp, err != nil)
+
+	data := EmailResponse{
+		ID:            id.String(),
+		Region:        h.Region,
+		Trigger:       trigger,
+		URI:           req.URI,
+		SPF:           report.SPF.Value,
+		DMARC:         report.DMARC.Value,
+		Policy:        report.Policy,
+		WorkspaceID:   workspaceId,
+		MonitorID:     monitorId,
+		CronTimestamp: req.CronTimestamp,
+		Labels:        labelsJSON(req.Labels),
+		Timestamp:     start.UTC().UnixMilli(),
+		Latency:       latency,
+		SPFLookups:    report.SPFLookups,
+		Broken:        len(broken),
+	}
+	if len(broken) > 0 {
+		if j, err := json.Marshal(broken); err == nil {
+			data.BrokenRecords = string(j)
+		}
+	}
+
+	switch {
+	case err != nil:
+		data.RequestStatus = "error"
+		data.Error = 1
+		data.ErrorMessage = err.Error()
+		data.ErrorCode = string(checker.ClassifyCode(err))
+		if req.Status != "error" {
+			h.updateStatus(c, checker.UpdateData{
+				MonitorId:     req.MonitorID,
+				Status:        "error",
+				Region:        h.Region,
+				Message:       err.Error(),
+				CronTimestamp: req.CronTimestamp,
+				Latency:       latency,
+			})
+		}
+	case req.Status != "active":
+		h.updateStatus(c, checker.UpdateData{
+			MonitorId:     req.MonitorID,
+			Status:        "active",
+			Region:        h.Region,
+			CronTimestamp: req.CronTimestamp,
+			Latency:       latency,
+		})
+		data.RequestStatus = "success"
+	default:
+		data.RequestStatus = "success"
+	}
+
+	if err := h.events(c).SendEvent(ctx, data, dataSourceName); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to send event to tinybird")
+	}
+
+	event, f := c.Get("event")
+	if f {
+		t := event.(map[string]any)
+		t["checker"] = map[string]string{
+			"uri":          req.URI,
+			"workspace_id": req.WorkspaceID,
+			"monitor_id":   req.MonitorID,
+			"trigger":      trigger,
+			"type":         "email",
+		}
+		c.Set("event", t)
+	}
+
+	env := Envelope{
+		Type:      "email",
+		Region:    h.Region,
+		Timestamp: data.Timestamp,
+		Attempts:  []checker.Attempt{checker.NewAttempt(1, start, err)},
+		Timing:    EnvelopeTiming{TotalMs: latency},
+	}
+	if report.SPF.Name != "" {
+		env.Email = &report
+	}
+	env.hostNames(req.URI)
+	env.outcome(err, false)
+	h.finish(c, audit.Entry{
+		Trigger:     trigger,
+		WorkspaceID: req.WorkspaceID,
+		MonitorID:   req.MonitorID,
+		Target:      req.URI,
+	}, env)
+
+	respond(c, data, env)
+}
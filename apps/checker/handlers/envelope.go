@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/download"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/geoip"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/mailauth"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/oidc"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/probes"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// The /v2 routes run the same handlers as the legacy ones, but every check
+// type answers with an Envelope instead of its own response shape.
+
+const v2Key = "v2"
+
+const (
+	StatusSuccess  = "success"
+	StatusDegraded = "degraded"
+	StatusError    = "error"
+)
+
+// Error codes of the requests that could not be checked, check failures use
+// the checker.ErrorClass of the failure.
+const (
+	ErrCodeUnauthorized   = "unauthorized"
+	ErrCodeInvalidRequest = "invalid_request"
+	ErrCodeRateLimited    = "rate_limited"
+	ErrCodeNotFound       = "not_found"
+	ErrCodeMisdirected    = "misdirected_request"
+	ErrCodeInternal       = "internal_error"
+	ErrCodeTooLarge       = "request_too_large"
+)
+
+type EnvelopeError struct {
+	Code string `json:"code"`
+	// ErrorCode is the stable code of a check failure, finer than the class
+	// in Code, e.g. DNS_NXDOMAIN.
+	ErrorCode checker.ErrorCode `json:"errorCode,omitempty"`
+	Message   string            `json:"message"`
+	// Fields lists the rejected fields of an invalid request.
+	Fields []request.FieldError `json:"fields,omitempty"`
+}
+
+// EnvelopeTiming holds the duration of each phase of a check in
+// milliseconds, phases the check type does not have are omitted.
+type EnvelopeTiming struct {
+	DNSMs       int64 `json:"dnsMs,omitempty"`
+	ConnectMs   int64 `json:"connectMs,omitempty"`
+	ProxyMs     int64 `json:"proxyMs,omitempty"`
+	TLSMs       int64 `json:"tlsMs,omitempty"`
+	FirstByteMs int64 `json:"firstByteMs,omitempty"`
+	TransferMs  int64 `json:"transferMs,omitempty"`
+	TotalMs     int64 `json:"totalMs"`
+}
+
+type HTTPResult struct {
+	Headers   map[string]string     `json:"headers,omitempty"`
+	Body      string                `json:"body,omitempty"`
+	Redirects []checker.RedirectHop `json:"redirects,omitempty"`
+	HAR       *checker.HAR          `json:"har,omitempty"`
+	// CapturedHeaders are kept whatever the verbosity.
+	CapturedHeaders map[string]string `json:"capturedHeaders,omitempty"`
+	CacheStatus     string            `json:"cacheStatus,omitempty"`
+	BodyHash        string            `json:"bodyHash,omitempty"`
+	StatusCode      int               `json:"statusCode"`
+	// SecurityHeaders grades the security headers of the response, when
+	// asked for.
+	SecurityHeaders *checker.SecurityHeaders `json:"securityHeaders,omitempty"`
+	// BodyTruncated is set when the body was longer than the maxBodyBytes of
+	// the check and only its start was read.
+	BodyTruncated bool `json:"bodyTruncated,omitempty"`
+	// Conditional is the revalidation of the response of checks with
+	// conditional.
+	Conditional *checker.ConditionalResult `json:"conditional,omitempty"`
+}
+
+type DNSResult struct {
+	Records map[string][]string `json:"records"`
+}
+
+// Envelope is the response of every /v2 endpoint. Status is one of success,
+// degraded, error, circuit_open or skipped_dependency, and Error is set
+// whenever it is error or skipped_dependency.
+type Envelope struct {
+	Error      *EnvelopeError    `json:"error,omitempty"`
+	HTTP       *HTTPResult       `json:"http,omitempty"`
+	TLS        *checker.TLSInfo  `json:"tls,omitempty"`
+	DNS        *DNSResult        `json:"dns,omitempty"`
+	Status     string            `json:"status"`
+	Type       string            `json:"type,omitempty"`
+	Region     string            `json:"region,omitempty"`
+	Attempts   []checker.Attempt `json:"attempts"`
+	Timing     EnvelopeTiming    `json:"timing"`
+	Timestamp  int64             `json:"timestamp,omitempty"`
+	RetryAfter int64             `json:"retryAfter,omitempty"`
+	DryRun     bool              `json:"dryRun,omitempty"`
+	// Cached marks the result of an identical on-demand check served
+	// instead of running it, Age being its age in milliseconds.
+	Cached bool  `json:"cached,omitempty"`
+	Age    int64 `json:"age,omitempty"`
+	// RemoteIP is the address the check connected to, with its network and
+	// location in Geo when known.
+	RemoteIP string      `json:"remoteIp,omitempty"`
+	Geo      *geoip.Info `json:"geo,omitempty"`
+	// DualStack is the race of IPv4 and IPv6 connections of HTTP and TCP
+	// checks with dualStack.
+	DualStack *checker.DualStackResult `json:"dualStack,omitempty"`
+	// Content is the outcome of a content check.
+	Content *ContentResult `json:"content,omitempty"`
+	// Crawl is the outcome of a crawl check.
+	Crawl *CrawlResult `json:"crawl,omitempty"`
+	// GRPC is the outcome of a gRPC check.
+	GRPC *GRPCResult `json:"grpc,omitempty"`
+	// SMTP is the outcome of an SMTP check.
+	SMTP *SMTPResult `json:"smtp,omitempty"`
+	// Email is the outcome of an email check.
+	Email *mailauth.Report `json:"email,omitempty"`
+	// Hold is how long the connection of a TCP check with hold stayed open.
+	Hold *checker.HoldResult `json:"hold,omitempty"`
+	// Ports are the ports of a ports check.
+	Ports []probes.PortResult `json:"ports,omitempty"`
+	// OIDC is the outcome of an OIDC check.
+	OIDC *oidc.Report `json:"oidc,omitempty"`
+	// Download is the outcome of a download check.
+	Download *download.Result `json:"download,omitempty"`
+	// Host and ASCIIHost are the display and ASCII forms of an
+	// internationalized host, e.g. bücher.example and xn--bcher-kva.example,
+	// unset for ASCII ones.
+	Host      string `json:"host,omitempty"`
+	ASCIIHost string `json:"asciiHost,omitempty"`
+	// SlowPhases lists the phases above their degradedPhases threshold, e.g.
+	// tls, the check being degraded.
+	SlowPhases []string `json:"slowPhases,omitempty"`
+}
+
+// hostNames sets Host and ASCIIHost when the host of target, the URL, URI
+// or hostname of the check, is internationalized.
+func (e *Envelope) hostNames(target string) {
+	host := request.TargetHost(target)
+	if ascii, err := request.ASCIIHost(host); err == nil && ascii != host {
+		e.Host, e.ASCIIHost = host, ascii
+	}
+}
+
+// V2 marks the requests of the /v2 route group.
+func V2() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(v2Key, true)
+		c.Next()
+	}
+}
+
+// Deprecated flags a legacy route in favour of its /v2 successor, path
+// parameters of the successor are filled from the request.
+func Deprecated(successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		link := successor
+		for _, p := range c.Params {
+			link = strings.ReplaceAll(link, ":"+p.Key, p.Value)
+		}
+
+		c.Header("Deprecation", "true")
+		c.Header("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", link))
+		c.Next()
+	}
+}
+
+// respond writes env on /v2 and the legacy body otherwise.
+func respond(c *gin.Context, legacy any, env Envelope) {
+	if env.Attempts == nil {
+		env.Attempts = []checker.Attempt{}
+	}
+	env.DryRun = dryRun(c)
+	notify(c, env)
+	if v, ok := c.Get(verbosityKey); ok {
+		env = v.(verbosity).envelope(env)
+	}
+
+	if c.GetBool(v2Key) {
+		c.JSON(http.StatusOK, env)
+
+		return
+	}
+
+	c.JSON(http.StatusOK, legacy)
+}
+
+// fail answers a request that could not be checked at all.
+func fail(c *gin.Context, status int, code, message string, fields ...request.FieldError) {
+	if c.GetBool(v2Key) {
+		c.JSON(status, Envelope{
+			Status:   StatusError,
+			Error:    &EnvelopeError{Code: code, Message: message, Fields: fields},
+			Attempts: []checker.Attempt{},
+		})
+
+		return
+	}
+
+	if len(fields) > 0 {
+		c.JSON(status, gin.H{"error": message, "fields": fields})
+
+		return
+	}
+
+	c.JSON(status, gin.H{"error": message})
+}
+
+// invalid answers a request whose body could not be decoded or validated,
+// with the list of rejected fields.
+func invalid(c *gin.Context, err error) {
+	var fields request.ValidationError
+	if !errors.As(err, &fields) {
+		fields = request.DecodeError(err)
+	}
+
+	fail(c, http.StatusBadRequest, ErrCodeInvalidRequest, "invalid request", fields...)
+}
+
+// outcome sets the status of the envelope from the error of the check, nil
+// when it succeeded, and whether it left its monitor degraded, as reported by
+// Handler.degraded so the envelope agrees with the status update.
+func (e *Envelope) outcome(err error, degraded bool) {
+	switch {
+	case err != nil:
+		e.Status = StatusError
+		e.Error = &EnvelopeError{Code: string(checker.ClassifyError(err)), ErrorCode: checker.ClassifyCode(err), Message: err.Error()}
+	case degraded:
+		e.Status = StatusDegraded
+	default:
+		e.Status = StatusSuccess
+	}
+}
+
+// slowPhases lists the phases of timing above their threshold.
+func slowPhases(timing EnvelopeTiming, thresholds *request.PhaseThresholds) []string {
+	if thresholds == nil {
+		return nil
+	}
+
+	var slow []string
+	for _, p := range []struct {
+		name             string
+		value, threshold int64
+	}{
+		{"dns", timing.DNSMs, thresholds.DNS},
+		{"connect", timing.ConnectMs, thresholds.Connect},
+		{"tls", timing.TLSMs, thresholds.TLS},
+		{"firstByte", timing.FirstByteMs, thresholds.FirstByte},
+		{"total", timing.TotalMs, thresholds.Total},
+	} {
+		if p.threshold > 0 && p.value > p.threshold {
+			slow = append(slow, p.name)
+		}
+	}
+
+	return slow
+}
+
+// slowPhases degrades a successful check with phases above their threshold.
+func (e *Envelope) slowPhases(thresholds *request.PhaseThresholds) {
+	e.SlowPhases = slowPhases(e.Timing, thresholds)
+	if e.Status == StatusSuccess && len(e.SlowPhases) > 0 {
+		e.Status = StatusDegraded
+	}
+}
+
+// envelopeTiming returns t in milliseconds.
+func envelopeTiming(t probes.Timing) EnvelopeTiming {
+	return EnvelopeTiming{
+		DNSMs:       t.DNS.Milliseconds(),
+		ConnectMs:   t.Connect.Milliseconds(),
+		ProxyMs:     t.Proxy.Milliseconds(),
+		TLSMs:       t.TLS.Milliseconds(),
+		FirstByteMs: t.FirstByte.Milliseconds(),
+		TransferMs:  t.Transfer.Milliseconds(),
+		TotalMs:     t.Total.Milliseconds(),
+	}
+}
+
+func httpTiming(res checker.Response) EnvelopeTiming {
+	return envelopeTiming(probes.HTTPTiming(res))
+}
+
+func tcpTiming(res checker.TCPResponse) EnvelopeTiming {
+	timing := envelopeTiming(probes.TCPTiming(res.Timing))
+	timing.TotalMs = res.Latency
+
+	return timing
+}
+
+func httpEnvelope(region string, res checker.Response, err error, degraded bool) Envelope {
+	env := Envelope{
+		Type:      "http",
+		Region:    region,
+		Timestamp: res.Timestamp,
+		Attempts:  res.Attempts,
+		TLS:       res.TLS,
+		RemoteIP:  res.RemoteIP,
+		Geo:       res.Geo,
+		DualStack: res.DualStack,
+		Timing:    httpTiming(res),
+	}
+
+	if res.Status != 0 {
+		env.HTTP = &HTTPResult{StatusCode: res.Status, Headers: res.Headers, Body: res.Body, Redirects: res.Redirects, HAR: res.HAR, CapturedHeaders: res.CapturedHeaders, CacheStatus: res.CacheStatus, BodyHash: res.BodyHash, SecurityHeaders: res.SecurityHeaders, BodyTruncated: res.Truncated, Conditional: res.Conditional}
+	}
+
+	// The check ran but failed its assertions or returned an unsuccessful
+	// status code.
+	if err == nil && (res.Error != "" || (res.Status != 0 && !statusCode(res.Status).IsSuccessful())) {
+		message := "assertion failed"
+		if n := len(res.Attempts); n > 0 && res.Attempts[n-1].Error != "" {
+			message = res.Attempts[n-1].Error
+		} else if checker.ClassifyStatus(res.Status) != "" {
+			message = fmt.Sprintf("unexpected status code %d", res.Status)
+		}
+		err = &checker.ClassifiedError{Class: res.ErrorClass(), Code: res.ErrorCode(), Err: errors.New(message)}
+	}
+
+	env.outcome(err, degraded)
+
+	return env
+}
+
+func tcpEnvelope(region string, res checker.TCPResponse, err error, degraded bool) Envelope {
+	env := Envelope{
+		Type:      "tcp",
+		Region:    region,
+		Timestamp: res.Timestamp,
+		Attempts:  res.Attempts,
+		TLS:       res.TLS,
+		Hold:      res.Hold,
+		RemoteIP:  res.RemoteIP,
+		Geo:       res.Geo,
+		DualStack: res.DualStack,
+		Timing:    tcpTiming(res),
+	}
+
+	env.outcome(err, degraded)
+
+	return env
+}
+
+func dnsEnvelope(data DNSResponse, attempts []checker.Attempt, err error, degraded bool) Envelope {
+	env := Envelope{
+		Type:      "dns",
+		Region:    data.Region,
+		Timestamp: data.Timestamp,
+		Attempts:  attempts,
+		Timing:    EnvelopeTiming{DNSMs: data.Latency, TotalMs: data.Latency},
+	}
+	env.hostNames(data.URI)
+
+	if data.Records != nil {
+		env.DNS = &DNSResult{Records: data.Records}
+	}
+
+	env.outcome(err, degraded)
+
+	return env
+}
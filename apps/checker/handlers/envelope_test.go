@@ -0,0 +1,145 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/tinybird"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestHandler_V2Envelope(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/slow" {
+			time.Sleep(30 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	tbClient := tinybird.NewClient(&http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(""))}
+	})}, "apiKey")
+
+	h := handlers.Handler{TbClient: tbClient, Secret: "test", CloudProvider: "local", Region: "local"}
+	router := gin.New()
+	router.POST("/checker/http", handlers.Deprecated("/v2/checker/http"), h.HTTPCheckerHandler)
+	router.Group("/v2", handlers.V2()).POST("/checker/http", h.HTTPCheckerHandler)
+
+	do := func(path, auth string, data request.HttpCheckerRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(data)
+		req, _ := http.NewRequest(http.MethodPost, path, strings.NewReader(string(body)))
+		req.Header.Set("Authorization", auth)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("success", func(t *testing.T) {
+		w := do("/v2/checker/http", "Basic test", request.HttpCheckerRequest{
+			URL: target.URL, Method: http.MethodGet, Status: "active", Timeout: 1000,
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var env handlers.Envelope
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+		assert.Equal(t, handlers.StatusSuccess, env.Status)
+		assert.Equal(t, "http", env.Type)
+		assert.Nil(t, env.Error)
+		assert.Len(t, env.Attempts, 1)
+		if assert.NotNil(t, env.HTTP) {
+			assert.Equal(t, http.StatusOK, env.HTTP.StatusCode)
+		}
+	})
+
+	t.Run("degraded until recovered", func(t *testing.T) {
+		// Faster than degradedAfter, but not under recoverBelow.
+		w := do("/v2/checker/http", "Basic test", request.HttpCheckerRequest{
+			URL: target.URL + "/slow", Method: http.MethodGet, Status: "degraded", Timeout: 1000, DegradedAfter: 5000, RecoverBelow: 10,
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var env handlers.Envelope
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+		assert.Equal(t, handlers.StatusDegraded, env.Status)
+	})
+
+	t.Run("check failure", func(t *testing.T) {
+		w := do("/v2/checker/http", "Basic test", request.HttpCheckerRequest{
+			URL: target.URL + "/down", Method: http.MethodGet, Status: "error", Timeout: 1000, Retry: 1,
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var env handlers.Envelope
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+		assert.Equal(t, handlers.StatusError, env.Status)
+		if assert.NotNil(t, env.Error) {
+			assert.Equal(t, string(checker.ErrorClassHTTP5xx), env.Error.Code)
+			assert.Equal(t, checker.ErrorCodeHTTP5xx, env.Error.ErrorCode)
+		}
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		w := do("/v2/checker/http", "", request.HttpCheckerRequest{})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.JSONEq(t, `{"status":"error","error":{"code":"unauthorized","message":"unauthorized"},"attempts":[],"timing":{"totalMs":0}}`, w.Body.String())
+	})
+
+	t.Run("legacy route is deprecated", func(t *testing.T) {
+		w := do("/checker/http", "", request.HttpCheckerRequest{})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "true", w.Header().Get("Deprecation"))
+		assert.Equal(t, `</v2/checker/http>; rel="successor-version"`, w.Header().Get("Link"))
+		assert.JSONEq(t, `{"error":"unauthorized"}`, w.Body.String())
+	})
+}
+
+func TestHandler_DryRun(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer target.Close()
+
+	tbClient := tinybird.NewClient(&http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+		t.Error("dry runs must not send events to tinybird")
+		return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(""))}
+	})}, "apiKey")
+
+	h := handlers.Handler{TbClient: tbClient, Secret: "test", CloudProvider: "local", Region: "local"}
+	router := gin.New()
+	router.Group("/v2", handlers.V2()).POST("/checker/http", h.HTTPCheckerHandler)
+
+	// "active" would trigger a status update to error without the dry run.
+	body, _ := json.Marshal(request.HttpCheckerRequest{
+		URL: target.URL, Method: http.MethodGet, Status: "active", Timeout: 1000, Retry: 1,
+	})
+	req, _ := http.NewRequest(http.MethodPost, "/v2/checker/http?dryRun=true", strings.NewReader(string(body)))
+	req.Header.Set("Authorization", "Basic test")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var env handlers.Envelope
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	assert.True(t, env.DryRun)
+	assert.Equal(t, handlers.StatusError, env.Status)
+	if assert.NotNil(t, env.HTTP) {
+		assert.Equal(t, http.StatusServiceUnavailable, env.HTTP.StatusCode)
+	}
+}
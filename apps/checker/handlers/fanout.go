@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// ErrCodePeerUnavailable is the error code of the regions whose checker did
+// not answer a fan-out.
+const ErrCodePeerUnavailable = "peer_unavailable"
+
+// maxFanOutResponseBytes bounds the envelope read from each peer.
+const maxFanOutResponseBytes = 1 << 20
+
+// FanOut runs an on-demand check from the checkers of several regions.
+type FanOut struct {
+	// Peers are the base URLs of the checker of each region.
+	Peers  map[string]*url.URL
+	Client *http.Client
+	// Authorize authenticates the request of body to a peer, nil to forward
+	// the Authorization header of the fan-out.
+	Authorize func(r *http.Request, body []byte)
+}
+
+type FanOutResult struct {
+	Region string `json:"region"`
+	// Result is the envelope answered by the checker of the region.
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *EnvelopeError  `json:"error,omitempty"`
+	// status is the HTTP status of the answer of the region, the check
+	// failing to run at all when it is not 200.
+	status int
+}
+
+type FanOutResponse struct {
+	Results []FanOutResult `json:"results"`
+}
+
+// FanOutHandler runs the check of the request from each of its regions, in
+// parallel, and answers their envelopes in the order of the regions.
+func (h Handler) FanOutHandler(c *gin.Context) {
+	if !h.authorized(c) {
+
+		return
+	}
+	if h.FanOut == nil || len(h.FanOut.Peers) == 0 {
+		fail(c, http.StatusNotFound, ErrCodeNotFound, "no peer region configured")
+
+		return
+	}
+
+	var req request.FanOutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		invalid(c, err)
+
+		return
+	}
+	var fields request.ValidationError
+	if err := req.Validate(); err != nil {
+		fields, _ = err.(request.ValidationError)
+	}
+	for i, region := range req.Regions {
+		if _, ok := h.FanOut.Peers[region]; !ok && region != "" {
+			fields = append(fields, request.FieldError{Field: fmt.Sprintf("regions[%d]", i), Reason: "no checker known for the region", Value: region})
+		}
+	}
+	if len(fields) > 0 {
+		invalid(c, fields)
+
+		return
+	}
+
+	res := FanOutResponse{Results: make([]FanOutResult, len(req.Regions))}
+	var wg sync.WaitGroup
+	for i, region := range req.Regions {
+		wg.Go(func() {
+			res.Results[i] = h.FanOut.run(c, region, "v2/"+req.Type+"/"+region, req.Request)
+		})
+	}
+	wg.Wait()
+
+	c.JSON(http.StatusOK, res)
+}
+
+// run posts body to the endpoint of the checker of region at path, which may
+// have a query.
+func (f *FanOut) run(c *gin.Context, region, path string, body []byte) FanOutResult {
+	ctx := c.Request.Context()
+	result := FanOutResult{Region: region}
+	unavailable := func(err error) FanOutResult {
+		log.Ctx(ctx).Warn().Err(err).Str("region", region).Msg("fan-out peer unavailable")
+		result.Error = &EnvelopeError{Code: ErrCodePeerUnavailable, Message: err.Error()}
+
+		return result
+	}
+
+	ref, err := url.Parse(path)
+	if err != nil {
+		return unavailable(err)
+	}
+	endpoint := f.Peers[region].JoinPath(ref.Path)
+	endpoint.RawQuery = ref.RawQuery
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return unavailable(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if f.Authorize != nil {
+		f.Authorize(req, body)
+	} else {
+		req.Header.Set("Authorization", c.GetHeader("Authorization"))
+	}
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return unavailable(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFanOutResponseBytes))
+	if err != nil {
+		return unavailable(err)
+	}
+	if !json.Valid(data) {
+		return unavailable(fmt.Errorf("unexpected response: %s", resp.Status))
+	}
+	result.Result = data
+	result.status = resp.StatusCode
+
+	return result
+}
+
+// quorumKey is the key of the wide event of a failure, recording the vote of
+// the regions of its quorum.
+const quorumKey = "quorum"
+
+// quorumReached has the checkers of the regions of quorum run the failed
+// scheduled check of c again, as a dry run, and reports whether enough of the
+// regions, this one included, failed for the monitor to go down. The regions
+// that do not answer do not vote. Checks without quorum always reach it.
+func (h Handler) quorumReached(c *gin.Context, quorum *request.Quorum, checkType string) bool {
+	if quorum == nil {
+		return true
+	}
+	ctx := c.Request.Context()
+	if h.FanOut == nil {
+		// Better a blip alert than a missed outage.
+		log.Ctx(ctx).Warn().Msg("no peer region configured for the quorum, reporting the failure")
+
+		return true
+	}
+
+	body, err := quorumBody(c)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("unable to forward the check to the quorum, reporting the failure")
+
+		return true
+	}
+
+	votes := map[string]string{h.Region: StatusError}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, region := range quorum.Regions {
+		if region == h.Region {
+			continue
+		}
+		if _, ok := h.FanOut.Peers[region]; !ok {
+			// Written under mu, the checks of the peers before it may be
+			// voting already.
+			mu.Lock()
+			votes[region] = ErrCodePeerUnavailable
+			mu.Unlock()
+			continue
+		}
+		wg.Go(func() {
+			vote := ErrCodePeerUnavailable
+			res := h.FanOut.run(c, region, "v2/checker/"+checkType+"?dryRun=true", body)
+			var env Envelope
+			if res.status == http.StatusOK && json.Unmarshal(res.Result, &env) == nil && env.Status != "" {
+				vote = env.Status
+			}
+			mu.Lock()
+			votes[region] = vote
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, vote := range votes {
+		if vote == StatusError {
+			failed++
+		}
+	}
+	required := quorum.Required()
+
+	if e, f := c.Get("event"); f {
+		t := e.(map[string]any)
+		t[quorumKey] = map[string]any{"failed": failed, "required": required, "votes": votes}
+		c.Set("event", t)
+	}
+
+	return failed >= required
+}
+
+// quorumBody returns the body of the check of c for the regions of its
+// quorum, without the quorum so they do not start a vote of their own.
+func quorumBody(c *gin.Context) ([]byte, error) {
+	raw, ok := c.Get(gin.BodyBytesKey)
+	if !ok {
+		return nil, fmt.Errorf("request body not kept")
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw.([]byte), &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "quorum")
+
+	return json.Marshal(fields)
+}
@@ -0,0 +1,78 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+)
+
+func TestHandler_FanOutHandler(t *testing.T) {
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Authorization") != "Basic peer" || string(body) != `{"url":"https://openstat.us"}` {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","region":"` + strings.TrimPrefix(r.URL.Path, "/v2/http/") + `"}`))
+	}))
+	defer peer.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer down.Close()
+
+	peerURL, _ := url.Parse(peer.URL)
+	downURL, _ := url.Parse(down.URL)
+	h := handlers.Handler{
+		Secret: "test",
+		Region: "ams",
+		FanOut: &handlers.FanOut{
+			Peers:  map[string]*url.URL{"ams": peerURL, "iad": peerURL, "syd": downURL},
+			Client: peer.Client(),
+			Authorize: func(r *http.Request, _ []byte) {
+				r.Header.Set("Authorization", "Basic peer")
+			},
+		},
+	}
+	router := gin.New()
+	router.POST("/v2/fanout", handlers.V2(), h.FanOutHandler)
+
+	fanOut := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/v2/fanout", strings.NewReader(body))
+		req.Header.Set("Authorization", "Basic test")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("aggregates the regions", func(t *testing.T) {
+		w := fanOut(`{"type": "http", "regions": ["iad", "syd", "ams"], "request": {"url":"https://openstat.us"}}`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var res handlers.FanOutResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		require.Len(t, res.Results, 3)
+		assert.Equal(t, "iad", res.Results[0].Region)
+		assert.JSONEq(t, `{"status":"success","region":"iad"}`, string(res.Results[0].Result))
+		assert.Equal(t, "syd", res.Results[1].Region)
+		require.NotNil(t, res.Results[1].Error)
+		assert.Equal(t, handlers.ErrCodePeerUnavailable, res.Results[1].Error.Code)
+		assert.JSONEq(t, `{"status":"success","region":"ams"}`, string(res.Results[2].Result))
+	})
+
+	t.Run("unknown region", func(t *testing.T) {
+		w := fanOut(`{"type": "http", "regions": ["iad", "gru"], "request": {"url":"https://openstat.us"}}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"field":"regions[1]"`)
+	})
+}
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/audit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/probes"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// GRPCResponse is the event of a gRPC check. Response is the JSON of the
+// response message.
+type GRPCResponse struct {
+	ID            string `json:"id"`
+	ErrorMessage  string `json:"errorMessage"`
+	ErrorCode     string `json:"errorCode,omitempty"`
+	Region        string `json:"region"`
+	Trigger       string `json:"trigger"`
+	URI           string `json:"uri"`
+	Method        string `json:"method"`
+	RequestStatus string `json:"requestStatus,omitempty"`
+	GRPCStatus    string `json:"grpcStatus,omitempty"`
+	Response      string `json:"response,omitempty"`
+	Labels        string `json:"labels,omitempty"`
+
+	WorkspaceID   int64 `json:"workspaceId"`
+	MonitorID     int64 `json:"monitorId"`
+	Timestamp     int64 `json:"timestamp"`
+	Latency       int64 `json:"latency"`
+	CronTimestamp int64 `json:"cronTimestamp"`
+	GRPCCode      int   `json:"grpcCode"`
+
+	Error uint8 `json:"error"`
+}
+
+// GRPCResult is the outcome of a gRPC check in the envelope.
+type GRPCResult struct {
+	// Code is the status code of the call, e.g. 0, and Status its name,
+	// e.g. OK.
+	Code     int             `json:"code"`
+	Status   string          `json:"status"`
+	Message  string          `json:"message,omitempty"`
+	Response json.RawMessage `json:"response,omitempty"`
+}
+
+// GRPCHandler invokes the method of a gRPC check, discovered with the server
+// reflection of its target, and evaluates its assertions on the status and
+// the JSON of the response.
+func (h Handler) GRPCHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	dataSourceName := "grpc_response__v0"
+
+	if !h.authorized(c) {
+		return
+	}
+
+	if h.replayed(c, "") {
+		return
+	}
+
+	var req request.GRPCCheckerRequest
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to decode checker request")
+		invalid(c, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid checker request")
+		invalid(c, err)
+		return
+	}
+	if h.blockedTarget(c, "uri", req.Address(), h.Guard.CheckHostPort) {
+		return
+	}
+
+	workspaceId, monitorId := monitorIDs(req.WorkspaceID, req.MonitorID)
+
+	if h.callbackTo(c, req.CallbackURL, req.WorkspaceID, req.MonitorID) {
+		return
+	}
+	if h.circuitOpen(c, req.MonitorID) {
+		return
+	}
+
+	trigger := req.Trigger
+	if trigger == "" {
+		trigger = "cron"
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to generate UUID")
+		return
+	}
+
+	start := time.Now()
+	err = h.Chaos.Inject(ctx, req.MonitorID)
+	var res probes.GRPCResult
+	if err == nil {
+		res, err = h.prober().GRPC(ctx, req)
+	}
+	latency := time.Since(start).Milliseconds()
+	if h.cancelled(c, req.MonitorID) {
+		return
+	}
+	if err == nil && !res.OK {
+		err = &checker.ClassifiedError{Class: checker.ErrorClassAssertion, Err: fmt.Errorf("assertion failed, status %s: %s", res.Code, res.Message)}
+	}
+	h.recordOutcome(c, req.MonitorID, req.CronTimestamp, err != nil)
+
+	data := GRPCResponse{
+		ID:            id.String(),
+		Region:        h.Region,
+		Trigger:       trigger,
+		URI:           req.URI,
+		Method:        req.Method,
+		WorkspaceID:   workspaceId,
+		MonitorID:     monitorId,
+		CronTimestamp: req.CronTimestamp,
+		Labels:        labelsJSON(req.Labels),
+		Timestamp:     start.UTC().UnixMilli(),
+		Latency:       latency,
+		Response:      res.Response,
+	}
+	// The calls not reaching the server have no status.
+	called := res.Timing.Total > 0
+	if called {
+		data.GRPCCode, data.GRPCStatus = int(res.Code), res.Code.String()
+	}
+
+	switch {
+	case err != nil:
+		data.RequestStatus = "error"
+		data.Error = 1
+		data.ErrorMessage = err.Error()
+		data.ErrorCode = string(checker.ClassifyCode(err))
+		if req.Status != "error" {
+			h.updateStatus(c, checker.UpdateData{
+				MonitorId:     req.MonitorID,
+				Status:        "error",
+				Region:        h.Region,
+				Message:       err.Error(),
+				CronTimestamp: req.CronTimestamp,
+				Latency:       latency,
+			})
+		}
+	case req.Status != "active":
+		h.updateStatus(c, checker.UpdateData{
+			MonitorId:     req.MonitorID,
+			Status:        "active",
+			Region:        h.Region,
+			CronTimestamp: req.CronTimestamp,
+			Latency:       latency,
+		})
+		data.RequestStatus = "success"
+	default:
+		data.RequestStatus = "success"
+	}
+
+	if err := h.events(c).SendEvent(ctx, data, dataSourceName); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to send event to tinybird")
+	}
+
+	event, f := c.Get("event")
+	if f {
+		t := event.(map[string]any)
+		t["checker"] = map[string]string{
+			"uri":          req.URI,
+			"workspace_id": req.WorkspaceID,
+			"monitor_id":   req.MonitorID,
+			"trigger":      trigger,
+			"type":         "grpc",
+		}
+		c.Set("event", t)
+	}
+
+	env := Envelope{
+		Type:      "grpc",
+		Region:    h.Region,
+		Timestamp: data.Timestamp,
+		Attempts:  []checker.Attempt{checker.NewAttempt(1, start, err)},
+		Timing:    EnvelopeTiming{TotalMs: latency},
+	}
+	if called {
+		env.GRPC = &GRPCResult{Code: int(res.Code), Status: res.Code.String(), Message: res.Message}
+		if res.Response != "" {
+			env.GRPC.Response = json.RawMessage(res.Response)
+		}
+	}
+	env.hostNames(req.URI)
+	env.outcome(err, false)
+	h.finish(c, audit.Entry{
+		Trigger:     trigger,
+		WorkspaceID: req.WorkspaceID,
+		MonitorID:   req.MonitorID,
+		Target:      req.URI,
+	}, env)
+
+	respond(c, data, env)
+}
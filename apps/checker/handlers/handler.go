@@ -1,20 +1,486 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/audit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/auth"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/callback"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/chaos"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/circuit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/content"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/dependency"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/fixture"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/geoip"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/heartbeat"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/latency"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/mailbox"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/oauth2"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/probes"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/replay"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/ssrf"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/statsd"
 	"github.com/openstatushq/openstatus/apps/checker/pkg/tinybird"
+	"github.com/openstatushq/openstatus/apps/checker/request"
 )
 
 type Handler struct {
 	TbClient      tinybird.Client
+	Breaker       *circuit.Breaker
 	Secret        string
 	CloudProvider string
 	Region        string
+	// Auth authenticates the requests, Basic with Secret when nil.
+	Auth    auth.Authenticator
+	Version string
+	// EgressIPs are the public addresses of the checker, when known.
+	EgressIPs []string
+	// Guard keeps the checks off the internal network, nil to allow
+	// every target.
+	Guard *ssrf.Guard
+	// Audit records every executed check, when set.
+	Audit audit.Sink
+	// Tokens caches the OAuth2 tokens of authenticated checks, nil to
+	// fetch one for every check.
+	Tokens *oauth2.Cache
+	// Heartbeats records the pings of /heartbeat/:token, nil when no
+	// heartbeat is configured.
+	Heartbeats *heartbeat.Watchdog
+	// Dependencies keeps the monitors down in each tick, for the checks of
+	// their children.
+	Dependencies *dependency.Tracker
+	// Outbox retries the status updates until they are delivered, nil to
+	// send them once.
+	Outbox *checker.Outbox
+	// Replay forwards the checks routed to another region than theirs, nil
+	// to run every check here.
+	Replay replay.Provider
+	// GeoIP enriches the addresses checked with their network and location,
+	// nil to record the addresses only.
+	GeoIP *geoip.DB
+	// FanOut runs the checks of /v2/fanout from the peer regions, nil when
+	// no peer is configured.
+	FanOut *FanOut
+	// Latencies averages the latencies of the monitors with a degraded
+	// window, nil to compare each check on its own.
+	Latencies *latency.Averages
+	// Chaos injects faults into the scheduled checks of chosen monitors, nil
+	// outside of test deployments.
+	Chaos *chaos.Monkey
+	// Recorder captures the HTTP interactions of the scheduled checks, for
+	// the fixtures of the tests, nil to record nothing.
+	Recorder *fixture.Recorder
+	// Contents keeps the text of the last run of the content checks sent
+	// without their previous content, nil to keep none.
+	Contents *content.Store
+	// StatsD receives the latency and outcome of each check, tagged by
+	// monitor and region, when set.
+	StatsD *statsd.Client
+	// Callbacks posts the results of the checks to their callbackUrl, nil
+	// to reject the checks with a callback.
+	Callbacks *callback.Sender
+	// Batches keeps the results of the batches for their pages to be read,
+	// nil to answer every result of a batch at once.
+	Batches *Batches
+	// Mailbox receives the messages of the SMTP checks, nil to reject
+	// them.
+	Mailbox *mailbox.Mailbox
+}
+
+const authenticatedKey = "authenticated"
+
+// authorized authenticates the request once: RequireAuth and the handlers
+// both call it, and a signed request must not be verified twice. A rejected
+// request is answered a 401, or a 413 when its body is too large to be
+// verified.
+func (h Handler) authorized(c *gin.Context) bool {
+	if c.GetBool(authenticatedKey) {
+		return true
+	}
+
+	authenticator := h.Auth
+	if authenticator == nil {
+		authenticator = auth.Basic{Secrets: []string{h.Secret}}
+	}
+
+	if err := authenticator.Authenticate(c.Request); err != nil {
+		log.Ctx(c.Request.Context()).Debug().Err(err).Msg("request rejected")
+		if errors.Is(err, auth.ErrBodyTooLarge) {
+			fail(c, http.StatusRequestEntityTooLarge, ErrCodeTooLarge, "request body too large")
+		} else {
+			fail(c, http.StatusUnauthorized, ErrCodeUnauthorized, "unauthorized")
+		}
+		return false
+	}
+	c.Set(authenticatedKey, true)
+
+	return true
+}
+
+// RequireAuth rejects unauthenticated requests before the middlewares that
+// follow it run.
+func (h Handler) RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !h.authorized(c) {
+			c.Abort()
+
+			return
+		}
+
+		c.Next()
+	}
 }
 
-// Authorization could be handle by middleware
+// labelsJSON encodes the labels of a check for its Tinybird events, which
+// keep them in a single String column.
+func labelsJSON(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(labels)
+
+	return string(b)
+}
+
+// monitorIDs parses the ids of the monitor of a check, validated with its
+// request, 0 when an on-demand check does not set them.
+func monitorIDs(workspaceID, monitorID string) (int64, int64) {
+	workspaceId, _ := strconv.ParseInt(workspaceID, 10, 64)
+	monitorId, _ := strconv.ParseInt(monitorID, 10, 64)
+
+	return workspaceId, monitorId
+}
+
+// replayed forwards the request to the checker of its region when it is not
+// this one, and reports whether it did. region is the one of the route, if
+// any.
+func (h Handler) replayed(c *gin.Context, region string) bool {
+	if h.Replay == nil {
+		return false
+	}
+	target := h.Replay.Target(c.Request, region)
+	if target == "" || target == h.Region {
+		return false
+	}
+
+	if err := h.Replay.Replay(c.Writer, c.Request, target); err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Str("target", target).Msg("failed to forward request")
+		fail(c, http.StatusMisdirectedRequest, ErrCodeMisdirected, err.Error())
+	}
+
+	return true
+}
+
+// blockedTarget rejects the check when its target is an address of the
+// network of the checker. Resolved names are checked again when dialed.
+func (h Handler) blockedTarget(c *gin.Context, field, target string, check func(string) error) bool {
+	if err := check(target); err != nil {
+		log.Ctx(c.Request.Context()).Warn().Err(err).Str("target", target).Msg("target blocked")
+		invalid(c, request.ValidationError{{Field: field, Reason: err.Error(), Value: target}})
+
+		return true
+	}
+
+	return false
+}
+
+// prober runs the checks, kept off the internal network by the guard.
+func (h Handler) prober() probes.Prober {
+	p := probes.Prober{Guard: h.Guard}
+	if h.Mailbox != nil {
+		p.Inbox = h.Mailbox
+	}
+
+	return p
+}
+
+// transport returns the transport of an HTTP check reaching its target
+// through conn.
+func (h Handler) transport(conn probes.Connection) *http.Transport {
+	return h.prober().Transport(conn)
+}
 
 func NewHTTPClient() *http.Client {
 	return &http.Client{}
 }
+
+// circuitOpen answers the request without running the check when the monitor
+// has been failing for a while, so hard-down targets do not trigger a storm of
+// retries and status updates on every tick.
+func (h Handler) circuitOpen(c *gin.Context, monitorID string) bool {
+	if dryRun(c) {
+		return false
+	}
+
+	allowed, wait := h.Breaker.Allow(monitorID)
+	if allowed {
+		return false
+	}
+
+	if e, f := c.Get("event"); f {
+		t := e.(map[string]any)
+		t["circuit"] = circuit.StatusOpen
+		c.Set("event", t)
+	}
+
+	respond(c, gin.H{"status": circuit.StatusOpen, "retryAfter": wait.Milliseconds()}, Envelope{
+		Status:     circuit.StatusOpen,
+		Region:     h.Region,
+		RetryAfter: wait.Milliseconds(),
+	})
+
+	return true
+}
+
+// cancelled reports whether the request of c was cancelled while its check
+// ran, the result then being dropped. The check had no outcome, so it
+// releases the trial of the circuit of the monitor, if it was one.
+func (h Handler) cancelled(c *gin.Context, monitorID string) bool {
+	ctx := c.Request.Context()
+	if ctx.Err() == nil {
+		return false
+	}
+
+	log.Ctx(ctx).Warn().Err(context.Cause(ctx)).Msg("request cancelled, dropping check result")
+	if !dryRun(c) {
+		h.Breaker.Release(monitorID)
+	}
+
+	return true
+}
+
+// recordOutcome feeds the result of a check to the circuit breaker and to
+// the checks of the monitors depending on it.
+func (h Handler) recordOutcome(c *gin.Context, monitorID string, cronTimestamp int64, failed bool) {
+	if dryRun(c) {
+		return
+	}
+
+	h.Dependencies.Record(monitorID, cronTimestamp, failed)
+	if failed {
+		h.Breaker.Failure(monitorID)
+		return
+	}
+
+	h.Breaker.Success(monitorID)
+}
+
+// scheduleOffset is how many milliseconds a check started after the time a
+// scheduler meant it to, 0 when it did not say.
+func scheduleOffset(scheduledAt int64) int64 {
+	if scheduledAt == 0 {
+		return 0
+	}
+
+	return time.Now().UnixMilli() - scheduledAt
+}
+
+// skippedDependency reports whether a failed check has one of its parents
+// down in the same tick, the check is then skipped instead of failed.
+func (h Handler) skippedDependency(c *gin.Context, parents []string, cronTimestamp int64) bool {
+	parent, down := h.Dependencies.Down(c.Request.Context(), parents, cronTimestamp)
+	if !down {
+		return false
+	}
+
+	if e, f := c.Get("event"); f {
+		t := e.(map[string]any)
+		t[dependency.StatusSkipped] = parent
+		c.Set("event", t)
+	}
+
+	return true
+}
+
+// degraded reports whether a successful check of latency leaves its monitor
+// degraded: once its average latency over window checks goes above
+// degradedAfter, and until it goes back under recoverBelow. Dry runs are
+// not averaged.
+func (h Handler) degraded(c *gin.Context, monitorID, status string, l, degradedAfter, recoverBelow int64, window int) bool {
+	if degradedAfter <= 0 {
+		return false
+	}
+	if !dryRun(c) {
+		l = h.Latencies.Add(monitorID, l, window)
+	}
+	if recoverBelow == 0 {
+		recoverBelow = degradedAfter
+	}
+
+	if status == "degraded" {
+		return l >= recoverBelow
+	}
+
+	return l > degradedAfter
+}
+
+// unconfirmedKey is the key of the wide event of a failure not confirmed by
+// the runs of the confirm block of its check.
+const unconfirmedKey = "unconfirmed"
+
+// confirmed re-runs a failed check with probe, which reports whether its run
+// failed, and reports whether enough of the runs failed for the monitor to go
+// down. It stops as soon as the outcome is known. Checks without confirm are
+// always confirmed.
+func (h Handler) confirmed(c *gin.Context, confirm *request.Confirm, probe func() bool) bool {
+	if confirm == nil || confirm.Checks < 2 {
+		return true
+	}
+	failures := confirm.Failures
+	if failures == 0 {
+		failures = confirm.Checks
+	}
+
+	failed := 1
+	for run := 1; run < confirm.Checks && failed < failures && failed+confirm.Checks-run >= failures; run++ {
+		if c.Request.Context().Err() != nil {
+			break
+		}
+		if probe() {
+			failed++
+		}
+	}
+	if failed >= failures {
+		return true
+	}
+
+	if e, f := c.Get("event"); f {
+		t := e.(map[string]any)
+		t[unconfirmedKey] = fmt.Sprintf("%d/%d", failed, confirm.Checks)
+		c.Set("event", t)
+	}
+
+	return false
+}
+
+// finish ends every executed check: it sends its metrics and records it in
+// the audit log, each whatever the other is set to.
+func (h Handler) finish(c *gin.Context, e audit.Entry, env Envelope) {
+	h.emitMetrics(c, e.MonitorID, env)
+	h.audit(c, e, env)
+}
+
+// audit records an executed check with its outcome. Dry runs are recorded
+// too: they reach the target all the same.
+func (h Handler) audit(c *gin.Context, e audit.Entry, env Envelope) {
+	if h.Audit == nil {
+		return
+	}
+
+	e.Type = env.Type
+	e.Region = h.Region
+	e.Outcome = env.Status
+	if env.Error != nil {
+		e.ErrorCode = env.Error.Code
+	}
+	e.CorrelationID = c.GetString("requestId")
+	e.ClientIP = c.ClientIP()
+	e.Timestamp = time.Now().UTC().UnixMilli()
+	e.DryRun = dryRun(c)
+
+	if err := h.Audit.Record(c.Request.Context(), e); err != nil {
+		log.Ctx(c.Request.Context()).Error().Err(err).Msg("failed to record audit entry")
+	}
+}
+
+// emitMetrics sends the latency of a check and counts it, and its error, to
+// StatsD. Dry runs send nothing, as they send no OTLP metrics.
+func (h Handler) emitMetrics(c *gin.Context, monitorID string, env Envelope) {
+	if h.StatsD == nil || dryRun(c) {
+		return
+	}
+
+	tags := []string{"type:" + env.Type, "monitor:" + monitorID, "region:" + h.Region, "status:" + env.Status}
+	h.StatsD.Timing("check.latency", env.Timing.TotalMs, tags...)
+	h.StatsD.Count("check.count", 1, tags...)
+	if env.Error != nil {
+		h.StatsD.Count("check.error", 1, append(tags, "code:"+env.Error.Code)...)
+	}
+}
+
+// dryRun reports whether the check was submitted with ?dryRun=true. Such
+// checks run and return their full result, but leave no trace: no Tinybird
+// events, no status updates, no OTLP metrics and no circuit breaker state.
+func dryRun(c *gin.Context) bool {
+	return c.Query("dryRun") == "true"
+}
+
+type discardEvents struct{}
+
+func (discardEvents) SendEvent(context.Context, any, string) error {
+	return nil
+}
+
+// events returns the Tinybird client of the request, which drops the events
+// of dry runs.
+func (h Handler) events(c *gin.Context) tinybird.Client {
+	if dryRun(c) {
+		return discardEvents{}
+	}
+
+	return h.TbClient
+}
+
+// updateStatus reports a status change of the monitor, unless the check is a
+// dry run.
+func (h Handler) updateStatus(c *gin.Context, data checker.UpdateData) {
+	if dryRun(c) {
+		return
+	}
+
+	h.sendStatus(c.Request.Context(), data)
+}
+
+// sendStatus sends a status update through the outbox.
+func (h Handler) sendStatus(ctx context.Context, data checker.UpdateData) {
+	if h.Outbox != nil {
+		h.Outbox.Add(data)
+		return
+	}
+
+	checker.UpdateStatus(ctx, data)
+}
+
+// tokenTimeout bounds the fetch of the token of an authenticated check.
+const tokenTimeout = 10 * time.Second
+
+// authenticate adds the token of the auth block of a check to the requests
+// of base to the host of target, base as is without auth.
+func (h Handler) authenticate(base http.RoundTripper, a *request.Auth, target string) http.RoundTripper {
+	if a == nil {
+		return base
+	}
+
+	u, err := url.Parse(target)
+	if err != nil {
+		return base
+	}
+
+	// The token endpoint is not monitored, its connections are not kept.
+	t := h.Guard.Transport()
+	t.DisableKeepAlives = true
+
+	return &oauth2.Transport{
+		Base:   base,
+		Cache:  h.Tokens,
+		Client: &http.Client{Transport: t, Timeout: tokenTimeout},
+		Host:   u.Host,
+		Config: oauth2.Config{
+			TokenURL:     a.TokenURL,
+			ClientID:     a.ClientID,
+			ClientSecret: a.ClientSecret,
+			Scopes:       a.Scopes,
+		},
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/heartbeat"
+)
+
+// HeartbeatData is the Tinybird event of a heartbeat status change, or of a
+// completed run. Duration is the time of the run in milliseconds, when the
+// job pinged its start.
+type HeartbeatData struct {
+	ID          string `json:"id"`
+	WorkspaceID string `json:"workspaceId"`
+	MonitorID   string `json:"monitorId"`
+	Region      string `json:"region"`
+	Status      string `json:"status"`
+	LastPing    int64  `json:"lastPing"`
+	Timestamp   int64  `json:"timestamp"`
+	Duration    int64  `json:"duration,omitempty"`
+}
+
+// HeartbeatHandler records a ping of the heartbeat of the token of the URL,
+// the token authenticating the request on its own.
+func (h Handler) HeartbeatHandler(c *gin.Context) {
+	if h.Heartbeats == nil || !h.Heartbeats.Ping(c.Request.Context(), c.Param("token")) {
+		fail(c, http.StatusNotFound, ErrCodeNotFound, "unknown heartbeat")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "ok"})
+}
+
+// HeartbeatStartHandler records the start of a run of the heartbeat of the
+// token of the URL, ended by the next ping of HeartbeatHandler.
+func (h Handler) HeartbeatStartHandler(c *gin.Context) {
+	if h.Heartbeats == nil || !h.Heartbeats.Start(c.Request.Context(), c.Param("token")) {
+		fail(c, http.StatusNotFound, ErrCodeNotFound, "unknown heartbeat")
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "ok"})
+}
+
+// ReportHeartbeat sends the event of a heartbeat, and updates the status of
+// its monitor unless the event only reports a completed run.
+func (h Handler) ReportHeartbeat(ctx context.Context, e heartbeat.Event) {
+	data := HeartbeatData{
+		ID:          uuid.New().String(),
+		WorkspaceID: e.Heartbeat.WorkspaceID,
+		MonitorID:   e.Heartbeat.MonitorID,
+		Region:      h.Region,
+		Status:      e.Status,
+		LastPing:    e.LastPing.UnixMilli(),
+		Timestamp:   e.Timestamp.UnixMilli(),
+		Duration:    e.Duration.Milliseconds(),
+	}
+	if err := h.TbClient.SendEvent(ctx, data, "heartbeat__v0"); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to send heartbeat event to tinybird")
+	}
+
+	update := checker.UpdateData{
+		MonitorId:     e.Heartbeat.MonitorID,
+		Status:        "active",
+		Region:        h.Region,
+		CronTimestamp: e.Timestamp.UnixMilli(),
+		Latency:       e.Duration.Milliseconds(),
+	}
+	switch e.Status {
+	case heartbeat.StatusCompleted:
+		return
+	case heartbeat.StatusMissed:
+		update.Status = "error"
+		update.Message = fmt.Sprintf("No heartbeat received since %s", e.LastPing.UTC().Format(time.RFC3339))
+	case heartbeat.StatusTimeout:
+		update.Status = "error"
+		update.Message = fmt.Sprintf("Run started at %s still running after %s", e.LastPing.UTC().Format(time.RFC3339), e.Duration.Round(time.Second))
+	}
+	h.sendStatus(ctx, update)
+}
@@ -0,0 +1,34 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/heartbeat"
+)
+
+func TestHandler_HeartbeatHandler(t *testing.T) {
+	ping := func(h handlers.Handler, token string) int {
+		router := gin.New()
+		router.POST("/heartbeat/:token", h.HeartbeatHandler)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/heartbeat/"+token, nil)
+		router.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusNotFound, ping(handlers.Handler{}, "token"), "no heartbeat configured")
+
+	h := handlers.Handler{Heartbeats: heartbeat.New([]heartbeat.Heartbeat{
+		{Token: "token", MonitorID: "1", Interval: "1m"},
+	}, func(context.Context, heartbeat.Event) {})}
+	assert.Equal(t, http.StatusOK, ping(h, "token"))
+	assert.Equal(t, http.StatusNotFound, ping(h, "other"))
+}
@@ -0,0 +1,177 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/fixture"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/tinybird"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// statusUpdates points the status updates of the handlers at a server
+// keeping them, and returns those received so far.
+func statusUpdates(t *testing.T) func() []checker.UpdateData {
+	t.Helper()
+
+	var mu sync.Mutex
+	var updates []checker.UpdateData
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data checker.UpdateData
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&data))
+		mu.Lock()
+		updates = append(updates, data)
+		mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+	t.Setenv("STATUS_UPDATE_URL", server.URL)
+	t.Setenv("GCP_PROJECT_ID", "")
+
+	return func() []checker.UpdateData {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]checker.UpdateData(nil), updates...)
+	}
+}
+
+// replayFixture replays the interactions of testdata/name.
+func replayFixture(t *testing.T, name string) *fixture.Server {
+	t.Helper()
+
+	interactions, err := fixture.Load(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	server := fixture.NewServer(interactions)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func integrationHandler() handlers.Handler {
+	tbClient := tinybird.NewClient(&http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(""))}
+	})}, "apiKey")
+
+	return handlers.Handler{TbClient: tbClient, Secret: "test", CloudProvider: "local", Region: "local"}
+}
+
+func post(t *testing.T, handler gin.HandlerFunc, data any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	router := gin.New()
+	router.POST("/checker", handler)
+	body, err := json.Marshal(data)
+	require.NoError(t, err)
+	req, _ := http.NewRequest(http.MethodPost, "/checker", strings.NewReader(string(body)))
+	req.Header.Set("Authorization", "Basic test")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestHandler_HTTPCheckerHandler_Fixtures(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		fixture       string
+		status        string
+		degradedAfter int64
+		maxAttempts   int64
+		// want is the status update sent, empty for none.
+		want       string
+		wantStatus int
+	}{
+		{name: "retried until up", fixture: "http_retry.json", status: "active"},
+		{name: "recovered", fixture: "http_retry.json", status: "error", want: "active", wantStatus: http.StatusOK},
+		{name: "degraded", fixture: "http_degraded.json", status: "active", degradedAfter: 10, want: "degraded", wantStatus: http.StatusOK},
+		{name: "down", fixture: "http_down.json", status: "active", maxAttempts: 2, want: "error", wantStatus: http.StatusInternalServerError},
+		{name: "still down", fixture: "http_down.json", status: "error", maxAttempts: 2},
+		{name: "connection reset", fixture: "http_reset.json", status: "active", maxAttempts: 1, want: "error"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			updates := statusUpdates(t)
+			target := replayFixture(t, tc.fixture)
+			h := integrationHandler()
+
+			w := post(t, h.HTTPCheckerHandler, request.HttpCheckerRequest{
+				WorkspaceID:   "1",
+				MonitorID:     "1",
+				URL:           target.URL,
+				Method:        http.MethodGet,
+				Status:        tc.status,
+				Timeout:       1000,
+				DegradedAfter: tc.degradedAfter,
+				RetryPolicy:   &request.RetryPolicy{InitialInterval: 1, MaxAttempts: tc.maxAttempts},
+			})
+
+			assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Zero(t, target.Unmatched(), "every attempt is in the fixture")
+			if tc.want == "" {
+				assert.Empty(t, updates())
+				return
+			}
+			if assert.Len(t, updates(), 1) {
+				assert.Equal(t, tc.want, updates()[0].Status)
+				assert.Equal(t, "1", updates()[0].MonitorId)
+				assert.Equal(t, tc.wantStatus, updates()[0].StatusCode)
+			}
+		})
+	}
+}
+
+func TestHandler_TCPHandler_StatusUpdates(t *testing.T) {
+	open, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer open.Close()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closed.Close()
+
+	for _, tc := range []struct {
+		name   string
+		uri    string
+		status string
+		want   string
+	}{
+		{name: "up", uri: open.Addr().String(), status: "active"},
+		{name: "recovered", uri: open.Addr().String(), status: "error", want: "active"},
+		{name: "down", uri: closed.Addr().String(), status: "active", want: "error"},
+		// Unlike the HTTP checks, every failure is reported.
+		{name: "still down", uri: closed.Addr().String(), status: "error", want: "error"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			updates := statusUpdates(t)
+			h := integrationHandler()
+
+			w := post(t, h.TCPHandler, request.TCPCheckerRequest{
+				WorkspaceID: "1",
+				MonitorID:   "1",
+				URI:         tc.uri,
+				Status:      tc.status,
+				Timeout:     1000,
+				RetryPolicy: &request.RetryPolicy{InitialInterval: 1, MaxAttempts: 1},
+			})
+
+			assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			if tc.want == "" {
+				assert.Empty(t, updates())
+				return
+			}
+			if assert.Len(t, updates(), 1) {
+				assert.Equal(t, tc.want, updates()[0].Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/audit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/oidc"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/probes"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// OIDCResponse is the event of an OIDC check. Problems is the JSON of the
+// problems of the discovery document and of the keys of the JWKS.
+type OIDCResponse struct {
+	ID            string `json:"id"`
+	ErrorMessage  string `json:"errorMessage"`
+	ErrorCode     string `json:"errorCode,omitempty"`
+	Region        string `json:"region"`
+	Trigger       string `json:"trigger"`
+	URL           string `json:"url"`
+	JWKSURI       string `json:"jwksUri,omitempty"`
+	Problems      string `json:"problems,omitempty"`
+	RequestStatus string `json:"requestStatus,omitempty"`
+	Labels        string `json:"labels,omitempty"`
+
+	WorkspaceID   int64 `json:"workspaceId"`
+	MonitorID     int64 `json:"monitorId"`
+	Timestamp     int64 `json:"timestamp"`
+	Latency       int64 `json:"latency"`
+	CronTimestamp int64 `json:"cronTimestamp"`
+	Keys          int   `json:"keys"`
+	Broken        int   `json:"broken"`
+
+	Error uint8 `json:"error"`
+}
+
+// OIDCHandler checks the discovery document and the JWKS of an OpenID
+// Connect identity provider, failing the check when a field or a key is
+// missing or broken, or when the certificate of a key expires.
+func (h Handler) OIDCHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	dataSourceName := "oidc_response__v0"
+
+	if !h.authorized(c) {
+		return
+	}
+
+	if h.replayed(c, "") {
+		return
+	}
+
+	var req request.OIDCCheckerRequest
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to decode checker request")
+		invalid(c, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid checker request")
+		invalid(c, err)
+		return
+	}
+	if h.blockedTarget(c, "url", req.URL, h.Guard.CheckURL) {
+		return
+	}
+
+	workspaceId, monitorId := monitorIDs(req.WorkspaceID, req.MonitorID)
+
+	if h.callbackTo(c, req.CallbackURL, req.WorkspaceID, req.MonitorID) {
+		return
+	}
+	if h.circuitOpen(c, req.MonitorID) {
+		return
+	}
+
+	trigger := req.Trigger
+	if trigger == "" {
+		trigger = "cron"
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to generate UUID")
+		return
+	}
+
+	// The transport of the guard keeps the jwks_uri advertised from reaching
+	// private addresses.
+	client := &http.Client{
+		Timeout:   request.Timeout("oidc", req.Timeout),
+		Transport: h.Recorder.Wrap(h.transport(probes.Connection{})),
+	}
+	defer client.CloseIdleConnections()
+
+	start := time.Now()
+	err = h.Chaos.Inject(ctx, req.MonitorID)
+	var report oidc.Report
+	if err == nil {
+		report, err = oidc.Check(ctx, client, req.URL, oidc.Config{
+			KeyIDs:      req.KeyIDs,
+			MinValidity: time.Duration(req.MinValidityDays) * 24 * time.Hour,
+		})
+	}
+	latency := time.Since(start).Milliseconds()
+	if h.cancelled(c, req.MonitorID) {
+		return
+	}
+
+	broken := report.Broken()
+	if err == nil && len(broken) > 0 {
+		err = &checker.ClassifiedError{Class: checker.ErrorClassAssertion, Err: fmt.Errorf("%d problems, first %s", len(broken), broken[0])}
+	}
+	h.recordOutcome(c, req.MonitorID, req.CronTimestamp, err != nil)
+
+	data := OIDCResponse{
+		ID:            id.String(),
+		Region:        h.Region,
+		Trigger:       trigger,
+		URL:           req.URL,
+		JWKSURI:       report.JWKSURI,
+		WorkspaceID:   workspaceId,
+		MonitorID:     monitorId,
+		CronTimestamp: req.CronTimestamp,
+		Labels:        labelsJSON(req.Labels),
+		Timestamp:     start.UTC().UnixMilli(),
+		Latency:       latency,
+		Keys:          len(report.Keys),
+		Broken:        len(broken),
+	}
+	if len(broken) > 0 {
+		if j, err := json.Marshal(broken); err == nil {
+			data.Problems = string(j)
+		}
+	}
+
+	switch {
+	case err != nil:
+		data.RequestStatus = "error"
+		data.Error = 1
+		data.ErrorMessage = err.Error()
+		data.ErrorCode = string(checker.ClassifyCode(err))
+		if req.Status != "error" {
+			h.updateStatus(c, checker.UpdateData{
+				MonitorId:     req.MonitorID,
+				Status:        "error",
+				Region:        h.Region,
+				Message:       err.Error(),
+				CronTimestamp: req.CronTimestamp,
+				Latency:       latency,
+			})
+		}
+	case req.Status != "active":
+		h.updateStatus(c, checker.UpdateData{
+			MonitorId:     req.MonitorID,
+			Status:        "active",
+			Region:        h.Region,
+			CronTimestamp: req.CronTimestamp,
+			Latency:       latency,
+		})
+		data.RequestStatus = "success"
+	default:
+		data.RequestStatus = "success"
+	}
+
+	if err := h.events(c).SendEvent(ctx, data, dataSourceName); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to send event to tinybird")
+	}
+
+	event, f := c.Get("event")
+	if f {
+		t := event.(map[string]any)
+		t["checker"] = map[string]string{
+			"uri":          req.URL,
+			"workspace_id": req.WorkspaceID,
+			"monitor_id":   req.MonitorID,
+			"trigger":      trigger,
+			"type":         "oidc",
+		}
+		c.Set("event", t)
+	}
+
+	env := Envelope{
+		Type:      "oidc",
+		Region:    h.Region,
+		Timestamp: data.Timestamp,
+		Attempts:  []checker.Attempt{checker.NewAttempt(1, start, err)},
+		Timing:    EnvelopeTiming{TotalMs: latency},
+		OIDC:      &report,
+	}
+	env.hostNames(req.URL)
+	env.outcome(err, false)
+	h.finish(c, audit.Entry{
+		Trigger:     trigger,
+		WorkspaceID: req.WorkspaceID,
+		MonitorID:   req.MonitorID,
+		Target:      req.URL,
+	}, env)
+
+	respond(c, data, env)
+}
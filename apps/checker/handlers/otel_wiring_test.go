@@ -63,7 +63,7 @@ func TestTCPHandler_ExportsOTLPOnSuccess(t *testing.T) {
 		WorkspaceID: "1",
 		MonitorID:   "1",
 		Status:      "active", // avoids the network UpdateStatus call
-		Timeout:     5,
+		Timeout:     5000,
 		Retry:       1,
 	}
 	req.OtelConfig.Endpoint = otlp.URL
@@ -93,7 +93,7 @@ func TestTCPHandlerRegion_ExportsOTLPOnFailure(t *testing.T) {
 	req := request.TCPCheckerRequest{
 		URI:     "127.0.0.1:1", // connection refused
 		Status:  "active",
-		Timeout: 5,
+		Timeout: 5000,
 	}
 	req.OtelConfig.Endpoint = otlp.URL
 	body, _ := json.Marshal(req)
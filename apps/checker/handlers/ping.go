@@ -1,14 +1,19 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
-	"github.com/cenkalti/backoff/v4"
+	"github.com/cenkalti/backoff/v5"
 	"github.com/gin-gonic/gin"
 	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/audit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/probes"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/retry"
 	"github.com/openstatushq/openstatus/apps/checker/request"
 	"github.com/rs/zerolog/log"
 )
@@ -53,39 +58,69 @@ func (h Handler) PingRegionHandler(c *gin.Context) {
 
 	fmt.Printf("Start of /ping/%s\n", region)
 
-	if c.GetHeader("Authorization") != fmt.Sprintf("Basic %s", h.Secret) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	if !h.authorized(c) {
 
 		return
 	}
 
-	if h.CloudProvider == "fly" {
-		if region != h.Region {
-			c.Header("fly-replay", fmt.Sprintf("region=%s", region))
-			c.String(http.StatusAccepted, "Forwarding request to %s", region)
+	if h.replayed(c, region) {
+		return
+	}
 
-			return
-		}
+	var req request.PingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to decode checker request")
+		invalid(c, err)
+
+		return
+	}
+	if err := req.Validate(); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid checker request")
+		invalid(c, err)
+
+		return
+	}
+	if h.blockedTarget(c, "url", req.URL, h.Guard.CheckURL) {
+		return
+	}
+	if proxyURL, _ := req.Proxy.ProxyURL(); proxyURL != nil && h.blockedTarget(c, "proxy.url", proxyURL.Redacted(), h.Guard.CheckURL) {
+		return
+	}
+	if req.ConnectTo != "" && h.blockedTarget(c, "connectTo", req.ConnectTo, h.Guard.CheckAddress) {
+		return
+	}
+	if req.Auth != nil && h.blockedTarget(c, "auth.tokenUrl", req.Auth.TokenURL, h.Guard.CheckURL) {
+		return
 	}
 
 	//  We need a new client for each request to avoid connection reuse.
 	requestClient := &http.Client{
 		Timeout: 45 * time.Second,
+		Transport: h.authenticate(h.transport(probes.Connection{
+			ClientCert: req.ClientCert,
+			Proxy:      req.Proxy,
+			IPFamily:   req.IPFamily,
+			ConnectTo:  req.ConnectTo,
+			ServerName: req.ServerName,
+		}), req.Auth, req.URL),
 	}
 
 	defer requestClient.CloseIdleConnections()
 
-	var req request.PingRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Ctx(ctx).Error().Err(err).Msg("failed to decode checker request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+	v, err := parseVerbosity(c)
+	if err != nil {
+		invalid(c, err)
 
 		return
 	}
 
-	var res checker.Response
+	policy := retry.FromRequest(req.RetryPolicy, 0)
 
-	op := func() error {
+	var called int
+	var attempts []checker.Attempt
+
+	op := func() (checker.Response, error) {
+		called++
 
 		headers := make([]struct {
 			Key   string `json:"key"`
@@ -104,22 +139,30 @@ func (h Handler) PingRegionHandler(c *gin.Context) {
 			URL:     req.URL,
 			Method:  req.Method,
 			Body:    req.Body,
+			Cookies: req.Cookies,
 		}
 
-		r, err := checker.Http(c.Request.Context(), requestClient, input)
+		start := time.Now()
+		r, err := checker.Http(ctx, requestClient, input)
+		attempts = append(attempts, checker.NewAttempt(called, start, err))
+
+		// The caller went away, drop the result instead of writing stale events.
+		if ctx.Err() != nil {
+			return checker.Response{}, backoff.Permanent(context.Cause(ctx))
+		}
 
 		if err != nil {
-			return fmt.Errorf("unable to ping: %w", err)
+			return checker.Response{}, policy.Wrap(fmt.Errorf("unable to ping: %w", err))
 		}
 
 		timingAsString, err := json.Marshal(r.Timing)
 		if err != nil {
-			return fmt.Errorf("error while parsing timing data %s: %w", req.URL, err)
+			return checker.Response{}, fmt.Errorf("error while parsing timing data %s: %w", req.URL, err)
 		}
 
 		headersAsString, err := json.Marshal(r.Headers)
 		if err != nil {
-			return nil
+			return checker.Response{}, nil
 		}
 
 		tbData := PingResponse{
@@ -134,22 +177,42 @@ func (h Handler) PingRegionHandler(c *gin.Context) {
 			Region:      h.Region,
 		}
 
-		res = r
+		res := r
 		res.Region = h.Region
+		res.Geo = h.GeoIP.Lookup(r.RemoteIP)
+		if v.har {
+			res.HAR = checker.NewHAR(input, r)
+		}
 
 		if tbData.RequestId != 0 {
-			if err := h.TbClient.SendEvent(ctx, tbData, dataSourceName); err != nil {
+			if err := h.events(c).SendEvent(ctx, tbData, dataSourceName); err != nil {
 				log.Ctx(ctx).Error().Err(err).Msg("failed to send event to tinybird")
 			}
 		}
 
-		return nil
+		return res, nil
+	}
+
+	res, err := backoff.Retry(ctx, op, policy.Options()...)
+	res.Attempts = attempts
+
+	if ctx.Err() != nil {
+		log.Ctx(ctx).Warn().Err(context.Cause(ctx)).Msg("request cancelled, dropping check result")
+		return
 	}
-	if err := backoff.Retry(op, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), 3)); err != nil {
-		c.JSON(http.StatusOK, gin.H{"message": "url not reachable"})
+	env := httpEnvelope(h.Region, res, err, false)
+	env.hostNames(req.URL)
+	entry := audit.Entry{Trigger: "api", RequestID: req.RequestId, Target: req.URL}
+	if req.WorkspaceId != 0 {
+		entry.WorkspaceID = strconv.FormatInt(req.WorkspaceId, 10)
+	}
+	h.finish(c, entry, env)
+
+	if err != nil {
+		respond(c, gin.H{"message": "url not reachable"}, env)
 
 		return
 	}
 
-	c.JSON(http.StatusOK, res)
+	respond(c, v.http(res), env)
 }
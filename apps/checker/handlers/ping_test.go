@@ -15,6 +15,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type RoundTripFunc func(req *http.Request) *http.Response
@@ -80,7 +81,8 @@ func TestHandler_PingRegion(t *testing.T) {
 		router.ServeHTTP(w, req)
 
 		assert.Equal(t, 400, w.Code)
-		assert.Contains(t, w.Body.String(), "{\"error\":\"invalid request\"}")
+		assert.Contains(t, w.Body.String(), "\"error\":\"invalid request\"")
+		assert.Contains(t, w.Body.String(), "\"field\":\"workspaceId\"")
 	})
 
 	t.Run("it should return 200 if the payload is ok", func(t *testing.T) {
@@ -114,4 +116,30 @@ func TestHandler_PingRegion(t *testing.T) {
 		assert.Equal(t, 200, w.Code)
 		fmt.Println(w.Body.String())
 	})
+
+	t.Run("it should return a HAR when asked for", func(t *testing.T) {
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("hello"))
+		}))
+		defer target.Close()
+
+		h := handlers.Handler{TbClient: client, Secret: "test", CloudProvider: "local", Region: "local"}
+		router := gin.New()
+		router.Group("/v2", handlers.V2()).POST("/http/:region", h.PingRegionHandler)
+
+		w := httptest.NewRecorder()
+		dataJson, _ := json.Marshal(request.PingRequest{URL: target.URL, Method: "GET"})
+		req, _ := http.NewRequest(http.MethodPost, "/v2/http/local?verbosity=har", strings.NewReader(string(dataJson)))
+		req.Header.Set("Authorization", "Basic test")
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var env handlers.Envelope
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+		if assert.NotNil(t, env.HTTP) && assert.NotNil(t, env.HTTP.HAR) {
+			require.Len(t, env.HTTP.HAR.Log.Entries, 1)
+			assert.Equal(t, "hello", env.HTTP.HAR.Log.Entries[0].Response.Content.Text)
+			assert.Empty(t, env.HTTP.Body, "the body is only in the HAR")
+		}
+	})
 }
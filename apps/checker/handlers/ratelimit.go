@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/ratelimit"
+)
+
+// RateLimit throttles the checks of each workspace, so one tenant submitting
+// a storm of on-demand checks cannot starve the region for the others.
+// Requests without a workspace are throttled by client IP.
+func RateLimit(l *ratelimit.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l == nil || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+
+		res := l.Allow(rateLimitKey(c))
+		if res.Limit == 0 {
+			c.Next()
+			return
+		}
+		c.Header("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+
+		if !res.Allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(res.RetryAfter.Seconds()))))
+			fail(c, http.StatusTooManyRequests, ErrCodeRateLimited, "rate limit exceeded")
+			c.Abort()
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitKey returns the workspace of the request, a string in scheduled
+// checks and a number in on-demand ones.
+func rateLimitKey(c *gin.Context) string {
+	body, err := io.ReadAll(c.Request.Body)
+	if err == nil {
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var req struct {
+			WorkspaceID json.RawMessage `json:"workspaceId"`
+		}
+		if json.Unmarshal(body, &req) == nil {
+			if id := strings.Trim(string(req.WorkspaceID), `"`); id != "" && id != "null" && id != "0" {
+				return "workspace:" + id
+			}
+		}
+	}
+
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,41 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/ratelimit"
+)
+
+func TestRateLimit(t *testing.T) {
+	router := gin.New()
+	router.POST("/checker/http", handlers.RateLimit(ratelimit.New(60, 1)), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	do := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/checker/http", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(`{"workspaceId":"1"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+	// On-demand checks send the workspace as a number.
+	w = do(`{"workspaceId":1}`)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"error":"rate limit exceeded"}`, w.Body.String())
+
+	assert.Equal(t, http.StatusOK, do(`{"workspaceId":"2"}`).Code)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CheckTypes lists the check types served by every checker.
+var CheckTypes = []string{"http", "tcp", "dns", "content", "crawl", "grpc", "email", "ports", "oidc", "download"}
+
+// checkTypes lists the check types served by this checker, CheckTypes and
+// the SMTP checks once a mailbox receives them.
+func (h Handler) checkTypes() []string {
+	if h.Mailbox == nil {
+		return CheckTypes
+	}
+
+	return append(CheckTypes[:len(CheckTypes):len(CheckTypes)], "smtp")
+}
+
+// RegionInfo describes what a checker can do, so the control plane routes
+// jobs on live capabilities rather than on static configuration.
+type RegionInfo struct {
+	Region        string   `json:"region"`
+	CloudProvider string   `json:"cloudProvider"`
+	Version       string   `json:"version"`
+	CheckTypes    []string `json:"checkTypes"`
+	EgressIPs     []string `json:"egressIps"`
+	IPv4          bool     `json:"ipv4"`
+	IPv6          bool     `json:"ipv6"`
+}
+
+func (h Handler) RegionHandler(c *gin.Context) {
+	if !h.authorized(c) {
+
+		return
+	}
+
+	v4 := egressIP("udp4", "1.1.1.1:53")
+	v6 := egressIP("udp6", "[2606:4700:4700::1111]:53")
+
+	info := RegionInfo{
+		Region:        h.Region,
+		CloudProvider: h.CloudProvider,
+		Version:       h.Version,
+		CheckTypes:    h.checkTypes(),
+		EgressIPs:     h.EgressIPs,
+		IPv4:          v4 != nil,
+		IPv6:          v6 != nil,
+	}
+
+	// Without configured egress IPs, report the source addresses picked by
+	// the kernel. They may be private when the checker sits behind a NAT.
+	if len(info.EgressIPs) == 0 {
+		info.EgressIPs = []string{}
+		for _, ip := range []net.IP{v4, v6} {
+			if ip != nil {
+				info.EgressIPs = append(info.EgressIPs, ip.String())
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, info)
+}
+
+// egressIP returns the source address used to reach addr over network, or
+// nil when there is no route. Connecting a UDP socket sends no packet.
+func egressIP(network, addr string) net.IP {
+	conn, err := net.Dial(network, addr)
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+
+	local, ok := conn.LocalAddr().(*net.UDPAddr)
+	if !ok || local.IP.IsUnspecified() {
+		return nil
+	}
+
+	return local.IP
+}
@@ -0,0 +1,51 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+)
+
+func TestHandler_RegionHandler(t *testing.T) {
+	h := handlers.Handler{
+		Secret:        "test",
+		CloudProvider: "fly",
+		Region:        "ams",
+		Version:       "v1.2.3",
+		EgressIPs:     []string{"203.0.113.1"},
+	}
+	router := gin.New()
+	router.GET("/region", h.RegionHandler)
+
+	t.Run("unauthorized", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/region", nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("describes the checker", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/region", nil)
+		req.Header.Set("Authorization", "Basic test")
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var info handlers.RegionInfo
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+		assert.Equal(t, "ams", info.Region)
+		assert.Equal(t, "fly", info.CloudProvider)
+		assert.Equal(t, "v1.2.3", info.Version)
+		assert.Equal(t, handlers.CheckTypes, info.CheckTypes)
+		assert.Equal(t, []string{"203.0.113.1"}, info.EgressIPs)
+	})
+}
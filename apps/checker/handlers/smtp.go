@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/audit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/probes"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// SMTPResponse is the event of an SMTP check. Latency is the round trip,
+// SendLatency the time the server took to accept the message and
+// DeliveryLatency the time it took to reach the mailbox after.
+type SMTPResponse struct {
+	ID            string `json:"id"`
+	ErrorMessage  string `json:"errorMessage"`
+	ErrorCode     string `json:"errorCode,omitempty"`
+	Region        string `json:"region"`
+	Trigger       string `json:"trigger"`
+	URI           string `json:"uri"`
+	MessageID     string `json:"messageId"`
+	RequestStatus string `json:"requestStatus,omitempty"`
+	Labels        string `json:"labels,omitempty"`
+
+	WorkspaceID     int64 `json:"workspaceId"`
+	MonitorID       int64 `json:"monitorId"`
+	Timestamp       int64 `json:"timestamp"`
+	Latency         int64 `json:"latency"`
+	SendLatency     int64 `json:"sendLatency"`
+	DeliveryLatency int64 `json:"deliveryLatency"`
+	CronTimestamp   int64 `json:"cronTimestamp"`
+
+	Error uint8 `json:"error"`
+}
+
+// SMTPResult is the outcome of an SMTP check in the envelope.
+type SMTPResult struct {
+	MessageID  string `json:"messageId"`
+	SendMs     int64  `json:"sendMs"`
+	DeliveryMs int64  `json:"deliveryMs"`
+}
+
+// SMTPHandler sends a message through the SMTP server of a check and waits
+// for it in the mailbox of the checker, reporting how long it took to be
+// delivered.
+func (h Handler) SMTPHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	dataSourceName := "smtp_response__v0"
+
+	if !h.authorized(c) {
+		return
+	}
+
+	if h.replayed(c, "") {
+		return
+	}
+
+	var req request.SMTPCheckerRequest
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to decode checker request")
+		invalid(c, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid checker request")
+		invalid(c, err)
+		return
+	}
+	if h.Mailbox == nil {
+		invalid(c, request.ValidationError{{Field: "type", Reason: "SMTP checks are not enabled on this checker", Value: "smtp"}})
+		return
+	}
+	if h.blockedTarget(c, "uri", req.Address(), h.Guard.CheckHostPort) {
+		return
+	}
+
+	workspaceId, monitorId := monitorIDs(req.WorkspaceID, req.MonitorID)
+
+	if h.callbackTo(c, req.CallbackURL, req.WorkspaceID, req.MonitorID) {
+		return
+	}
+	if h.circuitOpen(c, req.MonitorID) {
+		return
+	}
+
+	trigger := req.Trigger
+	if trigger == "" {
+		trigger = "cron"
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to generate UUID")
+		return
+	}
+
+	start := time.Now()
+	err = h.Chaos.Inject(ctx, req.MonitorID)
+	var res probes.SMTPResult
+	if err == nil {
+		res, err = h.prober().SMTP(ctx, req)
+	}
+	latency := time.Since(start).Milliseconds()
+	if h.cancelled(c, req.MonitorID) {
+		return
+	}
+	h.recordOutcome(c, req.MonitorID, req.CronTimestamp, err != nil)
+
+	data := SMTPResponse{
+		ID:              id.String(),
+		Region:          h.Region,
+		Trigger:         trigger,
+		URI:             req.URI,
+		MessageID:       res.MessageID,
+		WorkspaceID:     workspaceId,
+		MonitorID:       monitorId,
+		CronTimestamp:   req.CronTimestamp,
+		Labels:          labelsJSON(req.Labels),
+		Timestamp:       start.UTC().UnixMilli(),
+		Latency:         latency,
+		SendLatency:     res.Send.Milliseconds(),
+		DeliveryLatency: res.Delivery.Milliseconds(),
+	}
+
+	degraded := err == nil && h.degraded(c, req.MonitorID, req.Status, latency, req.DegradedAfter, 0, 0)
+	switch {
+	case err != nil:
+		data.RequestStatus = "error"
+		data.Error = 1
+		data.ErrorMessage = err.Error()
+		data.ErrorCode = string(checker.ClassifyCode(err))
+		if req.Status != "error" {
+			h.updateStatus(c, checker.UpdateData{
+				MonitorId:     req.MonitorID,
+				Status:        "error",
+				Region:        h.Region,
+				Message:       err.Error(),
+				CronTimestamp: req.CronTimestamp,
+				Latency:       latency,
+			})
+		}
+	case degraded:
+		if req.Status != "degraded" {
+			h.updateStatus(c, checker.UpdateData{
+				MonitorId:     req.MonitorID,
+				Status:        "degraded",
+				Region:        h.Region,
+				CronTimestamp: req.CronTimestamp,
+				Latency:       latency,
+			})
+		}
+		data.RequestStatus = "degraded"
+	case req.Status != "active":
+		h.updateStatus(c, checker.UpdateData{
+			MonitorId:     req.MonitorID,
+			Status:        "active",
+			Region:        h.Region,
+			CronTimestamp: req.CronTimestamp,
+			Latency:       latency,
+		})
+		data.RequestStatus = "success"
+	default:
+		data.RequestStatus = "success"
+	}
+
+	if err := h.events(c).SendEvent(ctx, data, dataSourceName); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to send event to tinybird")
+	}
+
+	event, f := c.Get("event")
+	if f {
+		t := event.(map[string]any)
+		t["checker"] = map[string]string{
+			"uri":          req.URI,
+			"workspace_id": req.WorkspaceID,
+			"monitor_id":   req.MonitorID,
+			"trigger":      trigger,
+			"type":         "smtp",
+		}
+		c.Set("event", t)
+	}
+
+	env := Envelope{
+		Type:      "smtp",
+		Region:    h.Region,
+		Timestamp: data.Timestamp,
+		Attempts:  []checker.Attempt{checker.NewAttempt(1, start, err)},
+		Timing: EnvelopeTiming{
+			ConnectMs: res.Timing.Connect.Milliseconds(),
+			TLSMs:     res.Timing.TLS.Milliseconds(),
+			TotalMs:   latency,
+		},
+	}
+	// The messages not accepted by the server have no latencies.
+	if res.Send > 0 {
+		env.SMTP = &SMTPResult{MessageID: res.MessageID, SendMs: data.SendLatency, DeliveryMs: data.DeliveryLatency}
+	}
+	env.hostNames(req.URI)
+	env.outcome(err, degraded)
+	h.finish(c, audit.Entry{
+		Trigger:     trigger,
+		WorkspaceID: req.WorkspaceID,
+		MonitorID:   req.MonitorID,
+		Target:      req.URI,
+	}, env)
+
+	respond(c, data, env)
+}
@@ -0,0 +1,63 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/mailbox"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/tinybird"
+)
+
+func TestHandler_SMTPHandler(t *testing.T) {
+	tbClient := tinybird.NewClient(&http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(""))}
+	})}, "apiKey")
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := closed.Addr().String()
+	closed.Close()
+
+	check := func(h handlers.Handler) (*httptest.ResponseRecorder, handlers.Envelope) {
+		router := gin.New()
+		router.Group("/v2", handlers.V2()).POST("/checker/smtp", h.SMTPHandler)
+
+		body := `{"uri":"` + address + `","from":"monitor@openstat.us","tls":"none","status":"active","workspaceId":"1","monitorId":"2","timeout":1000}`
+		req, _ := http.NewRequest(http.MethodPost, "/v2/checker/smtp?dryRun=true", strings.NewReader(body))
+		req.Header.Set("Authorization", "Basic test")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var env handlers.Envelope
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+		return w, env
+	}
+
+	t.Run("without mailbox", func(t *testing.T) {
+		w, env := check(handlers.Handler{TbClient: tbClient, Secret: "test", Region: "local"})
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.NotNil(t, env.Error)
+		assert.Equal(t, "type", env.Error.Fields[0].Field)
+	})
+
+	t.Run("server unreachable", func(t *testing.T) {
+		h := handlers.Handler{TbClient: tbClient, Secret: "test", Region: "local", Mailbox: &mailbox.Mailbox{Address: "checks@openstat.us"}}
+		w, env := check(h)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "smtp", env.Type)
+		assert.Equal(t, handlers.StatusError, env.Status)
+		require.NotNil(t, env.Error)
+		assert.Equal(t, "connection_refused", env.Error.Code)
+		assert.Nil(t, env.SMTP)
+	})
+}
@@ -0,0 +1,64 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/ssrf"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/tinybird"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestHandler_BlockedTarget(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		t.Error("blocked targets must not be reached")
+	}))
+	defer target.Close()
+
+	tbClient := tinybird.NewClient(&http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(""))}
+	})}, "apiKey")
+
+	// Allowing another range leaves the names to the dialer.
+	guard := ssrf.New([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")})
+	h := handlers.Handler{TbClient: tbClient, Secret: "test", CloudProvider: "local", Region: "local", Guard: guard}
+	router := gin.New()
+	router.Group("/v2", handlers.V2()).POST("/checker/http", h.HTTPCheckerHandler)
+
+	do := func(url string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(request.HttpCheckerRequest{URL: url, Method: http.MethodGet, Timeout: 1000, Retry: 1})
+		req, _ := http.NewRequest(http.MethodPost, "/v2/checker/http?dryRun=true", strings.NewReader(string(body)))
+		req.Header.Set("Authorization", "Basic test")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("address", func(t *testing.T) {
+		w := do(target.URL)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"field":"url"`)
+	})
+
+	t.Run("resolved name", func(t *testing.T) {
+		w := do(strings.Replace(target.URL, "127.0.0.1", "localhost", 1))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var env handlers.Envelope
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+		if assert.NotNil(t, env.Error) {
+			assert.Equal(t, string(checker.ErrorClassBlocked), env.Error.Code)
+		}
+	})
+}
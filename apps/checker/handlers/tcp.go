@@ -1,17 +1,22 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strconv"
 	"time"
 
-	"github.com/cenkalti/backoff/v4"
+	"github.com/cenkalti/backoff/v5"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
 	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/audit"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/dependency"
 	otelOS "github.com/openstatushq/openstatus/apps/checker/pkg/otel"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/probes"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/retry"
 	"github.com/openstatushq/openstatus/apps/checker/request"
 	"github.com/rs/zerolog/log"
 )
@@ -21,10 +26,18 @@ type TCPData struct {
 	ID            string `json:"id"`
 	Timing        string `json:"timing"`
 	ErrorMessage  string `json:"errorMessage"`
+	ErrorCode     string `json:"errorCode,omitempty"`
 	Region        string `json:"region"`
 	Trigger       string `json:"trigger"`
 	URI           string `json:"uri"`
 	RequestStatus string `json:"requestStatus,omitempty"`
+	Labels        string `json:"labels,omitempty"`
+	// RemoteIP is the address the check connected to, with its autonomous
+	// system and country when known.
+	RemoteIP       string `json:"remoteIp,omitempty"`
+	ASOrganization string `json:"asOrganization,omitempty"`
+	Country        string `json:"country,omitempty"`
+	ASN            uint   `json:"asn,omitempty"`
 
 	RequestId     int64 `json:"requestId,omitempty"`
 	WorkspaceID   int64 `json:"workspaceId"`
@@ -32,6 +45,9 @@ type TCPData struct {
 	Timestamp     int64 `json:"timestamp"`
 	Latency       int64 `json:"latency"`
 	CronTimestamp int64 `json:"cronTimestamp"`
+	// ScheduleOffset is how late the check started, in milliseconds.
+	ScheduleOffset int64 `json:"scheduleOffset,omitempty"`
+	Attempts       int   `json:"attempts"`
 
 	Error uint8 `json:"error"`
 }
@@ -40,44 +56,46 @@ func (h Handler) TCPHandler(c *gin.Context) {
 	ctx := c.Request.Context()
 	dataSourceName := "tcp_response__v0"
 
-	if c.GetHeader("Authorization") != fmt.Sprintf("Basic %s", h.Secret) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	if !h.authorized(c) {
 
 		return
 	}
 
-	if h.CloudProvider == "fly" {
-		// if the request has been routed to a wrong region, we forward it to the correct one.
-		region := c.GetHeader("fly-prefer-region")
-		if region != "" && region != h.Region {
-			c.Header("fly-replay", fmt.Sprintf("region=%s", region))
-			c.String(http.StatusAccepted, "Forwarding request to %s", region)
-
-			return
-		}
+	// if the request has been routed to a wrong region, we forward it to the correct one.
+	if h.replayed(c, "") {
+		return
 	}
 
 	var req request.TCPCheckerRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := c.ShouldBindBodyWith(&req, binding.JSON); err != nil {
 		log.Ctx(ctx).Error().Err(err).Msg("failed to decode checker request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		invalid(c, err)
 
 		return
 	}
+	if err := req.ValidateScheduled(); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid checker request")
+		invalid(c, err)
 
-	workspaceId, err := strconv.ParseInt(req.WorkspaceID, 10, 64)
+		return
+	}
+	if h.blockedTarget(c, "uri", req.Address(), h.Guard.CheckHostPort) {
+		return
+	}
 
+	v, err := parseVerbosity(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		invalid(c, err)
 
 		return
 	}
 
-	monitorId, err := strconv.ParseInt(req.MonitorID, 10, 64)
-
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+	workspaceId, monitorId := monitorIDs(req.WorkspaceID, req.MonitorID)
 
+	if h.callbackTo(c, req.CallbackURL, req.WorkspaceID, req.MonitorID) {
+		return
+	}
+	if h.circuitOpen(c, req.MonitorID) {
 		return
 	}
 
@@ -99,25 +117,53 @@ func (h Handler) TCPHandler(c *gin.Context) {
 		c.Set("event", t)
 	}
 
-	var response checker.TCPResponse
+	policy := retry.FromRequest(req.RetryPolicy, req.Retry)
 
-	var retry int
-	if req.Retry != 0 {
-		retry = int(req.Retry)
-	} else {
-		retry = 3
-	}
+	checkCtx, cancel := retry.WithDeadline(ctx, req.TotalDeadline)
+	defer cancel()
 
-	op := func() error {
-		res, err := checker.PingTCP(int(req.Timeout), req.URI)
+	var called int
+	var attempts []checker.Attempt
+	var addresses []checker.AddressResult
+	var dualStack *checker.DualStackResult
+	// remoteIP is the address of the last attempt, for the evidence of a
+	// failed check.
+	var remoteIP string
+	// degraded is set when the check left its monitor degraded.
+	var degraded bool
+	offset := scheduleOffset(req.ScheduledAt)
+
+	op := func() (checker.TCPResponse, error) {
+		called++
+		start := time.Now()
+		var probe probes.TCPResult
+		err := h.Chaos.Inject(checkCtx, req.MonitorID)
+		if err == nil {
+			probe, err = h.prober().TCP(checkCtx, req)
+		}
+		result := probe.Dial
+		remoteIP = result.RemoteIP
+		if err == nil && req.AllAddresses {
+			addresses, err = h.probeTCPAddresses(checkCtx, req)
+		}
+		if err == nil && req.DualStack {
+			dualStack, err = h.raceFamilies(checkCtx, req.Address(), request.Timeout("tcp", req.Timeout))
+		}
+		attempts = append(attempts, checker.NewAttempt(called, start, err))
+
+		// The caller went away, drop the result instead of writing stale events.
+		if ctx.Err() != nil {
+			return checker.TCPResponse{}, backoff.Permanent(context.Cause(ctx))
+		}
 
 		if err != nil {
-			return fmt.Errorf("unable to check tcp %s", err)
+			return checker.TCPResponse{}, policy.Wrap(fmt.Errorf("unable to check tcp %w", err))
 		}
+		res := result.Timing
 
 		timingAsString, err := json.Marshal(res)
 		if err != nil {
-			return fmt.Errorf("error while parsing timing data %s: %w", req.URI, err)
+			return checker.TCPResponse{}, fmt.Errorf("error while parsing timing data %s: %w", req.URI, err)
 		}
 
 		latency := res.TCPDone - res.TCPStart
@@ -135,49 +181,50 @@ func (h Handler) TCPHandler(c *gin.Context) {
 
 		id, err := uuid.NewV7()
 		if err != nil {
-			return fmt.Errorf("error while generating uuid %w", err)
+			return checker.TCPResponse{}, fmt.Errorf("error while generating uuid %w", err)
 		}
 
 		data := TCPData{
-			ID:            id.String(),
-			WorkspaceID:   workspaceId,
-			Timestamp:     res.TCPStart,
-			Error:         0,
-			ErrorMessage:  "",
-			Region:        h.Region,
-			MonitorID:     monitorId,
-			Timing:        string(timingAsString),
-			Latency:       latency,
-			CronTimestamp: req.CronTimestamp,
-			Trigger:       trigger,
-			URI:           req.URI,
-			RequestStatus: requestStatus,
+			ID:             id.String(),
+			WorkspaceID:    workspaceId,
+			Timestamp:      res.TCPStart,
+			Error:          0,
+			ErrorMessage:   "",
+			Region:         h.Region,
+			MonitorID:      monitorId,
+			Timing:         string(timingAsString),
+			Latency:        latency,
+			CronTimestamp:  req.CronTimestamp,
+			ScheduleOffset: offset,
+			Trigger:        trigger,
+			URI:            req.URI,
+			RequestStatus:  requestStatus,
+			Labels:         labelsJSON(req.Labels),
+			Attempts:       called,
 		}
 
-		response = checker.TCPResponse{
+		response := checker.TCPResponse{
 			Timestamp: res.TCPStart,
-			Timing: checker.TCPResponseTiming{
-				TCPStart: res.TCPStart,
-				TCPDone:  res.TCPDone,
-			},
-			Latency: latency,
-			Region:  h.Region,
-			JobType: "tcp",
+			Timing:    res,
+			Latency:   latency,
+			Region:    h.Region,
+			JobType:   "tcp",
+			RemoteIP:  result.RemoteIP,
+			IPFamily:  result.IPFamily,
+			Geo:       h.GeoIP.Lookup(result.RemoteIP),
+			TLS:       result.TLS,
+			Hold:      result.Hold,
 		}
-
-		if req.DegradedAfter == 0 && req.Status != "active" {
-			checker.UpdateStatus(ctx, checker.UpdateData{
-				MonitorId:     req.MonitorID,
-				Status:        "active",
-				Region:        h.Region,
-				CronTimestamp: req.CronTimestamp,
-				Latency:       latency,
-			})
-			data.RequestStatus = "success"
+		data.RemoteIP = result.RemoteIP
+		if response.Geo != nil {
+			data.ASN, data.ASOrganization, data.Country = response.Geo.ASN, response.Geo.Organization, response.Geo.Country
 		}
 
-		if (req.DegradedAfter > 0 && latency < req.DegradedAfter) && req.Status != "active" {
-			checker.UpdateStatus(ctx, checker.UpdateData{
+		degraded = h.degraded(c, req.MonitorID, req.Status, latency, req.DegradedAfter, req.RecoverBelow, req.DegradedWindow)
+		slow := slowPhases(tcpTiming(response), req.DegradedPhases)
+		degraded = degraded || len(slow) > 0
+		if !degraded && req.Status != "active" {
+			h.updateStatus(c, checker.UpdateData{
 				MonitorId:     req.MonitorID,
 				Status:        "active",
 				Region:        h.Region,
@@ -185,11 +232,10 @@ func (h Handler) TCPHandler(c *gin.Context) {
 				Latency:       latency,
 			})
 			data.RequestStatus = "success"
-
 		}
 
-		if req.DegradedAfter > 0 && latency > req.DegradedAfter && req.Status != "degraded" {
-			checker.UpdateStatus(ctx, checker.UpdateData{
+		if degraded && req.Status != "degraded" {
+			h.updateStatus(c, checker.UpdateData{
 				MonitorId:     req.MonitorID,
 				Status:        "degraded",
 				Region:        h.Region,
@@ -200,58 +246,107 @@ func (h Handler) TCPHandler(c *gin.Context) {
 
 		}
 
-		if err := h.TbClient.SendEvent(ctx, data, dataSourceName); err != nil {
+		if err := h.events(c).SendEvent(ctx, data, dataSourceName); err != nil {
 			log.Ctx(ctx).Error().Err(err).Msg("failed to send event to tinybird")
 		}
 
-		return nil
+		return response, nil
 	}
 
-	if err := backoff.Retry(op, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), uint64(retry))); err != nil {
+	response, err := backoff.Retry(checkCtx, op, policy.Options()...)
+	response.Attempts = attempts
+	response.Addresses = addresses
+	response.DualStack = dualStack
+
+	if h.cancelled(c, req.MonitorID) {
+		return
+	}
+	h.recordOutcome(c, req.MonitorID, req.CronTimestamp, err != nil)
+
+	var skipped bool
+	if err != nil {
 
 		id, e := uuid.NewV7()
 		if e != nil {
 			log.Ctx(ctx).Error().Err(e).Msg("failed to send event to tinybird")
 			return
 		}
+		skipped = h.skippedDependency(c, req.DependsOn, req.CronTimestamp)
+		requestStatus := "error"
+		if skipped {
+			requestStatus = dependency.StatusSkipped
+		}
 		data := TCPData{
-			ID:            id.String(),
-			WorkspaceID:   workspaceId,
-			CronTimestamp: req.CronTimestamp,
-			ErrorMessage:  err.Error(),
-			Region:        h.Region,
-			MonitorID:     monitorId,
-			Error:         1,
-			Trigger:       trigger,
-			URI:           req.URI,
-			RequestStatus: "error",
+			ID:             id.String(),
+			WorkspaceID:    workspaceId,
+			CronTimestamp:  req.CronTimestamp,
+			ScheduleOffset: offset,
+			ErrorMessage:   err.Error(),
+			ErrorCode:      string(checker.ClassifyCode(err)),
+			Region:         h.Region,
+			MonitorID:      monitorId,
+			Error:          1,
+			Trigger:        trigger,
+			URI:            req.URI,
+			RequestStatus:  requestStatus,
+			Labels:         labelsJSON(req.Labels),
+			Attempts:       called,
+			RemoteIP:       remoteIP,
 		}
-		if err := h.TbClient.SendEvent(ctx, data, dataSourceName); err != nil {
+		if err := h.events(c).SendEvent(ctx, data, dataSourceName); err != nil {
 			log.Ctx(ctx).Error().Err(err).Msg("failed to send event to tinybird")
 		}
-		checker.UpdateStatus(ctx, checker.UpdateData{
-			MonitorId:     req.MonitorID,
-			Status:        "error",
-			Message:       err.Error(),
-			Region:        h.Region,
-			CronTimestamp: req.CronTimestamp,
-		})
+		confirm := func() bool {
+			if h.Chaos.Inject(checkCtx, req.MonitorID) != nil {
+				return true
+			}
+			result, err := checker.DialTCP(checkCtx, req.Address(), h.prober().TCPOptions(req))
+			if err == nil {
+				err = probes.TLSVersionAssertions(req.RawAssertions, result.TLS)
+			}
+			return err != nil
+		}
+		if !skipped && h.confirmed(c, req.Confirm, confirm) && h.quorumReached(c, req.Quorum, "tcp") {
+			update := checker.UpdateData{
+				MonitorId:     req.MonitorID,
+				Status:        "error",
+				Message:       err.Error(),
+				Region:        h.Region,
+				CronTimestamp: req.CronTimestamp,
+			}
+			if remoteIP != "" {
+				update.Evidence = &checker.Evidence{ResolvedIP: remoteIP, Geo: h.GeoIP.Lookup(remoteIP)}
+			}
+			h.updateStatus(c, update)
+		}
 
 		response.Error = 1
 	}
 
-	if req.OtelConfig.Endpoint != "" {
+	if req.OtelConfig.Endpoint != "" && !dryRun(c) {
 		otelOS.RecordTCPMetrics(ctx, req, response, h.Region)
 	}
 
-	returnData := c.Query("data")
-	if returnData == "true" {
-		c.JSON(http.StatusOK, response)
+	env := tcpEnvelope(h.Region, response, err, degraded)
+	env.slowPhases(req.DegradedPhases)
+	env.hostNames(req.URI)
+	if skipped {
+		env.Status = dependency.StatusSkipped
+	}
+	h.finish(c, audit.Entry{
+		Trigger:     trigger,
+		WorkspaceID: req.WorkspaceID,
+		MonitorID:   req.MonitorID,
+		Target:      req.URI,
+	}, env)
+
+	if v.set {
+		respond(c, v.tcp(response), env)
 
 		return
 	}
 
-	c.JSON(http.StatusOK, nil)
+	respond(c, nil, env)
 }
 
 func (h Handler) TCPHandlerRegion(c *gin.Context) {
@@ -265,59 +360,94 @@ func (h Handler) TCPHandlerRegion(c *gin.Context) {
 		return
 	}
 
-	if c.GetHeader("Authorization") != fmt.Sprintf("Basic %s", h.Secret) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+	if !h.authorized(c) {
 
 		return
 	}
 
-	if h.CloudProvider == "fly" {
-		// if the request has been routed to a wrong region, we forward it to the correct one.
-		region := c.GetHeader("fly-prefer-region")
-		if region != "" && region != h.Region {
-			c.Header("fly-replay", fmt.Sprintf("region=%s", region))
-			c.String(http.StatusAccepted, "Forwarding request to %s", region)
-
-			return
-		}
+	// if the request has been routed to a wrong region, we forward it to the correct one.
+	if h.replayed(c, "") {
+		return
 	}
 
 	var req request.TCPCheckerRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		log.Ctx(ctx).Error().Err(err).Msg("failed to decode checker request")
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		invalid(c, err)
 
 		return
 	}
+	if err := req.Validate(); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("invalid checker request")
+		invalid(c, err)
 
-	var called int
+		return
+	}
+	if h.blockedTarget(c, "uri", req.Address(), h.Guard.CheckHostPort) {
+		return
+	}
+	if h.callbackTo(c, req.CallbackURL, req.WorkspaceID, req.MonitorID) {
+		return
+	}
+
+	v, err := parseVerbosity(c)
+	if err != nil {
+		invalid(c, err)
+
+		return
+	}
+
+	policy := retry.FromRequest(req.RetryPolicy, req.Retry)
 
-	var response checker.TCPResponse
+	checkCtx, cancel := retry.WithDeadline(ctx, req.TotalDeadline)
+	defer cancel()
 
-	op := func() error {
+	var called int
+	var attempts []checker.Attempt
+	var addresses []checker.AddressResult
+	var dualStack *checker.DualStackResult
+
+	op := func() (checker.TCPResponse, error) {
 		called++
-		timestamp := time.Now().UTC().UnixMilli()
-		res, err := checker.PingTCP(int(req.Timeout), req.URI)
+		start := time.Now()
+		timestamp := start.UTC().UnixMilli()
+		probe, err := h.prober().TCP(checkCtx, req)
+		result := probe.Dial
+		if err == nil && req.AllAddresses {
+			addresses, err = h.probeTCPAddresses(checkCtx, req)
+		}
+		if err == nil && req.DualStack {
+			dualStack, err = h.raceFamilies(checkCtx, req.Address(), request.Timeout("tcp", req.Timeout))
+		}
+		attempts = append(attempts, checker.NewAttempt(called, start, err))
+
+		// The caller went away, drop the result instead of writing stale events.
+		if ctx.Err() != nil {
+			return checker.TCPResponse{}, backoff.Permanent(context.Cause(ctx))
+		}
 
 		if err != nil {
-			return fmt.Errorf("unable to check tcp %s", err)
+			return checker.TCPResponse{}, policy.Wrap(fmt.Errorf("unable to check tcp %w", err))
 		}
+		res := result.Timing
 
-		response = checker.TCPResponse{
+		response := checker.TCPResponse{
 			Timestamp: timestamp,
-			Timing: checker.TCPResponseTiming{
-				TCPStart: res.TCPStart,
-				TCPDone:  res.TCPDone,
-			},
-			Latency: res.TCPDone - res.TCPStart,
-			Region:  h.Region,
-			JobType: "tcp",
+			Timing:    res,
+			Latency:   res.TCPDone - res.TCPStart,
+			Region:    h.Region,
+			JobType:   "tcp",
+			RemoteIP:  result.RemoteIP,
+			IPFamily:  result.IPFamily,
+			Geo:       h.GeoIP.Lookup(result.RemoteIP),
+			TLS:       result.TLS,
+			Hold:      result.Hold,
 		}
 
 		timingAsString, err := json.Marshal(res)
 		if err != nil {
-			return fmt.Errorf("error while parsing timing data %s: %w", req.URI, err)
+			return checker.TCPResponse{}, fmt.Errorf("error while parsing timing data %s: %w", req.URI, err)
 		}
 
 		latency := res.TCPDone - res.TCPStart
@@ -333,31 +463,50 @@ func (h Handler) TCPHandlerRegion(c *gin.Context) {
 			RequestId:     req.RequestId,
 			Trigger:       "api",
 			URI:           req.URI,
+			Labels:        labelsJSON(req.Labels),
 		}
 
 		if req.RequestId != 0 {
-			if err := h.TbClient.SendEvent(ctx, data, dataSourceName); err != nil {
+			if err := h.events(c).SendEvent(ctx, data, dataSourceName); err != nil {
 				log.Ctx(ctx).Error().Err(err).Msg("failed to send event to tinybird")
 			}
 		}
 
-		return nil
+		return response, nil
 	}
 
-	err := backoff.Retry(op, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), 3))
+	response, err := backoff.Retry(checkCtx, op, policy.Options()...)
+	response.Attempts = attempts
+	response.Addresses = addresses
+	response.DualStack = dualStack
+
+	if ctx.Err() != nil {
+		log.Ctx(ctx).Warn().Err(context.Cause(ctx)).Msg("request cancelled, dropping check result")
+		return
+	}
 	if err != nil {
 		response.Error = 1
 	}
 
-	if req.OtelConfig.Endpoint != "" {
+	if req.OtelConfig.Endpoint != "" && !dryRun(c) {
 		otelOS.RecordTCPMetrics(ctx, req, response, region)
 	}
 
+	env := tcpEnvelope(h.Region, response, err, false)
+	env.hostNames(req.URI)
+	h.finish(c, audit.Entry{
+		Trigger:     "api",
+		WorkspaceID: req.WorkspaceID,
+		MonitorID:   req.MonitorID,
+		RequestID:   req.RequestId,
+		Target:      req.URI,
+	}, env)
+
 	if err != nil {
-		c.JSON(http.StatusOK, gin.H{"message": "uri not reachable"})
+		respond(c, gin.H{"message": "uri not reachable"}, env)
 
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond(c, v.tcp(response), env)
 }
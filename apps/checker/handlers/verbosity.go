@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// verbosity selects the parts of a check result sent back to the caller. It
+// is read from `?verbosity=` (or its alias `?fields=`), a comma separated
+// list of:
+//
+//   - timing: latency and timing only, always included
+//   - headers: the response headers of HTTP checks
+//   - body: the response body of HTTP checks, truncated
+//   - attempts: every attempt of the check
+//   - full: all of the above
+//   - har: an HTTP Archive of on-demand HTTP checks, never part of full
+//
+// The legacy `?data=true` flag is the same as full. Only the answer is
+// stripped: the callbacks and the audit log get the whole result.
+type verbosity struct {
+	set      bool
+	headers  bool
+	body     bool
+	attempts bool
+	har      bool
+}
+
+// verbosityKey keeps the verbosity of the request, for respond to strip the
+// envelope answered.
+const verbosityKey = "verbosity"
+
+// parseVerbosity reads the verbosity of the request, kept for respond.
+func parseVerbosity(c *gin.Context) (verbosity, error) {
+	param := "verbosity"
+	value := c.Query(param)
+	if value == "" {
+		param = "fields"
+		value = c.Query(param)
+	}
+	if value == "" && c.Query("data") == "true" {
+		value = "full"
+	}
+	if value == "" {
+		return verbosity{}, nil
+	}
+
+	v := verbosity{set: true}
+	for _, field := range strings.Split(value, ",") {
+		switch strings.TrimSpace(field) {
+		case "timing":
+		case "headers", "include-headers":
+			v.headers = true
+		case "body", "include-body":
+			v.body = true
+		case "attempts":
+			v.attempts = true
+		case "full":
+			v.headers, v.body, v.attempts = true, true, true
+		case "har":
+			v.har = true
+		default:
+			return verbosity{}, request.ValidationError{{
+				Field:  param,
+				Reason: fmt.Sprintf("unknown field %q, expected timing, headers, body, attempts, full or har", field),
+				Value:  value,
+			}}
+		}
+	}
+	c.Set(verbosityKey, v)
+
+	return v, nil
+}
+
+// full reports whether nothing is stripped, the default of the /v2 routes.
+func (v verbosity) full() bool {
+	return !v.set || (v.headers && v.body && v.attempts)
+}
+
+func (v verbosity) http(res checker.Response) checker.Response {
+	if v.full() {
+		return res
+	}
+
+	if !v.headers {
+		res.Headers = nil
+	}
+	if !v.body {
+		res.Body = ""
+	}
+	if !v.attempts {
+		res.Attempts = nil
+	}
+
+	return res
+}
+
+// envelope strips env once the callback is sent with the whole of it.
+func (v verbosity) envelope(env Envelope) Envelope {
+	if v.full() {
+		return env
+	}
+
+	if env.HTTP != nil {
+		http := *env.HTTP
+		if !v.headers {
+			http.Headers = nil
+		}
+		if !v.body {
+			http.Body = ""
+		}
+		env.HTTP = &http
+	}
+	if !v.attempts {
+		env.Attempts = []checker.Attempt{}
+	}
+
+	return env
+}
+
+func (v verbosity) tcp(res checker.TCPResponse) checker.TCPResponse {
+	if !v.full() && !v.attempts {
+		res.Attempts = nil
+	}
+
+	return res
+}
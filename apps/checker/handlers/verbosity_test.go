@@ -0,0 +1,85 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/handlers"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/tinybird"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestHandler_Verbosity(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Check", "1")
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer target.Close()
+
+	tbClient := tinybird.NewClient(&http.Client{Transport: RoundTripFunc(func(req *http.Request) *http.Response {
+		return &http.Response{StatusCode: http.StatusAccepted, Body: io.NopCloser(strings.NewReader(""))}
+	})}, "apiKey")
+
+	h := handlers.Handler{TbClient: tbClient, Secret: "test", CloudProvider: "local", Region: "local"}
+	router := gin.New()
+	router.POST("/checker/http", h.HTTPCheckerHandler)
+
+	do := func(query string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(request.HttpCheckerRequest{
+			URL: target.URL, Method: http.MethodGet, Status: "active", Timeout: 1000,
+		})
+		req, _ := http.NewRequest(http.MethodPost, "/checker/http"+query, strings.NewReader(string(body)))
+		req.Header.Set("Authorization", "Basic test")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		query    string
+		headers  bool
+		body     bool
+		attempts bool
+	}{
+		{query: "?data=true", headers: true, body: true, attempts: true},
+		{query: "?verbosity=full", headers: true, body: true, attempts: true},
+		{query: "?verbosity=timing"},
+		{query: "?verbosity=headers", headers: true},
+		{query: "?fields=include-body,attempts", body: true, attempts: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := do(tt.query)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var res checker.Response
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+			assert.Equal(t, http.StatusOK, res.Status)
+			assert.NotZero(t, res.Timestamp)
+			assert.Equal(t, tt.headers, res.Headers != nil)
+			assert.Equal(t, tt.body, res.Body == "hello")
+			assert.Equal(t, tt.attempts, res.Attempts != nil)
+		})
+	}
+
+	t.Run("no verbosity", func(t *testing.T) {
+		w := do("")
+		assert.Equal(t, "null", w.Body.String())
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		w := do("?verbosity=everything")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"field":"verbosity"`)
+	})
+}
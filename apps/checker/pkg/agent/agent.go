@@ -0,0 +1,171 @@
+// Package agent connects a private location checker to the control plane: it
+// registers the agent on startup, so it shows up as a selectable private
+// location without manual provisioning, reports its liveness with periodic
+// heartbeats and fetches its monitors and settings, through the RPCs of the
+// PrivateLocationService. The requests are authenticated with the API key of
+// the workspace and signed with the key of the agent, whose public half is
+// sent with the registration, and the config is signed by the control plane.
+// The agent also updates itself to signed releases.
+package agent
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/cenkalti/backoff/v5"
+	"google.golang.org/protobuf/proto"
+
+	v1 "github.com/openstatushq/openstatus/apps/checker/proto/private_location/v1"
+)
+
+// SignatureHeader carries the base64 ed25519 signature of the message of a
+// request, deterministically encoded.
+const SignatureHeader = "openstatus-signature"
+
+// Client calls the agent RPCs of the control plane with RPC, authenticated
+// with the API key, signing the requests with Key.
+type Client struct {
+	RPC v1.PrivateLocationServiceClient
+	Key ed25519.PrivateKey
+}
+
+// PublicKey returns the public key of the agent.
+func (c *Client) PublicKey() ed25519.PublicKey {
+	return c.Key.Public().(ed25519.PublicKey)
+}
+
+// Register registers the agent once, returning the private location it runs
+// as.
+func (c *Client) Register(ctx context.Context, reg *v1.RegisterRequest) (string, error) {
+	req, err := signed(c.Key, reg)
+	if err != nil {
+		return "", err
+	}
+	res, err := c.RPC.Register(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	return res.Msg.GetLocationId(), nil
+}
+
+// RegisterUntilDone registers the agent, retrying with backoff until the
+// control plane accepts it or ctx is done. The agent keeps checking in the
+// meantime, with the monitors already assigned to its location.
+func (c *Client) RegisterUntilDone(ctx context.Context, reg *v1.RegisterRequest) (string, error) {
+	b := backoff.NewExponentialBackOff()
+	b.MaxInterval = 5 * time.Minute
+
+	return backoff.Retry(ctx, func() (string, error) {
+		location, err := c.Register(ctx, reg)
+		if rejected(err) {
+			return location, backoff.Permanent(err)
+		}
+
+		return location, err
+	}, backoff.WithBackOff(b), backoff.WithMaxElapsedTime(0))
+}
+
+// rejected reports whether err is an answer of the control plane that
+// retrying the same request would not change.
+func rejected(err error) bool {
+	switch connect.CodeOf(err) {
+	case connect.CodeInvalidArgument, connect.CodeNotFound, connect.CodeAlreadyExists, connect.CodePermissionDenied,
+		connect.CodeFailedPrecondition, connect.CodeUnimplemented, connect.CodeUnauthenticated:
+		return true
+	default:
+		return false
+	}
+}
+
+// signed returns the request of msg, carrying its signature by key.
+func signed[T any, M interface {
+	*T
+	proto.Message
+}](key ed25519.PrivateKey, msg M) (*connect.Request[T], error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode request: %w", err)
+	}
+
+	req := connect.NewRequest((*T)(msg))
+	req.Header().Set(SignatureHeader, base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)))
+
+	return req, nil
+}
+
+// LoadKey reads the PEM ed25519 private key of the agent at path, and creates
+// it when the file does not exist, so the agent keeps its identity across
+// restarts.
+func LoadKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return createKey(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read agent key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("agent key %s is not PEM encoded", path)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse agent key: %w", err)
+	}
+	ed, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("agent key %s is not an ed25519 key", path)
+	}
+
+	return ed, nil
+}
+
+func createKey(path string) (ed25519.PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate agent key: %w", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode agent key: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("unable to create agent key: %w", err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		return nil, fmt.Errorf("unable to create agent key: %w", err)
+	}
+
+	return key, nil
+}
+
+// ParseLabels parses labels listed as "team=platform,env=prod".
+func ParseLabels(s string) (map[string]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	labels := make(map[string]string)
+	for pair := range strings.SplitSeq(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid label %q, expected key=value", pair)
+		}
+		labels[key] = value
+	}
+
+	return labels, nil
+}
@@ -0,0 +1,144 @@
+package agent_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/agent"
+	v1 "github.com/openstatushq/openstatus/apps/checker/proto/private_location/v1"
+)
+
+// controlPlane serves the agent RPCs of the tests.
+type controlPlane struct {
+	v1.UnimplementedPrivateLocationServiceHandler
+	register  func(*connect.Request[v1.RegisterRequest]) (*connect.Response[v1.RegisterResponse], error)
+	heartbeat func(*connect.Request[v1.HeartbeatRequest]) (*connect.Response[v1.HeartbeatResponse], error)
+	config    func(*connect.Request[v1.ConfigRequest]) (*connect.Response[v1.ConfigResponse], error)
+}
+
+func (p *controlPlane) Register(_ context.Context, req *connect.Request[v1.RegisterRequest]) (*connect.Response[v1.RegisterResponse], error) {
+	return p.register(req)
+}
+
+func (p *controlPlane) Heartbeat(_ context.Context, req *connect.Request[v1.HeartbeatRequest]) (*connect.Response[v1.HeartbeatResponse], error) {
+	return p.heartbeat(req)
+}
+
+func (p *controlPlane) Config(_ context.Context, req *connect.Request[v1.ConfigRequest]) (*connect.Response[v1.ConfigResponse], error) {
+	return p.config(req)
+}
+
+// newClient returns a client of the agent RPCs served by p, with a new key.
+func newClient(t *testing.T, p *controlPlane) *agent.Client {
+	t.Helper()
+
+	key, err := agent.LoadKey(filepath.Join(t.TempDir(), "agent.key"))
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.Handle(v1.NewPrivateLocationServiceHandler(p))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return &agent.Client{RPC: v1.NewPrivateLocationServiceClient(server.Client(), server.URL), Key: key}
+}
+
+// verify reports whether the request of msg is signed by public.
+func verify(public ed25519.PublicKey, header http.Header, msg proto.Message) bool {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return false
+	}
+	signature, err := base64.StdEncoding.DecodeString(header.Get(agent.SignatureHeader))
+
+	return err == nil && ed25519.Verify(public, data, signature)
+}
+
+func TestClient_Register(t *testing.T) {
+	client := newClient(t, &controlPlane{register: func(req *connect.Request[v1.RegisterRequest]) (*connect.Response[v1.RegisterResponse], error) {
+		assert.True(t, verify(req.Msg.GetPublicKey(), req.Header(), req.Msg), "signed with the registered key")
+		assert.Equal(t, "office-paris", req.Msg.GetRegion())
+		assert.Equal(t, map[string]string{"env": "prod"}, req.Msg.GetLabels())
+
+		return connect.NewResponse(&v1.RegisterResponse{LocationId: "42"}), nil
+	}})
+
+	location, err := client.Register(t.Context(), &v1.RegisterRequest{
+		Region:       "office-paris",
+		Labels:       map[string]string{"env": "prod"},
+		Capabilities: []string{"http"},
+		PublicKey:    client.PublicKey(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "42", location)
+}
+
+func TestClient_RegisterUntilDone(t *testing.T) {
+	var calls atomic.Int32
+	client := newClient(t, &controlPlane{register: func(*connect.Request[v1.RegisterRequest]) (*connect.Response[v1.RegisterResponse], error) {
+		calls.Add(1)
+		return nil, connect.NewError(connect.CodeUnauthenticated, nil)
+	}})
+
+	_, err := client.RegisterUntilDone(t.Context(), &v1.RegisterRequest{Region: "office-paris"})
+	assert.Equal(t, connect.CodeUnauthenticated, connect.CodeOf(err))
+	assert.Equal(t, int32(1), calls.Load(), "a rejected registration is not retried")
+}
+
+func TestLoadKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "agent.key")
+
+	created, err := agent.LoadKey(path)
+	require.NoError(t, err)
+	loaded, err := agent.LoadKey(path)
+	require.NoError(t, err)
+	assert.True(t, created.Equal(loaded), "the key is kept across restarts")
+}
+
+func TestParseLabels(t *testing.T) {
+	labels, err := agent.ParseLabels("team=platform, env=prod")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "platform", "env": "prod"}, labels)
+
+	_, err = agent.ParseLabels("team")
+	assert.Error(t, err)
+}
+
+func TestClient_Beat(t *testing.T) {
+	beats := make(chan *v1.HeartbeatRequest, 10)
+	var client *agent.Client
+	client = newClient(t, &controlPlane{heartbeat: func(req *connect.Request[v1.HeartbeatRequest]) (*connect.Response[v1.HeartbeatResponse], error) {
+		assert.True(t, verify(client.PublicKey(), req.Header(), req.Msg), "signed with the key of the agent")
+		beats <- req.Msg
+		return connect.NewResponse(&v1.HeartbeatResponse{}), nil
+	}})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	var n atomic.Int64
+	go func() {
+		client.Beat(ctx, 10*time.Millisecond, func() *v1.HeartbeatRequest {
+			return &v1.HeartbeatRequest{Region: "office-paris", QueueDepth: n.Add(1)}
+		})
+		close(done)
+	}()
+
+	first, second := <-beats, <-beats
+	cancel()
+	<-done
+	assert.Equal(t, "office-paris", first.GetRegion())
+	assert.Equal(t, int64(1), first.GetQueueDepth(), "the first heartbeat is sent right away")
+	assert.Equal(t, int64(2), second.GetQueueDepth())
+}
@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	v1 "github.com/openstatushq/openstatus/apps/checker/proto/private_location/v1"
+)
+
+// ErrInvalidSignature is returned for a config not signed by the control
+// plane, which is then not applied.
+var ErrInvalidSignature = errors.New("config not signed by the control plane")
+
+// Config is what the control plane assigns to the agent.
+type Config struct {
+	Monitors *v1.MonitorsResponse
+	Settings Settings
+}
+
+// Settings tune the agent without redeploying it.
+type Settings struct {
+	// RefreshInterval is how often the config is fetched, e.g. "1m".
+	RefreshInterval string
+}
+
+// Refresh returns the refresh interval of the settings, 0 when unset or
+// invalid.
+func (s Settings) Refresh() time.Duration {
+	d, err := time.ParseDuration(s.RefreshInterval)
+	if err != nil || d <= 0 {
+		return 0
+	}
+
+	return d
+}
+
+// ConfigFetcher fetches the config of the agent, answered only when it
+// changed since the previous fetch thanks to its etag. The encoded config is
+// signed by the control plane with the key of ServerKey.
+type ConfigFetcher struct {
+	Client    *Client
+	ServerKey ed25519.PublicKey
+	etag      string
+}
+
+// Fetch returns the config of the agent, nil when it did not change since
+// the last one fetched.
+func (f *ConfigFetcher) Fetch(ctx context.Context) (*Config, error) {
+	req, err := signed(f.Client.Key, &v1.ConfigRequest{Etag: f.etag})
+	if err != nil {
+		return nil, err
+	}
+	res, err := f.Client.RPC.Config(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch config: %w", err)
+	}
+	if res.Msg.GetNotModified() {
+		return nil, nil
+	}
+
+	if !ed25519.Verify(f.ServerKey, res.Msg.GetConfig(), res.Msg.GetSignature()) {
+		return nil, ErrInvalidSignature
+	}
+	var config v1.AgentConfig
+	if err := proto.Unmarshal(res.Msg.GetConfig(), &config); err != nil {
+		return nil, fmt.Errorf("unable to decode config: %w", err)
+	}
+	monitors := config.GetMonitors()
+	if monitors == nil {
+		monitors = &v1.MonitorsResponse{}
+	}
+	// Only a config that could be read is cached, a broken one is fetched
+	// again.
+	f.etag = res.Msg.GetEtag()
+
+	return &Config{Monitors: monitors, Settings: Settings{RefreshInterval: config.GetRefreshInterval()}}, nil
+}
+
+// ParsePublicKey parses the base64 ed25519 public key of the control plane.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("expected a base64 ed25519 public key")
+	}
+
+	return ed25519.PublicKey(key), nil
+}
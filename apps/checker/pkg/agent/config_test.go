@@ -0,0 +1,69 @@
+package agent_test
+
+import (
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/agent"
+	v1 "github.com/openstatushq/openstatus/apps/checker/proto/private_location/v1"
+)
+
+func TestConfigFetcher_Fetch(t *testing.T) {
+	serverPublic, serverKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	config, err := proto.Marshal(&v1.AgentConfig{
+		Monitors: &v1.MonitorsResponse{
+			HttpMonitors: []*v1.HTTPMonitor{{Id: "1", Url: "https://openstat.us", Periodicity: "1m"}},
+			Region:       "office-paris",
+		},
+		RefreshInterval: "1m",
+	})
+	require.NoError(t, err)
+	client := newClient(t, &controlPlane{config: func(req *connect.Request[v1.ConfigRequest]) (*connect.Response[v1.ConfigResponse], error) {
+		if req.Msg.GetEtag() == "v1" {
+			return connect.NewResponse(&v1.ConfigResponse{NotModified: true}), nil
+		}
+		return connect.NewResponse(&v1.ConfigResponse{Etag: "v1", Config: config, Signature: ed25519.Sign(serverKey, config)}), nil
+	}})
+
+	fetcher := &agent.ConfigFetcher{Client: client, ServerKey: serverPublic}
+	fetched, err := fetcher.Fetch(t.Context())
+	require.NoError(t, err)
+	require.NotNil(t, fetched)
+	assert.Equal(t, "office-paris", fetched.Monitors.Region)
+	if assert.Len(t, fetched.Monitors.HttpMonitors, 1) {
+		assert.Equal(t, "https://openstat.us", fetched.Monitors.HttpMonitors[0].Url)
+	}
+	assert.Equal(t, time.Minute, fetched.Settings.Refresh())
+
+	fetched, err = fetcher.Fetch(t.Context())
+	require.NoError(t, err)
+	assert.Nil(t, fetched, "unchanged since the previous fetch")
+}
+
+func TestConfigFetcher_FetchUnsigned(t *testing.T) {
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	serverPublic, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	config, err := proto.Marshal(&v1.AgentConfig{})
+	require.NoError(t, err)
+	client := newClient(t, &controlPlane{config: func(req *connect.Request[v1.ConfigRequest]) (*connect.Response[v1.ConfigResponse], error) {
+		assert.Empty(t, req.Msg.GetEtag(), "a rejected config is not cached")
+		return connect.NewResponse(&v1.ConfigResponse{Etag: "v1", Config: config, Signature: ed25519.Sign(otherKey, config)}), nil
+	}})
+
+	fetcher := &agent.ConfigFetcher{Client: client, ServerKey: serverPublic}
+	for range 2 {
+		_, err = fetcher.Fetch(t.Context())
+		assert.ErrorIs(t, err, agent.ErrInvalidSignature)
+	}
+}
@@ -0,0 +1,41 @@
+package agent
+
+import (
+	"context"
+	"log"
+	"time"
+
+	v1 "github.com/openstatushq/openstatus/apps/checker/proto/private_location/v1"
+)
+
+// Heartbeat sends a single heartbeat, reporting the liveness of the agent for
+// the control plane to exclude the stale or wedged ones from scheduling.
+func (c *Client) Heartbeat(ctx context.Context, hb *v1.HeartbeatRequest) error {
+	req, err := signed(c.Key, hb)
+	if err != nil {
+		return err
+	}
+	_, err = c.RPC.Heartbeat(ctx, req)
+
+	return err
+}
+
+// Beat sends the heartbeat returned by status every interval, the first one
+// right away, until ctx is done. A failed heartbeat is logged and the next
+// one sent on time.
+func (c *Client) Beat(ctx context.Context, interval time.Duration, status func() *v1.HeartbeatRequest) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Heartbeat(ctx, status()); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to send agent heartbeat: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
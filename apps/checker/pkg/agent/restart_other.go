@@ -0,0 +1,11 @@
+//go:build !unix
+
+package agent
+
+import "errors"
+
+// Restart is not supported on this platform, the agent exits instead and
+// its supervisor starts the updated binary.
+func Restart(string) error {
+	return errors.New("restart not supported on this platform")
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package agent
+
+import (
+	"os"
+	"syscall"
+)
+
+// Restart replaces the running agent with the binary at path, keeping its
+// arguments, environment and process ID, so a supervisor does not notice.
+func Restart(path string) error {
+	return syscall.Exec(path, os.Args, os.Environ())
+}
@@ -0,0 +1,221 @@
+package agent
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ErrNoUpdate is returned by Check when the agent runs the latest release,
+// or a development build.
+var ErrNoUpdate = errors.New("no update available")
+
+// maxBinaryBytes bounds the download of a release.
+const maxBinaryBytes = 512 << 20
+
+// Manifest lists the latest release of the agent. It is signed by the
+// release key, its base64 signature being served next to it at the URL of
+// the manifest followed by .sig.
+type Manifest struct {
+	Version string `json:"version"`
+	// Binaries are the builds of the release by GOOS/GOARCH, e.g.
+	// linux/amd64.
+	Binaries map[string]Binary `json:"binaries"`
+}
+
+// Binary is a build of a release. Signature is the base64 ed25519
+// signature of the binary by the release key.
+type Binary struct {
+	URL       string `json:"url"`
+	SHA256    string `json:"sha256"`
+	Signature string `json:"signature"`
+}
+
+// Updater replaces the binary of the agent with the latest release of
+// ManifestURL, whose manifest and binaries are signed with the key of
+// PublicKey.
+type Updater struct {
+	HTTP        *http.Client
+	ManifestURL string
+	PublicKey   ed25519.PublicKey
+	// Current is the version of the running agent.
+	Current string
+}
+
+// Check returns the binary of the latest release for the platform of the
+// agent, and its version, or ErrNoUpdate.
+func (u *Updater) Check(ctx context.Context) (Binary, string, error) {
+	manifest, err := u.get(ctx, u.ManifestURL, 1<<20)
+	if err != nil {
+		return Binary{}, "", fmt.Errorf("unable to fetch manifest: %w", err)
+	}
+	encoded, err := u.get(ctx, u.ManifestURL+".sig", 1<<10)
+	if err != nil {
+		return Binary{}, "", fmt.Errorf("unable to fetch manifest signature: %w", err)
+	}
+	if !u.verify(manifest, strings.TrimSpace(string(encoded))) {
+		return Binary{}, "", errors.New("manifest not signed by the release key")
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(manifest, &m); err != nil {
+		return Binary{}, "", fmt.Errorf("unable to decode manifest: %w", err)
+	}
+	if !newer(m.Version, u.Current) {
+		return Binary{}, "", ErrNoUpdate
+	}
+	binary, ok := m.Binaries[runtime.GOOS+"/"+runtime.GOARCH]
+	if !ok {
+		return Binary{}, "", fmt.Errorf("release %s has no build for %s/%s", m.Version, runtime.GOOS, runtime.GOARCH)
+	}
+
+	return binary, m.Version, nil
+}
+
+// Install downloads binary, verifies its checksum and signature, and
+// replaces the executable at path with it. The running agent is left as is
+// until it restarts.
+func (u *Updater) Install(ctx context.Context, binary Binary, path string) error {
+	data, err := u.get(ctx, binary.URL, maxBinaryBytes)
+	if err != nil {
+		return fmt.Errorf("unable to download release: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), binary.SHA256) {
+		return errors.New("release checksum mismatch")
+	}
+	if !u.verify(data, binary.Signature) {
+		return errors.New("release not signed by the release key")
+	}
+
+	return replace(path, data)
+}
+
+// Executable returns the path of the binary of the running agent, the one
+// replaced by an update.
+func Executable() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("unable to locate executable: %w", err)
+	}
+
+	return filepath.EvalSymlinks(path)
+}
+
+// replace writes data next to path and renames it over path, so the
+// executable is never left half written.
+func replace(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".update-*")
+	if err != nil {
+		return fmt.Errorf("unable to write release: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("unable to write release: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to write release: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return fmt.Errorf("unable to write release: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("unable to replace executable: %w", err)
+	}
+
+	return nil
+}
+
+func (u *Updater) verify(data []byte, signature string) bool {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+
+	return err == nil && ed25519.Verify(u.PublicKey, data, sig)
+}
+
+func (u *Updater) get(ctx context.Context, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := u.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s answered %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s is larger than %d bytes", url, limit)
+	}
+
+	return data, nil
+}
+
+// newer reports whether version a is after b, both being dotted numbers
+// with an optional v prefix, e.g. v1.4.2. Development builds, whose version
+// is not a number, are never updated.
+func newer(a, b string) bool {
+	pa, okA := parseVersion(a)
+	pb, okB := parseVersion(b)
+	if !okA || !okB {
+		return false
+	}
+
+	for i := range max(len(pa), len(pb)) {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			return x > y
+		}
+	}
+
+	return false
+}
+
+func parseVersion(v string) ([]int, bool) {
+	v = strings.TrimPrefix(v, "v")
+	if v == "" {
+		return nil, false
+	}
+
+	var parts []int
+	for part := range strings.SplitSeq(v, ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+
+	return parts, true
+}
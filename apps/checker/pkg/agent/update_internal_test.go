@@ -0,0 +1,25 @@
+package agent
+
+import "testing"
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"v1.3.0", "v1.2.9", true},
+		{"1.10.0", "v1.9", true},
+		{"v1.2", "v1.2.0", false},
+		{"v1.2.9", "v1.3.0", false},
+		// The default version of main, when the build does not set it.
+		{"v1.3.0", "dev", false},
+		{"dev", "v1.3.0", false},
+		{"v1.3.0-rc1", "v1.2.0", false},
+	}
+
+	for _, tt := range tests {
+		if got := newer(tt.a, tt.b); got != tt.want {
+			t.Errorf("newer(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
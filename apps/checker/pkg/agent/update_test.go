@@ -0,0 +1,108 @@
+package agent_test
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/agent"
+)
+
+// releaseServer serves a manifest of version, with a binary of content,
+// signed by key.
+func releaseServer(t *testing.T, key ed25519.PrivateKey, version string, content []byte) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	sum := sha256.Sum256(content)
+	manifest, err := json.Marshal(agent.Manifest{
+		Version: version,
+		Binaries: map[string]agent.Binary{runtime.GOOS + "/" + runtime.GOARCH: {
+			URL:       server.URL + "/checker",
+			SHA256:    hex.EncodeToString(sum[:]),
+			Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, content)),
+		}},
+	})
+	require.NoError(t, err)
+
+	mux.HandleFunc("/manifest.json", func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write(manifest) })
+	mux.HandleFunc("/manifest.json.sig", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString(ed25519.Sign(key, manifest))))
+	})
+	mux.HandleFunc("/checker", func(w http.ResponseWriter, _ *http.Request) { _, _ = w.Write(content) })
+
+	return server
+}
+
+func TestUpdater(t *testing.T) {
+	public, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	server := releaseServer(t, key, "v1.3.0", []byte("new release"))
+
+	path := filepath.Join(t.TempDir(), "checker")
+	require.NoError(t, os.WriteFile(path, []byte("old release"), 0o755))
+
+	updater := &agent.Updater{ManifestURL: server.URL + "/manifest.json", PublicKey: public, Current: "v1.2.9"}
+	binary, release, err := updater.Check(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "v1.3.0", release)
+
+	require.NoError(t, updater.Install(t.Context(), binary, path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new release", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+	for _, current := range []string{"v1.3.0", "1.10.0", "dev"} {
+		updater.Current = current
+		_, _, err = updater.Check(t.Context())
+		assert.ErrorIs(t, err, agent.ErrNoUpdate, current)
+	}
+}
+
+func TestUpdater_Unsigned(t *testing.T) {
+	public, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	server := releaseServer(t, otherKey, "v2.0.0", []byte("tampered release"))
+
+	updater := &agent.Updater{ManifestURL: server.URL + "/manifest.json", PublicKey: public, Current: "v1.0.0"}
+	_, _, err = updater.Check(t.Context())
+	assert.Error(t, err)
+}
+
+func TestUpdater_InstallTampered(t *testing.T) {
+	public, key, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	server := releaseServer(t, key, "v2.0.0", []byte("new release"))
+
+	path := filepath.Join(t.TempDir(), "checker")
+	require.NoError(t, os.WriteFile(path, []byte("old release"), 0o755))
+
+	updater := &agent.Updater{ManifestURL: server.URL + "/manifest.json", PublicKey: public, Current: "v1.0.0"}
+	binary, _, err := updater.Check(t.Context())
+	require.NoError(t, err)
+
+	binary.SHA256 = hex.EncodeToString(make([]byte, sha256.Size))
+	assert.Error(t, updater.Install(t.Context(), binary, path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "old release", string(data), "a tampered release is not installed")
+}
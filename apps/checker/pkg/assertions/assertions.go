@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/openstatushq/openstatus/apps/checker/request"
@@ -28,6 +29,28 @@ type HeaderTarget struct {
 	Key           string                   `json:"key"`
 }
 
+type TLSVersionTarget struct {
+	AssertionType request.AssertionType    `json:"type"`
+	Comparator    request.NumberComparator `json:"compare"`
+	Target        string                   `json:"target"`
+}
+
+type SecurityGradeTarget struct {
+	AssertionType request.AssertionType    `json:"type"`
+	Comparator    request.NumberComparator `json:"compare"`
+	Target        string                   `json:"target"`
+}
+
+// JSONBodyTarget compares the value at Path of a JSON body, its keys and
+// array indexes separated by dots, e.g. items.0.name, with a string
+// comparator.
+type JSONBodyTarget struct {
+	AssertionType request.AssertionType    `json:"type"`
+	Comparator    request.StringComparator `json:"compare"`
+	Target        string                   `json:"target"`
+	Path          string                   `json:"path"`
+}
+
 type StringTargetType struct {
 	Comparator request.StringComparator `json:"compare"`
 	Target     string                   `json:"target"`
@@ -149,3 +172,73 @@ func (target RecordTarget) RecordEvaluate(s []string) bool {
 
 	return true
 }
+
+// TLSVersionEvaluate compares the negotiated version, empty without TLS
+// which fails the assertion.
+func (target TLSVersionTarget) TLSVersionEvaluate(version string) bool {
+	want, ok := request.ParseTLSVersion(target.Target)
+	if !ok {
+		return false
+	}
+	got, ok := request.ParseTLSVersion(version)
+	if !ok {
+		return false
+	}
+
+	return StatusTarget{Comparator: target.Comparator, Target: int64(want)}.StatusEvaluate(int64(got))
+}
+
+// SecurityGradeEvaluate compares the grade of the security headers, a better
+// grade being greater.
+func (target SecurityGradeTarget) SecurityGradeEvaluate(grade string) bool {
+	want, ok := request.ParseSecurityGrade(target.Target)
+	if !ok {
+		return false
+	}
+	got, ok := request.ParseSecurityGrade(grade)
+	if !ok {
+		return false
+	}
+
+	return StatusTarget{Comparator: target.Comparator, Target: int64(want)}.StatusEvaluate(int64(got))
+}
+
+// JSONBodyEvaluate compares the value at the path of the JSON of body. The
+// strings are compared as they are, the other values as their JSON and a
+// missing value as the empty string.
+func (target JSONBodyTarget) JSONBodyEvaluate(body string) bool {
+	var value any
+	if err := json.Unmarshal([]byte(body), &value); err != nil {
+		return false
+	}
+
+	if target.Path != "" {
+		for key := range strings.SplitSeq(target.Path, ".") {
+			switch v := value.(type) {
+			case map[string]any:
+				value = v[key]
+			case []any:
+				i, err := strconv.Atoi(key)
+				if err != nil || i < 0 || i >= len(v) {
+					value = nil
+					break
+				}
+				value = v[i]
+			default:
+				value = nil
+			}
+		}
+	}
+
+	var s string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		s = v
+	default:
+		data, _ := json.Marshal(v)
+		s = string(data)
+	}
+
+	return StringTargetType{Comparator: target.Comparator, Target: target.Target}.StringEvaluate(s)
+}
@@ -200,3 +200,69 @@ func TestRecordTarget_RecordEvaluate(t *testing.T) {
 		})
 	}
 }
+
+func TestTLSVersionTarget_TLSVersionEvaluate(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  TLSVersionTarget
+		version string
+		want    bool
+	}{
+		{name: "at least 1.2", target: TLSVersionTarget{Comparator: request.NumberGreaterThanEqual, Target: "1.2"}, version: "TLS 1.3", want: true},
+		{name: "re-enabled 1.0", target: TLSVersionTarget{Comparator: request.NumberGreaterThanEqual, Target: "1.2"}, version: "TLS 1.0", want: false},
+		{name: "exact version", target: TLSVersionTarget{Comparator: request.NumberEquals, Target: "TLS 1.3"}, version: "TLS 1.3", want: true},
+		{name: "no TLS", target: TLSVersionTarget{Comparator: request.NumberGreaterThanEqual, Target: "1.2"}, version: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.target.TLSVersionEvaluate(tt.version); got != tt.want {
+				t.Errorf("TLSVersionTarget.TLSVersionEvaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecurityGradeTarget_SecurityGradeEvaluate(t *testing.T) {
+	tests := []struct {
+		name   string
+		target SecurityGradeTarget
+		grade  string
+		want   bool
+	}{
+		{name: "at least B", target: SecurityGradeTarget{Comparator: request.NumberGreaterThanEqual, Target: "B"}, grade: "A", want: true},
+		{name: "below B", target: SecurityGradeTarget{Comparator: request.NumberGreaterThanEqual, Target: "B"}, grade: "D", want: false},
+		{name: "exact grade", target: SecurityGradeTarget{Comparator: request.NumberEquals, Target: "a"}, grade: "A", want: true},
+		{name: "not graded", target: SecurityGradeTarget{Comparator: request.NumberGreaterThanEqual, Target: "F"}, grade: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.target.SecurityGradeEvaluate(tt.grade); got != tt.want {
+				t.Errorf("SecurityGradeTarget.SecurityGradeEvaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONBodyTarget_JSONBodyEvaluate(t *testing.T) {
+	body := `{"status":"SERVING","items":[{"name":"a","count":3}],"ok":true}`
+	tests := []struct {
+		name   string
+		target JSONBodyTarget
+		want   bool
+	}{
+		{name: "string", target: JSONBodyTarget{Comparator: request.StringEquals, Path: "status", Target: "SERVING"}, want: true},
+		{name: "array index", target: JSONBodyTarget{Comparator: request.StringEquals, Path: "items.0.name", Target: "a"}, want: true},
+		{name: "number", target: JSONBodyTarget{Comparator: request.StringEquals, Path: "items.0.count", Target: "3"}, want: true},
+		{name: "boolean", target: JSONBodyTarget{Comparator: request.StringEquals, Path: "ok", Target: "true"}, want: true},
+		{name: "missing", target: JSONBodyTarget{Comparator: request.StringEmpty, Path: "items.1.name"}, want: true},
+		{name: "whole body", target: JSONBodyTarget{Comparator: request.StringContains, Target: `"SERVING"`}, want: true},
+		{name: "mismatch", target: JSONBodyTarget{Comparator: request.StringEquals, Path: "status", Target: "NOT_SERVING"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.target.JSONBodyEvaluate(body); got != tt.want {
+				t.Errorf("JSONBodyTarget.JSONBodyEvaluate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+// Package audit keeps an append-only record of the checks executed by the
+// checker and of what triggered them, for compliance.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/tinybird"
+)
+
+// Entry records one executed check.
+type Entry struct {
+	// Trigger is cron for scheduled checks and api for on-demand ones.
+	Trigger     string `json:"trigger"`
+	WorkspaceID string `json:"workspaceId,omitempty"`
+	MonitorID   string `json:"monitorId,omitempty"`
+	Type        string `json:"type"`
+	Target      string `json:"target"`
+	Region      string `json:"region"`
+	// Outcome is the status of the check envelope.
+	Outcome   string `json:"outcome"`
+	ErrorCode string `json:"errorCode,omitempty"`
+	// CorrelationID is the X-Request-ID of the submission.
+	CorrelationID string `json:"correlationId,omitempty"`
+	ClientIP      string `json:"clientIp,omitempty"`
+	RequestID     int64  `json:"requestId,omitempty"`
+	Timestamp     int64  `json:"timestamp"`
+	DryRun        bool   `json:"dryRun,omitempty"`
+}
+
+// Sink stores the entries. Implementations must be safe for concurrent use.
+type Sink interface {
+	Record(ctx context.Context, e Entry) error
+}
+
+// File appends the entries to a file as JSON lines.
+type File struct {
+	f  *os.File
+	mu sync.Mutex
+}
+
+func NewFile(path string) (*File, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open audit log: %w", err)
+	}
+
+	return &File{f: f}, nil
+}
+
+func (f *File) Record(_ context.Context, e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("unable to encode audit entry: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("unable to write audit entry: %w", err)
+	}
+
+	return nil
+}
+
+func (f *File) Close() error {
+	return f.f.Close()
+}
+
+// Tinybird sends the entries to their own datasource, apart from the check
+// results.
+type Tinybird struct {
+	Client     tinybird.Client
+	Datasource string
+}
+
+func (t Tinybird) Record(ctx context.Context, e Entry) error {
+	return t.Client.SendEvent(ctx, e, t.Datasource)
+}
+
+// Multi records the entries in every sink.
+type Multi []Sink
+
+func (m Multi) Record(ctx context.Context, e Entry) error {
+	var errs []error
+	for _, s := range m {
+		if err := s.Record(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,36 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/audit"
+)
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	require.NoError(t, os.WriteFile(path, []byte(`{"trigger":"cron"}`+"\n"), 0o600))
+
+	f, err := audit.NewFile(path)
+	require.NoError(t, err)
+
+	require.NoError(t, f.Record(t.Context(), audit.Entry{Trigger: "api", Type: "http", Target: "https://openstat.us", Outcome: "success"}))
+	require.NoError(t, f.Close())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 2, "entries are appended")
+
+	var e audit.Entry
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &e))
+	assert.Equal(t, "https://openstat.us", e.Target)
+	assert.Equal(t, "success", e.Outcome)
+}
@@ -0,0 +1,65 @@
+// Package auth authenticates the requests sent to the checker by the
+// control plane.
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+)
+
+var ErrUnauthorized = errors.New("unauthorized")
+
+// ErrBodyTooLarge rejects a request whose body is longer than MaxBodyBytes,
+// which is not read further to verify it.
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// MaxBodyBytes bounds the body of a request read before it is authenticated.
+const MaxBodyBytes = 10 << 20
+
+// Authenticator checks the credentials of a request. It may read the body
+// but must leave it readable for the handler.
+type Authenticator interface {
+	Authenticate(r *http.Request) error
+}
+
+// Basic accepts requests carrying `Authorization: Basic <secret>` for any of
+// its secrets, usually the current one and the previous one while it is
+// being rotated across the fleet.
+type Basic struct {
+	Secrets []string
+}
+
+func (b Basic) Authenticate(r *http.Request) error {
+	header := r.Header.Get("Authorization")
+
+	// Compare with every secret so the timing does not tell which matched.
+	ok := false
+	for _, secret := range b.Secrets {
+		ok = equal(header, "Basic "+secret) || ok
+	}
+	if !ok {
+		return ErrUnauthorized
+	}
+
+	return nil
+}
+
+// Any accepts a request as soon as one of its authenticators does, which
+// lets two schemes coexist while the dispatchers migrate.
+type Any []Authenticator
+
+func (a Any) Authenticate(r *http.Request) error {
+	err := ErrUnauthorized
+	for _, authenticator := range a {
+		if err = authenticator.Authenticate(r); err == nil || errors.Is(err, ErrBodyTooLarge) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
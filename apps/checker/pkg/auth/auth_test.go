@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBasic(t *testing.T) {
+	b := Basic{Secrets: []string{"secret", "previous"}}
+
+	r, _ := http.NewRequest(http.MethodPost, "/", nil)
+	assert.ErrorIs(t, b.Authenticate(r), ErrUnauthorized)
+
+	r.Header.Set("Authorization", "Basic secret")
+	assert.NoError(t, b.Authenticate(r))
+
+	r.Header.Set("Authorization", "Basic previous")
+	assert.NoError(t, b.Authenticate(r))
+
+	r.Header.Set("Authorization", "Basic other")
+	assert.ErrorIs(t, b.Authenticate(r), ErrUnauthorized)
+}
+
+func TestHMAC(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	h := NewHMAC([]string{"secret", "previous"}, time.Minute)
+	h.now = func() time.Time { return now }
+
+	signed := func(body string, timestamp int64, signature string) *http.Request {
+		r, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		r.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+		r.Header.Set(SignatureHeader, signature)
+		return r
+	}
+
+	t.Run("valid signature, body left readable", func(t *testing.T) {
+		r := signed(`{"a":1}`, now.Unix(), Sign("secret", now.Unix(), []byte(`{"a":1}`)))
+		assert.NoError(t, h.Authenticate(r))
+
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"a":1}`, string(body))
+	})
+
+	t.Run("replayed request", func(t *testing.T) {
+		r := signed(`{"a":1}`, now.Unix(), Sign("secret", now.Unix(), []byte(`{"a":1}`)))
+		assert.ErrorIs(t, h.Authenticate(r), ErrUnauthorized)
+	})
+
+	t.Run("previous secret", func(t *testing.T) {
+		r := signed(`{"a":1}`, now.Unix(), Sign("previous", now.Unix(), []byte(`{"a":1}`)))
+		assert.NoError(t, h.Authenticate(r))
+	})
+
+	t.Run("tampered body", func(t *testing.T) {
+		r := signed(`{"a":2}`, now.Unix(), Sign("secret", now.Unix(), []byte(`{"a":1}`)))
+		assert.ErrorIs(t, h.Authenticate(r), ErrUnauthorized)
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		body := strings.Repeat("a", MaxBodyBytes+1)
+		r := signed(body, now.Unix(), Sign("secret", now.Unix(), []byte(body)))
+		assert.ErrorIs(t, Any{h, Basic{Secrets: []string{"secret"}}}.Authenticate(r), ErrBodyTooLarge)
+	})
+
+	t.Run("outside of the window", func(t *testing.T) {
+		old := now.Add(-2 * time.Minute).Unix()
+		r := signed(`{}`, old, Sign("secret", old, []byte(`{}`)))
+		assert.ErrorIs(t, h.Authenticate(r), ErrUnauthorized)
+	})
+
+	t.Run("any", func(t *testing.T) {
+		a := Any{h, Basic{Secrets: []string{"secret"}}}
+
+		r, _ := http.NewRequest(http.MethodPost, "/", nil)
+		r.Header.Set("Authorization", "Basic secret")
+		assert.NoError(t, a.Authenticate(r))
+
+		r.Header.Set("Authorization", "Basic nope")
+		assert.ErrorIs(t, a.Authenticate(r), ErrUnauthorized)
+	})
+}
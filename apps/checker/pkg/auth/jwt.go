@@ -0,0 +1,310 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultJWKSRefresh is how long the keys of a JWKS are kept before being
+// fetched again.
+const DefaultJWKSRefresh = time.Hour
+
+// leeway absorbs the clock skew between the issuer and the checker.
+const leeway = time.Minute
+
+// minRefetch rate limits the fetches triggered by tokens signed with an
+// unknown key.
+const minRefetch = time.Minute
+
+// JWT accepts `Authorization: Bearer <token>` signed with RS256/384/512 or
+// ES256/384/512 by a key of the JWKS served at the configured URL, issued by
+// issuer for audience. Rotated keys are picked up without a redeploy: the
+// JWKS is refreshed periodically and whenever a token names an unknown key.
+type JWT struct {
+	now       func() time.Time
+	client    *http.Client
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	url       string
+	issuer    string
+	audience  string
+	refresh   time.Duration
+	mu        sync.Mutex
+}
+
+func NewJWT(client *http.Client, jwksURL, issuer, audience string, refresh time.Duration) *JWT {
+	if refresh <= 0 {
+		refresh = DefaultJWKSRefresh
+	}
+
+	return &JWT{
+		client:   client,
+		url:      jwksURL,
+		issuer:   issuer,
+		audience: audience,
+		refresh:  refresh,
+		now:      time.Now,
+	}
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Audience  audience `json:"aud"`
+	Issuer    string   `json:"iss"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+}
+
+// audience is a string or an array of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	*a = many
+
+	return nil
+}
+
+func (j *JWT) Authenticate(r *http.Request) error {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return ErrUnauthorized
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("%w: malformed token", ErrUnauthorized)
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return fmt.Errorf("%w: malformed header", ErrUnauthorized)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrUnauthorized)
+	}
+
+	key, err := j.key(r, header.Kid)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	}
+
+	if err := verify(header.Alg, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return fmt.Errorf("%w: malformed claims", ErrUnauthorized)
+	}
+
+	now := j.now()
+	switch {
+	case claims.ExpiresAt == 0 || now.After(time.Unix(claims.ExpiresAt, 0).Add(leeway)):
+		return fmt.Errorf("%w: token expired", ErrUnauthorized)
+	case claims.NotBefore != 0 && now.Add(leeway).Before(time.Unix(claims.NotBefore, 0)):
+		return fmt.Errorf("%w: token not valid yet", ErrUnauthorized)
+	case j.issuer != "" && claims.Issuer != j.issuer:
+		return fmt.Errorf("%w: unexpected issuer", ErrUnauthorized)
+	case j.audience != "" && !slices.Contains(claims.Audience, j.audience):
+		return fmt.Errorf("%w: unexpected audience", ErrUnauthorized)
+	}
+
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// key returns the public key kid, fetching the JWKS when it is stale or
+// does not know the key.
+func (j *JWT) key(r *http.Request, kid string) (crypto.PublicKey, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	age := j.now().Sub(j.fetchedAt)
+	key, known := j.keys[kid]
+	if age > j.refresh || (!known && age > minRefetch) {
+		keys, err := j.fetch(r)
+		if err != nil && !known {
+			return nil, err
+		}
+		if err == nil {
+			j.keys, j.fetchedAt = keys, j.now()
+			key, known = keys[kid]
+		}
+	}
+
+	if !known {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+
+	return key, nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (j *JWT) fetch(r *http.Request) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create jwks request: %w", err)
+	}
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to fetch jwks: unexpected status code %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("unable to decode jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		key, err := k.publicKey()
+		if err != nil {
+			// Skip the keys we cannot use rather than the whole set.
+			continue
+		}
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func verify(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	var (
+		h    hash.Hash
+		hash crypto.Hash
+	)
+	switch alg[min(2, len(alg)):] {
+	case "256":
+		h, hash = sha256.New(), crypto.SHA256
+	case "384":
+		h, hash = sha512.New384(), crypto.SHA384
+	case "512":
+		h, hash = sha512.New(), crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch {
+	case strings.HasPrefix(alg, "RS"):
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("key does not match the algorithm")
+		}
+		if err := rsa.VerifyPKCS1v15(k, hash, digest, signature); err != nil {
+			return errors.New("invalid signature")
+		}
+	case strings.HasPrefix(alg, "ES"):
+		k, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(signature)%2 != 0 {
+			return errors.New("key does not match the algorithm")
+		}
+		half := len(signature) / 2
+		r := new(big.Int).SetBytes(signature[:half])
+		s := new(big.Int).SetBytes(signature[half:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errors.New("invalid signature")
+		}
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func signToken(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]any) string {
+	t.Helper()
+
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		s, err := rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+		signature = s
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		require.NoError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+
+	return signed + "." + b64(signature)
+}
+
+func TestJWT(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var fetches int
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches++
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+		}})
+	}))
+	defer jwks.Close()
+
+	now := time.Unix(1_700_000_000, 0)
+	j := NewJWT(jwks.Client(), jwks.URL, "https://openstat.us", "checker", time.Hour)
+	j.now = func() time.Time { return now }
+
+	valid := map[string]any{"iss": "https://openstat.us", "aud": []string{"checker"}, "exp": now.Add(time.Minute).Unix()}
+
+	authenticate := func(token string) error {
+		r, _ := http.NewRequest(http.MethodPost, "/", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		return j.Authenticate(r)
+	}
+
+	assert.NoError(t, authenticate(signToken(t, "RS256", "rsa", rsaKey, valid)))
+	assert.NoError(t, authenticate(signToken(t, "ES256", "ec", ecKey, valid)))
+	assert.Equal(t, 1, fetches)
+
+	t.Run("wrong key", func(t *testing.T) {
+		other, _ := rsa.GenerateKey(rand.Reader, 2048)
+		assert.ErrorIs(t, authenticate(signToken(t, "RS256", "rsa", other, valid)), ErrUnauthorized)
+	})
+
+	t.Run("claims", func(t *testing.T) {
+		for name, claims := range map[string]map[string]any{
+			"expired":      {"iss": "https://openstat.us", "aud": "checker", "exp": now.Add(-time.Hour).Unix()},
+			"no expiry":    {"iss": "https://openstat.us", "aud": "checker"},
+			"wrong issuer": {"iss": "https://evil.example", "aud": "checker", "exp": now.Add(time.Minute).Unix()},
+			"wrong aud":    {"iss": "https://openstat.us", "aud": "other", "exp": now.Add(time.Minute).Unix()},
+		} {
+			assert.ErrorIs(t, authenticate(signToken(t, "RS256", "rsa", rsaKey, claims)), ErrUnauthorized, name)
+		}
+	})
+
+	t.Run("unknown key refetches the jwks, rate limited", func(t *testing.T) {
+		before := fetches
+		assert.Error(t, authenticate(signToken(t, "RS256", "rotated", rsaKey, valid)))
+		assert.Equal(t, before, fetches)
+
+		now = now.Add(2 * time.Minute)
+		valid["exp"] = now.Add(time.Minute).Unix()
+		assert.Error(t, authenticate(signToken(t, "RS256", "rotated", rsaKey, valid)))
+		assert.Equal(t, before+1, fetches)
+	})
+}
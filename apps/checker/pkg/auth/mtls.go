@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+)
+
+// ServerTLS returns the TLS configuration of the listener. With a client CA
+// every connection must present a certificate signed by it, which makes the
+// checker usable on untrusted networks.
+func ServerTLS(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load server certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if clientCAFile == "" {
+		return config, nil
+	}
+
+	pem, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read client CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in %s", clientCAFile)
+	}
+
+	config.ClientCAs = pool
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+
+	return config, nil
+}
+
+// ClientCert accepts requests made over a connection whose client
+// certificate was verified by the listener. When Names is set, the common
+// name or one of the DNS names of the certificate must be listed.
+type ClientCert struct {
+	Names []string
+}
+
+func (cc ClientCert) Authenticate(r *http.Request) error {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return ErrUnauthorized
+	}
+
+	if len(cc.Names) == 0 {
+		return nil
+	}
+
+	leaf := r.TLS.VerifiedChains[0][0]
+	if slices.Contains(cc.Names, leaf.Subject.CommonName) {
+		return nil
+	}
+	for _, name := range leaf.DNSNames {
+		if slices.Contains(cc.Names, name) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: client certificate not allowed", ErrUnauthorized)
+}
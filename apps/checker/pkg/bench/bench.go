@@ -0,0 +1,183 @@
+// Package bench loads a checker with synthetic check jobs at a fixed rate and
+// reports how it keeps up, to size a region before it takes more monitors.
+package bench
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Config describes a run.
+type Config struct {
+	// URL is the endpoint of the checker the jobs are posted to, e.g.
+	// http://localhost:8080/v2/checker/http?dryRun=true.
+	URL           string
+	Authorization string
+	// Job returns the body of the n-th job.
+	Job func(n int) []byte
+	// Rate is how many jobs are sent per second, whether the checker keeps
+	// up or not.
+	Rate     float64
+	Duration time.Duration
+	// Concurrency bounds the jobs in flight, the ones due while it is
+	// reached being dropped.
+	Concurrency int
+	Client      *http.Client
+}
+
+// Report is the outcome of a run. A job is dropped when it is due while
+// Concurrency are in flight, or when the checker sheds it with 429 or 503.
+type Report struct {
+	Sent      int `json:"sent"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Dropped   int `json:"dropped"`
+	// Throughput is how many jobs succeeded per second.
+	Throughput float64       `json:"throughput"`
+	DropRate   float64       `json:"dropRate"`
+	P50        time.Duration `json:"p50"`
+	P99        time.Duration `json:"p99"`
+	Max        time.Duration `json:"max"`
+	Elapsed    time.Duration `json:"elapsed"`
+}
+
+func (r Report) String() string {
+	return fmt.Sprintf("sent %d, succeeded %d, failed %d, dropped %d (%.2f%%) in %s\nthroughput %.1f jobs/s, latency p50 %s, p99 %s, max %s",
+		r.Sent, r.Succeeded, r.Failed, r.Dropped, r.DropRate*100, r.Elapsed.Round(time.Millisecond),
+		r.Throughput, r.P50.Round(time.Millisecond), r.P99.Round(time.Millisecond), r.Max.Round(time.Millisecond))
+}
+
+func (c Config) validate() error {
+	switch {
+	case c.URL == "":
+		return errors.New("no checker URL")
+	case c.Job == nil:
+		return errors.New("no job")
+	case c.Rate <= 0:
+		return errors.New("rate must be positive")
+	case c.Duration <= 0:
+		return errors.New("duration must be positive")
+	case c.Concurrency <= 0:
+		return errors.New("concurrency must be positive")
+	}
+
+	return nil
+}
+
+// Run sends the jobs of cfg until its duration elapsed or ctx is done, and
+// waits for the ones in flight.
+func Run(ctx context.Context, cfg Config) (Report, error) {
+	if err := cfg.validate(); err != nil {
+		return Report{}, err
+	}
+	client := cfg.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var (
+		report    Report
+		latencies []time.Duration
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+	)
+	slots := make(chan struct{}, cfg.Concurrency)
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / cfg.Rate))
+	defer ticker.Stop()
+	deadline := time.NewTimer(cfg.Duration)
+	defer deadline.Stop()
+
+	start := time.Now()
+	due := 0
+loop:
+	for ; ; due++ {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-deadline.C:
+			break loop
+		case <-ticker.C:
+		}
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			mu.Lock()
+			report.Dropped++
+			mu.Unlock()
+			continue
+		}
+
+		body := cfg.Job(due)
+		wg.Go(func() {
+			defer func() { <-slots }()
+			begin := time.Now()
+			status, err := send(ctx, client, cfg, body)
+			latency := time.Since(begin)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Sent++
+			switch {
+			case err != nil:
+				report.Failed++
+			case status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable:
+				report.Dropped++
+			case status >= http.StatusOK && status < http.StatusMultipleChoices:
+				report.Succeeded++
+				latencies = append(latencies, latency)
+			default:
+				report.Failed++
+			}
+		})
+	}
+	wg.Wait()
+	report.Elapsed = time.Since(start)
+
+	if due > 0 {
+		report.DropRate = float64(report.Dropped) / float64(due)
+	}
+	report.Throughput = float64(report.Succeeded) / report.Elapsed.Seconds()
+	if len(latencies) > 0 {
+		slices.Sort(latencies)
+		report.P50 = percentile(latencies, 0.50)
+		report.P99 = percentile(latencies, 0.99)
+		report.Max = latencies[len(latencies)-1]
+	}
+
+	return report, nil
+}
+
+func send(ctx context.Context, client *http.Client, cfg Config, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.Authorization != "" {
+		req.Header.Set("Authorization", cfg.Authorization)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	return res.StatusCode, nil
+}
+
+// percentile returns the p-th percentile of sorted, by the nearest rank.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(float64(len(sorted))*p+0.5) - 1
+
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
@@ -0,0 +1,51 @@
+package bench_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/bench"
+)
+
+func TestRun(t *testing.T) {
+	var jobs atomic.Int64
+	checker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Basic test", r.Header.Get("Authorization"))
+		if jobs.Add(1)%5 == 0 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer checker.Close()
+
+	report, err := bench.Run(t.Context(), bench.Config{
+		URL:           checker.URL,
+		Authorization: "Basic test",
+		Job:           func(n int) []byte { return []byte(`{}`) },
+		Rate:          200,
+		Duration:      300 * time.Millisecond,
+		Concurrency:   2,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int(jobs.Load()), report.Sent)
+	assert.Positive(t, report.Succeeded)
+	assert.Zero(t, report.Failed)
+	assert.Greater(t, report.Dropped, report.Sent-report.Succeeded, "jobs due while 2 are in flight are dropped")
+	assert.Greater(t, report.DropRate, 0.5)
+	assert.GreaterOrEqual(t, report.P50, 20*time.Millisecond)
+	assert.GreaterOrEqual(t, report.P99, report.P50)
+	assert.GreaterOrEqual(t, report.Max, report.P99)
+}
+
+func TestRun_Invalid(t *testing.T) {
+	_, err := bench.Run(t.Context(), bench.Config{URL: "http://localhost", Job: func(int) []byte { return nil }, Duration: time.Second, Concurrency: 1})
+	assert.ErrorContains(t, err, "rate must be positive")
+}
@@ -0,0 +1,120 @@
+// Package callback posts the result of a check to the callbackUrl of its
+// request once it completed, so integrations are notified instead of
+// polling. The body is signed like the requests of the hmac auth mode:
+//
+//	X-Openstatus-Timestamp: 1700000000
+//	X-Openstatus-Signature: sha256=<hex HMAC-SHA256 of "<timestamp>.<body>">
+//
+// and auth.HMAC verifies it, when the Sender has a secret.
+package callback
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/rs/zerolog/log"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/auth"
+)
+
+// maxTries bounds the deliveries of a callback, its first one included.
+const maxTries = 3
+
+// Sender posts the callbacks in the background, retrying the failed ones.
+type Sender struct {
+	// Client posts the callbacks, its transport keeping them off the
+	// internal network.
+	Client *http.Client
+	// Secret signs the callbacks, which are sent unsigned without it.
+	Secret string
+	wg     sync.WaitGroup
+}
+
+// Send posts the JSON of body to url in the background. ctx only carries the
+// values of the request, the delivery outlives it.
+func (s *Sender) Send(ctx context.Context, url string, body any) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to encode callback")
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	s.wg.Go(func() {
+		if err := s.Deliver(ctx, url, data); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("url", url).Msg("failed to deliver callback")
+		}
+	})
+}
+
+// Wait waits for the callbacks being delivered, on shutdown.
+func (s *Sender) Wait() {
+	s.wg.Wait()
+}
+
+// Deliver posts body to url, signed, retrying up to maxTries times unless
+// the receiver rejects it with a 4xx status.
+func (s *Sender) Deliver(ctx context.Context, url string, body []byte) error {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = time.Second
+
+	_, err := backoff.Retry(ctx, func() (struct{}, error) {
+		err := s.post(ctx, url, body)
+		var status *StatusError
+		if errors.As(err, &status) && status.Code < http.StatusInternalServerError && status.Code != http.StatusTooManyRequests {
+			return struct{}{}, backoff.Permanent(err)
+		}
+
+		return struct{}{}, err
+	}, backoff.WithBackOff(b), backoff.WithMaxTries(maxTries))
+
+	return err
+}
+
+// StatusError is a callback rejected by its receiver.
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("callback answered %d", e.Code)
+}
+
+func (s *Sender) post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Secret != "" {
+		timestamp := time.Now().Unix()
+		req.Header.Set(auth.TimestampHeader, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(auth.SignatureHeader, auth.Sign(s.Secret, timestamp, body))
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode >= 300 {
+		return &StatusError{Code: resp.StatusCode}
+	}
+
+	return nil
+}
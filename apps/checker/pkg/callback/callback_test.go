@@ -0,0 +1,89 @@
+package callback
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/auth"
+)
+
+func TestSender_Send(t *testing.T) {
+	verifier := auth.NewHMAC([]string{"secret"}, time.Minute)
+	received := make(chan map[string]any, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := verifier.Authenticate(r); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		received <- body
+	}))
+	defer srv.Close()
+
+	s := &Sender{Secret: "secret"}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.Send(ctx, srv.URL, map[string]any{"monitorId": "1"})
+	// The delivery outlives the request of the check.
+	cancel()
+	s.Wait()
+
+	select {
+	case body := <-received:
+		assert.Equal(t, "1", body["monitorId"])
+	default:
+		t.Fatal("callback not delivered")
+	}
+}
+
+func TestSender_Unsigned(t *testing.T) {
+	var signature string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(auth.SignatureHeader)
+	}))
+	defer srv.Close()
+
+	require.NoError(t, (&Sender{}).Deliver(context.Background(), srv.URL, []byte(`{}`)))
+	assert.Empty(t, signature)
+}
+
+func TestSender_Deliver(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		wantErr  bool
+		calls    int32
+	}{
+		{name: "delivered", statuses: []int{http.StatusOK}, calls: 1},
+		{name: "retried", statuses: []int{http.StatusServiceUnavailable, http.StatusNoContent}, calls: 2},
+		{name: "rejected", statuses: []int{http.StatusBadRequest}, wantErr: true, calls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := calls.Add(1)
+				w.WriteHeader(tt.statuses[min(int(n), len(tt.statuses))-1])
+			}))
+			defer srv.Close()
+
+			err := (&Sender{Secret: "secret"}).Deliver(context.Background(), srv.URL, []byte(`{}`))
+			if tt.wantErr {
+				var status *StatusError
+				require.ErrorAs(t, err, &status)
+				assert.Equal(t, http.StatusBadRequest, status.Code)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.calls, calls.Load())
+		})
+	}
+}
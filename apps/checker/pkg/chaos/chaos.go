@@ -0,0 +1,153 @@
+// Package chaos injects latency and failures into the checks of chosen
+// monitors, so the alerting pipeline and the status pages can be exercised
+// end to end without breaking real targets. It is meant for test
+// deployments only.
+package chaos
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+)
+
+// AllMonitors is the monitor ID of the fault of the monitors without one of
+// their own.
+const AllMonitors = "*"
+
+// Fault is what the checks of a monitor go through.
+type Fault struct {
+	MonitorID string `json:"monitorId"`
+	// Latency is added before each attempt, e.g. "2s".
+	Latency string `json:"latency,omitempty"`
+	// FailureRate is the probability of an attempt failing, from 0 to 1.
+	FailureRate float64 `json:"failureRate,omitempty"`
+	// Error is the class of the failures, timeout by default.
+	Error checker.ErrorClass `json:"error,omitempty"`
+
+	latency time.Duration
+}
+
+// injectable are the error classes a fault may fail with.
+var injectable = map[checker.ErrorClass]string{
+	checker.ErrorClassDNS:               "no such host",
+	checker.ErrorClassConnectionRefused: "connection refused",
+	checker.ErrorClassTimeout:           "i/o timeout",
+	checker.ErrorClassTLS:               "tls: handshake failure",
+	checker.ErrorClassHTTP5xx:           "503 Service Unavailable",
+	checker.ErrorClassHTTP4xx:           "404 Not Found",
+	checker.ErrorClassUnknown:           "unexpected failure",
+}
+
+func (f *Fault) parse() error {
+	if f.Latency != "" {
+		d, err := time.ParseDuration(f.Latency)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid latency %q", f.Latency)
+		}
+		f.latency = d
+	}
+	if f.FailureRate < 0 || f.FailureRate > 1 {
+		return fmt.Errorf("invalid failureRate %v, must be between 0 and 1", f.FailureRate)
+	}
+	if f.Error == "" {
+		f.Error = checker.ErrorClassTimeout
+	}
+	if _, ok := injectable[f.Error]; !ok {
+		return fmt.Errorf("invalid error %q", f.Error)
+	}
+
+	return nil
+}
+
+type Config struct {
+	Faults []Fault `json:"faults"`
+}
+
+// LoadConfig reads the faults of the file at path.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("unable to read config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("unable to decode config: %w", err)
+	}
+
+	seen := make(map[string]struct{}, len(cfg.Faults))
+	for i := range cfg.Faults {
+		f := &cfg.Faults[i]
+		if f.MonitorID == "" {
+			return Config{}, fmt.Errorf("fault %d: monitorId is required", i)
+		}
+		if _, ok := seen[f.MonitorID]; ok {
+			return Config{}, fmt.Errorf("fault %s: duplicate monitorId", f.MonitorID)
+		}
+		seen[f.MonitorID] = struct{}{}
+
+		if err := f.parse(); err != nil {
+			return Config{}, fmt.Errorf("fault %s: %w", f.MonitorID, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// Monkey injects the faults of a config.
+//
+// A nil *Monkey is valid and injects nothing.
+type Monkey struct {
+	faults map[string]Fault
+	// random returns a number in [0, 1).
+	random func() float64
+}
+
+// New injects faults, validated by LoadConfig.
+func New(faults []Fault) *Monkey {
+	m := &Monkey{faults: make(map[string]Fault, len(faults)), random: rand.Float64}
+	for _, f := range faults {
+		m.faults[f.MonitorID] = f
+	}
+
+	return m
+}
+
+// Inject delays an attempt of the check of monitorID by the latency of its
+// fault and returns the failure it is to report instead of running, nil to
+// run it.
+func (m *Monkey) Inject(ctx context.Context, monitorID string) error {
+	if m == nil {
+		return nil
+	}
+	f, ok := m.faults[monitorID]
+	if !ok {
+		if f, ok = m.faults[AllMonitors]; !ok {
+			return nil
+		}
+	}
+
+	if f.latency > 0 {
+		t := time.NewTimer(f.latency)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-t.C:
+		}
+	}
+
+	if f.FailureRate == 0 || m.random() >= f.FailureRate {
+		return nil
+	}
+
+	return &checker.ClassifiedError{
+		Err:   fmt.Errorf("chaos: %s", injectable[f.Error]),
+		Class: f.Error,
+	}
+}
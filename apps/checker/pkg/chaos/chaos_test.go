@@ -0,0 +1,59 @@
+package chaos
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+)
+
+func TestLoadConfig(t *testing.T) {
+	load := func(t *testing.T, content string) (Config, error) {
+		path := filepath.Join(t.TempDir(), "chaos.json")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return LoadConfig(path)
+	}
+
+	cfg, err := load(t, `{"faults": [{"monitorId": "1", "latency": "2s", "failureRate": 0.5}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, cfg.Faults[0].latency)
+	assert.Equal(t, checker.ErrorClassTimeout, cfg.Faults[0].Error)
+
+	_, err = load(t, `{"faults": [{"monitorId": "1", "failureRate": 2}]}`)
+	assert.ErrorContains(t, err, "invalid failureRate")
+	_, err = load(t, `{"faults": [{"monitorId": "1", "error": "assertion"}]}`)
+	assert.ErrorContains(t, err, "invalid error")
+	_, err = load(t, `{"faults": [{"monitorId": "1", "latency": "soon"}]}`)
+	assert.ErrorContains(t, err, "invalid latency")
+	_, err = load(t, `{"faults": [{"monitorId": "1"}, {"monitorId": "1"}]}`)
+	assert.ErrorContains(t, err, "duplicate monitorId")
+}
+
+func TestMonkey_Inject(t *testing.T) {
+	m := New([]Fault{
+		{MonitorID: "1", FailureRate: 1, Error: checker.ErrorClassDNS},
+		{MonitorID: "2", FailureRate: 0.5, Error: checker.ErrorClassTLS},
+		{MonitorID: AllMonitors, latency: time.Hour},
+	})
+
+	err := m.Inject(t.Context(), "1")
+	assert.Equal(t, checker.ErrorClassDNS, checker.ClassifyError(err))
+
+	m.random = func() float64 { return 0.7 }
+	assert.NoError(t, m.Inject(t.Context(), "2"))
+	m.random = func() float64 { return 0.2 }
+	assert.Equal(t, checker.ErrorClassTLS, checker.ClassifyError(m.Inject(t.Context(), "2")))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, m.Inject(ctx, "3"), context.DeadlineExceeded, "the latency of every other monitor")
+
+	var none *Monkey
+	assert.NoError(t, none.Inject(t.Context(), "1"))
+}
@@ -0,0 +1,121 @@
+// Package circuit keeps track of monitors that keep failing so the checker
+// can stop hammering targets that are hard down.
+package circuit
+
+import (
+	"sync"
+	"time"
+)
+
+// StatusOpen is reported instead of running the check while the circuit of a
+// monitor is open.
+const StatusOpen = "circuit_open"
+
+type state struct {
+	openedAt time.Time
+	failures int
+	trial    bool
+}
+
+// Breaker counts consecutive failures per key (usually the monitor id). Once
+// the threshold is reached the circuit opens and Allow returns false until the
+// cooldown has elapsed, after which a single trial attempt is let through.
+//
+// A nil *Breaker is valid and always allows the check.
+type Breaker struct {
+	now       func() time.Time
+	states    map[string]*state
+	cooldown  time.Duration
+	threshold int
+	mu        sync.Mutex
+}
+
+func New(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		states:    make(map[string]*state),
+	}
+}
+
+// Allow reports whether a check for key should run. When it returns false,
+// the second value is the time left before the next trial attempt.
+func (b *Breaker) Allow(key string) (bool, time.Duration) {
+	if b == nil || b.threshold <= 0 || key == "" {
+		return true, 0
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, ok := b.states[key]
+	if !ok || s.failures < b.threshold {
+		return true, 0
+	}
+
+	elapsed := b.now().Sub(s.openedAt)
+	if elapsed < b.cooldown {
+		return false, b.cooldown - elapsed
+	}
+
+	// Half open: only one trial at a time, the others keep short-circuiting
+	// until it reports back.
+	if s.trial {
+		return false, b.cooldown
+	}
+	s.trial = true
+
+	return true, 0
+}
+
+// Success closes the circuit for key.
+func (b *Breaker) Success(key string) {
+	if b == nil || key == "" {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.states, key)
+}
+
+// Release ends the trial of key without an outcome, e.g. when its check was
+// cancelled, so the next check is let through as the trial instead of the
+// circuit staying half open forever.
+func (b *Breaker) Release(key string) {
+	if b == nil || key == "" {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if s, ok := b.states[key]; ok {
+		s.trial = false
+	}
+}
+
+// Failure records a failed check for key and opens the circuit once the
+// threshold is reached. A failed trial re-opens it for a full cooldown.
+func (b *Breaker) Failure(key string) {
+	if b == nil || key == "" {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s, ok := b.states[key]
+	if !ok {
+		s = &state{}
+		b.states[key] = s
+	}
+
+	s.failures++
+	s.trial = false
+	if s.failures >= b.threshold {
+		s.openedAt = b.now()
+	}
+}
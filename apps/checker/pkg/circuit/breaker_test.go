@@ -0,0 +1,71 @@
+package circuit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Now()
+	b := New(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	t.Run("closed until threshold is reached", func(t *testing.T) {
+		b.Failure("1")
+		ok, _ := b.Allow("1")
+		assert.True(t, ok)
+
+		b.Failure("1")
+		ok, wait := b.Allow("1")
+		assert.False(t, ok)
+		assert.Equal(t, time.Minute, wait)
+	})
+
+	t.Run("other monitors are not affected", func(t *testing.T) {
+		ok, _ := b.Allow("2")
+		assert.True(t, ok)
+	})
+
+	t.Run("single trial after cooldown", func(t *testing.T) {
+		now = now.Add(time.Minute)
+
+		ok, _ := b.Allow("1")
+		assert.True(t, ok)
+		ok, _ = b.Allow("1")
+		assert.False(t, ok)
+	})
+
+	t.Run("failed trial re-opens the circuit", func(t *testing.T) {
+		b.Failure("1")
+		ok, wait := b.Allow("1")
+		assert.False(t, ok)
+		assert.Equal(t, time.Minute, wait)
+	})
+
+	t.Run("released trial lets the next one through", func(t *testing.T) {
+		now = now.Add(time.Minute)
+
+		ok, _ := b.Allow("1")
+		assert.True(t, ok)
+		b.Release("1")
+		ok, _ = b.Allow("1")
+		assert.True(t, ok)
+		ok, _ = b.Allow("1")
+		assert.False(t, ok)
+	})
+
+	t.Run("success closes the circuit", func(t *testing.T) {
+		b.Success("1")
+		ok, _ := b.Allow("1")
+		assert.True(t, ok)
+	})
+
+	t.Run("nil breaker always allows", func(t *testing.T) {
+		var nb *Breaker
+		nb.Failure("1")
+		ok, _ := nb.Allow("1")
+		assert.True(t, ok)
+	})
+}
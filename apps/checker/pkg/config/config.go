@@ -0,0 +1,175 @@
+// Package config reads the settings of the checker from a YAML or TOML file
+// instead of loose environment variables. Each setting of the file stands for
+// the environment variable of the same name in upper case, nested keys being
+// joined with an underscore: "rate_limit: {per_minute: 60}" is
+// RATE_LIMIT_PER_MINUTE=60. The environment overrides the file.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Settings lists the settings of the checker, the file may not set any other.
+var Settings = []string{
+	"AUDIT_LOG_DATASOURCE", "AUDIT_LOG_FILE", "AUTH_MODE",
+	"AWS_ACCESS_KEY_ID", "AWS_REGION", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN",
+	"AXIOM_DATASET", "AXIOM_TOKEN",
+	"CHAOS_CONFIG", "CIRCUIT_BREAKER_COOLDOWN", "CIRCUIT_BREAKER_THRESHOLD",
+	"CLOUD_PROVIDER", "CRON_SECRET", "CRON_SECRET_PREVIOUS",
+	"DRAIN_TIMEOUT", "EGRESS_IPS", "EVENT_HUBS_CONNECTION_STRING", "FIXTURE_RECORD",
+	"FLY_REGION", "KOYEB_REGION", "RAILWAY_REPLICA_REGION", "REGION",
+	"GCP_CLIENT_EMAIL", "GCP_PRIVATE_KEY", "GCP_PRIVATE_KEY_ID", "GCP_PROJECT_ID",
+	"GEOIP_DATABASES",
+	"HEARTBEAT_CONFIG",
+	"HMAC_SECRET", "HMAC_SECRET_PREVIOUS", "HMAC_WINDOW",
+	"IDEMPOTENCY_TTL",
+	"JOB_QUEUE_TOKEN", "JOB_QUEUE_URL", "JOB_QUEUE_WORKERS",
+	"JWKS_REFRESH", "JWKS_URL", "JWT_AUDIENCE", "JWT_ISSUER",
+	"KINESIS_STREAM",
+	"LOG_LEVEL", "LOKI_AUTHORIZATION", "LOKI_TENANT_ID", "LOKI_URL",
+	"NODE_NAME", "PEER_URLS", "POD_LABELS_FILE", "PORT",
+	"RATE_LIMIT_BURST", "RATE_LIMIT_PER_MINUTE",
+	"SCHEDULE_CONFIG", "SCHEDULE_JITTER", "SCHEDULE_REFRESH",
+	"SENTRY_DSN", "SENTRY_ENVIRONMENT", "SHUTDOWN_DELAY",
+	"SSRF_ALLOWLIST", "SSRF_PROTECTION", "STATSD_ADDR", "STATSD_PREFIX",
+	"STATUS_QUEUE_DIR", "STATUS_QUEUE_SIZE",
+	"STATUS_UPDATE_AUTHORIZATION", "STATUS_UPDATE_URL",
+	"TINYBIRD_TOKEN", "TINYBIRD_URL",
+	"TLS_CERT_FILE", "TLS_CLIENT_CA_FILE", "TLS_CLIENT_NAMES", "TLS_KEY_FILE",
+}
+
+// Load reads the settings of the file at path, YAML unless its extension is
+// .toml. Unknown settings are rejected, so a typo does not silently leave a
+// setting to its default.
+func Load(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read config: %w", err)
+	}
+
+	var raw map[string]any
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = toml.Unmarshal(data, &raw)
+	} else {
+		err = yaml.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode config: %w", err)
+	}
+
+	settings := make(map[string]string)
+	if err := flatten(settings, "", raw); err != nil {
+		return nil, err
+	}
+
+	var unknown []string
+	for name := range settings {
+		if !slices.Contains(Settings, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		slices.Sort(unknown)
+		return nil, fmt.Errorf("unknown settings: %s", strings.Join(unknown, ", "))
+	}
+
+	return settings, nil
+}
+
+func flatten(settings map[string]string, prefix string, raw map[string]any) error {
+	for key, value := range raw {
+		name := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		if nested, ok := value.(map[string]any); ok {
+			if err := flatten(settings, name, nested); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, ok := settings[name]; ok {
+			return fmt.Errorf("duplicate setting %s", name)
+		}
+		s, err := scalar(value)
+		if err != nil {
+			return fmt.Errorf("setting %s: %w", name, err)
+		}
+		settings[name] = s
+	}
+
+	return nil
+}
+
+// scalar formats a value like its environment variable, lists being comma
+// separated.
+func scalar(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			s, err := scalar(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", v)
+	}
+}
+
+// File is a config file applied to the environment. The variables set before
+// its first Apply are left as is, the others follow the file.
+type File struct {
+	Path string
+	// applied are the variables set from the file.
+	applied map[string]bool
+}
+
+// Apply loads the file and sets the variables of its settings, unsetting the
+// ones it set before and the file does not anymore. The environment is left
+// as is when the file is invalid.
+func (f *File) Apply() error {
+	settings, err := Load(f.Path)
+	if err != nil {
+		return err
+	}
+	if f.applied == nil {
+		f.applied = make(map[string]bool)
+	}
+
+	for name := range f.applied {
+		if _, ok := settings[name]; !ok {
+			_ = os.Unsetenv(name)
+			delete(f.applied, name)
+		}
+	}
+	for name, value := range settings {
+		if _, ok := os.LookupEnv(name); ok && !f.applied[name] {
+			continue
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return err
+		}
+		f.applied[name] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/config"
+)
+
+func write(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	expected := map[string]string{
+		"REGION":                "ams",
+		"CRON_SECRET":           "secret",
+		"RATE_LIMIT_PER_MINUTE": "60",
+		"RATE_LIMIT_BURST":      "10",
+		"SSRF_PROTECTION":       "false",
+		"EGRESS_IPS":            "192.0.2.1,192.0.2.2",
+	}
+
+	t.Run("yaml", func(t *testing.T) {
+		settings, err := config.Load(write(t, "checker.yaml", `
+region: ams
+cron_secret: secret
+rate_limit:
+  per_minute: 60
+  burst: 10
+ssrf_protection: false
+egress_ips: [192.0.2.1, 192.0.2.2]
+`))
+		require.NoError(t, err)
+		assert.Equal(t, expected, settings)
+	})
+
+	t.Run("toml", func(t *testing.T) {
+		settings, err := config.Load(write(t, "checker.toml", `
+region = "ams"
+cron_secret = "secret"
+ssrf_protection = false
+egress_ips = ["192.0.2.1", "192.0.2.2"]
+
+[rate_limit]
+per_minute = 60
+burst = 10
+`))
+		require.NoError(t, err)
+		assert.Equal(t, expected, settings)
+	})
+
+	t.Run("unknown settings", func(t *testing.T) {
+		_, err := config.Load(write(t, "checker.yaml", "regoin: ams\nrate_limit:\n  per_hour: 1\n"))
+		assert.EqualError(t, err, "unknown settings: RATE_LIMIT_PER_HOUR, REGOIN")
+	})
+
+	t.Run("duplicate settings", func(t *testing.T) {
+		_, err := config.Load(write(t, "checker.yaml", "rate_limit_burst: 1\nrate_limit:\n  burst: 2\n"))
+		assert.EqualError(t, err, "duplicate setting RATE_LIMIT_BURST")
+	})
+}
+
+func TestFile_Apply(t *testing.T) {
+	t.Setenv("REGION", "iad")
+	t.Setenv("LOG_LEVEL", "")
+	t.Setenv("PORT", "")
+	os.Unsetenv("LOG_LEVEL")
+	os.Unsetenv("PORT")
+
+	f := config.File{Path: write(t, "checker.yaml", "region: ams\nlog_level: debug\nport: 8081\n")}
+	require.NoError(t, f.Apply())
+	assert.Equal(t, "iad", os.Getenv("REGION"))
+	assert.Equal(t, "debug", os.Getenv("LOG_LEVEL"))
+	assert.Equal(t, "8081", os.Getenv("PORT"))
+
+	require.NoError(t, os.WriteFile(f.Path, []byte("region: ams\nlog_level: warn\n"), 0o600))
+	require.NoError(t, f.Apply())
+	assert.Equal(t, "iad", os.Getenv("REGION"))
+	assert.Equal(t, "warn", os.Getenv("LOG_LEVEL"))
+	_, ok := os.LookupEnv("PORT")
+	assert.False(t, ok)
+
+	require.NoError(t, os.WriteFile(f.Path, []byte("log_levle: info\n"), 0o600))
+	assert.Error(t, f.Apply())
+	assert.Equal(t, "warn", os.Getenv("LOG_LEVEL"))
+}
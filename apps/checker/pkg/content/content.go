@@ -0,0 +1,228 @@
+// Package content extracts the text of the parts of a page a content check
+// watches, and diffs it with the text of the previous run, so defacements
+// and unexpected edits show up even when the page still answers 200.
+package content
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+// MaxBytes caps the text extracted from a page.
+const MaxBytes = 64 << 10
+
+// blocks are the elements whose text starts a new line.
+var blocks = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true,
+	"dd": true, "div": true, "dl": true, "dt": true, "figcaption": true, "footer": true,
+	"form": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "li": true, "main": true, "nav": true, "ol": true,
+	"p": true, "pre": true, "section": true, "table": true, "td": true, "th": true,
+	"tr": true, "ul": true,
+}
+
+// Extract returns the text of the elements of the page matching sel, a line
+// per block, without the empty ones. Elements nested in another match are
+// only extracted once.
+func Extract(page io.Reader, sel Selector) (string, error) {
+	doc, err := html.Parse(page)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if sel.Match(n) {
+			text(&b, n)
+			b.WriteByte('\n')
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	var lines []string
+	size := 0
+	for line := range strings.SplitSeq(b.String(), "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			continue
+		}
+		if size += len(line) + 1; size > MaxBytes {
+			break
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+var newlines = strings.NewReplacer("\n", " ", "\r", " ")
+
+func text(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		// Only the blocks break lines, not the wrapping of the source.
+		b.WriteString(newlines.Replace(n.Data))
+		return
+	case html.ElementNode:
+		switch n.Data {
+		case "script", "style", "noscript", "template":
+			return
+		}
+	}
+
+	block := n.Type == html.ElementNode && blocks[n.Data]
+	if block {
+		b.WriteByte('\n')
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		text(b, c)
+	}
+	if block {
+		b.WriteByte('\n')
+	}
+}
+
+// Hash returns the hex SHA-256 of text.
+func Hash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// maxDiffLines bounds the lines compared, the diff of longer texts only
+// listing the lines not in the other text.
+const maxDiffLines = 2000
+
+// MaxDiffEntries caps the lines of each side of a Diff.
+const MaxDiffEntries = 50
+
+// Diff lists the lines of a text added and removed since the previous one.
+type Diff struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+	// Truncated is set when there were more than MaxDiffEntries lines on a
+	// side.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Compare returns the lines of current added and removed since previous, by
+// a longest common subsequence of their lines.
+func Compare(previous, current string) Diff {
+	a, b := lines(previous), lines(current)
+
+	var d Diff
+	if len(a) > maxDiffLines || len(b) > maxDiffLines {
+		d.Removed = missing(a, b)
+		d.Added = missing(b, a)
+	} else {
+		d.Removed, d.Added = lcsDiff(a, b)
+	}
+
+	if len(d.Added) > MaxDiffEntries {
+		d.Added, d.Truncated = d.Added[:MaxDiffEntries], true
+	}
+	if len(d.Removed) > MaxDiffEntries {
+		d.Removed, d.Truncated = d.Removed[:MaxDiffEntries], true
+	}
+
+	return d
+}
+
+func lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, "\n")
+}
+
+// lcsDiff returns the lines of a not in b and the ones of b not in a, in
+// order.
+func lcsDiff(a, b []string) (removed, added []string) {
+	// lcs[i][j] is the length of the LCS of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			removed = append(removed, a[i])
+			i++
+		default:
+			added = append(added, b[j])
+			j++
+		}
+	}
+
+	return append(removed, a[i:]...), append(added, b[j:]...)
+}
+
+// missing returns the lines of a not in b.
+func missing(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, line := range b {
+		in[line] = true
+	}
+
+	var out []string
+	for _, line := range a {
+		if !in[line] {
+			out = append(out, line)
+		}
+	}
+
+	return out
+}
+
+// Store keeps the text of the last run of each monitor, for the checks whose
+// request does not carry the previous one.
+//
+// A nil *Store is valid and keeps nothing.
+type Store struct {
+	mu   sync.Mutex
+	last map[string]string
+}
+
+func NewStore() *Store {
+	return &Store{last: make(map[string]string)}
+}
+
+// Swap keeps text as the last one of monitorID and returns the previous one,
+// false when there was none.
+func (s *Store) Swap(monitorID, text string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.last[monitorID]
+	s.last[monitorID] = text
+
+	return previous, ok
+}
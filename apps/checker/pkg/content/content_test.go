@@ -0,0 +1,76 @@
+package content_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/content"
+)
+
+func TestExtract(t *testing.T) {
+	sel, err := content.ParseSelector("main")
+	require.NoError(t, err)
+
+	text, err := content.Extract(strings.NewReader(`<main>
+	<h1>Title</h1>
+	<p>Some   <b>bold</b>
+	text</p>
+	<script>var x = 1</script><style>p {}</style>
+	<ul><li>a</li><li>b</li></ul>
+	<main>nested</main>
+</main>`), sel)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Title\nSome bold text\na\nb\nnested", text)
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous string
+		current  string
+		want     content.Diff
+	}{
+		{"same", "a\nb", "a\nb", content.Diff{}},
+		{"first", "", "a", content.Diff{Added: []string{"a"}}},
+		{"changed line", "a\nb\nc", "a\nB\nc", content.Diff{Added: []string{"B"}, Removed: []string{"b"}}},
+		{"moved", "a\nb\nc", "b\nc\na", content.Diff{Added: []string{"a"}, Removed: []string{"a"}}},
+		{"appended", "a", "a\nb\nc", content.Diff{Added: []string{"b", "c"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, content.Compare(tt.previous, tt.current))
+		})
+	}
+}
+
+func TestCompare_Truncated(t *testing.T) {
+	var lines []string
+	for i := range 3000 {
+		lines = append(lines, fmt.Sprint(i))
+	}
+
+	diff := content.Compare("", strings.Join(lines, "\n"))
+
+	assert.Len(t, diff.Added, content.MaxDiffEntries)
+	assert.Empty(t, diff.Removed)
+	assert.True(t, diff.Truncated)
+}
+
+func TestStore(t *testing.T) {
+	s := content.NewStore()
+
+	_, ok := s.Swap("1", "a")
+	assert.False(t, ok)
+	previous, ok := s.Swap("1", "b")
+	assert.True(t, ok)
+	assert.Equal(t, "a", previous)
+
+	var none *content.Store
+	_, ok = none.Swap("1", "a")
+	assert.False(t, ok)
+}
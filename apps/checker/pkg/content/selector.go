@@ -0,0 +1,226 @@
+package content
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Selector is a parsed CSS selector, of the subset enough to point at the
+// content of a page: type, universal, #id, .class and [attr], [attr=value]
+// selectors, compounded and combined with the descendant and child
+// combinators, in comma separated groups. Attribute values may not hold
+// spaces, commas or >.
+type Selector struct {
+	groups [][]step
+}
+
+// step is a compound selector and how it relates to the previous step.
+type step struct {
+	compound compound
+	// child is the > combinator with the previous step, the descendant
+	// one otherwise.
+	child bool
+}
+
+type compound struct {
+	tag     string
+	id      string
+	classes []string
+	attrs   []attr
+}
+
+type attr struct {
+	name  string
+	value string
+	// exists matches the attribute whatever its value.
+	exists bool
+}
+
+var errEmpty = errors.New("empty selector")
+
+// ParseSelector parses s.
+func ParseSelector(s string) (Selector, error) {
+	var sel Selector
+	for group := range strings.SplitSeq(s, ",") {
+		steps, err := parseGroup(group)
+		if err != nil {
+			return Selector{}, fmt.Errorf("invalid selector %q: %w", s, err)
+		}
+		sel.groups = append(sel.groups, steps)
+	}
+
+	return sel, nil
+}
+
+func parseGroup(s string) ([]step, error) {
+	var steps []step
+	child := false
+	fields := strings.Fields(strings.ReplaceAll(s, ">", " > "))
+	if len(fields) == 0 {
+		return nil, errEmpty
+	}
+	for i, field := range fields {
+		if field == ">" {
+			if child || i == 0 || i == len(fields)-1 {
+				return nil, errors.New("misplaced >")
+			}
+			child = true
+			continue
+		}
+		c, err := parseCompound(field)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step{compound: c, child: child})
+		child = false
+	}
+
+	return steps, nil
+}
+
+func parseCompound(s string) (compound, error) {
+	var c compound
+	name := func(i int) (string, int) {
+		j := i
+		for j < len(s) && isNameByte(s[j]) {
+			j++
+		}
+		return s[i:j], j
+	}
+
+	i := 0
+	if s[0] == '*' {
+		i = 1
+	} else if isNameByte(s[0]) {
+		c.tag, i = name(0)
+		c.tag = strings.ToLower(c.tag)
+	}
+	for i < len(s) {
+		switch s[i] {
+		case '#', '.':
+			n, j := name(i + 1)
+			if n == "" {
+				return c, fmt.Errorf("missing name after %c", s[i])
+			}
+			if s[i] == '#' {
+				c.id = n
+			} else {
+				c.classes = append(c.classes, n)
+			}
+			i = j
+		case '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return c, errors.New("unclosed [")
+			}
+			a, err := parseAttr(s[i+1 : i+end])
+			if err != nil {
+				return c, err
+			}
+			c.attrs = append(c.attrs, a)
+			i += end + 1
+		default:
+			return c, fmt.Errorf("unsupported %q", s[i:])
+		}
+	}
+
+	return c, nil
+}
+
+func parseAttr(s string) (attr, error) {
+	name, value, ok := strings.Cut(s, "=")
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return attr{}, errors.New("missing attribute name")
+	}
+	if !ok {
+		return attr{name: name, exists: true}, nil
+	}
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+
+	return attr{name: name, value: value}, nil
+}
+
+func isNameByte(b byte) bool {
+	return b == '-' || b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// Match reports whether n matches the selector.
+func (s Selector) Match(n *html.Node) bool {
+	for _, steps := range s.groups {
+		if matchSteps(n, steps) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchSteps matches n against the last step and its ancestors against the
+// previous ones.
+func matchSteps(n *html.Node, steps []step) bool {
+	last := steps[len(steps)-1]
+	if !last.compound.match(n) {
+		return false
+	}
+	if len(steps) == 1 {
+		return true
+	}
+
+	for p := n.Parent; p != nil; p = p.Parent {
+		if matchSteps(p, steps[:len(steps)-1]) {
+			return true
+		}
+		if last.child {
+			return false
+		}
+	}
+
+	return false
+}
+
+func (c compound) match(n *html.Node) bool {
+	if n.Type != html.ElementNode || (c.tag != "" && n.Data != c.tag) {
+		return false
+	}
+	if c.id != "" && attribute(n, "id") != c.id {
+		return false
+	}
+	classes := strings.Fields(attribute(n, "class"))
+	for _, class := range c.classes {
+		if !slices.Contains(classes, class) {
+			return false
+		}
+	}
+	for _, a := range c.attrs {
+		value, ok := lookup(n, a.name)
+		if !ok || (!a.exists && value != a.value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func lookup(n *html.Node, name string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			return a.Val, true
+		}
+	}
+
+	return "", false
+}
+
+func attribute(n *html.Node, name string) string {
+	value, _ := lookup(n, name)
+
+	return value
+}
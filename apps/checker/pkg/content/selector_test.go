@@ -0,0 +1,56 @@
+package content_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/content"
+)
+
+const page = `<html><body>
+<div id="header" class="top bar"><a href="/" data-nav>Home</a></div>
+<main>
+  <article class="post"><h2>First</h2><p>One</p></article>
+  <section><article class="post featured"><h2>Second</h2></article></section>
+</main>
+<footer><p lang="en">Footer</p></footer>
+</body></html>`
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     string
+	}{
+		{"h2", "First\nSecond"},
+		{"#header", "Home"},
+		{"div.top.bar", "Home"},
+		{"a[data-nav]", "Home"},
+		{"p[lang=en]", "Footer"},
+		{`p[lang="en"]`, "Footer"},
+		{"main article h2", "First\nSecond"},
+		{"main > article h2", "First"},
+		{"main>article>h2", "First"},
+		{".featured, footer", "Second\nFooter"},
+		{"* > .featured", "Second"},
+		{"table", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			sel, err := content.ParseSelector(tt.selector)
+			require.NoError(t, err)
+			text, err := content.Extract(strings.NewReader(page), sel)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, text)
+		})
+	}
+}
+
+func TestParseSelector_Invalid(t *testing.T) {
+	for _, selector := range []string{"", " ", "main,", "> p", "main >", "main > > p", "p:first-child", "#", "[lang", "[=en]"} {
+		_, err := content.ParseSelector(selector)
+		assert.Error(t, err, selector)
+	}
+}
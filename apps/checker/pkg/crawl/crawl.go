@@ -0,0 +1,308 @@
+// Package crawl follows the links of a page, level by level, and checks that
+// each of them answers, to catch the broken links of a site, and the HTTP
+// sub-resources of its HTTPS pages, before its visitors do.
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	DefaultDepth       = 1
+	DefaultMaxLinks    = 100
+	DefaultConcurrency = 8
+)
+
+// maxPage caps the bytes of a page parsed for its links.
+const maxPage = 2 << 20
+
+// Config bounds a crawl.
+type Config struct {
+	// Depth is how many levels of links are checked: 1 checks the links of
+	// the page, 2 also the links of the pages of the same host it links
+	// to, and so on.
+	Depth int
+	// MaxLinks caps the links checked, the crawl being truncated beyond.
+	MaxLinks    int
+	Concurrency int
+	// Header is sent with every request.
+	Header http.Header
+}
+
+// Link is a link checked by a crawl.
+type Link struct {
+	URL string `json:"url"`
+	// Source is the page linking to URL.
+	Source string `json:"source"`
+	Error  string `json:"error,omitempty"`
+	Status int    `json:"status,omitempty"`
+	Depth  int    `json:"depth"`
+}
+
+// Broken reports whether the link did not answer with a 2xx or 3xx status.
+func (l Link) Broken() bool {
+	return l.Error != "" || l.Status < http.StatusOK || l.Status >= http.StatusBadRequest
+}
+
+// MixedContent is a sub-resource of an HTTPS page loaded over HTTP, which
+// browsers block or flag as insecure.
+type MixedContent struct {
+	// Page is the HTTPS page loading URL.
+	Page string `json:"page"`
+	URL  string `json:"url"`
+	// Element is the tag loading URL, e.g. img or script.
+	Element string `json:"element"`
+}
+
+// Result lists the links checked, in the order they were found, and the
+// mixed content of the pages crawled.
+type Result struct {
+	Links        []Link         `json:"links"`
+	MixedContent []MixedContent `json:"mixedContent,omitempty"`
+	// Truncated is set when more than MaxLinks links were found.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Broken returns the broken links of the result.
+func (r Result) Broken() []Link {
+	var broken []Link
+	for _, l := range r.Links {
+		if l.Broken() {
+			broken = append(broken, l)
+		}
+	}
+
+	return broken
+}
+
+func (c Config) withDefaults() Config {
+	if c.Depth <= 0 {
+		c.Depth = DefaultDepth
+	}
+	if c.MaxLinks <= 0 {
+		c.MaxLinks = DefaultMaxLinks
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = DefaultConcurrency
+	}
+
+	return c
+}
+
+// Crawl fetches the page at start, following its redirects, and checks its
+// links with client. The links do not follow redirects, a 3xx being a valid
+// answer, and only the pages of the host of start are crawled further, the
+// HTTPS ones being searched for mixed content. The error is the one of the
+// page at start, the ones of its links are reported with them.
+func Crawl(ctx context.Context, client *http.Client, start string, cfg Config) (Result, error) {
+	cfg = cfg.withDefaults()
+	root, err := url.Parse(start)
+	if err != nil {
+		return Result{}, err
+	}
+
+	status, found, err := fetch(ctx, client, cfg.Header, start, true)
+	if err != nil {
+		return Result{}, err
+	}
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		return Result{}, fmt.Errorf("unexpected status code %d", status)
+	}
+	var res Result
+	res.MixedContent = found.mixed
+
+	links := *client
+	links.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	seen := map[string]bool{strip(root): true}
+	enqueue := func(queue []Link, source string, depth int, urls []string) []Link {
+		for _, u := range urls {
+			if seen[u] {
+				continue
+			}
+			if len(res.Links)+len(queue) >= cfg.MaxLinks {
+				res.Truncated = true
+				break
+			}
+			seen[u] = true
+			queue = append(queue, Link{URL: u, Source: source, Depth: depth})
+		}
+		return queue
+	}
+
+	queue := enqueue(nil, start, 1, found.links)
+	for depth := 1; len(queue) > 0; depth++ {
+		pages := check(ctx, &links, cfg, queue, func(l Link) bool {
+			return depth < cfg.Depth && sameHost(root, l.URL)
+		})
+		res.Links = append(res.Links, queue...)
+
+		var next []Link
+		for i, l := range queue {
+			next = enqueue(next, l.URL, depth+1, pages[i].links)
+			res.MixedContent = append(res.MixedContent, pages[i].mixed...)
+		}
+		queue = next
+	}
+
+	return res, ctx.Err()
+}
+
+// check checks the links of queue concurrently, setting their status, and
+// returns the pages of those to crawl.
+func check(ctx context.Context, client *http.Client, cfg Config, queue []Link, crawl func(Link) bool) []page {
+	pages := make([]page, len(queue))
+	slots := make(chan struct{}, cfg.Concurrency)
+	var wg sync.WaitGroup
+	for i := range queue {
+		slots <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-slots }()
+			l := &queue[i]
+			status, found, err := fetch(ctx, client, cfg.Header, l.URL, crawl(*l))
+			l.Status = status
+			if err != nil {
+				l.Error = err.Error()
+			}
+			pages[i] = found
+		})
+	}
+	wg.Wait()
+
+	return pages
+}
+
+// page is what a crawl keeps of a page.
+type page struct {
+	links []string
+	mixed []MixedContent
+}
+
+// fetch gets u and returns its status, with its page when parse is set and
+// it is a successful HTML page.
+func fetch(ctx context.Context, client *http.Client, header http.Header, u string, parse bool) (int, page, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, page{}, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, page{}, err
+	}
+	defer res.Body.Close()
+
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if !parse || res.StatusCode >= http.StatusMultipleChoices || mediaType != "text/html" {
+		_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxPage))
+		return res.StatusCode, page{}, nil
+	}
+
+	p, err := extract(io.LimitReader(res.Body, maxPage), res.Request.URL)
+	if err != nil {
+		return res.StatusCode, page{}, fmt.Errorf("unable to parse page: %w", err)
+	}
+
+	return res.StatusCode, p, nil
+}
+
+// subresources are the attributes of the elements loading a sub-resource.
+var subresources = map[string]string{
+	"img": "src", "script": "src", "iframe": "src", "audio": "src", "video": "src",
+	"source": "src", "track": "src", "embed": "src", "object": "data", "link": "href",
+}
+
+// extract returns the http and https links of the page at base, resolved,
+// without their fragment and in document order, and its sub-resources
+// loaded over HTTP when base is HTTPS.
+func extract(r io.Reader, base *url.URL) (page, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return page{}, err
+	}
+
+	var p page
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if href, ok := attribute(n, "href"); ok && n.Data == "a" {
+				if u, err := base.Parse(href); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+					p.links = append(p.links, strip(u))
+				}
+			}
+			if src, ok := attribute(n, subresources[n.Data]); ok && base.Scheme == "https" && loads(n) {
+				if u, err := base.Parse(src); err == nil && u.Scheme == "http" {
+					p.mixed = append(p.mixed, MixedContent{Page: base.String(), URL: u.String(), Element: n.Data})
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	return p, nil
+}
+
+// loads reports whether the element loads its sub-resource, a link only
+// does for some relations.
+func loads(n *html.Node) bool {
+	if n.Data != "link" {
+		return true
+	}
+	rel, _ := attribute(n, "rel")
+	for r := range strings.FieldsSeq(strings.ToLower(rel)) {
+		switch r {
+		case "stylesheet", "icon", "preload", "modulepreload", "manifest":
+			return true
+		}
+	}
+
+	return false
+}
+
+func attribute(n *html.Node, name string) (string, bool) {
+	if name == "" {
+		return "", false
+	}
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			return strings.TrimSpace(a.Val), true
+		}
+	}
+
+	return "", false
+}
+
+// strip returns u without its fragment, with the root path of its host when
+// it has none, the form the links are compared in.
+func strip(u *url.URL) string {
+	v := *u
+	v.Fragment, v.RawFragment = "", ""
+	if v.Path == "" && v.Opaque == "" {
+		v.Path = "/"
+	}
+
+	return v.String()
+}
+
+func sameHost(root *url.URL, u string) bool {
+	v, err := url.Parse(u)
+
+	return err == nil && strings.EqualFold(v.Host, root.Host)
+}
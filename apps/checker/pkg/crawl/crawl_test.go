@@ -0,0 +1,123 @@
+package crawl_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/crawl"
+)
+
+func site(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<a href="/docs#intro">Docs</a> <a href="/missing">Missing</a> <a href="/moved">Moved</a>
+			<a href="mailto:hi@openstat.us">Mail</a> <a href="/docs">Docs again</a> <a href="/">Home</a>`)
+	})
+	mux.HandleFunc("/docs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<a href="deep">Deep</a> <a href="/gone">Gone</a>`)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/missing", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/deep", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<a href="/deeper">Deeper</a>`)
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func urls(links []crawl.Link) []string {
+	var out []string
+	for _, l := range links {
+		out = append(out, l.URL)
+	}
+
+	return out
+}
+
+func TestCrawl(t *testing.T) {
+	server := site(t)
+
+	res, err := crawl.Crawl(context.Background(), server.Client(), server.URL, crawl.Config{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{server.URL + "/docs", server.URL + "/missing", server.URL + "/moved"}, urls(res.Links))
+	assert.Equal(t, http.StatusMovedPermanently, res.Links[2].Status, "redirects are not followed")
+	if broken := res.Broken(); assert.Len(t, broken, 1) {
+		assert.Equal(t, http.StatusNotFound, broken[0].Status)
+		assert.Equal(t, server.URL, broken[0].Source)
+	}
+	assert.False(t, res.Truncated)
+}
+
+func TestCrawl_Depth(t *testing.T) {
+	server := site(t)
+
+	res, err := crawl.Crawl(context.Background(), server.Client(), server.URL, crawl.Config{Depth: 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{server.URL + "/docs", server.URL + "/missing", server.URL + "/moved", server.URL + "/deep", server.URL + "/gone"}, urls(res.Links))
+	assert.Equal(t, []string{server.URL + "/missing", server.URL + "/gone"}, urls(res.Broken()))
+	assert.Equal(t, server.URL+"/docs", res.Links[4].Source)
+	assert.Equal(t, 2, res.Links[4].Depth)
+}
+
+func TestCrawl_MaxLinks(t *testing.T) {
+	server := site(t)
+
+	res, err := crawl.Crawl(context.Background(), server.Client(), server.URL, crawl.Config{Depth: 3, MaxLinks: 2})
+	require.NoError(t, err)
+
+	assert.Len(t, res.Links, 2)
+	assert.True(t, res.Truncated)
+}
+
+func TestCrawl_StartPage(t *testing.T) {
+	server := site(t)
+
+	_, err := crawl.Crawl(context.Background(), server.Client(), server.URL+"/gone", crawl.Config{})
+	assert.ErrorContains(t, err, "unexpected status code 410")
+}
+
+func TestCrawl_MixedContent(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head>
+			<link rel="stylesheet" href="http://cdn.example/site.css">
+			<link rel="canonical" href="http://example.com/">
+			<script src="/app.js"></script>
+		</head><body>
+			<img src="http://cdn.example/logo.png">
+			<a href="/about">About</a>
+		</body></html>`)
+	})
+	mux.HandleFunc("/about", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<iframe src="http://video.example/embed"></iframe>`)
+	})
+	mux.HandleFunc("/app.js", func(w http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+
+	res, err := crawl.Crawl(context.Background(), server.Client(), server.URL, crawl.Config{Depth: 2})
+	require.NoError(t, err)
+
+	assert.Empty(t, res.Broken())
+	assert.Equal(t, []crawl.MixedContent{
+		{Page: server.URL, URL: "http://cdn.example/site.css", Element: "link"},
+		{Page: server.URL, URL: "http://cdn.example/logo.png", Element: "img"},
+		{Page: server.URL + "/about", URL: "http://video.example/embed", Element: "iframe"},
+	}, res.MixedContent)
+}
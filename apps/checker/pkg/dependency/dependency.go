@@ -0,0 +1,119 @@
+// Package dependency keeps the outcome of the checks of each cron tick, so a
+// monitor depending on a parent that is down reports the parent instead of
+// failing along with it.
+package dependency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// StatusSkipped is reported instead of an error by checks failing while one
+// of their parents is down in the same tick.
+const StatusSkipped = "skipped_dependency"
+
+// retention is how long the outcomes of a tick are kept.
+const retention = 10 * time.Minute
+
+type key struct {
+	monitorID     string
+	cronTimestamp int64
+}
+
+type outcome struct {
+	done    chan struct{}
+	created time.Time
+	down    bool
+}
+
+// Tracker records whether each monitor is down in a tick, the tick being the
+// cronTimestamp of its check.
+//
+// A nil *Tracker is valid and never reports a parent down.
+type Tracker struct {
+	now      func() time.Time
+	outcomes map[key]*outcome
+	// Wait bounds how long Down waits for the parents not checked yet.
+	wait time.Duration
+	mu   sync.Mutex
+}
+
+func New(wait time.Duration) *Tracker {
+	return &Tracker{now: time.Now, outcomes: make(map[key]*outcome), wait: wait}
+}
+
+// outcome returns the outcome of a monitor in a tick, creating it pending.
+// The lock must be held.
+func (t *Tracker) outcome(k key) *outcome {
+	o, ok := t.outcomes[k]
+	if !ok {
+		o = &outcome{done: make(chan struct{}), created: t.now()}
+		t.outcomes[k] = o
+	}
+
+	return o
+}
+
+// Record stores whether the monitor is down in the tick of cronTimestamp.
+func (t *Tracker) Record(monitorID string, cronTimestamp int64, down bool) {
+	if t == nil || monitorID == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	for k, o := range t.outcomes {
+		if now.Sub(o.created) > retention {
+			delete(t.outcomes, k)
+		}
+	}
+
+	o := t.outcome(key{monitorID, cronTimestamp})
+	select {
+	case <-o.done:
+		o.down = o.down || down
+	default:
+		o.down = down
+		close(o.done)
+	}
+}
+
+// Down returns the first of parents down in the tick of cronTimestamp. The
+// parents are usually checked at the same time as their children, so Down
+// waits for the ones not recorded yet, up to the wait of the tracker or until
+// ctx is done.
+func (t *Tracker) Down(ctx context.Context, parents []string, cronTimestamp int64) (string, bool) {
+	if t == nil || len(parents) == 0 {
+		return "", false
+	}
+
+	t.mu.Lock()
+	pending := make([]*outcome, len(parents))
+	for i, parent := range parents {
+		pending[i] = t.outcome(key{parent, cronTimestamp})
+	}
+	t.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, t.wait)
+	defer cancel()
+
+	for i, o := range pending {
+		select {
+		case <-o.done:
+		case <-ctx.Done():
+			continue
+		}
+
+		t.mu.Lock()
+		down := o.down
+		t.mu.Unlock()
+		if down {
+			return parents[i], true
+		}
+	}
+
+	return "", false
+}
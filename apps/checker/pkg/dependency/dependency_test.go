@@ -0,0 +1,48 @@
+package dependency_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/dependency"
+)
+
+func TestTracker_Down(t *testing.T) {
+	tracker := dependency.New(time.Second)
+	tracker.Record("1", 1000, false)
+	tracker.Record("2", 1000, true)
+	tracker.Record("3", 2000, true)
+
+	parent, down := tracker.Down(t.Context(), []string{"1", "2"}, 1000)
+	assert.True(t, down)
+	assert.Equal(t, "2", parent)
+
+	_, down = tracker.Down(t.Context(), []string{"1", "3"}, 1000)
+	assert.False(t, down, "only the parents down in the same tick count")
+
+	t.Run("waits for the parents", func(t *testing.T) {
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			tracker.Record("4", 3000, true)
+		}()
+		parent, down := tracker.Down(t.Context(), []string{"4"}, 3000)
+		assert.True(t, down)
+		assert.Equal(t, "4", parent)
+	})
+
+	t.Run("gives up on parents never checked", func(t *testing.T) {
+		start := time.Now()
+		_, down := dependency.New(100*time.Millisecond).Down(t.Context(), []string{"5"}, 3000)
+		assert.False(t, down)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("nil tracker", func(t *testing.T) {
+		var tracker *dependency.Tracker
+		tracker.Record("1", 1000, true)
+		_, down := tracker.Down(t.Context(), []string{"1"}, 1000)
+		assert.False(t, down)
+	})
+}
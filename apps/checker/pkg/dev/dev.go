@@ -0,0 +1,94 @@
+// Package dev stubs the services the checker reports to, Tinybird and the
+// status updates of the control plane, with an in-memory sink, so the checker
+// runs locally without production credentials.
+package dev
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+)
+
+// maxRecords is how many events and status updates the sink keeps, the
+// oldest ones being dropped first.
+const maxRecords = 1000
+
+// Event is an event of a check, as it would have been sent to Tinybird.
+type Event struct {
+	DataSource string    `json:"dataSource"`
+	Event      any       `json:"event"`
+	Received   time.Time `json:"received"`
+}
+
+// Snapshot is what the sink received, oldest first.
+type Snapshot struct {
+	Events        []Event              `json:"events"`
+	StatusUpdates []checker.UpdateData `json:"statusUpdates"`
+}
+
+// Sink keeps the events and status updates in memory, a tinybird.Client
+// and an http.Handler serving them: GET answers the Snapshot and DELETE
+// clears it.
+type Sink struct {
+	mu      sync.Mutex
+	events  []Event
+	updates []checker.UpdateData
+}
+
+func (s *Sink) SendEvent(_ context.Context, event any, dataSourceName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = keep(append(s.events, Event{DataSource: dataSourceName, Event: event, Received: time.Now().UTC()}))
+
+	return nil
+}
+
+// UpdateStatus keeps a status update, in place of checker.UpdateStatus.
+func (s *Sink) UpdateStatus(_ context.Context, data checker.UpdateData) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.updates = keep(append(s.updates, data))
+
+	return nil
+}
+
+func keep[T any](records []T) []T {
+	if len(records) > maxRecords {
+		return records[len(records)-maxRecords:]
+	}
+
+	return records
+}
+
+// Snapshot returns what the sink received so far.
+func (s *Sink) Snapshot() Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return Snapshot{
+		Events:        append([]Event{}, s.events...),
+		StatusUpdates: append([]checker.UpdateData{}, s.updates...),
+	}
+}
+
+func (s *Sink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(s.Snapshot())
+	case http.MethodDelete:
+		s.mu.Lock()
+		s.events, s.updates = nil, nil
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, DELETE")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,41 @@
+package dev_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/dev"
+)
+
+func TestSink(t *testing.T) {
+	sink := &dev.Sink{}
+	require.NoError(t, sink.SendEvent(t.Context(), map[string]any{"monitorId": "1"}, "ping_response__v8"))
+	require.NoError(t, sink.UpdateStatus(t.Context(), checker.UpdateData{MonitorId: "1", Status: "error"}))
+
+	w := httptest.NewRecorder()
+	sink.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dev/events", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got dev.Snapshot
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	if assert.Len(t, got.Events, 1) {
+		assert.Equal(t, "ping_response__v8", got.Events[0].DataSource)
+		assert.Equal(t, map[string]any{"monitorId": "1"}, got.Events[0].Event)
+	}
+	assert.Equal(t, []checker.UpdateData{{MonitorId: "1", Status: "error"}}, got.StatusUpdates)
+
+	w = httptest.NewRecorder()
+	sink.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/dev/events", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Empty(t, sink.Snapshot().Events)
+	assert.Empty(t, sink.Snapshot().StatusUpdates)
+
+	w = httptest.NewRecorder()
+	sink.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/dev/events", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
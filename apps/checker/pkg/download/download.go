@@ -0,0 +1,79 @@
+// Package download streams a file, e.g. a release artifact or an installer,
+// hashing it on the fly so its integrity is checked without holding it in
+// memory, and measures the throughput of the transfer.
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+)
+
+// Result is the outcome of a download.
+type Result struct {
+	Status int `json:"status"`
+	// Bytes is the size of the file downloaded, SHA256 its hex digest, unset
+	// when the download did not complete.
+	Bytes  int64  `json:"bytes"`
+	SHA256 string `json:"sha256,omitempty"`
+	// FirstByteMs is the time until the response headers, TransferMs the one
+	// of the body, whose Throughput is in bytes per second.
+	FirstByteMs int64 `json:"firstByteMs"`
+	TransferMs  int64 `json:"transferMs"`
+	Throughput  int64 `json:"throughput"`
+}
+
+// Download fetches rawURL with client and header, hashing its body as it
+// is read. It fails when the response is not successful or when the body
+// exceeds maxBytes, announced or not, before reading any further.
+func Download(ctx context.Context, client *http.Client, rawURL string, header http.Header, maxBytes int64) (Result, error) {
+	var res Result
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return res, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return res, err
+	}
+	defer resp.Body.Close()
+	res.Status = resp.StatusCode
+	res.FirstByteMs = time.Since(start).Milliseconds()
+
+	if class := checker.ClassifyStatus(resp.StatusCode); class != "" {
+		return res, &checker.ClassifiedError{Class: class, Err: fmt.Errorf("%s answered %d", rawURL, resp.StatusCode)}
+	}
+	if resp.ContentLength > maxBytes {
+		return res, &checker.ClassifiedError{Class: checker.ErrorClassAssertion, Err: fmt.Errorf("the file is %d bytes, over the cap of %d", resp.ContentLength, maxBytes)}
+	}
+
+	transfer := time.Now()
+	hash := sha256.New()
+	res.Bytes, err = io.Copy(hash, io.LimitReader(resp.Body, maxBytes+1))
+	elapsed := time.Since(transfer)
+	res.TransferMs = elapsed.Milliseconds()
+	if elapsed > 0 {
+		res.Throughput = int64(float64(res.Bytes) / elapsed.Seconds())
+	}
+	if err != nil {
+		return res, fmt.Errorf("download interrupted after %d bytes: %w", res.Bytes, err)
+	}
+	if res.Bytes > maxBytes {
+		return res, &checker.ClassifiedError{Class: checker.ErrorClassAssertion, Err: fmt.Errorf("the file exceeds the cap of %d bytes", maxBytes)}
+	}
+	res.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	return res, nil
+}
@@ -0,0 +1,71 @@
+package download_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/download"
+)
+
+var artifact = strings.Repeat("openstatus", 1000)
+
+func server(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/artifact.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(artifact)))
+		w.Write([]byte(artifact))
+	})
+	mux.HandleFunc("/chunked", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(artifact[:5000]))
+		w.(http.Flusher).Flush()
+		w.Write([]byte(artifact[5000:]))
+	})
+	s := httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestDownload(t *testing.T) {
+	s := server(t)
+	sum := sha256.Sum256([]byte(artifact))
+
+	res, err := download.Download(context.Background(), s.Client(), s.URL+"/artifact.tar.gz", http.Header{"Authorization": {"Bearer token"}}, 1<<20)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.Status)
+	assert.Equal(t, int64(len(artifact)), res.Bytes)
+	assert.Equal(t, hex.EncodeToString(sum[:]), res.SHA256)
+	assert.Positive(t, res.Throughput)
+
+	_, err = download.Download(context.Background(), s.Client(), s.URL+"/artifact.tar.gz", nil, 1<<20)
+	assert.Equal(t, checker.ErrorClassHTTP4xx, checker.ClassifyError(err))
+}
+
+func TestDownloadCap(t *testing.T) {
+	s := server(t)
+
+	res, err := download.Download(context.Background(), s.Client(), s.URL+"/artifact.tar.gz", http.Header{"Authorization": {"Bearer token"}}, 100)
+	assert.ErrorContains(t, err, "the file is 10000 bytes, over the cap of 100")
+	assert.Zero(t, res.Bytes, "an announced file over the cap is not read")
+
+	res, err = download.Download(context.Background(), s.Client(), s.URL+"/chunked", nil, 6000)
+	assert.ErrorContains(t, err, "the file exceeds the cap of 6000 bytes")
+	assert.Equal(t, int64(6001), res.Bytes, "the body is not read past the cap")
+	assert.Empty(t, res.SHA256)
+}
@@ -0,0 +1,221 @@
+// Package fixture records the interactions of checks with their targets and
+// replays them from an httptest server, so the handlers are tested end to end
+// against real answers without reaching the network.
+package fixture
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"time"
+)
+
+// Interaction is a request of a check and what answered it.
+type Interaction struct {
+	Method string `json:"method"`
+	// Path is the path of the request URL, its query included.
+	Path   string            `json:"path"`
+	Status int               `json:"status,omitempty"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   string            `json:"body,omitempty"`
+	// Latency is how long the answer took, in milliseconds.
+	Latency int64 `json:"latency,omitempty"`
+	// Error is set instead of the response when the request failed, the
+	// replay closing the connection without answering.
+	Error string `json:"error,omitempty"`
+}
+
+// Recorder records the interactions of the requests going through the
+// transports it wraps.
+//
+// A nil *Recorder is valid and records nothing.
+type Recorder struct {
+	mu           sync.Mutex
+	interactions []Interaction
+}
+
+// Wrap returns base recording its interactions, base as is for a nil
+// recorder.
+func (r *Recorder) Wrap(base http.RoundTripper) http.RoundTripper {
+	if r == nil {
+		return base
+	}
+
+	return &recording{base: base, recorder: r}
+}
+
+type recording struct {
+	base     http.RoundTripper
+	recorder *Recorder
+}
+
+func (t *recording) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	res, err := t.base.RoundTrip(req)
+	i := Interaction{Method: req.Method, Path: req.URL.RequestURI()}
+	if err != nil {
+		i.Error = err.Error()
+		i.Latency = time.Since(start).Milliseconds()
+		t.recorder.add(i)
+		return nil, err
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	i.Latency = time.Since(start).Milliseconds()
+	if err != nil {
+		i.Error = err.Error()
+		t.recorder.add(i)
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	i.Status = res.StatusCode
+	i.Body = string(body)
+	i.Header = make(map[string]string, len(res.Header))
+	for key := range res.Header {
+		i.Header[key] = res.Header.Get(key)
+	}
+	t.recorder.add(i)
+
+	return res, nil
+}
+
+// CloseIdleConnections closes the idle connections of the wrapped transport.
+func (t *recording) CloseIdleConnections() {
+	if c, ok := t.base.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
+
+func (r *Recorder) add(i Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.interactions = append(r.interactions, i)
+}
+
+// Interactions returns what was recorded so far, in order.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Interaction(nil), r.interactions...)
+}
+
+// Save writes what was recorded so far to the file at path, for Load.
+func (r *Recorder) Save(path string) error {
+	data, err := json.MarshalIndent(r.Interactions(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o600)
+}
+
+// Load reads the interactions of a file written by Save.
+func Load(path string) ([]Interaction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read fixture: %w", err)
+	}
+
+	var interactions []Interaction
+	if err := json.Unmarshal(data, &interactions); err != nil {
+		return nil, fmt.Errorf("unable to decode fixture: %w", err)
+	}
+
+	return interactions, nil
+}
+
+// Server replays interactions, each request getting the first interaction
+// not replayed yet with its method and path. Requests without one are
+// answered 404 and counted in Unmatched.
+type Server struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	interactions []Interaction
+	unmatched    int
+}
+
+// NewServer starts a server replaying interactions, to Close once done.
+func NewServer(interactions []Interaction) *Server {
+	s := &Server{interactions: append([]Interaction(nil), interactions...)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+
+	return s
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	// A client retries the idempotent requests of a reused connection that
+	// closes, which would hide the replayed errors.
+	w.Header().Set("Connection", "close")
+
+	i, ok := s.next(r.Method, r.URL.RequestURI())
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	if i.Latency > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(time.Duration(i.Latency) * time.Millisecond):
+		}
+	}
+
+	if i.Error != "" {
+		if hj, ok := w.(http.Hijacker); ok {
+			if conn, _, err := hj.Hijack(); err == nil {
+				conn.Close()
+			}
+		}
+		return
+	}
+
+	for key, value := range i.Header {
+		w.Header().Set(key, value)
+	}
+	if i.Status == 0 {
+		i.Status = http.StatusOK
+	}
+	w.WriteHeader(i.Status)
+	_, _ = io.WriteString(w, i.Body)
+}
+
+func (s *Server) next(method, path string) (Interaction, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for n, i := range s.interactions {
+		if i.Method == method && i.Path == path {
+			s.interactions = append(s.interactions[:n], s.interactions[n+1:]...)
+			return i, true
+		}
+	}
+	s.unmatched++
+
+	return Interaction{}, false
+}
+
+// Pending returns how many interactions were not replayed.
+func (s *Server) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.interactions)
+}
+
+// Unmatched returns how many requests had no interaction to replay.
+func (s *Server) Unmatched() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.unmatched
+}
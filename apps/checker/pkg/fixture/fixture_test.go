@@ -0,0 +1,68 @@
+package fixture_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/fixture"
+)
+
+func TestRecorder_Replay(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Target", r.URL.Path)
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = io.WriteString(w, "recorded")
+	}))
+
+	rec := &fixture.Recorder{}
+	client := &http.Client{Transport: rec.Wrap(http.DefaultTransport)}
+	res, err := client.Get(target.URL + "/a?b=c")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, "recorded", string(body), "the body is still read by the check")
+
+	target.Close()
+	_, err = client.Get(target.URL + "/down")
+	require.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	require.NoError(t, rec.Save(path))
+	interactions, err := fixture.Load(path)
+	require.NoError(t, err)
+	require.Len(t, interactions, 2)
+	assert.Equal(t, "/a?b=c", interactions[0].Path)
+	assert.NotEmpty(t, interactions[1].Error)
+
+	replay := fixture.NewServer(interactions)
+	defer replay.Close()
+
+	res, err = http.Get(replay.URL + "/a?b=c")
+	require.NoError(t, err)
+	body, _ = io.ReadAll(res.Body)
+	res.Body.Close()
+	assert.Equal(t, http.StatusTeapot, res.StatusCode)
+	assert.Equal(t, "/a", res.Header.Get("X-Target"))
+	assert.Equal(t, "recorded", string(body))
+
+	_, err = http.Get(replay.URL + "/down")
+	assert.Error(t, err, "the connection is closed without an answer")
+
+	res, err = http.Get(replay.URL + "/a?b=c")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode, "each interaction is replayed once")
+	assert.Equal(t, 0, replay.Pending())
+	assert.Equal(t, 1, replay.Unmatched())
+}
+
+func TestRecorder_Nil(t *testing.T) {
+	var rec *fixture.Recorder
+	assert.Equal(t, http.DefaultTransport, rec.Wrap(http.DefaultTransport))
+}
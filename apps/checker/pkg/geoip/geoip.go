@@ -0,0 +1,374 @@
+// Package geoip enriches the addresses checks connect to with their
+// autonomous system and location, read from MaxMind DB files such as
+// GeoLite2-ASN and GeoLite2-City, so a failure coinciding with a target
+// moving to another network or provider shows in its result.
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"os"
+)
+
+// Info describes the network and location of an address, the fields the
+// databases do not hold are left empty.
+type Info struct {
+	ASN          uint   `json:"asn,omitempty"`
+	Organization string `json:"organization,omitempty"`
+	Country      string `json:"country,omitempty"`
+	City         string `json:"city,omitempty"`
+}
+
+// DB looks addresses up in MaxMind DB files.
+//
+// A nil *DB is valid and knows no address.
+type DB struct {
+	readers []*reader
+}
+
+// Open reads the database files of paths, whose lookups are merged: e.g. an
+// ASN database and a City one.
+func Open(paths ...string) (*DB, error) {
+	db := &DB{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %w", path, err)
+		}
+		r, err := newReader(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid database %s: %w", path, err)
+		}
+		db.readers = append(db.readers, r)
+	}
+
+	return db, nil
+}
+
+// Lookup returns what the databases know of ip, nil when it is not a valid
+// address or none of them knows it.
+func (db *DB) Lookup(ip string) *Info {
+	if db == nil {
+		return nil
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil
+	}
+
+	var info Info
+	for _, r := range db.readers {
+		record, err := r.lookup(addr.Unmap())
+		if err != nil || record == nil {
+			continue
+		}
+		if asn, ok := path(record, "autonomous_system_number").(uint64); ok {
+			info.ASN = uint(asn)
+		}
+		if org, ok := path(record, "autonomous_system_organization").(string); ok {
+			info.Organization = org
+		}
+		if country, ok := path(record, "country", "iso_code").(string); ok {
+			info.Country = country
+		}
+		if city, ok := path(record, "city", "names", "en").(string); ok {
+			info.City = city
+		}
+	}
+	if info == (Info{}) {
+		return nil
+	}
+
+	return &info
+}
+
+// path returns the value at keys of nested maps, nil when there is none.
+func path(v any, keys ...string) any {
+	for _, key := range keys {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+
+	return v
+}
+
+// metadataStart precedes the metadata, at the end of the file.
+var metadataStart = []byte("\xab\xcd\xefMaxMind.com")
+
+// dataSeparator is the size of the zeros between the search tree and the
+// data section.
+const dataSeparator = 16
+
+var errInvalid = errors.New("invalid database")
+
+// reader walks the binary search tree of a MaxMind DB file, whose leaves
+// point into its data section.
+type reader struct {
+	tree       []byte
+	data       []byte
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	// ipv4Start is the node of ::/96 in an IPv6 tree, where IPv4 lookups
+	// start.
+	ipv4Start uint
+}
+
+func newReader(file []byte) (*reader, error) {
+	i := bytes.LastIndex(file, metadataStart)
+	if i < 0 {
+		return nil, errors.New("metadata not found")
+	}
+	meta, _, err := decoder{buf: file[i+len(metadataStart):]}.decode(0)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reader{}
+	for key, dst := range map[string]*uint{"node_count": &r.nodeCount, "record_size": &r.recordSize, "ip_version": &r.ipVersion} {
+		v, ok := path(meta, key).(uint64)
+		if !ok {
+			return nil, fmt.Errorf("%w: no %s", errInvalid, key)
+		}
+		*dst = uint(v)
+	}
+	if r.recordSize != 24 && r.recordSize != 28 && r.recordSize != 32 {
+		return nil, fmt.Errorf("%w: unsupported record size %d", errInvalid, r.recordSize)
+	}
+
+	treeSize := r.nodeCount * r.recordSize / 4
+	if treeSize+dataSeparator > uint(i) {
+		return nil, fmt.Errorf("%w: truncated search tree", errInvalid)
+	}
+	r.tree = file[:treeSize]
+	r.data = file[treeSize+dataSeparator : i]
+
+	if r.ipVersion == 6 {
+		for range 96 {
+			if r.ipv4Start >= r.nodeCount {
+				break
+			}
+			r.ipv4Start = r.record(r.ipv4Start, 0)
+		}
+	}
+
+	return r, nil
+}
+
+// record returns the left (bit 0) or right (bit 1) record of node.
+func (r *reader) record(node uint, bit byte) uint {
+	b := r.tree[node*r.recordSize/4:]
+	switch r.recordSize {
+	case 24:
+		b = b[uint(bit)*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xf0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0f)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		return uint(binary.BigEndian.Uint32(b[uint(bit)*4:]))
+	}
+}
+
+// lookup returns the record of addr, nil when the database has none.
+func (r *reader) lookup(addr netip.Addr) (any, error) {
+	ip := addr.AsSlice()
+	node := uint(0)
+	if addr.Is4() && r.ipVersion == 6 {
+		node = r.ipv4Start
+	} else if addr.Is6() && r.ipVersion == 4 {
+		return nil, nil
+	}
+
+	for i := 0; i < len(ip)*8 && node < r.nodeCount; i++ {
+		node = r.record(node, ip[i/8]>>(7-i%8)&1)
+	}
+	if node == r.nodeCount {
+		return nil, nil
+	}
+	if node < r.nodeCount {
+		return nil, fmt.Errorf("%w: search tree too deep", errInvalid)
+	}
+
+	offset := node - r.nodeCount - dataSeparator
+	v, _, err := decoder{buf: r.data}.decode(offset)
+
+	return v, err
+}
+
+// The types of the data section.
+const (
+	typeExtended = iota
+	typePointer
+	typeString
+	typeDouble
+	typeBytes
+	typeUint16
+	typeUint32
+	typeMap
+	typeInt32
+	typeUint64
+	typeUint128
+	typeArray
+	typeContainer
+	typeEndMarker
+	typeBool
+	typeFloat
+)
+
+// maxDepth bounds the nesting of the values, so a corrupted file does not
+// recurse forever.
+const maxDepth = 32
+
+// decoder decodes the values of a data section, maps to map[string]any,
+// arrays to []any, unsigned integers to uint64 and floats to float64.
+type decoder struct {
+	buf   []byte
+	depth int
+}
+
+func (d decoder) read(offset, n uint) ([]byte, error) {
+	if offset+n > uint(len(d.buf)) || offset+n < offset {
+		return nil, fmt.Errorf("%w: value out of bounds", errInvalid)
+	}
+
+	return d.buf[offset : offset+n], nil
+}
+
+// decode returns the value at offset and the offset following it.
+func (d decoder) decode(offset uint) (any, uint, error) {
+	if d.depth > maxDepth {
+		return nil, 0, fmt.Errorf("%w: values nested too deep", errInvalid)
+	}
+	d.depth++
+
+	b, err := d.read(offset, 1)
+	if err != nil {
+		return nil, 0, err
+	}
+	offset++
+	kind, size := uint(b[0]>>5), uint(b[0]&0x1f)
+
+	if kind == typePointer {
+		n := size>>3 + 1
+		b, err := d.read(offset, n)
+		if err != nil {
+			return nil, 0, err
+		}
+		pointer := uint(0)
+		if n < 4 {
+			pointer = size & 0x7
+		}
+		for _, c := range b {
+			pointer = pointer<<8 | uint(c)
+		}
+		pointer += [...]uint{0, 2048, 526336, 0}[n-1]
+		v, _, err := d.decode(pointer)
+
+		return v, offset + n, err
+	}
+
+	if kind == typeExtended {
+		b, err := d.read(offset, 1)
+		if err != nil {
+			return nil, 0, err
+		}
+		offset++
+		kind = 7 + uint(b[0])
+	}
+	if size >= 29 {
+		n := size - 28
+		b, err := d.read(offset, n)
+		if err != nil {
+			return nil, 0, err
+		}
+		offset += n
+		extra := uint(0)
+		for _, c := range b {
+			extra = extra<<8 | uint(c)
+		}
+		size = [...]uint{29, 285, 65821}[n-1] + extra
+	}
+
+	switch kind {
+	case typeMap:
+		m := make(map[string]any, min(size, 64))
+		for range size {
+			key, next, err := d.decode(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			s, ok := key.(string)
+			if !ok {
+				return nil, 0, fmt.Errorf("%w: map key not a string", errInvalid)
+			}
+			m[s], offset, err = d.decode(next)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+		return m, offset, nil
+	case typeArray:
+		a := make([]any, 0, min(size, 64))
+		for range size {
+			v, next, err := d.decode(offset)
+			if err != nil {
+				return nil, 0, err
+			}
+			a, offset = append(a, v), next
+		}
+		return a, offset, nil
+	case typeBool:
+		return size != 0, offset, nil
+	case typeContainer, typeEndMarker:
+		return nil, offset, nil
+	}
+
+	b, err = d.read(offset, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	offset += size
+	switch kind {
+	case typeString:
+		return string(b), offset, nil
+	case typeBytes:
+		return bytes.Clone(b), offset, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, fmt.Errorf("%w: double of %d bytes", errInvalid, size)
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), offset, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, fmt.Errorf("%w: float of %d bytes", errInvalid, size)
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), offset, nil
+	case typeUint16, typeUint32, typeUint64, typeInt32:
+		if size > 8 {
+			return nil, 0, fmt.Errorf("%w: integer of %d bytes", errInvalid, size)
+		}
+		v := uint64(0)
+		for _, c := range b {
+			v = v<<8 | uint64(c)
+		}
+		if kind == typeInt32 {
+			return int64(int32(v)), offset, nil
+		}
+		return v, offset, nil
+	case typeUint128:
+		// Too large for the fields read here.
+		return bytes.Clone(b), offset, nil
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown type %d", errInvalid, kind)
+	}
+}
@@ -0,0 +1,154 @@
+package geoip_test
+
+import (
+	"bytes"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/geoip"
+)
+
+// encode writes v in the format of the data section: maps, strings and
+// uint32 are enough for the tests.
+func encode(buf *bytes.Buffer, v any) {
+	control := func(kind, size int) {
+		if size < 29 {
+			buf.WriteByte(byte(kind<<5 | size))
+			return
+		}
+		buf.WriteByte(byte(kind<<5 | 29))
+		buf.WriteByte(byte(size - 29))
+	}
+
+	switch v := v.(type) {
+	case string:
+		control(2, len(v))
+		buf.WriteString(v)
+	case uint32:
+		b := []byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}
+		for len(b) > 0 && b[0] == 0 {
+			b = b[1:]
+		}
+		control(6, len(b))
+		buf.Write(b)
+	case map[string]any:
+		control(7, len(v))
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			encode(buf, k)
+			encode(buf, v[k])
+		}
+	}
+}
+
+type node struct {
+	children [2]*node
+	// leaves are the offsets in the data section plus one, 0 for none.
+	leaves [2]int
+}
+
+// build writes a database of 24 bits records mapping each network to its
+// record.
+func build(t *testing.T, ipVersion int, networks map[string]map[string]any) string {
+	t.Helper()
+
+	root := &node{}
+	var data bytes.Buffer
+	for network, record := range networks {
+		prefix := netip.MustParsePrefix(network)
+		ip := prefix.Addr().AsSlice()
+		offset := data.Len()
+		encode(&data, record)
+
+		n := root
+		for i := range prefix.Bits() {
+			bit := ip[i/8] >> (7 - i%8) & 1
+			if i == prefix.Bits()-1 {
+				n.leaves[bit] = offset + 1
+				break
+			}
+			if n.children[bit] == nil {
+				n.children[bit] = &node{}
+			}
+			n = n.children[bit]
+		}
+	}
+
+	var nodes []*node
+	index := make(map[*node]int)
+	for queue := []*node{root}; len(queue) > 0; queue = queue[1:] {
+		index[queue[0]] = len(nodes)
+		nodes = append(nodes, queue[0])
+		for _, c := range queue[0].children {
+			if c != nil {
+				queue = append(queue, c)
+			}
+		}
+	}
+
+	var file bytes.Buffer
+	for _, n := range nodes {
+		for bit := range 2 {
+			record := len(nodes)
+			if c := n.children[bit]; c != nil {
+				record = index[c]
+			} else if n.leaves[bit] > 0 {
+				record = len(nodes) + 16 + n.leaves[bit] - 1
+			}
+			file.Write([]byte{byte(record >> 16), byte(record >> 8), byte(record)})
+		}
+	}
+	file.Write(make([]byte, 16))
+	file.Write(data.Bytes())
+	file.WriteString("\xab\xcd\xefMaxMind.com")
+	encode(&file, map[string]any{
+		"node_count":  uint32(len(nodes)),
+		"record_size": uint32(24),
+		"ip_version":  uint32(ipVersion),
+	})
+
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	require.NoError(t, os.WriteFile(path, file.Bytes(), 0o600))
+
+	return path
+}
+
+func TestDB_Lookup(t *testing.T) {
+	asn := build(t, 6, map[string]map[string]any{
+		"::c000:200/120": {"autonomous_system_number": uint32(64496), "autonomous_system_organization": "Example Networks"},
+		"2001:db8::/32":  {"autonomous_system_number": uint32(64497), "autonomous_system_organization": "Example Six"},
+	})
+	city := build(t, 4, map[string]map[string]any{
+		"192.0.2.0/25": {"country": map[string]any{"iso_code": "NL"}, "city": map[string]any{"names": map[string]any{"en": "Amsterdam"}}},
+	})
+
+	db, err := geoip.Open(asn, city)
+	require.NoError(t, err)
+
+	assert.Equal(t, &geoip.Info{ASN: 64496, Organization: "Example Networks", Country: "NL", City: "Amsterdam"}, db.Lookup("192.0.2.1"))
+	assert.Equal(t, &geoip.Info{ASN: 64496, Organization: "Example Networks"}, db.Lookup("192.0.2.200"))
+	assert.Equal(t, &geoip.Info{ASN: 64497, Organization: "Example Six"}, db.Lookup("2001:db8::1"))
+	assert.Nil(t, db.Lookup("198.51.100.1"))
+	assert.Nil(t, db.Lookup("not an ip"))
+
+	var none *geoip.DB
+	assert.Nil(t, none.Lookup("192.0.2.1"))
+}
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "invalid.mmdb")
+	require.NoError(t, os.WriteFile(path, []byte("not a database"), 0o600))
+
+	_, err := geoip.Open(path)
+	assert.Error(t, err)
+}
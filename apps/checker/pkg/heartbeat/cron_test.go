@@ -0,0 +1,38 @@
+package heartbeat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule_Next(t *testing.T) {
+	// A Wednesday.
+	from := time.Date(2026, 1, 14, 10, 7, 30, 0, time.UTC)
+
+	for expr, want := range map[string]time.Time{
+		"* * * * *":         time.Date(2026, 1, 14, 10, 8, 0, 0, time.UTC),
+		"*/15 * * * *":      time.Date(2026, 1, 14, 10, 15, 0, 0, time.UTC),
+		"@hourly":           time.Date(2026, 1, 14, 11, 0, 0, 0, time.UTC),
+		"30 2 * * *":        time.Date(2026, 1, 15, 2, 30, 0, 0, time.UTC),
+		"0 9 * * 1-5":       time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC),
+		"0 0 * * 7":         time.Date(2026, 1, 18, 0, 0, 0, 0, time.UTC),
+		"0 0 1 * *":         time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		"0 0 13 * 5":        time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC),
+		"0 12 29 2 *":       time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC),
+		"5,10 8-10/2 * * *": time.Date(2026, 1, 14, 10, 10, 0, 0, time.UTC),
+	} {
+		t.Run(expr, func(t *testing.T) {
+			s, err := ParseSchedule(expr)
+			require.NoError(t, err)
+			assert.Equal(t, want, s.Next(from))
+		})
+	}
+
+	for _, expr := range []string{"* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := ParseSchedule(expr)
+		assert.Error(t, err, expr)
+	}
+}
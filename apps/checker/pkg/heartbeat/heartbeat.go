@@ -0,0 +1,264 @@
+// Package heartbeat monitors cron jobs and background workers that check in
+// with the checker: a heartbeat not pinged within its interval is reported
+// missed, like a dead man's switch.
+package heartbeat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// The statuses of an Event.
+const (
+	StatusMissed    = "missed"
+	StatusTimeout   = "timeout"
+	StatusCompleted = "completed"
+	StatusRecovered = "recovered"
+)
+
+// Heartbeat is pinged on /heartbeat/<Token> at least every Interval, or on
+// every run of the cron expression of Schedule, give or take Grace. Jobs
+// also pinging /heartbeat/<Token>/start when they start have their runs
+// timed, and reported when they last longer than MaxDuration.
+type Heartbeat struct {
+	Token       string `json:"token"`
+	MonitorID   string `json:"monitorId"`
+	WorkspaceID string `json:"workspaceId"`
+	Interval    string `json:"interval,omitempty"`
+	Schedule    string `json:"schedule,omitempty"`
+	Grace       string `json:"grace,omitempty"`
+	MaxDuration string `json:"maxDuration,omitempty"`
+
+	interval    time.Duration
+	schedule    *Schedule
+	grace       time.Duration
+	maxDuration time.Duration
+}
+
+func (hb *Heartbeat) parse() error {
+	switch {
+	case hb.Schedule != "" && hb.Interval != "":
+		return fmt.Errorf("interval and schedule are mutually exclusive")
+	case hb.Schedule != "":
+		s, err := ParseSchedule(hb.Schedule)
+		if err != nil {
+			return err
+		}
+		hb.schedule = &s
+	default:
+		d, err := time.ParseDuration(hb.Interval)
+		if err != nil || d < time.Second {
+			return fmt.Errorf("invalid interval %q", hb.Interval)
+		}
+		hb.interval = d
+	}
+
+	for _, f := range []struct {
+		name  string
+		value string
+		d     *time.Duration
+	}{
+		{"grace", hb.Grace, &hb.grace},
+		{"maxDuration", hb.MaxDuration, &hb.maxDuration},
+	} {
+		if f.value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(f.value)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid %s %q", f.name, f.value)
+		}
+		*f.d = d
+	}
+
+	return nil
+}
+
+// deadline is when the next ping is due at the latest, after a ping at last.
+// Schedules are evaluated in UTC.
+func (hb Heartbeat) deadline(last time.Time) time.Time {
+	if hb.schedule != nil {
+		next := hb.schedule.Next(last.UTC())
+		if next.IsZero() {
+			return next
+		}
+		return next.Add(hb.grace)
+	}
+
+	return last.Add(hb.interval + hb.grace)
+}
+
+type Config struct {
+	Heartbeats []Heartbeat `json:"heartbeats"`
+}
+
+// LoadConfig reads the heartbeats of the file at path.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("unable to read config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("unable to decode config: %w", err)
+	}
+
+	seen := make(map[string]struct{}, len(cfg.Heartbeats))
+	for i := range cfg.Heartbeats {
+		hb := &cfg.Heartbeats[i]
+		if hb.Token == "" || hb.MonitorID == "" {
+			return Config{}, fmt.Errorf("heartbeat %d: token and monitorId are required", i)
+		}
+		if _, ok := seen[hb.Token]; ok {
+			return Config{}, fmt.Errorf("heartbeat %s: duplicate token", hb.MonitorID)
+		}
+		seen[hb.Token] = struct{}{}
+
+		if err := hb.parse(); err != nil {
+			return Config{}, fmt.Errorf("heartbeat %s: %w", hb.MonitorID, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// Event reports a heartbeat missing its deadline, a run lasting too long or
+// completed, or a heartbeat pinged again after. Duration is the time of the
+// run, when it pinged its start.
+type Event struct {
+	Heartbeat Heartbeat
+	Status    string
+	LastPing  time.Time
+	Timestamp time.Time
+	Duration  time.Duration
+}
+
+type state struct {
+	heartbeat Heartbeat
+	last      time.Time
+	// started is the start of the running run, zero when none is.
+	started time.Time
+	missed  bool
+	overrun bool
+}
+
+// Watchdog keeps the last ping of each heartbeat and reports the changes of
+// their status with Report. The interval of a heartbeat starts with the
+// watchdog, so a restarted checker does not report every heartbeat missed.
+type Watchdog struct {
+	Report func(context.Context, Event)
+	now    func() time.Time
+	mu     sync.Mutex
+	states map[string]*state
+}
+
+// New watches heartbeats, validated by LoadConfig.
+func New(heartbeats []Heartbeat, report func(context.Context, Event)) *Watchdog {
+	w := &Watchdog{Report: report, now: time.Now, states: make(map[string]*state, len(heartbeats))}
+	now := w.now()
+	for _, hb := range heartbeats {
+		_ = hb.parse()
+		w.states[hb.Token] = &state{heartbeat: hb, last: now}
+	}
+
+	return w
+}
+
+// Ping records a ping of the heartbeat of token, the end of its run when it
+// pinged its start, and reports whether the heartbeat exists.
+func (w *Watchdog) Ping(ctx context.Context, token string) bool {
+	w.mu.Lock()
+	s, ok := w.states[token]
+	if !ok {
+		w.mu.Unlock()
+		return false
+	}
+	now := w.now()
+	event := Event{Heartbeat: s.heartbeat, Status: StatusCompleted, LastPing: s.last, Timestamp: now}
+	if !s.started.IsZero() {
+		event.Duration = now.Sub(s.started)
+	}
+	if s.missed || s.overrun {
+		event.Status = StatusRecovered
+	}
+	report := event.Status == StatusRecovered || !s.started.IsZero()
+	s.last, s.started, s.missed, s.overrun = now, time.Time{}, false, false
+	w.mu.Unlock()
+
+	if report {
+		w.Report(ctx, event)
+	}
+
+	return true
+}
+
+// Start records the start of a run of the heartbeat of token, and reports
+// whether the heartbeat exists. A started run counts as a ping, a run that
+// never ends is reported missed at the next deadline.
+func (w *Watchdog) Start(ctx context.Context, token string) bool {
+	w.mu.Lock()
+	s, ok := w.states[token]
+	if !ok {
+		w.mu.Unlock()
+		return false
+	}
+	now := w.now()
+	event := Event{Heartbeat: s.heartbeat, Status: StatusRecovered, LastPing: s.last, Timestamp: now}
+	recovered := s.missed || s.overrun
+	s.last, s.started, s.missed, s.overrun = now, now, false, false
+	w.mu.Unlock()
+
+	if recovered {
+		w.Report(ctx, event)
+	}
+
+	return true
+}
+
+// Check reports the heartbeats past their deadline and the runs lasting
+// longer than their maxDuration, once until they are pinged again.
+func (w *Watchdog) Check(ctx context.Context) {
+	var events []Event
+
+	w.mu.Lock()
+	now := w.now()
+	for _, s := range w.states {
+		hb := s.heartbeat
+		if !s.started.IsZero() && hb.maxDuration > 0 && !s.overrun && now.Sub(s.started) > hb.maxDuration {
+			s.overrun = true
+			events = append(events, Event{Heartbeat: hb, Status: StatusTimeout, LastPing: s.last, Timestamp: now, Duration: now.Sub(s.started)})
+		}
+
+		deadline := hb.deadline(s.last)
+		if s.missed || deadline.IsZero() || !now.After(deadline) {
+			continue
+		}
+		s.missed = true
+		events = append(events, Event{Heartbeat: hb, Status: StatusMissed, LastPing: s.last, Timestamp: now})
+	}
+	w.mu.Unlock()
+
+	for _, e := range events {
+		w.Report(ctx, e)
+	}
+}
+
+// Run checks the heartbeats every period until ctx is done.
+func (w *Watchdog) Run(ctx context.Context, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Check(ctx)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package heartbeat
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	load := func(t *testing.T, content string) (Config, error) {
+		path := filepath.Join(t.TempDir(), "heartbeats.json")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return LoadConfig(path)
+	}
+
+	cfg, err := load(t, `{"heartbeats": [{"token": "t", "monitorId": "1", "interval": "5m"}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, cfg.Heartbeats[0].interval)
+
+	cfg, err = load(t, `{"heartbeats": [{"token": "t", "monitorId": "1", "schedule": "@daily", "grace": "10m", "maxDuration": "1h"}]}`)
+	require.NoError(t, err)
+	assert.NotNil(t, cfg.Heartbeats[0].schedule)
+	assert.Equal(t, time.Hour, cfg.Heartbeats[0].maxDuration)
+
+	_, err = load(t, `{"heartbeats": [{"token": "t", "monitorId": "1", "interval": "1m", "schedule": "@daily"}]}`)
+	assert.ErrorContains(t, err, "mutually exclusive")
+	_, err = load(t, `{"heartbeats": [{"token": "t", "monitorId": "1", "interval": "soon"}]}`)
+	assert.ErrorContains(t, err, "invalid interval")
+	_, err = load(t, `{"heartbeats": [{"token": "t", "monitorId": "1", "interval": "1m"}, {"token": "t", "monitorId": "2", "interval": "1m"}]}`)
+	assert.ErrorContains(t, err, "duplicate token")
+}
+
+func TestWatchdog(t *testing.T) {
+	var events []Event
+	w := New([]Heartbeat{{Token: "t", MonitorID: "1", Interval: "1m"}}, func(_ context.Context, e Event) {
+		events = append(events, e)
+	})
+	now := time.Now()
+	w.now = func() time.Time { return now }
+
+	now = now.Add(30 * time.Second)
+	assert.True(t, w.Ping(t.Context(), "t"))
+	assert.False(t, w.Ping(t.Context(), "unknown"))
+
+	now = now.Add(time.Minute)
+	w.Check(t.Context())
+	assert.Empty(t, events, "pinged within its interval")
+
+	now = now.Add(time.Second)
+	w.Check(t.Context())
+	w.Check(t.Context())
+	require.Len(t, events, 1, "a missed heartbeat is reported once")
+	assert.Equal(t, StatusMissed, events[0].Status)
+	assert.Equal(t, "1", events[0].Heartbeat.MonitorID)
+
+	assert.True(t, w.Ping(t.Context(), "t"))
+	require.Len(t, events, 2)
+	assert.Equal(t, StatusRecovered, events[1].Status)
+}
+
+func TestWatchdog_Schedule(t *testing.T) {
+	var events []Event
+	w := New([]Heartbeat{{Token: "t", MonitorID: "1", Schedule: "0 * * * *", Grace: "5m"}}, func(_ context.Context, e Event) {
+		events = append(events, e)
+	})
+	now := time.Date(2026, 1, 14, 10, 0, 20, 0, time.UTC)
+	w.now = func() time.Time { return now }
+	assert.True(t, w.Ping(t.Context(), "t"))
+
+	now = time.Date(2026, 1, 14, 11, 4, 0, 0, time.UTC)
+	w.Check(t.Context())
+	assert.Empty(t, events, "within the grace period of the 11:00 run")
+
+	now = time.Date(2026, 1, 14, 11, 5, 1, 0, time.UTC)
+	w.Check(t.Context())
+	require.Len(t, events, 1)
+	assert.Equal(t, StatusMissed, events[0].Status)
+}
+
+func TestWatchdog_Runs(t *testing.T) {
+	var events []Event
+	w := New([]Heartbeat{{Token: "t", MonitorID: "1", Interval: "1h", MaxDuration: "10m"}}, func(_ context.Context, e Event) {
+		events = append(events, e)
+	})
+	now := time.Now()
+	w.now = func() time.Time { return now }
+
+	assert.True(t, w.Start(t.Context(), "t"))
+	assert.False(t, w.Start(t.Context(), "unknown"))
+	now = now.Add(2 * time.Minute)
+	assert.True(t, w.Ping(t.Context(), "t"))
+	require.Len(t, events, 1)
+	assert.Equal(t, StatusCompleted, events[0].Status)
+	assert.Equal(t, 2*time.Minute, events[0].Duration)
+
+	assert.True(t, w.Start(t.Context(), "t"))
+	now = now.Add(11 * time.Minute)
+	w.Check(t.Context())
+	w.Check(t.Context())
+	require.Len(t, events, 2, "a run lasting too long is reported once")
+	assert.Equal(t, StatusTimeout, events[1].Status)
+
+	now = now.Add(time.Minute)
+	assert.True(t, w.Ping(t.Context(), "t"))
+	require.Len(t, events, 3)
+	assert.Equal(t, StatusRecovered, events[2].Status)
+	assert.Equal(t, 12*time.Minute, events[2].Duration)
+}
@@ -0,0 +1,192 @@
+// Package idempotency deduplicates repeated check submissions so dispatcher
+// retries do not run a check twice, which would write duplicate Tinybird rows
+// and flap the monitor status.
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header carries the key chosen by the caller. Without it, scheduled checks
+// are keyed by their monitor and cron timestamp.
+const Header = "Idempotency-Key"
+
+// ReplayedHeader is set on responses served from the store.
+const ReplayedHeader = "Idempotent-Replayed"
+
+type entry struct {
+	expires time.Time
+	done    chan struct{}
+	header  http.Header
+	body    []byte
+	status  int
+}
+
+// Store keeps the responses of the submissions seen in the last ttl. A nil
+// *Store is valid and never deduplicates.
+type Store struct {
+	now       func() time.Time
+	entries   map[string]*entry
+	nextSweep time.Time
+	ttl       time.Duration
+	mu        sync.Mutex
+}
+
+func New(ttl time.Duration) *Store {
+	if ttl <= 0 {
+		return nil
+	}
+
+	return &Store{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]*entry),
+	}
+}
+
+// Middleware answers a repeated submission with the response of the first
+// one. A duplicate arriving while the first is still running waits for it.
+// Only successful responses are kept, so failed submissions can be retried.
+func (s *Store) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s == nil || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+
+		key, ok := requestKey(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		e, first := s.claim(key)
+		if !first {
+			select {
+			case <-e.done:
+			case <-c.Request.Context().Done():
+				c.AbortWithStatus(http.StatusRequestTimeout)
+				return
+			}
+
+			// The first submission failed and released the key, run again.
+			if e.status == 0 {
+				c.Next()
+				return
+			}
+
+			for k, v := range e.header {
+				c.Writer.Header()[k] = v
+			}
+			c.Header(ReplayedHeader, "true")
+			c.Data(e.status, e.header.Get("Content-Type"), e.body)
+			c.Abort()
+
+			return
+		}
+
+		w := &recorder{ResponseWriter: c.Writer}
+		c.Writer = w
+		// Deferred so duplicates waiting on a panicking handler are released.
+		defer s.complete(key, e, w)
+
+		c.Next()
+	}
+}
+
+func (s *Store) claim(key string) (*entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.After(s.nextSweep) {
+		for k, e := range s.entries {
+			if !e.expires.IsZero() && now.After(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.nextSweep = now.Add(s.ttl)
+	}
+
+	if e, ok := s.entries[key]; ok && (e.expires.IsZero() || now.Before(e.expires)) {
+		return e, false
+	}
+
+	e := &entry{done: make(chan struct{})}
+	s.entries[key] = e
+
+	return e, true
+}
+
+func (s *Store) complete(key string, e *entry, w *recorder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if w.Written() && w.Status() == http.StatusOK {
+		e.status = w.Status()
+		e.header = w.Header().Clone()
+		e.body = w.body.Bytes()
+		e.expires = s.now().Add(s.ttl)
+	} else {
+		delete(s.entries, key)
+	}
+
+	close(e.done)
+}
+
+// requestKey derives the key of a submission. It is scoped to the route and
+// the credentials so a key cannot be used to read someone else's result.
+func requestKey(c *gin.Context) (string, bool) {
+	key := c.GetHeader(Header)
+
+	if key == "" {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			return "", false
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		var scheduled struct {
+			MonitorID     string `json:"monitorId"`
+			CronTimestamp int64  `json:"cronTimestamp"`
+		}
+		if json.Unmarshal(body, &scheduled) != nil || scheduled.MonitorID == "" || scheduled.CronTimestamp == 0 {
+			return "", false
+		}
+		key = fmt.Sprintf("%s@%d", scheduled.MonitorID, scheduled.CronTimestamp)
+	}
+
+	h := sha256.New()
+	for _, part := range []string{c.Request.URL.Path, c.Request.URL.RawQuery, c.GetHeader("Authorization"), key} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// recorder copies the response body while writing it.
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *recorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
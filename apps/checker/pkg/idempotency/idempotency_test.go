@@ -0,0 +1,81 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	now := time.Now()
+	store := New(time.Minute)
+	store.now = func() time.Time { return now }
+
+	var runs int
+	status := http.StatusOK
+	router := gin.New()
+	router.Use(store.Middleware())
+	router.POST("/checker/http", func(c *gin.Context) {
+		runs++
+		c.JSON(status, gin.H{"run": runs})
+	})
+
+	do := func(body string, header map[string]string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, "/checker/http", strings.NewReader(body))
+		req.Header.Set("Authorization", "Basic test")
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("scheduled checks are keyed by monitor and cron timestamp", func(t *testing.T) {
+		scheduled := `{"monitorId":"1","cronTimestamp":1000}`
+
+		first := do(scheduled, nil)
+		second := do(scheduled, nil)
+		assert.Equal(t, 1, runs)
+		assert.JSONEq(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "true", second.Header().Get(ReplayedHeader))
+
+		do(`{"monitorId":"1","cronTimestamp":2000}`, nil)
+		assert.Equal(t, 2, runs)
+	})
+
+	t.Run("explicit key", func(t *testing.T) {
+		do(`{}`, map[string]string{Header: "abc"})
+		do(`{}`, map[string]string{Header: "abc"})
+		assert.Equal(t, 3, runs)
+	})
+
+	t.Run("requests without key are not deduplicated", func(t *testing.T) {
+		do(`{}`, nil)
+		do(`{}`, nil)
+		assert.Equal(t, 5, runs)
+	})
+
+	t.Run("failed responses are not kept", func(t *testing.T) {
+		status = http.StatusBadRequest
+		do(`{}`, map[string]string{Header: "failed"})
+		status = http.StatusOK
+		do(`{}`, map[string]string{Header: "failed"})
+		assert.Equal(t, 7, runs)
+	})
+
+	t.Run("entries expire", func(t *testing.T) {
+		now = now.Add(2 * time.Minute)
+		do(`{}`, map[string]string{Header: "abc"})
+		assert.Equal(t, 8, runs)
+	})
+
+	t.Run("nil store", func(t *testing.T) {
+		assert.Nil(t, New(0))
+	})
+}
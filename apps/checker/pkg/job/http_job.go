@@ -5,13 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/cenkalti/backoff/v5"
 	"github.com/google/uuid"
 	"github.com/openstatushq/openstatus/apps/checker/checker"
 	"github.com/openstatushq/openstatus/apps/checker/pkg/assertions"
 	"github.com/openstatushq/openstatus/apps/checker/pkg/otel"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/retry"
 	v1 "github.com/openstatushq/openstatus/apps/checker/proto/private_location/v1"
 	"github.com/openstatushq/openstatus/apps/checker/request"
 )
@@ -56,28 +56,14 @@ func ProtoStringAssertionToComparator(assertion v1.StringComparator) (request.St
 
 func (jr jobRunner) HTTPJob(ctx context.Context, monitor *v1.HTTPMonitor, region string) (*HttpPrivateRegionData, error) {
 
-	retry := monitor.Retry
-	if retry == 0 {
-		retry = 3
-	}
+	policy := retry.FromRequest(nil, monitor.Retry)
 
 	requestClient := &http.Client{
-		Timeout: time.Duration(monitor.Timeout) * time.Millisecond,
+		Timeout: request.Timeout("http", monitor.Timeout),
 	}
 	defer requestClient.CloseIdleConnections()
 
-	if !monitor.FollowRedirects {
-		requestClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		}
-	} else {
-		requestClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-			if len(via) >= 10 {
-				return http.ErrUseLastResponse
-			}
-			return nil
-		}
-	}
+	requestClient.CheckRedirect = checker.CheckRedirect(monitor.FollowRedirects, 0)
 
 	var degradedAfter int64
 	if monitor.DegradedAt != nil {
@@ -222,7 +208,7 @@ func (jr jobRunner) HTTPJob(ctx context.Context, monitor *v1.HTTPMonitor, region
 			// Mark the recorded response as errored so OTel emits the error counter
 			// for non-2xx / failed assertions, matching the public checker.
 			lastRes.Error = "Error"
-			if called < int(retry) {
+			if !policy.IsLastAttempt(called) {
 				return nil, fmt.Errorf("unable to ping: %v with status %v", res, res.Status)
 			}
 		}
@@ -230,7 +216,7 @@ func (jr jobRunner) HTTPJob(ctx context.Context, monitor *v1.HTTPMonitor, region
 		return &data, nil
 	}
 
-	resp, err := backoff.Retry(ctx, op, backoff.WithMaxTries(uint(retry)), backoff.WithBackOff(backoff.NewExponentialBackOff()))
+	resp, err := backoff.Retry(ctx, op, policy.Options()...)
 
 	if req.OtelConfig.Endpoint != "" {
 		if err != nil && lastRes.Error == "" {
@@ -141,7 +141,7 @@ func TestTCPJob_RecordsOTelMetrics(t *testing.T) {
 
 	monitor := &v1.TCPMonitor{
 		Uri:        strings.TrimPrefix(target.URL, "http://"),
-		Timeout:    5,
+		Timeout:    5000,
 		Retry:      1,
 		OtelConfig: &v1.OtelConfig{Endpoint: otlp.server.URL},
 	}
@@ -157,7 +157,7 @@ func TestTCPJob_RecordsOTelOnFailure(t *testing.T) {
 
 	monitor := &v1.TCPMonitor{
 		Uri:        "127.0.0.1:1",
-		Timeout:    1,
+		Timeout:    1000,
 		Retry:      1,
 		OtelConfig: &v1.OtelConfig{Endpoint: otlp.server.URL},
 	}
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/openstatushq/openstatus/apps/checker/checker"
 	"github.com/openstatushq/openstatus/apps/checker/pkg/otel"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/retry"
 	v1 "github.com/openstatushq/openstatus/apps/checker/proto/private_location/v1"
 	"github.com/openstatushq/openstatus/apps/checker/request"
 )
@@ -36,10 +37,7 @@ type TCPPrivateRegionData struct {
 // runAssertions performs all configured assertions for TCP and returns their results
 
 func (jobRunner) TCPJob(ctx context.Context, monitor *v1.TCPMonitor, region string) (*TCPPrivateRegionData, error) {
-	retry := monitor.Retry
-	if retry == 0 {
-		retry = 3
-	}
+	policy := retry.FromRequest(nil, monitor.Retry)
 
 	var degradedAfter int64
 	if monitor.DegradedAt != nil {
@@ -53,9 +51,9 @@ func (jobRunner) TCPJob(ctx context.Context, monitor *v1.TCPMonitor, region stri
 
 	op := func() (*TCPPrivateRegionData, error) {
 		called++
-		res, err := checker.PingTCP(int(monitor.Timeout), monitor.Uri)
+		res, err := checker.PingTCP(ctx, request.Timeout("tcp", monitor.Timeout), request.TCPCheckerRequest{URI: monitor.Uri}.Address())
 		if err != nil {
-			if called < int(retry) {
+			if !policy.IsLastAttempt(called) {
 				return nil, fmt.Errorf("TCP connection failed: %w", err)
 			}
 			// On final attempt, return the error in the result
@@ -111,15 +109,12 @@ func (jobRunner) TCPJob(ctx context.Context, monitor *v1.TCPMonitor, region stri
 		return data, nil
 	}
 
-	resp, err := backoff.Retry(ctx, op,
-		backoff.WithMaxTries(uint(retry)),
-		backoff.WithBackOff(backoff.NewExponentialBackOff()),
-	)
+	resp, err := backoff.Retry(ctx, op, policy.Options()...)
 
 	recordTCPOtel(ctx, req, lastResult, region, err != nil)
 
 	if err != nil {
-		return nil, fmt.Errorf("TCP job failed after %d retries: %w", retry, err)
+		return nil, fmt.Errorf("TCP job failed after %d attempts: %w", policy.MaxAttempts, err)
 	}
 	return resp, nil
 }
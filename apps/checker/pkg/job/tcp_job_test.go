@@ -12,7 +12,7 @@ func TestTCPJob_Success(t *testing.T) {
 
 	monitor := &v1.TCPMonitor{
 		Uri:     "openstatus.dev:80",
-		Timeout: 1,
+		Timeout: 1000,
 		Retry:   1,
 	}
 	data, err := job.NewJobRunner().TCPJob(context.Background(), monitor, "test-region")
@@ -31,7 +31,7 @@ func TestTCPJob_Failure(t *testing.T) {
 
 	monitor := &v1.TCPMonitor{
 		Uri:     "localhost:1234",
-		Timeout: 1,
+		Timeout: 1000,
 		Retry:   1,
 	}
 
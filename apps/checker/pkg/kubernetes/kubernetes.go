@@ -0,0 +1,161 @@
+// Package kubernetes discovers the region of a checker running on
+// Kubernetes from the labels of its node, so a multi-region deployment
+// shares a single manifest instead of setting REGION in each cluster.
+package kubernetes
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The well-known labels of the nodes.
+const (
+	RegionLabel = "topology.kubernetes.io/region"
+	ZoneLabel   = "topology.kubernetes.io/zone"
+)
+
+// serviceAccountDir holds the credentials mounted in every pod.
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+var ErrNoRegion = errors.New("no region label")
+
+// InCluster reports whether the checker runs in a Kubernetes pod.
+func InCluster() bool {
+	return os.Getenv("KUBERNETES_SERVICE_HOST") != ""
+}
+
+// Node is where the checker runs.
+type Node struct {
+	Region string
+	Zone   string
+	// Provider is the cloud provider of the node, from its provider ID,
+	// e.g. "aws" for aws:///us-east-1a/i-0123. Empty on bare metal.
+	Provider string
+}
+
+// Client reads the nodes from the API server.
+type Client struct {
+	BaseURL string
+	Token   string
+	HTTP    *http.Client
+}
+
+// NewInCluster returns a client authenticated with the service account of
+// the pod, which must be allowed to get nodes.
+func NewInCluster() (*Client, error) {
+	token, err := os.ReadFile(filepath.Join(serviceAccountDir, "token"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read service account token: %w", err)
+	}
+	ca, err := os.ReadFile(filepath.Join(serviceAccountDir, "ca.crt"))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read cluster CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("invalid cluster CA")
+	}
+
+	host := net.JoinHostPort(os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT"))
+
+	return &Client{
+		BaseURL: "https://" + host,
+		Token:   strings.TrimSpace(string(token)),
+		HTTP: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+		},
+	}, nil
+}
+
+// Node returns the node of the given name, e.g. the spec.nodeName of the pod
+// passed with the downward API.
+func (c *Client) Node(ctx context.Context, name string) (Node, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/api/v1/nodes/"+url.PathEscape(name), nil)
+	if err != nil {
+		return Node{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Accept", "application/json")
+
+	res, err := c.HTTP.Do(req)
+	if err != nil {
+		return Node{}, fmt.Errorf("unable to get node %s: %w", name, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return Node{}, fmt.Errorf("unable to get node %s: %s", name, res.Status)
+	}
+
+	var node struct {
+		Metadata struct {
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Spec struct {
+			ProviderID string `json:"providerID"`
+		} `json:"spec"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&node); err != nil {
+		return Node{}, fmt.Errorf("unable to decode node %s: %w", name, err)
+	}
+
+	n := Node{
+		Region: node.Metadata.Labels[RegionLabel],
+		Zone:   node.Metadata.Labels[ZoneLabel],
+	}
+	if provider, _, ok := strings.Cut(node.Spec.ProviderID, "://"); ok {
+		n.Provider = provider
+	}
+	if n.Region == "" {
+		return n, fmt.Errorf("%w %s on node %s", ErrNoRegion, RegionLabel, name)
+	}
+
+	return n, nil
+}
+
+// LabelsFile reads the region from a file of the downward API, the labels
+// or annotations of the pod, for the clusters where the checker may not get
+// nodes. The file holds a key="value" line per label.
+func LabelsFile(path string) (Node, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Node{}, fmt.Errorf("unable to read labels: %w", err)
+	}
+	defer f.Close()
+
+	labels := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		labels[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return Node{}, fmt.Errorf("unable to read labels: %w", err)
+	}
+
+	n := Node{Region: labels[RegionLabel], Zone: labels[ZoneLabel]}
+	if n.Region == "" {
+		return n, fmt.Errorf("%w %s in %s", ErrNoRegion, RegionLabel, path)
+	}
+
+	return n, nil
+}
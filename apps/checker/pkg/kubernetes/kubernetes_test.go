@@ -0,0 +1,59 @@
+package kubernetes_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/kubernetes"
+)
+
+func TestClient_Node(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/nodes/node-1":
+			_, _ = w.Write([]byte(`{
+				"metadata": {"labels": {"topology.kubernetes.io/region": "us-east-1", "topology.kubernetes.io/zone": "us-east-1a"}},
+				"spec": {"providerID": "aws:///us-east-1a/i-0123"}
+			}`))
+		case "/api/v1/nodes/node-2":
+			_, _ = w.Write([]byte(`{"metadata": {"labels": {}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := &kubernetes.Client{BaseURL: server.URL, Token: "token", HTTP: server.Client()}
+
+	node, err := client.Node(t.Context(), "node-1")
+	require.NoError(t, err)
+	assert.Equal(t, kubernetes.Node{Region: "us-east-1", Zone: "us-east-1a", Provider: "aws"}, node)
+
+	_, err = client.Node(t.Context(), "node-2")
+	assert.ErrorIs(t, err, kubernetes.ErrNoRegion)
+
+	_, err = client.Node(t.Context(), "node-3")
+	assert.Error(t, err)
+}
+
+func TestLabelsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "labels")
+	require.NoError(t, os.WriteFile(path, []byte("app=\"checker\"\ntopology.kubernetes.io/region=\"eu-west-3\"\n"), 0o600))
+
+	node, err := kubernetes.LabelsFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-3", node.Region)
+
+	require.NoError(t, os.WriteFile(path, []byte("app=\"checker\"\n"), 0o600))
+	_, err = kubernetes.LabelsFile(path)
+	assert.ErrorIs(t, err, kubernetes.ErrNoRegion)
+}
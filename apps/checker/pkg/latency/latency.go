@@ -0,0 +1,67 @@
+// Package latency keeps the last latencies of each monitor, for the rolling
+// average deciding whether it is degraded.
+package latency
+
+import (
+	"sync"
+	"time"
+)
+
+// retention is how long the latencies of a monitor not checked anymore are
+// kept.
+const retention = time.Hour
+
+type series struct {
+	updated   time.Time
+	latencies []int64
+}
+
+// Averages keeps the latencies of the successful checks of each monitor.
+//
+// A nil *Averages is valid and averages nothing.
+type Averages struct {
+	now    func() time.Time
+	series map[string]*series
+	mu     sync.Mutex
+}
+
+func New() *Averages {
+	return &Averages{now: time.Now, series: make(map[string]*series)}
+}
+
+// Add records the latency of a check of the monitor, and returns the average
+// of its last window latencies, this one included. A window of 1 or less
+// returns latency as is.
+func (a *Averages) Add(monitorID string, latency int64, window int) int64 {
+	if a == nil || monitorID == "" || window <= 1 {
+		return latency
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	for id, s := range a.series {
+		if now.Sub(s.updated) > retention {
+			delete(a.series, id)
+		}
+	}
+
+	s, ok := a.series[monitorID]
+	if !ok {
+		s = &series{}
+		a.series[monitorID] = s
+	}
+	s.updated = now
+	s.latencies = append(s.latencies, latency)
+	if len(s.latencies) > window {
+		s.latencies = s.latencies[len(s.latencies)-window:]
+	}
+
+	var sum int64
+	for _, l := range s.latencies {
+		sum += l
+	}
+
+	return sum / int64(len(s.latencies))
+}
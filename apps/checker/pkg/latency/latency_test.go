@@ -0,0 +1,22 @@
+package latency_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/latency"
+)
+
+func TestAverages(t *testing.T) {
+	a := latency.New()
+
+	assert.Equal(t, int64(500), a.Add("1", 500, 1))
+	assert.Equal(t, int64(100), a.Add("2", 100, 3))
+	assert.Equal(t, int64(200), a.Add("2", 300, 3))
+	assert.Equal(t, int64(300), a.Add("2", 500, 3))
+	assert.Equal(t, int64(500), a.Add("2", 700, 3))
+
+	var none *latency.Averages
+	assert.Equal(t, int64(42), none.Add("1", 42, 3))
+}
@@ -0,0 +1,293 @@
+// Package loki ships the events of the checks to Grafana Loki as structured
+// log lines, for the self-hosters building their dashboards in Grafana
+// rather than on Tinybird.
+package loki
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// queueSize is how many lines wait to be pushed, the next ones being
+	// dropped.
+	queueSize = 1000
+	// batchSize is how many lines are pushed at once at most.
+	batchSize = 100
+	// flushInterval is how long a line waits for its batch to fill.
+	flushInterval = time.Second
+)
+
+// ErrQueueFull is returned for the events sent while the queue is full.
+var ErrQueueFull = errors.New("loki queue full, event dropped")
+
+// Options are attached to every push.
+type Options struct {
+	// Authorization is the Authorization header of the pushes, e.g. Basic
+	// credentials for Grafana Cloud.
+	Authorization string
+	// TenantID is the X-Scope-OrgID header of multi-tenant Loki.
+	TenantID string
+	// Labels are added to every stream, e.g. job and provider.
+	Labels map[string]string
+}
+
+// Client pushes the events in the background, batched. It is a
+// tinybird.Client: every event is a line, its JSON, in the stream of its
+// source, region, monitor and status labels.
+//
+// A nil *Client is valid and ships nothing.
+type Client struct {
+	endpoint string
+	opts     Options
+	http     *http.Client
+	entries  chan entry
+	done     chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+}
+
+type entry struct {
+	labels    map[string]string
+	timestamp time.Time
+	line      string
+}
+
+// New returns a client of the Loki at rawURL, e.g. http://loki:3100, or of
+// its push endpoint.
+func New(rawURL string, opts Options) (*Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, errors.New("url must be http(s)://host[/path]")
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/loki/api/v1/push"
+	}
+
+	c := &Client{
+		endpoint: u.String(),
+		opts:     opts,
+		http:     &http.Client{Timeout: 10 * time.Second},
+		entries:  make(chan entry, queueSize),
+		done:     make(chan struct{}),
+	}
+	go c.run()
+
+	return c, nil
+}
+
+// SendEvent queues event as a line of the stream of dataSourceName.
+func (c *Client) SendEvent(_ context.Context, event any, dataSourceName string) error {
+	if c == nil {
+		return nil
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("unable to encode event: %w", err)
+	}
+	e := entry{labels: labels(line), timestamp: time.Now(), line: string(line)}
+	e.labels["source"] = dataSourceName
+	for k, v := range c.opts.Labels {
+		e.labels[k] = v
+	}
+	if ms, ok := timestamp(line); ok {
+		e.timestamp = time.UnixMilli(ms)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	select {
+	case c.entries <- e:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// SetCredentials replaces the Authorization and X-Scope-OrgID headers of the
+// next pushes, to rotate them without a restart.
+func (c *Client) SetCredentials(authorization, tenantID string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.opts.Authorization, c.opts.TenantID = authorization, tenantID
+}
+
+// labels returns the region, monitor and status labels of the JSON of an
+// event, the ones it has.
+func labels(line []byte) map[string]string {
+	var fields struct {
+		Region        string      `json:"region"`
+		MonitorID     json.Number `json:"monitorId"`
+		RequestStatus string      `json:"requestStatus"`
+	}
+	// Events whose fields are of other types are shipped without them.
+	_ = json.Unmarshal(line, &fields)
+
+	l := make(map[string]string, 6)
+	if fields.Region != "" {
+		l["region"] = fields.Region
+	}
+	if fields.MonitorID != "" {
+		l["monitor"] = fields.MonitorID.String()
+	}
+	if fields.RequestStatus != "" {
+		l["status"] = fields.RequestStatus
+	}
+
+	return l
+}
+
+func timestamp(line []byte) (int64, bool) {
+	var fields struct {
+		Timestamp int64 `json:"timestamp"`
+	}
+	if err := json.Unmarshal(line, &fields); err != nil || fields.Timestamp <= 0 {
+		return 0, false
+	}
+
+	return fields.Timestamp, true
+}
+
+// Close pushes the queued lines, waiting for them at most timeout.
+func (c *Client) Close(timeout time.Duration) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.entries)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-c.done:
+	case <-time.After(timeout):
+	}
+}
+
+func (c *Client) run() {
+	defer close(c.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	var batch []entry
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := c.push(context.Background(), batch); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to push %d lines to loki: %v\n", len(batch), err)
+		}
+		batch = nil
+	}
+	for {
+		select {
+		case e, ok := <-c.entries:
+			if !ok {
+				flush()
+				return
+			}
+			if batch = append(batch, e); len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+type stream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (c *Client) push(ctx context.Context, batch []entry) error {
+	var streams []*stream
+	byKey := make(map[string]*stream)
+	for _, e := range batch {
+		key := streamKey(e.labels)
+		s, ok := byKey[key]
+		if !ok {
+			s = &stream{Stream: e.labels}
+			byKey[key] = s
+			streams = append(streams, s)
+		}
+		s.Values = append(s.Values, [2]string{strconv.FormatInt(e.timestamp.UnixNano(), 10), e.line})
+	}
+
+	body, err := json.Marshal(map[string]any{"streams": streams})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.mu.Lock()
+	authorization, tenantID := c.opts.Authorization, c.opts.TenantID
+	c.mu.Unlock()
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	if tenantID != "" {
+		req.Header.Set("X-Scope-OrgID", tenantID)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// streamKey identifies a label set, whatever the order of its labels.
+func streamKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[k]))
+		b.WriteByte(',')
+	}
+
+	return b.String()
+}
@@ -0,0 +1,104 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		pushes []map[string][]stream
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/loki/api/v1/push", r.URL.Path)
+		require.Equal(t, "Basic secret", r.Header.Get("Authorization"))
+		require.Equal(t, "tenant", r.Header.Get("X-Scope-OrgID"))
+
+		var body map[string][]stream
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		pushes = append(pushes, body)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, Options{Authorization: "Basic secret", TenantID: "tenant", Labels: map[string]string{"job": "checker"}})
+	require.NoError(t, err)
+
+	type event struct {
+		Region        string `json:"region"`
+		MonitorID     int64  `json:"monitorId"`
+		RequestStatus string `json:"requestStatus"`
+		Timestamp     int64  `json:"timestamp"`
+	}
+	ctx := context.Background()
+	require.NoError(t, c.SendEvent(ctx, event{Region: "ams", MonitorID: 1, RequestStatus: "success", Timestamp: 1700000000000}, "ping_response__v8"))
+	require.NoError(t, c.SendEvent(ctx, event{Region: "ams", MonitorID: 1, RequestStatus: "success", Timestamp: 1700000001000}, "ping_response__v8"))
+	require.NoError(t, c.SendEvent(ctx, event{Region: "ams", MonitorID: 2, RequestStatus: "error", Timestamp: 1700000002000}, "ping_response__v8"))
+	c.Close(5 * time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, pushes, 1)
+	streams := pushes[0]["streams"]
+	require.Len(t, streams, 2)
+	require.Equal(t, map[string]string{"job": "checker", "source": "ping_response__v8", "region": "ams", "monitor": "1", "status": "success"}, streams[0].Stream)
+	require.Len(t, streams[0].Values, 2)
+	require.Equal(t, "1700000000000000000", streams[0].Values[0][0])
+	require.JSONEq(t, `{"region":"ams","monitorId":1,"requestStatus":"success","timestamp":1700000000000}`, streams[0].Values[0][1])
+	require.Equal(t, "2", streams[1].Stream["monitor"])
+	require.Equal(t, "error", streams[1].Stream["status"])
+
+	// Closed, the events are dropped.
+	require.NoError(t, c.SendEvent(ctx, event{}, "ping_response__v8"))
+}
+
+func TestClient_SetCredentials(t *testing.T) {
+	var (
+		mu             sync.Mutex
+		authorizations []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorizations = append(authorizations, r.Header.Get("Authorization")+" "+r.Header.Get("X-Scope-OrgID"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, Options{Authorization: "Basic revoked", TenantID: "tenant"})
+	require.NoError(t, err)
+	c.SetCredentials("Basic rotated", "other")
+	require.NoError(t, c.SendEvent(context.Background(), struct{}{}, "ping_response__v8"))
+	c.Close(5 * time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"Basic rotated other"}, authorizations)
+
+	var nilClient *Client
+	nilClient.SetCredentials("Basic rotated", "")
+}
+
+func TestNew(t *testing.T) {
+	c, err := New("https://logs.example.com/custom/push", Options{})
+	require.NoError(t, err)
+	require.Equal(t, "https://logs.example.com/custom/push", c.endpoint)
+	c.Close(time.Second)
+
+	_, err = New("loki:3100", Options{})
+	require.Error(t, err)
+
+	var nilClient *Client
+	require.NoError(t, nilClient.SendEvent(context.Background(), struct{}{}, "ping_response__v8"))
+	nilClient.Close(time.Second)
+}
@@ -0,0 +1,397 @@
+// Package mailauth checks the email authentication records of a domain: the
+// syntax of its SPF record, the keys of its DKIM selectors and the presence
+// of its DMARC policy, whose removal or breakage lets the mail of the domain
+// land in spam or be spoofed without anyone noticing.
+package mailauth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MaxSPFLookups is the most DNS lookups the evaluation of an SPF record may
+// need, includes followed, per RFC 7208.
+const MaxSPFLookups = 10
+
+// MinRSABits is the smallest RSA key of a DKIM selector accepted.
+const MinRSABits = 1024
+
+// Policies are the DMARC policies, weakest first.
+var Policies = []string{"none", "quarantine", "reject"}
+
+// Resolver looks up the TXT records of a name, e.g. net.DefaultResolver.
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// Config is what a domain is checked for.
+type Config struct {
+	// Selectors are the DKIM selectors whose keys are checked, none by
+	// default.
+	Selectors []string
+	// MinPolicy is the weakest DMARC policy accepted, any by default.
+	MinPolicy string
+}
+
+// Record is a record checked, with its problem when it is broken.
+type Record struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Report is the outcome of the check of a domain.
+type Report struct {
+	SPF Record `json:"spf"`
+	// SPFLookups counts the DNS lookups of the evaluation of the SPF
+	// record, its includes followed.
+	SPFLookups int      `json:"spfLookups"`
+	DKIM       []Record `json:"dkim,omitempty"`
+	DMARC      Record   `json:"dmarc"`
+	// Policy is the DMARC policy of the domain.
+	Policy string `json:"policy,omitempty"`
+}
+
+// Broken returns the records of r with a problem.
+func (r Report) Broken() []Record {
+	var broken []Record
+	for _, record := range append([]Record{r.SPF, r.DMARC}, r.DKIM...) {
+		if record.Error != "" {
+			broken = append(broken, record)
+		}
+	}
+
+	return broken
+}
+
+// Check checks the records of domain. A missing or broken record is
+// reported in the Report, the error being the failure of a lookup.
+func Check(ctx context.Context, resolver Resolver, domain string, cfg Config) (Report, error) {
+	var report Report
+	var err error
+
+	report.SPF, err = lookup(ctx, resolver, domain, "v=spf1", "SPF")
+	if err != nil {
+		return report, err
+	}
+	if report.SPF.Error == "" {
+		s := &spf{ctx: ctx, resolver: resolver}
+		if err := s.check(domain, report.SPF.Value); err != nil {
+			var lookupErr *net.DNSError
+			if errors.As(err, &lookupErr) && !lookupErr.IsNotFound {
+				return report, err
+			}
+			report.SPF.Error = err.Error()
+		}
+		report.SPFLookups = s.lookups
+	}
+
+	for _, selector := range cfg.Selectors {
+		record, err := lookup(ctx, resolver, selector+"._domainkey."+domain, "", "DKIM")
+		if err != nil {
+			return report, err
+		}
+		if record.Error == "" {
+			if err := checkDKIM(record.Value); err != nil {
+				record.Error = err.Error()
+			}
+		}
+		report.DKIM = append(report.DKIM, record)
+	}
+
+	report.DMARC, err = lookup(ctx, resolver, "_dmarc."+domain, "v=DMARC1", "DMARC")
+	if err != nil {
+		return report, err
+	}
+	if report.DMARC.Error == "" {
+		report.Policy, err = checkDMARC(report.DMARC.Value, cfg.MinPolicy)
+		if err != nil {
+			report.DMARC.Error = err.Error()
+		}
+	}
+
+	return report, nil
+}
+
+// lookup returns the record of name starting with version, the only TXT
+// record of name without version. A missing record is reported as its
+// problem, other failures are returned.
+func lookup(ctx context.Context, resolver Resolver, name, version, kind string) (Record, error) {
+	record := Record{Name: name}
+
+	txt, err := resolver.LookupTXT(ctx, name)
+	var lookupErr *net.DNSError
+	if err != nil && !(errors.As(err, &lookupErr) && lookupErr.IsNotFound) {
+		return record, fmt.Errorf("unable to look up %s: %w", name, err)
+	}
+
+	var values []string
+	for _, value := range txt {
+		if version == "" || hasVersion(value, version) {
+			values = append(values, value)
+		}
+	}
+	switch len(values) {
+	case 0:
+		record.Error = "no " + kind + " record"
+	case 1:
+		record.Value = values[0]
+	default:
+		record.Value = values[0]
+		record.Error = fmt.Sprintf("%d %s records, a single one expected", len(values), kind)
+	}
+
+	return record, nil
+}
+
+// hasVersion reports whether value starts with the version tag.
+func hasVersion(value, version string) bool {
+	if len(value) < len(version) || !strings.EqualFold(value[:len(version)], version) {
+		return false
+	}
+	rest := value[len(version):]
+
+	return rest == "" || rest[0] == ' ' || rest[0] == ';'
+}
+
+// spf checks an SPF record and the ones it includes, counting their DNS
+// lookups.
+type spf struct {
+	ctx      context.Context
+	resolver Resolver
+	lookups  int
+}
+
+func (s *spf) check(domain, record string) error {
+	var redirect string
+	for _, term := range strings.Fields(record)[1:] {
+		if name, value, ok := modifier(term); ok {
+			switch strings.ToLower(name) {
+			case "redirect":
+				if redirect != "" {
+					return errors.New("redirect is set twice")
+				}
+				if value == "" {
+					return errors.New("redirect is missing its domain")
+				}
+				redirect = value
+			case "exp":
+				if value == "" {
+					return errors.New("exp is missing its domain")
+				}
+			}
+			continue
+		}
+
+		name, value, err := mechanism(term)
+		if err != nil {
+			return err
+		}
+		switch name {
+		case "include":
+			if err := s.follow(value); err != nil {
+				return fmt.Errorf("include:%s: %w", value, err)
+			}
+		case "a", "mx", "ptr", "exists":
+			if err := s.count(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if redirect != "" {
+		if err := s.follow(redirect); err != nil {
+			return fmt.Errorf("redirect=%s: %w", redirect, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *spf) count() error {
+	s.lookups++
+	if s.lookups > MaxSPFLookups {
+		return fmt.Errorf("more than %d DNS lookups", MaxSPFLookups)
+	}
+
+	return nil
+}
+
+// follow checks the SPF record of domain, unless it is a macro expanded
+// when the mail is received.
+func (s *spf) follow(domain string) error {
+	if err := s.count(); err != nil {
+		return err
+	}
+	if strings.Contains(domain, "%") {
+		return nil
+	}
+
+	record, err := lookup(s.ctx, s.resolver, domain, "v=spf1", "SPF")
+	if err != nil {
+		return err
+	}
+	if record.Error != "" {
+		return errors.New(record.Error)
+	}
+
+	return s.check(domain, record.Value)
+}
+
+// modifier returns the name and value of an SPF modifier, name=value.
+func modifier(term string) (string, string, bool) {
+	name, value, ok := strings.Cut(term, "=")
+	if !ok || name == "" || strings.ContainsAny(name, ":/") {
+		return "", "", false
+	}
+
+	return name, value, true
+}
+
+// mechanism returns the lowercase name of an SPF mechanism and its domain or
+// address, checked.
+func mechanism(term string) (string, string, error) {
+	raw := strings.TrimLeft(term, "+-~?")
+	if len(term)-len(raw) > 1 {
+		return "", "", fmt.Errorf("invalid mechanism %q", term)
+	}
+	name, value, hasValue := strings.Cut(raw, ":")
+	if !hasValue {
+		name, _, _ = strings.Cut(raw, "/")
+	}
+	name = strings.ToLower(name)
+
+	switch name {
+	case "all":
+		if !strings.EqualFold(raw, "all") {
+			return "", "", fmt.Errorf("invalid mechanism %q", term)
+		}
+	case "include", "exists":
+		if value == "" {
+			return "", "", fmt.Errorf("%s is missing its domain", name)
+		}
+	case "a", "mx", "ptr":
+		if hasValue && value == "" {
+			return "", "", fmt.Errorf("%s is missing its domain", name)
+		}
+	case "ip4", "ip6":
+		if err := checkNetwork(name, value); err != nil {
+			return "", "", err
+		}
+	default:
+		return "", "", fmt.Errorf("unknown mechanism %q", term)
+	}
+
+	return name, value, nil
+}
+
+// checkNetwork checks the address or network of an ip4 or ip6 mechanism.
+func checkNetwork(name, value string) error {
+	var addr netip.Addr
+	var err error
+	if strings.Contains(value, "/") {
+		var prefix netip.Prefix
+		prefix, err = netip.ParsePrefix(value)
+		addr = prefix.Addr()
+	} else {
+		addr, err = netip.ParseAddr(value)
+	}
+	if err != nil || (name == "ip4") != addr.Is4() {
+		return fmt.Errorf("invalid %s address %q", name, value)
+	}
+
+	return nil
+}
+
+// tags returns the tags of a DKIM or DMARC record, k=v pairs separated by
+// semicolons.
+func tags(record string) map[string]string {
+	t := map[string]string{}
+	for _, tag := range strings.Split(record, ";") {
+		name, value, _ := strings.Cut(tag, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" {
+			t[name] = strings.TrimSpace(value)
+		}
+	}
+
+	return t
+}
+
+// checkDKIM checks the key of a DKIM record.
+func checkDKIM(record string) error {
+	t := tags(record)
+	if v, ok := t["v"]; ok && v != "DKIM1" {
+		return fmt.Errorf("unsupported version %q", v)
+	}
+	key, ok := t["p"]
+	if !ok {
+		return errors.New("missing key")
+	}
+	if key == "" {
+		return errors.New("the key is revoked")
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(key), ""))
+	if err != nil {
+		return errors.New("the key is not valid base64")
+	}
+
+	switch k := strings.ToLower(t["k"]); k {
+	case "", "rsa":
+		key, err := x509.ParsePKIXPublicKey(data)
+		if err != nil {
+			return fmt.Errorf("invalid RSA key: %w", err)
+		}
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("the key is not an RSA key")
+		}
+		if bits := rsaKey.N.BitLen(); bits < MinRSABits {
+			return fmt.Errorf("RSA key of %d bits, at least %d expected", bits, MinRSABits)
+		}
+	case "ed25519":
+		if len(data) != ed25519.PublicKeySize {
+			return errors.New("invalid ed25519 key")
+		}
+	default:
+		return fmt.Errorf("unsupported key type %q", k)
+	}
+
+	return nil
+}
+
+// checkDMARC returns the policy of a DMARC record, checked to be at least
+// minPolicy.
+func checkDMARC(record, minPolicy string) (string, error) {
+	t := tags(record)
+	policy := strings.ToLower(t["p"])
+	if policy == "" {
+		return "", errors.New("missing policy")
+	}
+	if !slices.Contains(Policies, policy) {
+		return "", fmt.Errorf("invalid policy %q", policy)
+	}
+	if sp, ok := t["sp"]; ok && !slices.Contains(Policies, strings.ToLower(sp)) {
+		return policy, fmt.Errorf("invalid subdomain policy %q", sp)
+	}
+	if pct, ok := t["pct"]; ok {
+		if n, err := strconv.Atoi(pct); err != nil || n < 0 || n > 100 {
+			return policy, fmt.Errorf("invalid pct %q", pct)
+		}
+	}
+	if minPolicy != "" && slices.Index(Policies, policy) < slices.Index(Policies, minPolicy) {
+		return policy, fmt.Errorf("policy %s, at least %s expected", policy, minPolicy)
+	}
+
+	return policy, nil
+}
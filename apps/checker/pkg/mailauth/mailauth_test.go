@@ -0,0 +1,119 @@
+package mailauth_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/mailauth"
+)
+
+// resolver answers the TXT records of its names, the other names not
+// existing.
+type resolver map[string][]string
+
+func (r resolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	if name == "servfail.openstat.us" {
+		return nil, &net.DNSError{Err: "server misbehaving", Name: name}
+	}
+	txt, ok := r[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+
+	return txt, nil
+}
+
+func TestCheck(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	valid := resolver{
+		"openstat.us":                        {"google-site-verification=abc", "v=spf1 include:_spf.openstat.us ip4:192.0.2.0/24 ip6:2001:db8::/32 mx -all"},
+		"_spf.openstat.us":                   {"v=spf1 a:mail.openstat.us ~all"},
+		"google._domainkey.openstat.us":      {"v=DKIM1; k=rsa; p=" + base64.StdEncoding.EncodeToString(der)},
+		"ed._domainkey.openstat.us":          {"v=DKIM1; k=ed25519; p=" + base64.StdEncoding.EncodeToString(edKey)},
+		"_dmarc.openstat.us":                 {"v=DMARC1; p=quarantine; pct=100; rua=mailto:dmarc@openstat.us"},
+		"revoked._domainkey.openstat.us":     {"v=DKIM1; p="},
+		"invalid._domainkey.openstat.us":     {"v=DKIM1; k=rsa; p=not-base64!"},
+		"_dmarc.none.openstat.us":            {"v=DMARC1; p=none"},
+		"none.openstat.us":                   {"v=spf1 include:missing.openstat.us -all"},
+		"loop.openstat.us":                   {"v=spf1 include:loop.openstat.us -all"},
+		"broken.openstat.us":                 {"v=spf1 ip4:192.0.2.300 -all", "v=spf1 -all"},
+		"_dmarc.broken.openstat.us":          {"v=DMARC1; p=reject", "v=DMARC1; p=none"},
+		"syntax.openstat.us":                 {"v=spf1 include: -all"},
+		"_dmarc.syntax.openstat.us":          {"v=DMARC1; p=block"},
+		"google._domainkey.none.openstat.us": {"v=DKIM1; p=" + base64.StdEncoding.EncodeToString(der)},
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		report, err := mailauth.Check(t.Context(), valid, "openstat.us", mailauth.Config{Selectors: []string{"google", "ed"}, MinPolicy: "quarantine"})
+		require.NoError(t, err)
+		assert.Empty(t, report.Broken())
+		assert.Equal(t, 3, report.SPFLookups)
+		assert.Equal(t, "quarantine", report.Policy)
+		require.Len(t, report.DKIM, 2)
+		assert.Equal(t, "google._domainkey.openstat.us", report.DKIM[0].Name)
+	})
+
+	t.Run("broken keys", func(t *testing.T) {
+		report, err := mailauth.Check(t.Context(), valid, "openstat.us", mailauth.Config{Selectors: []string{"revoked", "invalid", "missing"}})
+		require.NoError(t, err)
+		require.Len(t, report.DKIM, 3)
+		assert.Equal(t, "the key is revoked", report.DKIM[0].Error)
+		assert.Equal(t, "the key is not valid base64", report.DKIM[1].Error)
+		assert.Equal(t, "no DKIM record", report.DKIM[2].Error)
+	})
+
+	t.Run("weak policy and missing include", func(t *testing.T) {
+		report, err := mailauth.Check(t.Context(), valid, "none.openstat.us", mailauth.Config{Selectors: []string{"google"}, MinPolicy: "quarantine"})
+		require.NoError(t, err)
+		assert.Equal(t, "include:missing.openstat.us: no SPF record", report.SPF.Error)
+		assert.Equal(t, "policy none, at least quarantine expected", report.DMARC.Error)
+		assert.Len(t, report.Broken(), 2)
+	})
+
+	t.Run("duplicated records", func(t *testing.T) {
+		report, err := mailauth.Check(t.Context(), valid, "broken.openstat.us", mailauth.Config{})
+		require.NoError(t, err)
+		assert.Equal(t, "2 SPF records, a single one expected", report.SPF.Error)
+		assert.Equal(t, "2 DMARC records, a single one expected", report.DMARC.Error)
+	})
+
+	t.Run("syntax", func(t *testing.T) {
+		report, err := mailauth.Check(t.Context(), valid, "syntax.openstat.us", mailauth.Config{})
+		require.NoError(t, err)
+		assert.Equal(t, "include is missing its domain", report.SPF.Error)
+		assert.Equal(t, `invalid policy "block"`, report.DMARC.Error)
+	})
+
+	t.Run("too many lookups", func(t *testing.T) {
+		report, err := mailauth.Check(t.Context(), valid, "loop.openstat.us", mailauth.Config{})
+		require.NoError(t, err)
+		assert.Contains(t, report.SPF.Error, "more than 10 DNS lookups")
+	})
+
+	t.Run("removed records", func(t *testing.T) {
+		report, err := mailauth.Check(t.Context(), valid, "removed.openstat.us", mailauth.Config{})
+		require.NoError(t, err)
+		assert.Equal(t, "no SPF record", report.SPF.Error)
+		assert.Equal(t, "no DMARC record", report.DMARC.Error)
+	})
+
+	t.Run("lookup failure", func(t *testing.T) {
+		_, err := mailauth.Check(t.Context(), valid, "servfail.openstat.us", mailauth.Config{})
+		assert.ErrorContains(t, err, "server misbehaving")
+	})
+}
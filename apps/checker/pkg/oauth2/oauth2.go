@@ -0,0 +1,206 @@
+// Package oauth2 fetches the tokens of the OAuth2 client credentials grant
+// for authenticated checks, and keeps them until they expire so a monitor
+// checked every minute does not hit the token endpoint every minute.
+package oauth2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// expiryDelta renews the tokens before they expire, so a token does not
+// expire between its fetch and the request of the check.
+const expiryDelta = 30 * time.Second
+
+// defaultLifetime is how long the tokens of a server not sending expires_in
+// are kept.
+const defaultLifetime = time.Minute
+
+type Config struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+type token struct {
+	expires       time.Time
+	authorization string
+}
+
+// Cache keeps the tokens of each client until they expire. A nil *Cache is
+// valid and fetches a token for every request.
+type Cache struct {
+	now    func() time.Time
+	tokens map[string]token
+	mu     sync.Mutex
+}
+
+func NewCache() *Cache {
+	return &Cache{now: time.Now, tokens: make(map[string]token)}
+}
+
+// Authorization returns the Authorization header of cfg, fetching a token
+// from the token endpoint with client when none is cached.
+func (c *Cache) Authorization(ctx context.Context, client *http.Client, cfg Config) (string, error) {
+	key := cfg.key()
+	if c != nil {
+		c.mu.Lock()
+		t, ok := c.tokens[key]
+		c.mu.Unlock()
+		if ok && c.now().Before(t.expires) {
+			return t.authorization, nil
+		}
+	}
+
+	authorization, lifetime, err := fetch(ctx, client, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	if c != nil {
+		c.mu.Lock()
+		now := c.now()
+		for k, t := range c.tokens {
+			if now.After(t.expires) {
+				delete(c.tokens, k)
+			}
+		}
+		c.tokens[key] = token{authorization: authorization, expires: now.Add(lifetime - expiryDelta)}
+		c.mu.Unlock()
+	}
+
+	return authorization, nil
+}
+
+// Invalidate drops the token of cfg, once the target rejected it.
+func (c *Cache) Invalidate(cfg Config) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.tokens, cfg.key())
+}
+
+// key identifies the token of a client, the secret is hashed with the rest
+// so a rotated secret does not reuse the token of the previous one.
+func (cfg Config) key() string {
+	h := sha256.New()
+	for _, part := range []string{cfg.TokenURL, cfg.ClientID, cfg.ClientSecret, strings.Join(cfg.Scopes, " ")} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func fetch(ctx context.Context, client *http.Client, cfg Config) (string, time.Duration, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(cfg.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("unable to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(cfg.ClientID), url.QueryEscape(cfg.ClientSecret))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("unable to fetch token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", 0, fmt.Errorf("unable to read token response: %w", err)
+	}
+
+	var payload struct {
+		AccessToken      string `json:"access_token"`
+		TokenType        string `json:"token_type"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+		ExpiresIn        int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil && resp.StatusCode == http.StatusOK {
+		return "", 0, fmt.Errorf("unable to decode token response: %w", err)
+	}
+
+	switch {
+	case payload.Error != "":
+		return "", 0, fmt.Errorf("unable to fetch token: %s %s", payload.Error, payload.ErrorDescription)
+	case resp.StatusCode != http.StatusOK:
+		return "", 0, fmt.Errorf("unable to fetch token: unexpected status code %d", resp.StatusCode)
+	case payload.AccessToken == "":
+		return "", 0, fmt.Errorf("unable to fetch token: no access_token in the response")
+	}
+
+	tokenType := payload.TokenType
+	// Servers often answer "bearer", which some APIs reject.
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	lifetime := defaultLifetime
+	if payload.ExpiresIn > 0 {
+		lifetime = max(time.Duration(payload.ExpiresIn)*time.Second, expiryDelta+time.Second)
+	}
+
+	return tokenType + " " + payload.AccessToken, lifetime, nil
+}
+
+// Transport sets the Authorization header of the requests to Host, the host
+// of the checked URL, so the token does not follow a redirect elsewhere.
+// A token rejected with 401 is dropped and fetched again by the next check.
+type Transport struct {
+	Base   http.RoundTripper
+	Cache  *Cache
+	Client *http.Client
+	Host   string
+	Config Config
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host != t.Host {
+		return t.Base.RoundTrip(req)
+	}
+
+	authorization, err := t.Cache.Authorization(req.Context(), t.Client, t.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	// RoundTrippers must not modify the request they are given.
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := t.Base.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		t.Cache.Invalidate(t.Config)
+	}
+
+	return resp, err
+}
+
+// CloseIdleConnections closes the connections of the base transport, the
+// check clients close them once done.
+func (t *Transport) CloseIdleConnections() {
+	if c, ok := t.Base.(interface{ CloseIdleConnections() }); ok {
+		c.CloseIdleConnections()
+	}
+}
@@ -0,0 +1,98 @@
+package oauth2_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/oauth2"
+)
+
+func TestCache_Authorization(t *testing.T) {
+	var fetches int
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		id, secret, _ := r.BasicAuth()
+		require.NoError(t, r.ParseForm())
+		if id != "client" || secret != "s3cret" || r.PostForm.Get("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+			return
+		}
+		assert.Equal(t, "read write", r.PostForm.Get("scope"))
+		_, _ = w.Write([]byte(`{"access_token":"token","token_type":"bearer","expires_in":3600}`))
+	}))
+	defer tokens.Close()
+
+	cfg := oauth2.Config{TokenURL: tokens.URL, ClientID: "client", ClientSecret: "s3cret", Scopes: []string{"read", "write"}}
+
+	t.Run("cached", func(t *testing.T) {
+		fetches = 0
+		cache := oauth2.NewCache()
+		for range 2 {
+			authorization, err := cache.Authorization(t.Context(), tokens.Client(), cfg)
+			require.NoError(t, err)
+			assert.Equal(t, "Bearer token", authorization)
+		}
+		assert.Equal(t, 1, fetches)
+
+		cache.Invalidate(cfg)
+		_, err := cache.Authorization(t.Context(), tokens.Client(), cfg)
+		require.NoError(t, err)
+		assert.Equal(t, 2, fetches)
+	})
+
+	t.Run("nil cache", func(t *testing.T) {
+		fetches = 0
+		var cache *oauth2.Cache
+		for range 2 {
+			_, err := cache.Authorization(t.Context(), tokens.Client(), cfg)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, fetches)
+	})
+
+	t.Run("rejected client", func(t *testing.T) {
+		wrong := cfg
+		wrong.ClientSecret = "wrong"
+		_, err := oauth2.NewCache().Authorization(t.Context(), tokens.Client(), wrong)
+		assert.ErrorContains(t, err, "invalid_client")
+	})
+}
+
+func TestTransport(t *testing.T) {
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+	}))
+	defer tokens.Close()
+
+	var authorization string
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer target.Close()
+
+	req, err := http.NewRequest(http.MethodGet, target.URL, nil)
+	require.NoError(t, err)
+	transport := &oauth2.Transport{
+		Base:   http.DefaultTransport,
+		Client: tokens.Client(),
+		Host:   req.URL.Host,
+		Config: oauth2.Config{TokenURL: tokens.URL, ClientID: "client", ClientSecret: "s3cret"},
+	}
+
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "Bearer token", authorization)
+	assert.Empty(t, req.Header.Get("Authorization"), "the request of the caller is not modified")
+
+	transport.Host = "elsewhere.openstat.us"
+	resp, err = (&http.Client{Transport: transport}).Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Empty(t, authorization, "the token is only sent to the checked host")
+}
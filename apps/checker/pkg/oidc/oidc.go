@@ -0,0 +1,301 @@
+// Package oidc checks an OpenID Connect identity provider: the structure of
+// its discovery document and the keys of its JWKS, with the validity of
+// their certificates, whose breakage fails every login relying on it before
+// anyone notices the provider itself.
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+)
+
+// DiscoveryPath is the path of the discovery document under the issuer.
+const DiscoveryPath = "/.well-known/openid-configuration"
+
+// MaxDocumentBytes caps the discovery document and the JWKS read.
+const MaxDocumentBytes = 1 << 20
+
+// MinRSABits is the smallest RSA key of a JWKS accepted.
+const MinRSABits = 2048
+
+// curveBytes are the sizes of the coordinates of the EC and OKP curves.
+var curveBytes = map[string]int{"P-256": 32, "P-384": 48, "P-521": 66, "Ed25519": 32, "Ed448": 57}
+
+// Config is what an identity provider is checked for.
+type Config struct {
+	// KeyIDs are the ids of the keys the JWKS must hold, none by default.
+	KeyIDs []string
+	// MinValidity is how long the certificates of the keys must remain
+	// valid, until they expire by default.
+	MinValidity time.Duration
+}
+
+// Key is a key of the JWKS, with its problem when it is broken.
+type Key struct {
+	ID   string `json:"kid,omitempty"`
+	Type string `json:"kty"`
+	Use  string `json:"use,omitempty"`
+	Alg  string `json:"alg,omitempty"`
+	// Bits is the size of RSA keys, Curve the curve of EC and OKP ones.
+	Bits  int    `json:"bits,omitempty"`
+	Curve string `json:"crv,omitempty"`
+	// NotAfter is the expiry of the certificate of the key, when it has one.
+	NotAfter *time.Time `json:"notAfter,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// Report is the outcome of the check of an identity provider.
+type Report struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwksUri,omitempty"`
+	// Problems are the ones of the discovery document and of the JWKS as a
+	// whole.
+	Problems []string `json:"problems,omitempty"`
+	Keys     []Key    `json:"keys,omitempty"`
+}
+
+// Broken returns the problems of r, the ones of its keys prefixed with
+// their id.
+func (r Report) Broken() []string {
+	broken := slices.Clone(r.Problems)
+	for _, key := range r.Keys {
+		if key.Error != "" {
+			broken = append(broken, fmt.Sprintf("key %s: %s", key.ID, key.Error))
+		}
+	}
+
+	return broken
+}
+
+// discovery holds the fields of a discovery document which are checked.
+type discovery struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	JWKSURI               string   `json:"jwks_uri"`
+	ResponseTypes         []string `json:"response_types_supported"`
+	SubjectTypes          []string `json:"subject_types_supported"`
+	SigningAlgs           []string `json:"id_token_signing_alg_values_supported"`
+}
+
+type jwk struct {
+	ID    string   `json:"kid"`
+	Type  string   `json:"kty"`
+	Use   string   `json:"use"`
+	Alg   string   `json:"alg"`
+	N     string   `json:"n"`
+	E     string   `json:"e"`
+	Curve string   `json:"crv"`
+	X     string   `json:"x"`
+	Y     string   `json:"y"`
+	X5C   []string `json:"x5c"`
+}
+
+// Check fetches the discovery document of issuer and its JWKS with client. A
+// missing field, key or expired certificate is reported in the Report, the
+// error being the failure of a request.
+func Check(ctx context.Context, client *http.Client, issuer string, cfg Config) (Report, error) {
+	issuer = strings.TrimSuffix(issuer, "/")
+	report := Report{Issuer: issuer}
+
+	var doc discovery
+	if ok, err := fetch(ctx, client, issuer+DiscoveryPath, &doc); err != nil || !ok {
+		if err == nil {
+			report.Problems = append(report.Problems, "the discovery document is not valid JSON")
+		}
+		return report, err
+	}
+	report.checkDiscovery(doc)
+	if doc.JWKSURI == "" || !isHTTPURL(doc.JWKSURI) {
+		return report, nil
+	}
+	report.JWKSURI = doc.JWKSURI
+
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if ok, err := fetch(ctx, client, doc.JWKSURI, &jwks); err != nil || !ok {
+		if err == nil {
+			report.Problems = append(report.Problems, "the JWKS is not valid JSON")
+		}
+		return report, err
+	}
+	if len(jwks.Keys) == 0 {
+		report.Problems = append(report.Problems, "the JWKS has no keys")
+		return report, nil
+	}
+
+	signing := false
+	for _, k := range jwks.Keys {
+		key := Key{ID: k.ID, Type: k.Type, Use: k.Use, Alg: k.Alg, Curve: k.Curve}
+		if err := checkKey(&key, k, cfg.MinValidity); err != nil {
+			key.Error = err.Error()
+		} else if k.Use == "" || k.Use == "sig" {
+			signing = true
+		}
+		report.Keys = append(report.Keys, key)
+	}
+	if !signing {
+		report.Problems = append(report.Problems, "the JWKS has no valid signing key")
+	}
+	for _, id := range cfg.KeyIDs {
+		if !slices.ContainsFunc(jwks.Keys, func(k jwk) bool { return k.ID == id }) {
+			report.Problems = append(report.Problems, fmt.Sprintf("key %s is missing from the JWKS", id))
+		}
+	}
+
+	return report, nil
+}
+
+// fetch decodes the JSON at rawURL into v, returning false when the response
+// is not valid JSON and an error when the request failed or was not
+// successful.
+func fetch(ctx context.Context, client *http.Client, rawURL string, v any) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		class := checker.ClassifyStatus(res.StatusCode)
+		if class == "" {
+			class = checker.ErrorClassUnknown
+		}
+		return false, &checker.ClassifiedError{Class: class, Err: fmt.Errorf("%s answered %d", rawURL, res.StatusCode)}
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, MaxDocumentBytes))
+	if err != nil {
+		return false, fmt.Errorf("unable to read %s: %w", rawURL, err)
+	}
+
+	return json.Unmarshal(body, v) == nil, nil
+}
+
+// checkDiscovery checks the fields of doc required by OpenID Connect
+// Discovery.
+func (r *Report) checkDiscovery(doc discovery) {
+	if strings.TrimSuffix(doc.Issuer, "/") != r.Issuer {
+		r.Problems = append(r.Problems, fmt.Sprintf("issuer %q does not match the URL", doc.Issuer))
+	}
+	for _, endpoint := range []struct{ name, value string }{
+		{"authorization_endpoint", doc.AuthorizationEndpoint},
+		{"jwks_uri", doc.JWKSURI},
+	} {
+		switch {
+		case endpoint.value == "":
+			r.Problems = append(r.Problems, "missing "+endpoint.name)
+		case !isHTTPURL(endpoint.value):
+			r.Problems = append(r.Problems, fmt.Sprintf("invalid %s %q", endpoint.name, endpoint.value))
+		}
+	}
+	if doc.TokenEndpoint != "" && !isHTTPURL(doc.TokenEndpoint) {
+		r.Problems = append(r.Problems, fmt.Sprintf("invalid token_endpoint %q", doc.TokenEndpoint))
+	}
+	for _, list := range []struct {
+		name   string
+		values []string
+	}{
+		{"response_types_supported", doc.ResponseTypes},
+		{"subject_types_supported", doc.SubjectTypes},
+		{"id_token_signing_alg_values_supported", doc.SigningAlgs},
+	} {
+		if len(list.values) == 0 {
+			r.Problems = append(r.Problems, "missing "+list.name)
+		}
+	}
+}
+
+func isHTTPURL(value string) bool {
+	u, err := url.Parse(value)
+
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
+
+// checkKey checks the parameters of k and the certificate of its x5c, if
+// any, filling in key.
+func checkKey(key *Key, k jwk, minValidity time.Duration) error {
+	var rsaKey *rsa.PublicKey
+	switch k.Type {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil || len(n) == 0 {
+			return errors.New("invalid modulus")
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return errors.New("invalid exponent")
+		}
+		rsaKey = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		key.Bits = rsaKey.N.BitLen()
+		if key.Bits < MinRSABits {
+			return fmt.Errorf("RSA key of %d bits, at least %d expected", key.Bits, MinRSABits)
+		}
+	case "EC", "OKP":
+		size, ok := curveBytes[k.Curve]
+		if !ok {
+			return fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		coordinates := []string{k.X}
+		if k.Type == "EC" {
+			coordinates = append(coordinates, k.Y)
+		}
+		for _, c := range coordinates {
+			if b, err := base64.RawURLEncoding.DecodeString(c); err != nil || len(b) != size {
+				return fmt.Errorf("invalid %s coordinates", k.Curve)
+			}
+		}
+	case "":
+		return errors.New("missing kty")
+	default:
+		return fmt.Errorf("unsupported key type %q", k.Type)
+	}
+
+	if len(k.X5C) == 0 {
+		return nil
+	}
+	der, err := base64.StdEncoding.DecodeString(k.X5C[0])
+	if err != nil {
+		return errors.New("the certificate is not valid base64")
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return fmt.Errorf("invalid certificate: %w", err)
+	}
+	key.NotAfter = &cert.NotAfter
+
+	now := time.Now()
+	switch {
+	case now.Before(cert.NotBefore):
+		return fmt.Errorf("the certificate is not valid before %s", cert.NotBefore.UTC().Format(time.RFC3339))
+	case now.After(cert.NotAfter):
+		return fmt.Errorf("the certificate expired on %s", cert.NotAfter.UTC().Format(time.RFC3339))
+	case now.Add(minValidity).After(cert.NotAfter):
+		return fmt.Errorf("the certificate expires on %s", cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+	if certKey, ok := cert.PublicKey.(*rsa.PublicKey); rsaKey != nil && (!ok || !certKey.Equal(rsaKey)) {
+		return errors.New("the certificate does not match the key")
+	}
+
+	return nil
+}
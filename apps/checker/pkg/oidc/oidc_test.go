@@ -0,0 +1,167 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/oidc"
+)
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func rsaKey(t *testing.T, kid string, bits int, notAfter time.Time) map[string]any {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: kid},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return map[string]any{
+		"kid": kid,
+		"kty": "RSA",
+		"use": "sig",
+		"alg": "RS256",
+		"n":   b64(key.N.Bytes()),
+		"e":   b64(big.NewInt(int64(key.E)).Bytes()),
+		"x5c": []string{base64.StdEncoding.EncodeToString(der)},
+	}
+}
+
+func ecKey(t *testing.T, kid string) map[string]any {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return map[string]any{"kid": kid, "kty": "EC", "crv": "P-256", "x": b64(key.X.FillBytes(make([]byte, 32))), "y": b64(key.Y.FillBytes(make([]byte, 32)))}
+}
+
+// provider serves a discovery document, edited by discovery, and the JWKS of
+// keys.
+func provider(t *testing.T, discovery func(doc map[string]any), keys ...map[string]any) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc(oidc.DiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		doc := map[string]any{
+			"issuer":                                server.URL,
+			"authorization_endpoint":                server.URL + "/authorize",
+			"token_endpoint":                        server.URL + "/token",
+			"jwks_uri":                              server.URL + "/jwks",
+			"response_types_supported":              []string{"code"},
+			"subject_types_supported":               []string{"public"},
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		}
+		if discovery != nil {
+			discovery(doc)
+		}
+		json.NewEncoder(w).Encode(doc)
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestCheck(t *testing.T) {
+	server := provider(t, nil, rsaKey(t, "current", 2048, time.Now().Add(90*24*time.Hour)), ecKey(t, "ec"))
+
+	report, err := oidc.Check(context.Background(), server.Client(), server.URL+"/", oidc.Config{KeyIDs: []string{"current", "ec"}, MinValidity: 30 * 24 * time.Hour})
+	require.NoError(t, err)
+	assert.Empty(t, report.Broken())
+	assert.Equal(t, server.URL+"/jwks", report.JWKSURI)
+	if assert.Len(t, report.Keys, 2) {
+		assert.Equal(t, 2048, report.Keys[0].Bits)
+		assert.NotNil(t, report.Keys[0].NotAfter)
+		assert.Equal(t, "P-256", report.Keys[1].Curve)
+	}
+}
+
+func TestCheckBrokenKeys(t *testing.T) {
+	expired := rsaKey(t, "expired", 2048, time.Now().Add(-time.Hour))
+	expiring := rsaKey(t, "expiring", 2048, time.Now().Add(24*time.Hour))
+	weak := rsaKey(t, "weak", 1024, time.Now().Add(time.Hour))
+	mismatched := rsaKey(t, "mismatched", 2048, time.Now().Add(90*24*time.Hour))
+	mismatched["x5c"] = rsaKey(t, "other", 2048, time.Now().Add(90*24*time.Hour))["x5c"]
+	server := provider(t, nil, expired, expiring, weak, mismatched, map[string]any{"kid": "oct", "kty": "oct", "k": "c2VjcmV0"})
+
+	report, err := oidc.Check(context.Background(), server.Client(), server.URL, oidc.Config{KeyIDs: []string{"next"}, MinValidity: 7 * 24 * time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"the JWKS has no valid signing key", "key next is missing from the JWKS"}, report.Problems)
+	if assert.Len(t, report.Keys, 5) {
+		assert.Contains(t, report.Keys[0].Error, "expired")
+		assert.Contains(t, report.Keys[1].Error, "expires")
+		assert.Equal(t, "RSA key of 1024 bits, at least 2048 expected", report.Keys[2].Error)
+		assert.Equal(t, "the certificate does not match the key", report.Keys[3].Error)
+		assert.Equal(t, `unsupported key type "oct"`, report.Keys[4].Error)
+	}
+	assert.Len(t, report.Broken(), 7)
+}
+
+func TestCheckDiscovery(t *testing.T) {
+	server := provider(t, func(doc map[string]any) {
+		doc["issuer"] = "https://accounts.openstat.us"
+		doc["authorization_endpoint"] = "/authorize"
+		delete(doc, "subject_types_supported")
+	}, ecKey(t, "ec"))
+
+	report, err := oidc.Check(context.Background(), server.Client(), server.URL, oidc.Config{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`issuer "https://accounts.openstat.us" does not match the URL`,
+		`invalid authorization_endpoint "/authorize"`,
+		"missing subject_types_supported",
+	}, report.Problems)
+	assert.Len(t, report.Keys, 1, "the JWKS is checked all the same")
+
+	server = provider(t, func(doc map[string]any) { delete(doc, "jwks_uri") })
+	report, err = oidc.Check(context.Background(), server.Client(), server.URL, oidc.Config{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"missing jwks_uri"}, report.Problems)
+	assert.Empty(t, report.Keys)
+}
+
+func TestCheckUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	_, err := oidc.Check(context.Background(), server.Client(), server.URL, oidc.Config{})
+	assert.ErrorContains(t, err, "answered 404")
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>"))
+	}))
+	t.Cleanup(server.Close)
+
+	report, err := oidc.Check(context.Background(), server.Client(), server.URL, oidc.Config{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"the discovery document is not valid JSON"}, report.Problems)
+}
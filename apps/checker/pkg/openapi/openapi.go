@@ -0,0 +1,230 @@
+// Package openapi builds the OpenAPI 3 document of the checker from the Go
+// types of its requests and responses, so the spec cannot drift from the
+// structs the handlers bind.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Schema map[string]any
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Parameter struct {
+	Schema   Schema `json:"schema"`
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required"`
+}
+
+type MediaType struct {
+	Schema Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Content  map[string]MediaType `json:"content"`
+	Required bool                 `json:"required"`
+}
+
+type Response struct {
+	Content     map[string]MediaType `json:"content,omitempty"`
+	Description string               `json:"description"`
+}
+
+type Operation struct {
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Summary     string                `json:"summary,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]Schema `json:"schemas"`
+	SecuritySchemes map[string]Schema `json:"securitySchemes"`
+}
+
+type Document struct {
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Info       Info                             `json:"info"`
+	OpenAPI    string                           `json:"openapi"`
+	Components Components                       `json:"components"`
+	mu         sync.Mutex
+}
+
+const securityScheme = "cronSecret"
+
+func New(title, version string) *Document {
+	return &Document{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: title, Version: version},
+		Paths:   make(map[string]map[string]*Operation),
+		Components: Components{
+			Schemas: make(map[string]Schema),
+			SecuritySchemes: map[string]Schema{
+				securityScheme: {
+					"type":        "apiKey",
+					"in":          "header",
+					"name":        "Authorization",
+					"description": "`Basic <CRON_SECRET>`",
+				},
+			},
+		},
+	}
+}
+
+// Add documents an authenticated endpoint. path uses the gin syntax, path
+// parameters are documented as strings. req and res are zero values of the
+// request and response bodies, nil when there is none.
+func (d *Document) Add(method, path, summary string, req, res any) *Operation {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	segments := strings.Split(path, "/")
+	var params []Parameter
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+			params = append(params, Parameter{Name: name, In: "path", Required: true, Schema: Schema{"type": "string"}})
+		}
+	}
+
+	op := &Operation{
+		Summary:     summary,
+		OperationID: operationID(method, segments),
+		Parameters:  params,
+		Responses: map[string]Response{
+			"401": {Description: "Missing or invalid secret"},
+		},
+		Security: []map[string][]string{{securityScheme: {}}},
+	}
+
+	if req != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: d.schema(reflect.TypeOf(req))}},
+		}
+		op.Responses["400"] = Response{Description: "Invalid request"}
+	}
+
+	ok := Response{Description: "Check result"}
+	if res != nil {
+		ok.Content = map[string]MediaType{"application/json": {Schema: d.schema(reflect.TypeOf(res))}}
+	}
+	op.Responses["200"] = ok
+
+	route := strings.Join(segments, "/")
+	if d.Paths[route] == nil {
+		d.Paths[route] = make(map[string]*Operation)
+	}
+	d.Paths[route][strings.ToLower(method)] = op
+
+	return op
+}
+
+// Handler serves the document as JSON.
+func (d *Document) Handler(c *gin.Context) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c.JSON(http.StatusOK, d)
+}
+
+func operationID(method string, segments []string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, segment := range segments {
+		segment = strings.Trim(segment, "{}")
+		if segment == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(segment[:1]) + segment[1:])
+	}
+
+	return b.String()
+}
+
+var rawMessage = reflect.TypeOf(json.RawMessage{})
+
+// schema returns the schema of t. Named structs are stored once in the
+// components and referenced.
+func (d *Document) schema(t reflect.Type) Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == rawMessage {
+		return Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": d.schema(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": d.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+		if _, ok := d.Components.Schemas[t.Name()]; !ok {
+			// Reserve the name first so recursive types terminate.
+			d.Components.Schemas[t.Name()] = Schema{}
+			d.Components.Schemas[t.Name()] = d.structSchema(t)
+		}
+		return Schema{"$ref": "#/components/schemas/" + t.Name()}
+	default:
+		return Schema{}
+	}
+}
+
+func (d *Document) structSchema(t reflect.Type) Schema {
+	properties := make(map[string]Schema)
+
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+				walk(field.Type)
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+
+			properties[name] = d.schema(field.Type)
+		}
+	}
+	walk(t)
+
+	return Schema{"type": "object", "properties": properties}
+}
@@ -0,0 +1,57 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/openapi"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestDocument(t *testing.T) {
+	spec := openapi.New("checker", "1.0.0")
+	spec.Add(http.MethodPost, "/tcp/:region", "TCP check", request.TCPCheckerRequest{}, checker.TCPResponse{})
+
+	router := gin.New()
+	router.GET("/openapi.json", spec.Handler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var doc struct {
+		Paths      map[string]map[string]openapi.Operation `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]map[string]any `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+		OpenAPI string `json:"openapi"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+
+	assert.Equal(t, "3.0.3", doc.OpenAPI)
+
+	op, ok := doc.Paths["/tcp/{region}"]["post"]
+	require.True(t, ok)
+	assert.Equal(t, "postTcpRegion", op.OperationID)
+	require.Len(t, op.Parameters, 1)
+	assert.Equal(t, "region", op.Parameters[0].Name)
+	assert.Equal(t, "#/components/schemas/TCPCheckerRequest", op.RequestBody.Content["application/json"].Schema["$ref"])
+
+	req := doc.Components.Schemas["TCPCheckerRequest"]
+	assert.Equal(t, "string", req.Properties["uri"]["type"])
+	assert.Equal(t, "integer", req.Properties["timeout"]["type"])
+	assert.Equal(t, "#/components/schemas/RetryPolicy", req.Properties["retryPolicy"]["$ref"])
+	assert.Equal(t, "object", req.Properties["otelConfig"]["type"])
+
+	res := doc.Components.Schemas["TCPResponse"]
+	assert.Equal(t, "array", res.Properties["attempts"]["type"])
+}
@@ -113,13 +113,24 @@ func recordStatusCounter(ctx context.Context, meter metric.Meter, att metric.Mea
 	counter.Add(ctx, 1, att)
 }
 
+// labelAttributes returns the labels of a check as openstatus.label.<name>
+// attributes of its metrics.
+func labelAttributes(labels map[string]string) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(labels))
+	for name, value := range labels {
+		attrs = append(attrs, attribute.String("openstatus.label."+name, value))
+	}
+
+	return attrs
+}
+
 func RecordHTTPMetrics(ctx context.Context, req request.HttpCheckerRequest, result checker.Response, region string) {
 	withMeter(ctx, req.OtelConfig.Endpoint, req.OtelConfig.Headers, func(meter metric.Meter) {
-		att := metric.WithAttributes(
+		att := metric.WithAttributes(append([]attribute.KeyValue{
 			attribute.String("openstatus.probes", region),
 			attribute.String("openstatus.target", req.URL),
 			semconv.HTTPResponseStatusCode(result.Status),
-		)
+		}, labelAttributes(req.Labels)...)...)
 
 		if result.Error != "" {
 			recordErrorCounter(ctx, meter, att)
@@ -151,10 +162,10 @@ func RecordHTTPMetrics(ctx context.Context, req request.HttpCheckerRequest, resu
 
 func RecordDNSMetrics(ctx context.Context, req request.DNSCheckerRequest, latency int64, isError bool, region string) {
 	withMeter(ctx, req.OtelConfig.Endpoint, req.OtelConfig.Headers, func(meter metric.Meter) {
-		att := metric.WithAttributes(
+		att := metric.WithAttributes(append([]attribute.KeyValue{
 			attribute.String("openstatus.probes", region),
 			attribute.String("openstatus.target", req.URI),
-		)
+		}, labelAttributes(req.Labels)...)...)
 
 		if isError {
 			recordErrorCounter(ctx, meter, att)
@@ -171,10 +182,10 @@ func RecordDNSMetrics(ctx context.Context, req request.DNSCheckerRequest, latenc
 
 func RecordTCPMetrics(ctx context.Context, req request.TCPCheckerRequest, result checker.TCPResponse, region string) {
 	withMeter(ctx, req.OtelConfig.Endpoint, req.OtelConfig.Headers, func(meter metric.Meter) {
-		att := metric.WithAttributes(
+		att := metric.WithAttributes(append([]attribute.KeyValue{
 			attribute.String("openstatus.probes", region),
 			attribute.String("openstatus.target", req.URI),
-		)
+		}, labelAttributes(req.Labels)...)...)
 
 		if result.Error == 1 {
 			recordErrorCounter(ctx, meter, att)
@@ -190,6 +201,9 @@ func RecordTCPMetrics(ctx context.Context, req request.TCPCheckerRequest, result
 		}{
 			{"openstatus.tcp.request.duration", "Duration of the check", float64(result.Latency)},
 			{"openstatus.tcp.tcp.duration", "Duration of the TCP connection", float64(result.Timing.TCPDone - result.Timing.TCPStart)},
+			{"openstatus.tcp.dns.duration", "Duration of the DNS lookup", float64(result.Timing.DNSDone - result.Timing.DNSStart)},
+			{"openstatus.tcp.connection.duration", "Duration of the connection", float64(result.Timing.ConnectDone - result.Timing.ConnectStart)},
+			{"openstatus.tcp.tls.duration", "Duration of the TLS handshake", float64(result.Timing.TLSDone - result.Timing.TLSStart)},
 		}
 
 		for _, t := range timings {
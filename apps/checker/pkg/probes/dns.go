@@ -0,0 +1,98 @@
+package probes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/assertions"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// DNSResult is the outcome of a DNS check.
+type DNSResult struct {
+	Response *checker.DnsResponse
+	// Records are the records of Response by type.
+	Records map[string][]string
+	Timing  Timing
+	// OK reports whether the records passed the assertions.
+	OK bool
+}
+
+// DNS runs the DNS check of req once, within its timeout, and evaluates its
+// assertions.
+func (Prober) DNS(ctx context.Context, req request.DNSCheckerRequest) (DNSResult, error) {
+	start := time.Now()
+	res, err := LookupDNS(ctx, req)
+	if err != nil {
+		return DNSResult{}, err
+	}
+
+	ok, err := DNSAssertions(req.RawAssertions, res)
+	if err != nil {
+		return DNSResult{}, err
+	}
+
+	return DNSResult{Response: res, Records: DNSRecords(res), Timing: Timing{Total: time.Since(start)}, OK: ok}, nil
+}
+
+// LookupDNS runs the lookups of req within its timeout, of the ASCII form of
+// internationalized domain names.
+func LookupDNS(ctx context.Context, req request.DNSCheckerRequest) (*checker.DnsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, request.Timeout("dns", req.Timeout))
+	defer cancel()
+
+	host, err := request.ASCIIHost(req.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	return checker.Dns(ctx, host)
+}
+
+// DNSRecords returns the records of res by type, every type listed.
+func DNSRecords(res *checker.DnsResponse) map[string][]string {
+	return map[string][]string{
+		"A":     append([]string{}, res.A...),
+		"AAAA":  append([]string{}, res.AAAA...),
+		"CNAME": {res.CNAME},
+		"MX":    append([]string{}, res.MX...),
+		"NS":    append([]string{}, res.NS...),
+		"TXT":   append([]string{}, res.TXT...),
+	}
+}
+
+// DNSAssertions evaluates the dnsRecord assertions of a DNS check against its
+// records.
+func DNSAssertions(raw []json.RawMessage, res *checker.DnsResponse) (bool, error) {
+	for _, a := range raw {
+		var assert assertions.RecordTarget
+		if err := json.Unmarshal(a, &assert); err != nil {
+			return false, fmt.Errorf("unable to parse assertion: %w", err)
+		}
+		var ok bool
+		switch assert.Key {
+		case request.RecordA:
+			ok = assert.RecordEvaluate(res.A)
+		case request.RecordAAAA:
+			ok = assert.RecordEvaluate(res.AAAA)
+		case request.RecordCNAME:
+			ok = assert.RecordEvaluate([]string{res.CNAME})
+		case request.RecordMX:
+			ok = assert.RecordEvaluate(res.MX)
+		case request.RecordNS:
+			ok = assert.RecordEvaluate(res.NS)
+		case request.RecordTXT:
+			ok = assert.RecordEvaluate(res.TXT)
+		default:
+			return false, fmt.Errorf("unknown record type in assertion: %s", assert.Key)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,334 @@
+package probes
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/assertions"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// GRPCResult is the outcome of a gRPC check.
+type GRPCResult struct {
+	// Code and Message are the status of the call.
+	Code    codes.Code
+	Message string
+	// Response is the JSON of the response message, empty when the call
+	// failed.
+	Response string
+	Timing   Timing
+	OK       bool
+}
+
+// GRPC runs the gRPC check of req once, within its timeout: it resolves its
+// method with the server reflection of the target, invokes it with the JSON
+// of the body of req and evaluates the assertions on the status and the JSON
+// of the response.
+func (p Prober) GRPC(ctx context.Context, req request.GRPCCheckerRequest) (GRPCResult, error) {
+	timeout := request.Timeout("grpc", req.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	conn, dialErr, err := p.grpcConn(req, timeout)
+	if err != nil {
+		return GRPCResult{}, err
+	}
+	defer conn.Close()
+
+	service, name, _ := req.Service()
+	method, err := resolveMethod(ctx, conn, service, name)
+	if err != nil {
+		return GRPCResult{}, grpcError(err, dialErr())
+	}
+
+	in := dynamicpb.NewMessage(method.Input())
+	if len(req.Body) > 0 {
+		if err := protojson.Unmarshal(req.Body, in); err != nil {
+			return GRPCResult{}, fmt.Errorf("body is not a %s: %w", method.Input().FullName(), err)
+		}
+	}
+	out := dynamicpb.NewMessage(method.Output())
+
+	if len(req.Metadata) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(req.Metadata))
+	}
+	err = conn.Invoke(ctx, "/"+service+"/"+name, in, out)
+
+	st := status.Convert(err)
+	res := GRPCResult{Code: st.Code(), Message: st.Message(), Timing: Timing{Total: time.Since(start)}}
+	if st.Code() == codes.Unavailable || st.Code() == codes.DeadlineExceeded {
+		return res, grpcError(err, dialErr())
+	}
+	if err == nil {
+		data, err := protojson.Marshal(out)
+		if err != nil {
+			return res, fmt.Errorf("unable to encode response: %w", err)
+		}
+		res.Response = string(data)
+	}
+
+	res.OK, err = GRPCAssertions(req.RawAssertions, res)
+
+	return res, err
+}
+
+// GRPCAssertions evaluates the status, textBody and jsonBody assertions of a
+// gRPC check against res, which needs the OK code without assertions.
+func GRPCAssertions(raw []json.RawMessage, res GRPCResult) (bool, error) {
+	if len(raw) == 0 {
+		return res.Code == codes.OK, nil
+	}
+
+	isSuccessful := true
+	for _, a := range raw {
+		var assert request.Assertion
+		if err := json.Unmarshal(a, &assert); err != nil {
+			return false, fmt.Errorf("unable to unmarshal assertion: %w", err)
+		}
+		switch assert.AssertionType {
+		case request.AssertionStatus:
+			var target assertions.StatusTarget
+			if err := json.Unmarshal(a, &target); err != nil {
+				return false, fmt.Errorf("unable to unmarshal StatusTarget: %w", err)
+			}
+			isSuccessful = isSuccessful && target.StatusEvaluate(int64(res.Code))
+		case request.AssertionTextBody:
+			var target assertions.StringTargetType
+			if err := json.Unmarshal(a, &target); err != nil {
+				return false, fmt.Errorf("unable to unmarshal StringTargetType: %w", err)
+			}
+			isSuccessful = isSuccessful && target.StringEvaluate(res.Response)
+		case request.AssertionJsonBody:
+			var target assertions.JSONBodyTarget
+			if err := json.Unmarshal(a, &target); err != nil {
+				return false, fmt.Errorf("unable to unmarshal JSONBodyTarget: %w", err)
+			}
+			isSuccessful = isSuccessful && target.JSONBodyEvaluate(res.Response)
+		}
+	}
+
+	return isSuccessful, nil
+}
+
+// grpcConn returns the connection of the gRPC check of req, dialed through
+// the guard, and a function returning the last error of its dials.
+func (p Prober) grpcConn(req request.GRPCCheckerRequest, timeout time.Duration) (*grpc.ClientConn, func() error, error) {
+	creds := credentials.NewTLS(&tls.Config{})
+	if req.Plaintext {
+		creds = insecure.NewCredentials()
+	}
+
+	var (
+		mu      sync.Mutex
+		lastErr error
+	)
+	dialer := p.Guard.Dialer(timeout)
+	dial := func(ctx context.Context, address string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		mu.Lock()
+		lastErr = err
+		mu.Unlock()
+
+		return conn, err
+	}
+	dialErr := func() error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return lastErr
+	}
+
+	// passthrough leaves the resolution of the address to the dialer.
+	conn, err := grpc.NewClient("passthrough:///"+req.Address(), grpc.WithTransportCredentials(creds), grpc.WithContextDialer(dial))
+
+	return conn, dialErr, err
+}
+
+// grpcError returns the error of a call which did not reach the server,
+// the one of its dial when known, so it is classified.
+func grpcError(err, dialErr error) error {
+	code := status.Code(err)
+	if dialErr != nil && (code == codes.Unavailable || code == codes.DeadlineExceeded) {
+		return dialErr
+	}
+
+	switch code {
+	case codes.DeadlineExceeded:
+		return &checker.ClassifiedError{Class: checker.ErrorClassTimeout, Err: err}
+	case codes.Unavailable:
+		return &checker.ClassifiedError{Class: checker.ErrorClassConnectionRefused, Err: err}
+	}
+
+	return err
+}
+
+// resolveMethod returns the descriptor of the unary method name of service,
+// resolved with the server reflection of conn.
+func resolveMethod(ctx context.Context, conn *grpc.ClientConn, service, name string) (protoreflect.MethodDescriptor, error) {
+	files := map[string]*descriptorpb.FileDescriptorProto{}
+	add := func(res *reflectionv1.ServerReflectionResponse) error {
+		if e := res.GetErrorResponse(); e != nil {
+			return status.Error(codes.Code(e.GetErrorCode()), e.GetErrorMessage())
+		}
+		for _, raw := range res.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			var file descriptorpb.FileDescriptorProto
+			if err := proto.Unmarshal(raw, &file); err != nil {
+				return fmt.Errorf("invalid file descriptor: %w", err)
+			}
+			files[file.GetName()] = &file
+		}
+
+		return nil
+	}
+
+	res, err := reflect(ctx, conn, &reflectionv1.ServerReflectionRequest{
+		MessageRequest: &reflectionv1.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := add(res); err != nil {
+		return nil, fmt.Errorf("unable to resolve %s: %w", service, err)
+	}
+
+	// The servers usually send the dependencies of the file with it, the
+	// missing ones are the well-known types or asked for.
+	for {
+		var missing []string
+		for _, file := range files {
+			for _, dep := range file.GetDependency() {
+				if _, ok := files[dep]; !ok && !slices.Contains(missing, dep) {
+					missing = append(missing, dep)
+				}
+			}
+		}
+		if len(missing) == 0 {
+			break
+		}
+		for _, dep := range missing {
+			if known, err := protoregistry.GlobalFiles.FindFileByPath(dep); err == nil {
+				files[dep] = protodesc.ToFileDescriptorProto(known)
+				continue
+			}
+			res, err := reflect(ctx, conn, &reflectionv1.ServerReflectionRequest{
+				MessageRequest: &reflectionv1.ServerReflectionRequest_FileByFilename{FileByFilename: dep},
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := add(res); err != nil {
+				return nil, fmt.Errorf("unable to resolve %s: %w", dep, err)
+			}
+			if _, ok := files[dep]; !ok {
+				return nil, fmt.Errorf("unable to resolve %s", dep)
+			}
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, file := range files {
+		set.File = append(set.File, file)
+	}
+	registry, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptors of %s: %w", service, err)
+	}
+
+	descriptor, err := registry.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("unknown service %s", service)
+	}
+	sd, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", service)
+	}
+	method := sd.Methods().ByName(protoreflect.Name(name))
+	if method == nil {
+		return nil, fmt.Errorf("unknown method %s of %s", name, service)
+	}
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return nil, fmt.Errorf("%s/%s is a streaming method, only the unary ones are checked", service, name)
+	}
+
+	return method, nil
+}
+
+// reflect asks req to the server reflection of conn, falling back to its
+// v1alpha version, the only one of the older servers.
+func reflect(ctx context.Context, conn *grpc.ClientConn, req *reflectionv1.ServerReflectionRequest) (*reflectionv1.ServerReflectionResponse, error) {
+	stream, err := reflectionv1.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err == nil {
+		var res *reflectionv1.ServerReflectionResponse
+		if err = stream.Send(req); err == nil {
+			res, err = stream.Recv()
+		}
+		_ = stream.CloseSend()
+		if status.Code(err) != codes.Unimplemented {
+			return res, err
+		}
+	} else if status.Code(err) != codes.Unimplemented {
+		return nil, err
+	}
+
+	// Both versions have the same messages on the wire.
+	var alphaReq reflectionv1alpha.ServerReflectionRequest
+	if err := convert(req, &alphaReq); err != nil {
+		return nil, err
+	}
+	alpha, err := reflectionv1alpha.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer alpha.CloseSend()
+	if err := alpha.Send(&alphaReq); err != nil {
+		return nil, err
+	}
+	alphaRes, err := alpha.Recv()
+	if status.Code(err) == codes.Unimplemented {
+		return nil, errors.New("server reflection is not enabled on the target")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var res reflectionv1.ServerReflectionResponse
+	if err := convert(alphaRes, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func convert(from, to proto.Message) error {
+	data, err := proto.Marshal(from)
+	if err != nil {
+		return err
+	}
+
+	return proto.Unmarshal(data, to)
+}
@@ -0,0 +1,91 @@
+package probes_test
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/probes"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestProber_GRPC(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("openstatus", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	check := func(method, body string, assertions ...string) request.GRPCCheckerRequest {
+		req := request.GRPCCheckerRequest{URI: listener.Addr().String(), Method: method, Plaintext: true, Timeout: 2000}
+		if body != "" {
+			req.Body = json.RawMessage(body)
+		}
+		for _, a := range assertions {
+			req.RawAssertions = append(req.RawAssertions, json.RawMessage(a))
+		}
+		return req
+	}
+
+	t.Run("invoked", func(t *testing.T) {
+		res, err := probes.Prober{}.GRPC(t.Context(), check("grpc.health.v1.Health/Check", `{"service":"openstatus"}`,
+			`{"type":"jsonBody","compare":"eq","path":"status","target":"SERVING"}`))
+		require.NoError(t, err)
+		assert.True(t, res.OK)
+		assert.Equal(t, codes.OK, res.Code)
+		assert.JSONEq(t, `{"status":"SERVING"}`, res.Response)
+	})
+
+	t.Run("status", func(t *testing.T) {
+		res, err := probes.Prober{}.GRPC(t.Context(), check("/grpc.health.v1.Health/Check", `{"service":"unknown"}`))
+		require.NoError(t, err)
+		assert.False(t, res.OK)
+		assert.Equal(t, codes.NotFound, res.Code)
+
+		res, err = probes.Prober{}.GRPC(t.Context(), check("grpc.health.v1.Health/Check", `{"service":"unknown"}`,
+			`{"type":"status","compare":"eq","target":5}`))
+		require.NoError(t, err)
+		assert.True(t, res.OK)
+	})
+
+	t.Run("unknown method", func(t *testing.T) {
+		_, err := probes.Prober{}.GRPC(t.Context(), check("grpc.health.v1.Health/Ping", ""))
+		assert.ErrorContains(t, err, "unknown method Ping")
+	})
+
+	t.Run("streaming method", func(t *testing.T) {
+		_, err := probes.Prober{}.GRPC(t.Context(), check("grpc.health.v1.Health/Watch", ""))
+		assert.ErrorContains(t, err, "streaming method")
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		_, err := probes.Prober{}.GRPC(t.Context(), check("grpc.health.v1.Health/Check", `{"unknown":1}`))
+		assert.ErrorContains(t, err, "grpc.health.v1.HealthCheckRequest")
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		closed, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		address := closed.Addr().String()
+		closed.Close()
+
+		req := check("grpc.health.v1.Health/Check", "")
+		req.URI = address
+		_, err = probes.Prober{}.GRPC(t.Context(), req)
+		assert.Equal(t, checker.ErrorClassConnectionRefused, checker.ClassifyError(err))
+	})
+}
@@ -0,0 +1,214 @@
+package probes
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/assertions"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// HTTPResult is the outcome of an HTTP check.
+type HTTPResult struct {
+	Response checker.Response
+	Timing   Timing
+	// OK reports whether the response passed the assertions, or had a 2xx
+	// status without assertions.
+	OK bool
+	// Failed is the first assertion that failed, nil when none did.
+	Failed json.RawMessage
+}
+
+// HTTP runs the HTTP check of req once, within its timeout, and evaluates
+// its assertions. Its client certificate, proxy, IP family, connectTo and
+// redirects are applied, its auth block is not.
+func (p Prober) HTTP(ctx context.Context, req request.HttpCheckerRequest) (HTTPResult, error) {
+	client := p.HTTPClient(req)
+	defer client.CloseIdleConnections()
+
+	res, err := checker.Http(ctx, client, req)
+	if err != nil {
+		return HTTPResult{}, err
+	}
+
+	ok, err := HTTPAssertions(req.RawAssertions, res)
+	if err != nil {
+		return HTTPResult{}, err
+	}
+	result := HTTPResult{Response: res, Timing: HTTPTiming(res), OK: ok}
+	if !ok {
+		result.Failed = FailedHTTPAssertion(req.RawAssertions, res)
+	}
+
+	return result, nil
+}
+
+// HTTPClient returns a client for the HTTP check of req, not shared with
+// other checks so no connection is reused.
+func (p Prober) HTTPClient(req request.HttpCheckerRequest) *http.Client {
+	return &http.Client{
+		Timeout:       request.Timeout("http", req.Timeout),
+		Transport:     p.Transport(HTTPConnection(req)),
+		CheckRedirect: checker.CheckRedirect(req.FollowRedirects, req.MaxRedirects),
+	}
+}
+
+// Connection is how an HTTP check reaches its target.
+type Connection struct {
+	ClientCert *request.ClientCertificate
+	Proxy      *request.Proxy
+	IPFamily   string
+	// ConnectTo is dialed instead of the host of the URL, which still goes
+	// in the Host header and the SNI unless ServerName overrides it.
+	ConnectTo  string
+	ServerName string
+}
+
+// HTTPConnection returns the connection of the HTTP check of req.
+func HTTPConnection(req request.HttpCheckerRequest) Connection {
+	return Connection{
+		ClientCert: req.ClientCert,
+		Proxy:      req.Proxy,
+		IPFamily:   req.IPFamily,
+		ConnectTo:  req.ConnectTo,
+		ServerName: req.ServerName,
+	}
+}
+
+// Transport returns the transport of an HTTP check, presenting the client
+// certificate of the check, going through its proxy, dialing its IP family
+// only and connecting to its connectTo address. The certificate and proxy
+// were validated with the request and only live as long as the transport.
+func (p Prober) Transport(conn Connection) *http.Transport {
+	t := p.Guard.Transport()
+	if cfg, _ := conn.ClientCert.TLSConfig(); cfg != nil {
+		t.TLSClientConfig = cfg
+	}
+	if conn.ServerName != "" {
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		t.TLSClientConfig.ServerName = conn.ServerName
+	}
+	if u, _ := conn.Proxy.ProxyURL(); u != nil {
+		t.Proxy = http.ProxyURL(u)
+	}
+	if network := checker.Network(conn.IPFamily); network != "tcp" {
+		dial := t.DialContext
+		t.DialContext = func(ctx context.Context, _, addr string) (net.Conn, error) {
+			return dial(ctx, network, addr)
+		}
+	}
+	if conn.ConnectTo != "" {
+		t.DialContext = checker.DialTo(t.DialContext, conn.ConnectTo)
+	}
+
+	return t
+}
+
+// HTTPAssertions evaluates the assertions of an HTTP check against its
+// response. Without assertions, a 2xx status passes.
+func HTTPAssertions(raw []json.RawMessage, res checker.Response) (bool, error) {
+	if len(raw) == 0 {
+		return res.Status >= 200 && res.Status < 300, nil
+	}
+
+	headers, _ := json.Marshal(res.Headers)
+	isSuccessful := true
+	for _, a := range raw {
+		var assert request.Assertion
+		if err := json.Unmarshal(a, &assert); err != nil {
+			return false, fmt.Errorf("unable to unmarshal assertion: %w", err)
+		}
+		switch assert.AssertionType {
+		case request.AssertionHeader:
+			var target assertions.HeaderTarget
+			if err := json.Unmarshal(a, &target); err != nil {
+				return false, fmt.Errorf("unable to unmarshal HeaderTarget: %w", err)
+			}
+			isSuccessful = isSuccessful && target.HeaderEvaluate(string(headers))
+		case request.AssertionTextBody:
+			var target assertions.StringTargetType
+			if err := json.Unmarshal(a, &target); err != nil {
+				return false, fmt.Errorf("unable to unmarshal StringTargetType: %w", err)
+			}
+			isSuccessful = isSuccessful && target.StringEvaluate(res.Body)
+		case request.AssertionStatus:
+			var target assertions.StatusTarget
+			if err := json.Unmarshal(a, &target); err != nil {
+				return false, fmt.Errorf("unable to unmarshal StatusTarget: %w", err)
+			}
+			isSuccessful = isSuccessful && target.StatusEvaluate(int64(res.Status))
+		case request.AssertionTLSVersion:
+			isSuccessful = isSuccessful && TLSVersionAssertions([]json.RawMessage{a}, res.TLS) == nil
+		case request.AssertionSecurityGrade:
+			var target assertions.SecurityGradeTarget
+			if err := json.Unmarshal(a, &target); err != nil {
+				return false, fmt.Errorf("unable to unmarshal SecurityGradeTarget: %w", err)
+			}
+			grade := res.SecurityHeaders
+			if grade == nil {
+				grade = checker.GradeSecurityHeaders(res.Headers, res.TLS != nil)
+			}
+			isSuccessful = isSuccessful && target.SecurityGradeEvaluate(grade.Grade)
+		case request.AssertionCacheStatus:
+			var target assertions.StringTargetType
+			if err := json.Unmarshal(a, &target); err != nil {
+				return false, fmt.Errorf("unable to unmarshal StringTargetType: %w", err)
+			}
+			target.Target = strings.ToUpper(target.Target)
+			isSuccessful = isSuccessful && target.StringEvaluate(res.CacheStatus)
+		case request.AssertionJsonBody:
+			// TODO: Implement JSON body assertion
+		}
+		// Unknown assertion types are rejected with the request, and
+		// skipped here.
+	}
+
+	return isSuccessful, nil
+}
+
+// FailedHTTPAssertion returns the first of raw failing against res, nil when
+// none does.
+func FailedHTTPAssertion(raw []json.RawMessage, res checker.Response) json.RawMessage {
+	for _, a := range raw {
+		if ok, err := HTTPAssertions([]json.RawMessage{a}, res); err == nil && !ok {
+			return a
+		}
+	}
+
+	return nil
+}
+
+// TLSVersionAssertions checks the tlsVersion assertions of a check against
+// the TLS connection it made, which fail without one.
+func TLSVersionAssertions(raw []json.RawMessage, info *checker.TLSInfo) error {
+	var version string
+	if info != nil {
+		version = info.Version
+	}
+
+	for _, a := range raw {
+		var target assertions.TLSVersionTarget
+		if err := json.Unmarshal(a, &target); err != nil || target.AssertionType != request.AssertionTLSVersion {
+			continue
+		}
+		if !target.TLSVersionEvaluate(version) {
+			if version == "" {
+				version = "no TLS"
+			}
+			return &checker.ClassifiedError{
+				Class: checker.ErrorClassAssertion,
+				Err:   fmt.Errorf("tls version %s does not match %s %s", version, target.Comparator, target.Target),
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package probes
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// MaxPortDials caps the connections of a ports check in flight at once.
+const MaxPortDials = 10
+
+// PortResult is the state of a port of a ports check.
+type PortResult struct {
+	Port int  `json:"port"`
+	Open bool `json:"open"`
+	// Expected tells whether the port is expected open.
+	Expected  bool  `json:"expected"`
+	ConnectMs int64 `json:"connectMs,omitempty"`
+	// Error is the code of why a port is closed, e.g. CONN_REFUSED.
+	Error string `json:"error,omitempty"`
+}
+
+// PortsResult is the outcome of a ports check, its ports in ascending order.
+type PortsResult struct {
+	RemoteIP string
+	Ports    []PortResult
+	Timing   Timing
+}
+
+// Unexpected returns the ports whose state is not the expected one.
+func (r PortsResult) Unexpected() []PortResult {
+	var unexpected []PortResult
+	for _, port := range r.Ports {
+		if port.Open != port.Expected {
+			unexpected = append(unexpected, port)
+		}
+	}
+
+	return unexpected
+}
+
+// Ports runs the ports check of req once: it resolves its host, then
+// connects to each of its ports, MaxPortDials at a time and each within the
+// timeout of req. A refused or timed out connection means the port is
+// closed, the failure of the lookup is returned.
+func (p Prober) Ports(ctx context.Context, req request.PortsCheckerRequest) (PortsResult, error) {
+	host, _ := request.ASCIIHost(req.URI)
+	start := time.Now()
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			return PortsResult{}, err
+		}
+		addr = addrs[0]
+	}
+	result := PortsResult{RemoteIP: addr.Unmap().String(), Timing: Timing{DNS: time.Since(start)}}
+	if err := p.Guard.CheckAddr(addr); err != nil {
+		return result, &checker.ClassifiedError{Class: checker.ErrorClassBlocked, Err: err}
+	}
+
+	open, closed := req.Ports()
+	for _, port := range open {
+		result.Ports = append(result.Ports, PortResult{Port: port, Expected: true})
+	}
+	for _, port := range closed {
+		result.Ports = append(result.Ports, PortResult{Port: port})
+	}
+	slices.SortFunc(result.Ports, func(a, b PortResult) int { return a.Port - b.Port })
+
+	dialer := p.Guard.Dialer(request.Timeout("ports", req.Timeout))
+	sem := make(chan struct{}, MaxPortDials)
+	var wg sync.WaitGroup
+	for i := range result.Ports {
+		sem <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-sem }()
+			port := &result.Ports[i]
+			dialStart := time.Now()
+			conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(result.RemoteIP, strconv.Itoa(port.Port)))
+			if err != nil {
+				port.Error = string(checker.ClassifyCode(err))
+				return
+			}
+			conn.Close()
+			port.Open = true
+			port.ConnectMs = time.Since(dialStart).Milliseconds()
+		})
+	}
+	wg.Wait()
+	result.Timing.Total = time.Since(start)
+
+	return result, ctx.Err()
+}
@@ -0,0 +1,73 @@
+// Package probes runs the checks of OpenStatus, HTTP, TCP, DNS, gRPC, SMTP
+// and ports, and evaluates their assertions, without the checker service
+// around them: no gin, no Tinybird events, no retries and no status updates.
+// The handlers of the checker run their checks with it, and other Go
+// programs embed it to run the same checks:
+//
+//	res, err := probes.Prober{}.HTTP(ctx, request.HttpCheckerRequest{
+//		URL:    "https://openstat.us",
+//		Method: http.MethodGet,
+//	})
+//
+// An error means the target could not be checked, e.g. it refused the
+// connection, checker.ClassifyCode telling why. A target that answered but
+// failed its assertions is not OK.
+package probes
+
+import (
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/ssrf"
+)
+
+// Prober runs checks. Its zero value checks any target.
+type Prober struct {
+	// Guard keeps the checks off the internal network, nil to allow every
+	// target.
+	Guard *ssrf.Guard
+	// Inbox receives the messages of the SMTP checks, nil to fail them.
+	Inbox Inbox
+}
+
+// Timing holds the duration of each phase of a check, zero for the phases
+// the check type does not have.
+type Timing struct {
+	DNS     time.Duration
+	Connect time.Duration
+	// Proxy is the connection to the proxy of proxied HTTP checks.
+	Proxy     time.Duration
+	TLS       time.Duration
+	FirstByte time.Duration
+	Transfer  time.Duration
+	Total     time.Duration
+}
+
+// HTTPTiming returns the phases of an HTTP check.
+func HTTPTiming(res checker.Response) Timing {
+	return Timing{
+		DNS:       ms(res.Timing.DnsDone - res.Timing.DnsStart),
+		Connect:   ms(res.Timing.ConnectDone - res.Timing.ConnectStart),
+		Proxy:     ms(res.Timing.ProxyConnectDone - res.Timing.ProxyConnectStart),
+		TLS:       ms(res.Timing.TlsHandshakeDone - res.Timing.TlsHandshakeStart),
+		FirstByte: ms(res.Timing.FirstByteDone - res.Timing.FirstByteStart),
+		Transfer:  ms(res.Timing.TransferDone - res.Timing.TransferStart),
+		Total:     ms(res.Latency),
+	}
+}
+
+// TCPTiming returns the phases of a TCP check, whose total is the time to
+// connect, the TLS handshake excluded.
+func TCPTiming(t checker.TCPResponseTiming) Timing {
+	return Timing{
+		DNS:     ms(t.DNSDone - t.DNSStart),
+		Connect: ms(t.ConnectDone - t.ConnectStart),
+		TLS:     ms(t.TLSDone - t.TLSStart),
+		Total:   ms(t.TCPDone - t.TCPStart),
+	}
+}
+
+// ms converts the milliseconds of the timings of the checker.
+func ms(v int64) time.Duration {
+	return time.Duration(v) * time.Millisecond
+}
@@ -0,0 +1,156 @@
+package probes_test
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/probes"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/ssrf"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestProber_HTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer server.Close()
+
+	status := json.RawMessage(`{"type":"status","compare":"eq","target":200}`)
+	header := json.RawMessage(`{"type":"header","compare":"contains","key":"Content-Type","target":"json"}`)
+	body := json.RawMessage(`{"type":"textBody","compare":"contains","target":"down"}`)
+
+	res, err := probes.Prober{}.HTTP(t.Context(), request.HttpCheckerRequest{URL: server.URL, Method: http.MethodGet, RawAssertions: []json.RawMessage{status, header}})
+	require.NoError(t, err)
+	assert.True(t, res.OK)
+	assert.Nil(t, res.Failed)
+	assert.Equal(t, http.StatusOK, res.Response.Status)
+	assert.Equal(t, time.Duration(res.Response.Latency)*time.Millisecond, res.Timing.Total)
+
+	res, err = probes.Prober{}.HTTP(t.Context(), request.HttpCheckerRequest{URL: server.URL, Method: http.MethodGet, RawAssertions: []json.RawMessage{status, body}})
+	require.NoError(t, err)
+	assert.False(t, res.OK)
+	assert.JSONEq(t, string(body), string(res.Failed))
+}
+
+func TestProber_HTTPGuarded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer server.Close()
+
+	_, err := probes.Prober{Guard: ssrf.New(nil)}.HTTP(t.Context(), request.HttpCheckerRequest{URL: server.URL, Method: http.MethodGet})
+	assert.Error(t, err, "the loopback address is not allowed")
+}
+
+func TestProber_TCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	res, err := probes.Prober{}.TCP(t.Context(), request.TCPCheckerRequest{URI: listener.Addr().String()})
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", res.Dial.RemoteIP)
+
+	_, err = probes.Prober{}.TCP(t.Context(), request.TCPCheckerRequest{
+		URI:           listener.Addr().String(),
+		RawAssertions: []json.RawMessage{json.RawMessage(`{"type":"tlsVersion","compare":"gte","target":"1.2"}`)},
+	})
+	assert.Equal(t, checker.ErrorCodeAssertionFailed, checker.ClassifyCode(err), "the connection has no TLS")
+
+	hold := &request.Hold{Duration: 1000}
+	res, err = probes.Prober{}.TCP(t.Context(), request.TCPCheckerRequest{URI: listener.Addr().String(), Hold: hold})
+	assert.ErrorContains(t, err, "connection closed after")
+	assert.True(t, res.Dial.Hold.Dropped)
+
+	hold.ExpectDrop = true
+	_, err = probes.Prober{}.TCP(t.Context(), request.TCPCheckerRequest{URI: listener.Addr().String(), Hold: hold})
+	assert.NoError(t, err)
+}
+
+func TestProber_Ports(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closed.Close()
+
+	open := strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+	down := strconv.Itoa(closed.Addr().(*net.TCPAddr).Port)
+	res, err := probes.Prober{}.Ports(t.Context(), request.PortsCheckerRequest{URI: "127.0.0.1", Open: []string{open, down}, Timeout: 1000})
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", res.RemoteIP)
+	require.Len(t, res.Ports, 2)
+	unexpected := res.Unexpected()
+	require.Len(t, unexpected, 1)
+	assert.Equal(t, down, strconv.Itoa(unexpected[0].Port))
+	assert.Equal(t, string(checker.ErrorCodeConnRefused), unexpected[0].Error)
+
+	res, err = probes.Prober{}.Ports(t.Context(), request.PortsCheckerRequest{URI: "127.0.0.1", Closed: []string{open}, Timeout: 1000})
+	require.NoError(t, err)
+	assert.True(t, res.Ports[0].Open)
+	assert.Len(t, res.Unexpected(), 1)
+
+	_, err = probes.Prober{Guard: ssrf.New(nil)}.Ports(t.Context(), request.PortsCheckerRequest{URI: "127.0.0.1", Open: []string{open}})
+	assert.Equal(t, checker.ErrorCodeBlocked, checker.ClassifyCode(err))
+}
+
+func TestTLSScanAssertions(t *testing.T) {
+	scan := &checker.TLSScan{Versions: []string{"TLS 1.1", "TLS 1.2"}, WeakCiphers: []string{"TLS_RSA_WITH_RC4_128_SHA"}}
+
+	err := probes.TLSScanAssertions(request.TLSScan{}, scan)
+	assert.Equal(t, checker.ErrorCodeAssertionFailed, checker.ClassifyCode(err))
+	assert.ErrorContains(t, err, "forbidden TLS 1.1 is enabled")
+
+	err = probes.TLSScanAssertions(request.TLSScan{ForbiddenVersions: []string{"1.0"}}, scan)
+	assert.ErrorContains(t, err, "weak cipher suite TLS_RSA_WITH_RC4_128_SHA is enabled")
+
+	assert.NoError(t, probes.TLSScanAssertions(request.TLSScan{ForbiddenVersions: []string{"1.0"}, AllowWeakCiphers: true}, scan))
+}
+
+func TestDNSAssertions(t *testing.T) {
+	res := &checker.DnsResponse{A: []string{"1.2.3.4"}, CNAME: "openstat.us."}
+
+	ok, err := probes.DNSAssertions([]json.RawMessage{json.RawMessage(`{"type":"dnsRecord","compare":"contains","key":"A","target":"1.2.3.4"}`)}, res)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = probes.DNSAssertions([]json.RawMessage{json.RawMessage(`{"type":"dnsRecord","compare":"eq","key":"CNAME","target":"example.com."}`)}, res)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	records := probes.DNSRecords(res)
+	assert.Equal(t, []string{"1.2.3.4"}, records["A"])
+	assert.Equal(t, []string{}, records["MX"], "every type is listed")
+}
+
+func TestTCPTiming(t *testing.T) {
+	timing := probes.TCPTiming(checker.TCPResponseTiming{TCPStart: 100, DNSStart: 100, DNSDone: 110, ConnectStart: 110, ConnectDone: 130, TCPDone: 130, TLSStart: 130, TLSDone: 170})
+	assert.Equal(t, probes.Timing{DNS: 10 * time.Millisecond, Connect: 20 * time.Millisecond, TLS: 40 * time.Millisecond, Total: 30 * time.Millisecond}, timing)
+}
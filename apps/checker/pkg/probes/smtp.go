@@ -0,0 +1,175 @@
+package probes
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/mailbox"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// Inbox receives the messages of the SMTP checks, e.g. a *mailbox.Mailbox.
+// The messages carry their token in the mailbox.Header header.
+type Inbox interface {
+	// Recipient returns the email address of the inbox.
+	Recipient() string
+	// Wait returns when the message of token was received, or an error
+	// once ctx is done.
+	Wait(ctx context.Context, token string) (time.Time, error)
+}
+
+// SMTPResult is the outcome of an SMTP check.
+type SMTPResult struct {
+	// MessageID is the Message-ID of the message sent.
+	MessageID string
+	// Send is the time the server took to accept the message, Delivery the
+	// time it took to reach the inbox after.
+	Send     time.Duration
+	Delivery time.Duration
+	Timing   Timing
+}
+
+// SMTP runs the SMTP check of req once: it sends a message through the
+// server of req to the inbox of p and waits for it, within the timeout of
+// req. A message accepted by the server but not delivered in time is a
+// timeout.
+func (p Prober) SMTP(ctx context.Context, req request.SMTPCheckerRequest) (SMTPResult, error) {
+	if p.Inbox == nil {
+		return SMTPResult{}, errors.New("no inbox receives the SMTP checks")
+	}
+	timeout := request.Timeout("smtp", req.Timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	token := hex.EncodeToString(b)
+	_, domain, _ := strings.Cut(req.From, "@")
+	res := SMTPResult{MessageID: "<" + token + "@" + domain + ">"}
+
+	start := time.Now()
+	if err := p.sendMail(ctx, req, token, res.MessageID, &res.Timing); err != nil {
+		return res, err
+	}
+	res.Send = time.Since(start)
+
+	received, err := p.Inbox.Wait(ctx, token)
+	if err != nil {
+		if ctx.Err() != nil {
+			return res, &checker.ClassifiedError{Class: checker.ErrorClassTimeout, Err: fmt.Errorf("message accepted but not delivered within %s", timeout)}
+		}
+		return res, fmt.Errorf("unable to read the inbox: %w", err)
+	}
+	res.Delivery = max(received.Sub(start)-res.Send, 0)
+	res.Timing.Total = time.Since(start)
+
+	return res, nil
+}
+
+// sendMail sends the message of token through the server of req, recording
+// the connection and TLS handshake in timing.
+func (p Prober) sendMail(ctx context.Context, req request.SMTPCheckerRequest, token, messageID string, timing *Timing) error {
+	address := req.Address()
+	host, _, _ := net.SplitHostPort(address)
+
+	start := time.Now()
+	conn, err := p.Guard.Dialer(request.Timeout("smtp", req.Timeout)).DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	timing.Connect = time.Since(start)
+	// The conversation ends with ctx.
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	config := &tls.Config{ServerName: host}
+	if req.TLS == request.SMTPImplicit {
+		start := time.Now()
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return &checker.ClassifiedError{Class: checker.ErrorClassTLS, Err: err}
+		}
+		timing.TLS = time.Since(start)
+		conn = tlsConn
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return smtpError(ctx, "greeting", err)
+	}
+	defer client.Close()
+
+	if req.TLS == "" || req.TLS == request.SMTPStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("the server does not offer STARTTLS")
+		}
+		start := time.Now()
+		if err := client.StartTLS(config); err != nil {
+			return &checker.ClassifiedError{Class: checker.ErrorClassTLS, Err: err}
+		}
+		timing.TLS = time.Since(start)
+	}
+	if req.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", req.Username, req.Password, host)); err != nil {
+			return smtpError(ctx, "AUTH", err)
+		}
+	}
+	if err := client.Mail(req.From); err != nil {
+		return smtpError(ctx, "MAIL FROM", err)
+	}
+	if err := client.Rcpt(p.Inbox.Recipient()); err != nil {
+		return smtpError(ctx, "RCPT TO", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return smtpError(ctx, "DATA", err)
+	}
+	if _, err := w.Write(message(req, token, messageID, p.Inbox.Recipient())); err != nil {
+		return smtpError(ctx, "DATA", err)
+	}
+	if err := w.Close(); err != nil {
+		return smtpError(ctx, "DATA", err)
+	}
+
+	return client.Quit()
+}
+
+// message returns the message of an SMTP check.
+func message(req request.SMTPCheckerRequest, token, messageID, recipient string) []byte {
+	var b strings.Builder
+	for _, header := range [][2]string{
+		{"From", req.From},
+		{"To", recipient},
+		{"Subject", "OpenStatus deliverability check " + token},
+		{"Date", time.Now().UTC().Format(time.RFC1123Z)},
+		{"Message-ID", messageID},
+		{mailbox.Header, token},
+		{"Content-Type", "text/plain; charset=utf-8"},
+	} {
+		b.WriteString(header[0] + ": " + header[1] + "\r\n")
+	}
+	b.WriteString("\r\nThis message checks the delivery of the mail of monitor " + req.MonitorID + " and can be deleted.\r\n")
+
+	return []byte(b.String())
+}
+
+// smtpError returns the error of the step of the conversation, a timeout
+// once ctx is done.
+func smtpError(ctx context.Context, step string, err error) error {
+	if ctx.Err() != nil {
+		return &checker.ClassifiedError{Class: checker.ErrorClassTimeout, Err: fmt.Errorf("%s: %w", step, err)}
+	}
+
+	return fmt.Errorf("%s: %w", step, err)
+}
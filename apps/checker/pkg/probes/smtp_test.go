@@ -0,0 +1,145 @@
+package probes_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/mailbox"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/probes"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// inbox receives the messages of smtpServer, when it delivers them.
+type inbox struct {
+	mu       sync.Mutex
+	received map[string]time.Time
+}
+
+func (i *inbox) Recipient() string { return "checks@openstat.us" }
+
+func (i *inbox) Wait(ctx context.Context, token string) (time.Time, error) {
+	for {
+		i.mu.Lock()
+		at, ok := i.received[token]
+		i.mu.Unlock()
+		if ok {
+			return at, nil
+		}
+		select {
+		case <-ctx.Done():
+			return time.Time{}, ctx.Err()
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+// smtpServer accepts the messages of the checks, without STARTTLS, and
+// delivers them to inbox unless it drops them.
+func smtpServer(t *testing.T, inbox *inbox, drop bool) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := textproto.NewReader(bufio.NewReader(conn))
+				fmt.Fprint(conn, "220 smtp.openstat.us ESMTP\r\n")
+				for {
+					line, err := r.ReadLine()
+					if err != nil {
+						return
+					}
+					switch cmd := strings.ToUpper(line); {
+					case strings.HasPrefix(cmd, "EHLO"):
+						fmt.Fprint(conn, "250-smtp.openstat.us\r\n250 8BITMIME\r\n")
+					case strings.HasPrefix(cmd, "RCPT") && !strings.Contains(line, "checks@openstat.us"):
+						fmt.Fprint(conn, "550 unknown recipient\r\n")
+					case cmd == "DATA":
+						fmt.Fprint(conn, "354 go ahead\r\n")
+						header, err := textproto.NewReader(bufio.NewReader(r.DotReader())).ReadMIMEHeader()
+						if err != nil {
+							return
+						}
+						if !drop {
+							inbox.mu.Lock()
+							inbox.received[header.Get(mailbox.Header)] = time.Now()
+							inbox.mu.Unlock()
+						}
+						fmt.Fprint(conn, "250 queued\r\n")
+					case cmd == "QUIT":
+						fmt.Fprint(conn, "221 bye\r\n")
+						return
+					default:
+						fmt.Fprint(conn, "250 ok\r\n")
+					}
+				}
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestProber_SMTP(t *testing.T) {
+	check := func(uri string) request.SMTPCheckerRequest {
+		return request.SMTPCheckerRequest{URI: uri, From: "monitor@openstat.us", TLS: request.SMTPNone, Timeout: 1000, Scheduled: request.Scheduled{MonitorID: "1"}}
+	}
+
+	t.Run("delivered", func(t *testing.T) {
+		inbox := &inbox{received: map[string]time.Time{}}
+		res, err := probes.Prober{Inbox: inbox}.SMTP(t.Context(), check(smtpServer(t, inbox, false)))
+		require.NoError(t, err)
+		assert.Contains(t, res.MessageID, "@openstat.us>")
+		assert.Positive(t, res.Timing.Total)
+		assert.GreaterOrEqual(t, res.Timing.Total, res.Send+res.Delivery)
+	})
+
+	t.Run("not delivered", func(t *testing.T) {
+		inbox := &inbox{received: map[string]time.Time{}}
+		_, err := probes.Prober{Inbox: inbox}.SMTP(t.Context(), check(smtpServer(t, inbox, true)))
+		assert.ErrorContains(t, err, "not delivered within 1s")
+		assert.Equal(t, checker.ErrorClassTimeout, checker.ClassifyError(err))
+	})
+
+	t.Run("starttls required", func(t *testing.T) {
+		inbox := &inbox{received: map[string]time.Time{}}
+		req := check(smtpServer(t, inbox, false))
+		req.TLS = ""
+		_, err := probes.Prober{Inbox: inbox}.SMTP(t.Context(), req)
+		assert.ErrorContains(t, err, "STARTTLS")
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		inbox := &inbox{received: map[string]time.Time{}}
+		rejecting := &rejectingInbox{inbox}
+		_, err := probes.Prober{Inbox: rejecting}.SMTP(t.Context(), check(smtpServer(t, inbox, false)))
+		assert.ErrorContains(t, err, "RCPT TO: 550")
+	})
+
+	t.Run("no inbox", func(t *testing.T) {
+		_, err := probes.Prober{}.SMTP(t.Context(), check("127.0.0.1:25"))
+		assert.Error(t, err)
+	})
+}
+
+// rejectingInbox has an address the server does not accept.
+type rejectingInbox struct{ *inbox }
+
+func (rejectingInbox) Recipient() string { return "unknown@openstat.us" }
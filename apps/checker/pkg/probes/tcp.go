@@ -0,0 +1,121 @@
+package probes
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+// TCPResult is the outcome of a TCP check.
+type TCPResult struct {
+	Dial   checker.TCPResult
+	Timing Timing
+}
+
+// TCP runs the TCP check of req once, within its timeout: it connects to its
+// address, performs the TLS handshake of TLS checks and evaluates their
+// tlsVersion assertions, revocation status and TLS scan. Checks with hold
+// then keep the connection open, failing when it is not dropped as expected.
+func (p Prober) TCP(ctx context.Context, req request.TCPCheckerRequest) (TCPResult, error) {
+	opts := p.TCPOptions(req)
+	if req.Hold != nil {
+		opts.Hold = time.Duration(req.Hold.Duration) * time.Millisecond
+		// The keep-alive probes of the system would hide an idle timeout.
+		opts.Dialer.KeepAlive = -1
+		if req.Hold.KeepAlive > 0 {
+			interval := time.Duration(req.Hold.KeepAlive) * time.Millisecond
+			opts.Dialer.KeepAliveConfig = net.KeepAliveConfig{Enable: true, Idle: interval, Interval: interval}
+		}
+	}
+	res, err := checker.DialTCP(ctx, req.Address(), opts)
+	if err != nil {
+		return TCPResult{}, err
+	}
+
+	result := TCPResult{Dial: res, Timing: TCPTiming(res.Timing)}
+	if err := TLSVersionAssertions(req.RawAssertions, res.TLS); err != nil {
+		return result, err
+	}
+	if req.Revocation == request.RevocationFail && res.TLS != nil {
+		if err := res.TLS.Revocation.Err(); err != nil {
+			return result, err
+		}
+	}
+	if req.TLSScan != nil && res.TLS != nil {
+		// The scan connects to the address the check did, with its name.
+		host, port, _ := net.SplitHostPort(req.Address())
+		cfg := opts.TLSConfig.Clone()
+		if cfg.ServerName == "" {
+			cfg.ServerName = host
+		}
+		address := req.Address()
+		if res.RemoteIP != "" {
+			address = net.JoinHostPort(res.RemoteIP, port)
+		}
+		res.TLS.Scan, err = checker.ScanTLS(ctx, opts.Dialer, address, cfg)
+		if err != nil {
+			return result, err
+		}
+		if err := TLSScanAssertions(*req.TLSScan, res.TLS.Scan); err != nil {
+			return result, err
+		}
+	}
+	if req.Hold != nil && res.Hold != nil {
+		switch {
+		case res.Hold.Dropped && !req.Hold.ExpectDrop:
+			return result, &checker.ClassifiedError{Class: checker.ErrorClassAssertion, Err: fmt.Errorf("connection %s after %d ms, expected held for %d ms", res.Hold.Reason, res.Hold.HeldMs, req.Hold.Duration)}
+		case !res.Hold.Dropped && req.Hold.ExpectDrop:
+			return result, &checker.ClassifiedError{Class: checker.ErrorClassAssertion, Err: fmt.Errorf("connection held for %d ms, expected dropped", res.Hold.HeldMs)}
+		}
+	}
+
+	return result, nil
+}
+
+// TLSScanAssertions fails when scan found a version or weak cipher suite
+// forbidden by s.
+func TLSScanAssertions(s request.TLSScan, scan *checker.TLSScan) error {
+	forbidden := map[uint16]bool{}
+	for _, version := range s.Forbidden() {
+		v, _ := request.ParseTLSVersion(version)
+		forbidden[v] = true
+	}
+	for _, version := range scan.Versions {
+		if v, _ := request.ParseTLSVersion(version); forbidden[v] {
+			return &checker.ClassifiedError{Class: checker.ErrorClassAssertion, Err: fmt.Errorf("forbidden %s is enabled", version)}
+		}
+	}
+	if !s.AllowWeakCiphers && len(scan.WeakCiphers) > 0 {
+		return &checker.ClassifiedError{Class: checker.ErrorClassAssertion, Err: fmt.Errorf("weak cipher suite %s is enabled", scan.WeakCiphers[0])}
+	}
+
+	return nil
+}
+
+// TCPOptions returns how to dial the target of the TCP check of req. The
+// client certificate was validated with the request.
+func (p Prober) TCPOptions(req request.TCPCheckerRequest) checker.TCPOptions {
+	opts := checker.TCPOptions{
+		Dialer:   p.Guard.Dialer(request.Timeout("tcp", req.Timeout)),
+		IPFamily: req.IPFamily,
+	}
+
+	if req.TLS {
+		opts.TLSConfig, _ = req.ClientCert.TLSConfig()
+		if opts.TLSConfig == nil {
+			opts.TLSConfig = &tls.Config{}
+		}
+		opts.TLSConfig.ServerName = req.ServerName
+		if req.Revocation != "" {
+			opts.Revocation = &http.Client{Timeout: request.Timeout("tcp", req.Timeout), Transport: p.Guard.Transport()}
+		}
+	}
+
+	return opts
+}
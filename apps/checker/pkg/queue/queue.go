@@ -0,0 +1,181 @@
+// Package queue pulls the checks to run from a job queue, instead of having
+// them pushed to the checker, so bursts of cron jobs wait in the queue and a
+// region scales by adding consumers without a load balancer in front.
+package queue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultWait is how long a poll of /jobs/next waits for a job.
+const DefaultWait = 30 * time.Second
+
+// ackTimeout bounds the acknowledgement of a job.
+const ackTimeout = 10 * time.Second
+
+// Job is a check to run, Request being the body of /checker/<type>.
+type Job struct {
+	ID      string          `json:"id"`
+	Type    string          `json:"type"`
+	Request json.RawMessage `json:"request"`
+}
+
+// Dispatch runs the check of a job.
+type Dispatch func(ctx context.Context, checkType string, body []byte) error
+
+// Consumer long-polls URL/jobs/next for the jobs of Region, runs them with
+// Dispatch and acknowledges them once done. A job whose check could not run
+// is released with /nack, for the queue to deliver it again, unless Dispatch
+// failed with a *backoff.PermanentError, e.g. an invalid request, which is
+// acknowledged as no delivery would run it.
+type Consumer struct {
+	Client   *http.Client
+	Dispatch Dispatch
+	URL      string
+	Token    string
+	Region   string
+	Workers  int
+	Wait     time.Duration
+}
+
+// Run consumes jobs with Workers concurrent polls until ctx is done. The jobs
+// already taken are run to completion before it returns.
+func (c *Consumer) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range max(c.Workers, 1) {
+		wg.Go(func() { c.consume(ctx) })
+	}
+	wg.Wait()
+}
+
+func (c *Consumer) consume(ctx context.Context) {
+	b := backoff.NewExponentialBackOff()
+	b.MaxInterval = 30 * time.Second
+
+	for ctx.Err() == nil {
+		job, err := c.Next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			wait := b.NextBackOff()
+			log.Ctx(ctx).Error().Err(err).Dur("retry_in", wait).Msg("failed to poll job queue")
+			select {
+			case <-ctx.Done():
+			case <-time.After(wait):
+			}
+			continue
+		}
+		b.Reset()
+		if job == nil {
+			continue
+		}
+
+		runErr := c.Dispatch(context.WithoutCancel(ctx), job.Type, job.Request)
+		if runErr != nil {
+			log.Ctx(ctx).Error().Err(runErr).Str("job_id", job.ID).Msg("failed to run job")
+			var permanent *backoff.PermanentError
+			if errors.As(runErr, &permanent) {
+				runErr = nil
+			}
+		}
+		// Acknowledge the job even when shutting down, its check already ran.
+		if err := c.ack(context.WithoutCancel(ctx), job.ID, runErr); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("job_id", job.ID).Msg("failed to acknowledge job")
+		}
+	}
+}
+
+// Next waits for the next job, and returns nil when none came before the
+// queue answered 204.
+func (c *Consumer) Next(ctx context.Context) (*Job, error) {
+	wait := c.Wait
+	if wait <= 0 {
+		wait = DefaultWait
+	}
+
+	query := url.Values{"region": {c.Region}, "wait": {wait.String()}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint("/jobs/next")+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+	c.authorize(req)
+
+	// The queue holds the poll for up to wait, leave it some slack to answer.
+	client := *c.Client
+	client.Timeout = wait + 10*time.Second
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to poll: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNoContent:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var job Job
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&job); err != nil {
+		return nil, fmt.Errorf("unable to decode job: %w", err)
+	}
+	if job.ID == "" || job.Type == "" {
+		return nil, fmt.Errorf("invalid job: missing id or type")
+	}
+
+	return &job, nil
+}
+
+func (c *Consumer) ack(ctx context.Context, id string, runErr error) error {
+	path, body := "/ack", []byte("{}")
+	if runErr != nil {
+		path = "/nack"
+		body, _ = json.Marshal(map[string]string{"error": runErr.Error()})
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint("/jobs/"+url.PathEscape(id)+path), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.authorize(req)
+
+	client := *c.Client
+	client.Timeout = ackTimeout
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (c *Consumer) endpoint(path string) string {
+	return strings.TrimSuffix(c.URL, "/") + path
+}
+
+func (c *Consumer) authorize(req *http.Request) {
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+}
@@ -0,0 +1,103 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/queue"
+)
+
+func TestConsumer(t *testing.T) {
+	var mu sync.Mutex
+	jobs := []string{
+		`{"id": "1", "type": "http", "request": {"url": "https://openstat.us"}}`,
+		`{"id": "2", "type": "tcp", "request": {"uri": "openstat.us:443"}}`,
+		`{"id": "3", "type": "dns", "request": {"uri": "openstat.us"}}`,
+	}
+	acks := make(map[string]string)
+	done := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/jobs/next":
+			assert.Equal(t, "ams", r.URL.Query().Get("region"))
+			if len(jobs) == 0 {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			_, _ = w.Write([]byte(jobs[0]))
+			jobs = jobs[1:]
+		case "/jobs/1/ack", "/jobs/2/nack", "/jobs/3/ack":
+			acks[r.URL.Path] = r.Method
+			if len(acks) == 3 {
+				close(done)
+			}
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	var ran []string
+	consumer := &queue.Consumer{
+		Client: server.Client(),
+		URL:    server.URL,
+		Token:  "token",
+		Region: "ams",
+		Wait:   time.Second,
+		Dispatch: func(_ context.Context, checkType string, body []byte) error {
+			ran = append(ran, checkType)
+			switch checkType {
+			case "tcp":
+				return errors.New("check answered 503")
+			case "dns":
+				return backoff.Permanent(errors.New("check answered 400"))
+			}
+			assert.JSONEq(t, `{"url": "https://openstat.us"}`, string(body))
+			return nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	stopped := make(chan struct{})
+	go func() {
+		consumer.Run(ctx)
+		close(stopped)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("jobs were not acknowledged")
+	}
+	cancel()
+	<-stopped
+
+	assert.Equal(t, []string{"http", "tcp", "dns"}, ran)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, map[string]string{"/jobs/1/ack": http.MethodPost, "/jobs/2/nack": http.MethodPost, "/jobs/3/ack": http.MethodPost}, acks, "invalid jobs are not delivered again")
+}
+
+func TestConsumer_Next(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"type": "http"}`))
+	}))
+	defer server.Close()
+
+	_, err := (&queue.Consumer{Client: server.Client(), URL: server.URL}).Next(t.Context())
+	require.ErrorContains(t, err, "missing id")
+}
@@ -0,0 +1,127 @@
+// Package ratelimit implements per key token buckets, so one workspace
+// cannot use up the capacity of a region.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	updated time.Time
+	tokens  float64
+}
+
+// Limiter refills each bucket with rate tokens per second up to burst. A nil
+// *Limiter is valid and allows everything, like a zero one until its limits
+// are Set.
+type Limiter struct {
+	now       func() time.Time
+	buckets   map[string]*bucket
+	nextSweep time.Time
+	rate      float64
+	burst     float64
+	mu        sync.Mutex
+}
+
+// New returns a limiter allowing perMinute requests per key, with bursts of
+// up to burst requests. A zero perMinute disables it.
+func New(perMinute, burst int) *Limiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = perMinute
+	}
+
+	return &Limiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(burst),
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Set changes the limits of l as New does, a zero perMinute disabling it, for
+// the reload of the configuration of the checker. The buckets are kept.
+func (l *Limiter) Set(perMinute, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.now == nil {
+		l.now = time.Now
+		l.buckets = make(map[string]*bucket)
+	}
+	if perMinute <= 0 {
+		l.rate, l.burst = 0, 0
+		return
+	}
+	if burst <= 0 {
+		burst = perMinute
+	}
+	l.rate, l.burst = float64(perMinute)/60, float64(burst)
+	l.nextSweep = time.Time{}
+}
+
+// Result describes the state of a bucket after a request.
+type Result struct {
+	// RetryAfter is set when the request is denied.
+	RetryAfter time.Duration
+	Limit      int
+	Remaining  int
+	Allowed    bool
+}
+
+// Allow takes a token from the bucket of key.
+func (l *Limiter) Allow(key string) Result {
+	if l == nil {
+		return Result{Allowed: true}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate == 0 {
+		return Result{Allowed: true}
+	}
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.updated).Seconds()*l.rate)
+	b.updated = now
+
+	res := Result{Limit: int(l.burst)}
+	if b.tokens < 1 {
+		res.RetryAfter = time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return res
+	}
+
+	b.tokens--
+	res.Allowed = true
+	res.Remaining = int(b.tokens)
+
+	return res
+}
+
+// sweep forgets the buckets that are full again, they behave like new ones.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Before(l.nextSweep) {
+		return
+	}
+
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.updated) > refill {
+			delete(l.buckets, key)
+		}
+	}
+	l.nextSweep = now.Add(refill)
+}
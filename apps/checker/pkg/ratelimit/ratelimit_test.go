@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+	l := New(60, 2)
+	l.now = func() time.Time { return now }
+
+	assert.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 1}, l.Allow("1"))
+	assert.Equal(t, Result{Allowed: true, Limit: 2, Remaining: 0}, l.Allow("1"))
+
+	denied := l.Allow("1")
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, time.Second, denied.RetryAfter)
+
+	// Other keys have their own bucket.
+	assert.True(t, l.Allow("2").Allowed)
+
+	now = now.Add(time.Second)
+	assert.True(t, l.Allow("1").Allowed)
+	assert.False(t, l.Allow("1").Allowed)
+
+	var nl *Limiter
+	assert.True(t, nl.Allow("1").Allowed)
+	assert.Nil(t, New(0, 10))
+}
+
+func TestLimiter_Set(t *testing.T) {
+	l := &Limiter{}
+	assert.Equal(t, Result{Allowed: true}, l.Allow("1"))
+
+	l.Set(60, 1)
+	assert.True(t, l.Allow("1").Allowed)
+	assert.False(t, l.Allow("1").Allowed)
+
+	l.Set(60, 5)
+	assert.Equal(t, 5, l.Allow("1").Limit)
+
+	l.Set(0, 0)
+	assert.Equal(t, Result{Allowed: true}, l.Allow("1"))
+}
@@ -0,0 +1,137 @@
+// Package replay forwards the checks routed by the platform to a checker of
+// another region than the one they are meant for.
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+)
+
+// Header names the region a check is meant for, on the providers without a
+// header of their own.
+const Header = "X-Openstatus-Region"
+
+// replayedHeader marks the requests proxied to a peer, which are never
+// proxied again so a misconfigured peer does not loop.
+const replayedHeader = "X-Openstatus-Replayed"
+
+var (
+	ErrUnknownRegion = errors.New("no checker known for region")
+	ErrLoop          = errors.New("request already replayed")
+)
+
+// Provider forwards the requests to the checker of their region.
+type Provider interface {
+	// Target returns the region r is meant for, named like the region of
+	// the checkers, or empty when any checker will do. region is the one of
+	// the route, if any.
+	Target(r *http.Request, region string) string
+	// Replay has the checker of region serve r, writing its response to w.
+	Replay(w http.ResponseWriter, r *http.Request, region string) error
+}
+
+// Fly has the edge of Fly.io replay the requests with the fly-replay header.
+type Fly struct{}
+
+func (Fly) Target(r *http.Request, region string) string {
+	if region != "" {
+		return region
+	}
+
+	return r.Header.Get("fly-prefer-region")
+}
+
+func (Fly) Replay(w http.ResponseWriter, _ *http.Request, region string) error {
+	w.Header().Set("fly-replay", "region="+region)
+	w.WriteHeader(http.StatusAccepted)
+	_, err := fmt.Fprintf(w, "Forwarding request to %s", region)
+
+	return err
+}
+
+// Peers proxies the requests to the checker of their region, for the
+// platforms without replay at their edge.
+type Peers struct {
+	// URLs are the base URLs of the checker of each region.
+	URLs map[string]*url.URL
+	// Prefix names the regions of the checkers on the platform, e.g.
+	// "koyeb_" for koyeb_fra. Regions requested without it are prefixed.
+	Prefix string
+	// Transport sends the proxied requests, http.DefaultTransport when nil.
+	Transport http.RoundTripper
+}
+
+// Koyeb proxies the requests to the peers of its regions, named koyeb_<region>.
+func Koyeb(urls map[string]*url.URL) *Peers {
+	return &Peers{URLs: urls, Prefix: "koyeb_"}
+}
+
+// Railway proxies the requests to the peers of its regions, named
+// railway_<region>.
+func Railway(urls map[string]*url.URL) *Peers {
+	return &Peers{URLs: urls, Prefix: "railway_"}
+}
+
+func (p *Peers) Target(r *http.Request, region string) string {
+	if region == "" {
+		region = r.Header.Get(Header)
+	}
+	if region == "" || strings.HasPrefix(region, p.Prefix) {
+		return region
+	}
+
+	return p.Prefix + region
+}
+
+func (p *Peers) Replay(w http.ResponseWriter, r *http.Request, region string) error {
+	if r.Header.Get(replayedHeader) != "" {
+		return ErrLoop
+	}
+	target, ok := p.URLs[region]
+	if !ok {
+		// The peers may be listed without the prefix of their region.
+		target, ok = p.URLs[strings.TrimPrefix(region, p.Prefix)]
+	}
+	if !ok {
+		return fmt.Errorf("%w %s", ErrUnknownRegion, region)
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			pr.Out.Header.Set(replayedHeader, "true")
+		},
+		Transport: p.Transport,
+	}
+	proxy.ServeHTTP(w, r)
+
+	return nil
+}
+
+// ParsePeers parses a comma separated list of region=url, e.g.
+// "fra=https://fra.checker.example.com,was=https://was.checker.example.com".
+func ParsePeers(s string) (map[string]*url.URL, error) {
+	peers := make(map[string]*url.URL)
+	for entry := range strings.SplitSeq(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		region, raw, ok := strings.Cut(entry, "=")
+		if !ok || region == "" {
+			return nil, fmt.Errorf("invalid peer %q, expected region=url", entry)
+		}
+		u, err := url.Parse(raw)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid url of peer %s: %q", region, raw)
+		}
+		peers[region] = u
+	}
+
+	return peers, nil
+}
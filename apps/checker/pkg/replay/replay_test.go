@@ -0,0 +1,92 @@
+package replay_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/replay"
+)
+
+func TestFly(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/checker/http", nil)
+	r.Header.Set("fly-prefer-region", "ams")
+
+	assert.Equal(t, "ams", replay.Fly{}.Target(r, ""))
+	assert.Equal(t, "iad", replay.Fly{}.Target(r, "iad"), "the region of the route wins")
+
+	w := httptest.NewRecorder()
+	require.NoError(t, replay.Fly{}.Replay(w, r, "ams"))
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "region=ams", w.Header().Get("fly-replay"))
+}
+
+func TestPeers(t *testing.T) {
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Path", r.URL.Path)
+		_, _ = w.Write(body)
+	}))
+	defer peer.Close()
+
+	peers, err := replay.ParsePeers("fra=" + peer.URL)
+	require.NoError(t, err)
+	p := replay.Koyeb(peers)
+
+	r := httptest.NewRequest(http.MethodPost, "/checker/http", strings.NewReader(`{"url":"https://openstat.us"}`))
+	r.Header.Set(replay.Header, "fra")
+	assert.Equal(t, "koyeb_fra", p.Target(r, ""))
+	assert.Equal(t, "koyeb_was", p.Target(r, "koyeb_was"))
+
+	w := httptest.NewRecorder()
+	require.NoError(t, p.Replay(w, r, "koyeb_fra"))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "/checker/http", w.Header().Get("X-Path"))
+	assert.Equal(t, `{"url":"https://openstat.us"}`, w.Body.String())
+
+	t.Run("unknown region", func(t *testing.T) {
+		err := p.Replay(httptest.NewRecorder(), r, "koyeb_was")
+		assert.ErrorIs(t, err, replay.ErrUnknownRegion)
+	})
+
+	t.Run("loop", func(t *testing.T) {
+		// The peer of fra is the checker itself.
+		looping := &replay.Peers{URLs: map[string]*url.URL{}}
+		proxied := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := looping.Replay(w, r, "fra"); err != nil {
+				http.Error(w, err.Error(), http.StatusMisdirectedRequest)
+			}
+		}))
+		defer proxied.Close()
+		looping.URLs["fra"] = must(url.Parse(proxied.URL))
+
+		w := httptest.NewRecorder()
+		require.NoError(t, looping.Replay(w, httptest.NewRequest(http.MethodPost, "/checker/http", nil), "fra"))
+		assert.Equal(t, http.StatusMisdirectedRequest, w.Code)
+	})
+}
+
+func TestParsePeers(t *testing.T) {
+	peers, err := replay.ParsePeers(" fra=https://fra.example.com, was=https://was.example.com:8080 ")
+	require.NoError(t, err)
+	assert.Equal(t, "fra.example.com", peers["fra"].Host)
+	assert.Equal(t, "was.example.com:8080", peers["was"].Host)
+
+	for _, s := range []string{"fra", "=https://fra.example.com", "fra=fra.example.com"} {
+		_, err := replay.ParsePeers(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
@@ -0,0 +1,224 @@
+// Package resultcache answers an on-demand check with the result of an
+// identical one run a few seconds before, e.g. when several users hit "test
+// now" on the same monitor, instead of checking the target again.
+package resultcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TTLField is the field of the body of a check allowing a cached result, in
+// milliseconds. Checks without it are always run.
+const TTLField = "cacheTtl"
+
+type entry struct {
+	at     time.Time
+	done   chan struct{}
+	header http.Header
+	body   []byte
+	status int
+}
+
+// Store keeps the results of the on-demand checks of the last max. A nil
+// *Store is valid and never caches.
+type Store struct {
+	now       func() time.Time
+	entries   map[string]*entry
+	nextSweep time.Time
+	max       time.Duration
+	mu        sync.Mutex
+}
+
+// New returns a store keeping the results up to max, which bounds the
+// cacheTtl of the checks, or nil when max is not positive.
+func New(max time.Duration) *Store {
+	if max <= 0 {
+		return nil
+	}
+
+	return &Store{
+		max:     max,
+		now:     time.Now,
+		entries: make(map[string]*entry),
+	}
+}
+
+// Middleware answers a check sent with a cacheTtl with the result of an
+// identical check younger than it, marked with "cached": true and its "age"
+// in milliseconds. An identical check arriving while the first is still
+// running waits for it. Only successful responses are kept.
+func (s *Store) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s == nil || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+
+		key, ttl, ok := requestKey(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		e, first := s.claim(key, min(ttl, s.max))
+		if !first {
+			select {
+			case <-e.done:
+			case <-c.Request.Context().Done():
+				c.AbortWithStatus(http.StatusRequestTimeout)
+				return
+			}
+
+			// The first check failed and released the key, run again.
+			if e.status == 0 {
+				c.Next()
+				return
+			}
+
+			s.serve(c, e)
+			c.Abort()
+
+			return
+		}
+
+		w := &recorder{ResponseWriter: c.Writer}
+		c.Writer = w
+		// Deferred so the checks waiting on a panicking handler are released.
+		defer s.complete(key, e, w)
+
+		c.Next()
+	}
+}
+
+// claim returns the entry of key, running or younger than ttl, or a new one
+// to fill, reporting whether it is new.
+func (s *Store) claim(key string, ttl time.Duration) (*entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.After(s.nextSweep) {
+		for k, e := range s.entries {
+			if !e.at.IsZero() && now.Sub(e.at) > s.max {
+				delete(s.entries, k)
+			}
+		}
+		s.nextSweep = now.Add(s.max)
+	}
+
+	if e, ok := s.entries[key]; ok && (e.at.IsZero() || now.Sub(e.at) < ttl) {
+		return e, false
+	}
+
+	e := &entry{done: make(chan struct{})}
+	s.entries[key] = e
+
+	return e, true
+}
+
+func (s *Store) complete(key string, e *entry, w *recorder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if w.Written() && w.Status() == http.StatusOK {
+		e.status = w.Status()
+		e.header = w.Header().Clone()
+		e.body = w.body.Bytes()
+		e.at = s.now()
+	} else if s.entries[key] == e {
+		delete(s.entries, key)
+	}
+
+	close(e.done)
+}
+
+func (s *Store) serve(c *gin.Context, e *entry) {
+	age := s.now().Sub(e.at)
+
+	for k, v := range e.header {
+		c.Writer.Header()[k] = v
+	}
+	c.Header("Age", strconv.FormatInt(int64(age/time.Second), 10))
+	c.Data(e.status, e.header.Get("Content-Type"), mark(e.body, age))
+}
+
+// mark adds the cached flag and the age of a cached result to its JSON
+// object.
+func mark(body []byte, age time.Duration) []byte {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) < 2 || trimmed[0] != '{' {
+		return body
+	}
+
+	var b bytes.Buffer
+	b.WriteString(`{"cached":true,"age":`)
+	b.WriteString(strconv.FormatInt(age.Milliseconds(), 10))
+	if rest := bytes.TrimSpace(trimmed[1:]); rest[0] != '}' {
+		b.WriteByte(',')
+	}
+	b.Write(trimmed[1:])
+
+	return b.Bytes()
+}
+
+// requestKey derives the key of a check from its body, its cacheTtl and
+// requestId aside, and returns its cacheTtl. It is scoped to the route and
+// the credentials so a result cannot be read by someone else.
+func requestKey(c *gin.Context) (string, time.Duration, bool) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return "", 0, false
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil {
+		return "", 0, false
+	}
+	var ttl int64
+	if json.Unmarshal(fields[TTLField], &ttl) != nil || ttl <= 0 {
+		return "", 0, false
+	}
+	delete(fields, TTLField)
+	delete(fields, "requestId")
+	// The keys of a map are marshalled sorted, the order of the fields of
+	// the body does not matter.
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return "", 0, false
+	}
+
+	h := sha256.New()
+	for _, part := range []string{c.Request.URL.Path, c.Request.URL.RawQuery, c.GetHeader("Authorization"), string(canonical)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), time.Duration(ttl) * time.Millisecond, true
+}
+
+// recorder copies the response body while writing it.
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *recorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,90 @@
+package resultcache
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	now := time.Now()
+	store := New(time.Minute)
+	store.now = func() time.Time { return now }
+
+	var runs int
+	status := http.StatusOK
+	router := gin.New()
+	router.Use(store.Middleware())
+	router.POST("/ping/:region", func(c *gin.Context) {
+		runs++
+		c.JSON(status, gin.H{"run": runs})
+	})
+
+	do := func(path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Basic test")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("identical checks share their result", func(t *testing.T) {
+		do("/ping/iad", `{"url":"https://openstat.us","requestId":1,"cacheTtl":5000}`)
+		now = now.Add(2 * time.Second)
+		w := do("/ping/iad", `{"requestId":2,"cacheTtl":5000,"url":"https://openstat.us"}`)
+		assert.Equal(t, 1, runs)
+
+		var body struct {
+			Cached bool  `json:"cached"`
+			Age    int64 `json:"age"`
+			Run    int   `json:"run"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.True(t, body.Cached)
+		assert.Equal(t, int64(2000), body.Age)
+		assert.Equal(t, 1, body.Run)
+		assert.Equal(t, "2", w.Header().Get("Age"))
+	})
+
+	t.Run("results older than the ttl of the check are not served", func(t *testing.T) {
+		do("/ping/iad", `{"url":"https://openstat.us","cacheTtl":1000}`)
+		assert.Equal(t, 2, runs)
+	})
+
+	t.Run("other regions and checks are run", func(t *testing.T) {
+		do("/ping/syd", `{"url":"https://openstat.us","cacheTtl":5000}`)
+		do("/ping/iad", `{"url":"https://openstat.us/status","cacheTtl":5000}`)
+		assert.Equal(t, 4, runs)
+	})
+
+	t.Run("checks without ttl are always run", func(t *testing.T) {
+		w := do("/ping/iad", `{"url":"https://openstat.us"}`)
+		assert.Equal(t, 5, runs)
+		assert.NotContains(t, w.Body.String(), "cached")
+	})
+
+	t.Run("failed responses are not kept", func(t *testing.T) {
+		status = http.StatusBadRequest
+		do("/ping/fra", `{"url":"https://openstat.us","cacheTtl":5000}`)
+		status = http.StatusOK
+		do("/ping/fra", `{"url":"https://openstat.us","cacheTtl":5000}`)
+		assert.Equal(t, 7, runs)
+	})
+
+	t.Run("nil store", func(t *testing.T) {
+		assert.Nil(t, New(0))
+	})
+}
+
+func TestMark(t *testing.T) {
+	assert.JSONEq(t, `{"cached":true,"age":1500}`, string(mark([]byte(`{}`), 1500*time.Millisecond)))
+	assert.JSONEq(t, `{"cached":true,"age":0,"status":"success"}`, string(mark([]byte(`{"status":"success"}`), 0)))
+	assert.Equal(t, `[1]`, string(mark([]byte(`[1]`), 0)))
+}
@@ -0,0 +1,140 @@
+// Package retry turns the retry settings of a check request into backoff
+// options, so every check type retries the same way.
+package retry
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+const (
+	// DefaultRetries are the retries after the first attempt, the same as
+	// the legacy `retry: 3`.
+	DefaultRetries         = 3
+	DefaultMaxAttempts     = DefaultRetries + 1
+	DefaultInitialInterval = 500 * time.Millisecond
+	DefaultMultiplier      = 1.5
+)
+
+// ErrDeadlineExceeded is the cause of the context returned by WithDeadline
+// once the total deadline of a check has passed.
+var ErrDeadlineExceeded = errors.New("total deadline exceeded")
+
+// WithDeadline bounds ctx by the total deadline of a check, in milliseconds.
+// Unlike the per-attempt timeout it covers every attempt and the waits in
+// between. Zero leaves ctx unbounded.
+func WithDeadline(ctx context.Context, totalDeadline int64) (context.Context, context.CancelFunc) {
+	if totalDeadline <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeoutCause(ctx, time.Duration(totalDeadline)*time.Millisecond, ErrDeadlineExceeded)
+}
+
+// Policy is the resolved retry configuration of a single check.
+type Policy struct {
+	InitialInterval time.Duration
+	// MaxElapsedTime bounds the whole retry loop, zero means no bound.
+	MaxElapsedTime time.Duration
+	Multiplier     float64
+	// RetryOn restricts the retries to some error classes, all of them
+	// are retried when empty.
+	RetryOn []checker.ErrorClass
+	// MaxAttempts counts the first attempt, 1 disables retries.
+	MaxAttempts uint
+	Jitter      bool
+}
+
+func Default() Policy {
+	return Policy{
+		InitialInterval: DefaultInitialInterval,
+		Multiplier:      DefaultMultiplier,
+		MaxAttempts:     DefaultMaxAttempts,
+		Jitter:          true,
+	}
+}
+
+// FromRequest resolves the policy of a check. retry is the legacy `retry`
+// field of the request, the number of retries after the first attempt;
+// maxAttempts in the policy takes precedence over it. Invalid values fall
+// back to the defaults.
+func FromRequest(p *request.RetryPolicy, retry int64) Policy {
+	policy := Default()
+
+	if retry > 0 {
+		policy.MaxAttempts = uint(retry) + 1
+	}
+
+	if p == nil {
+		return policy
+	}
+
+	if p.MaxAttempts > 0 {
+		policy.MaxAttempts = uint(p.MaxAttempts)
+	}
+	if p.InitialInterval > 0 {
+		policy.InitialInterval = time.Duration(p.InitialInterval) * time.Millisecond
+	}
+	if p.Multiplier >= 1 {
+		policy.Multiplier = p.Multiplier
+	}
+	if p.MaxElapsedTime > 0 {
+		policy.MaxElapsedTime = time.Duration(p.MaxElapsedTime) * time.Millisecond
+	}
+	if p.Jitter != nil {
+		policy.Jitter = *p.Jitter
+	}
+	for _, class := range p.RetryOn {
+		policy.RetryOn = append(policy.RetryOn, checker.ErrorClass(class))
+	}
+
+	return policy
+}
+
+// IsLastAttempt reports whether the given 1-based attempt is the last one
+// the policy allows.
+func (p Policy) IsLastAttempt(attempt int) bool {
+	return attempt >= int(p.MaxAttempts)
+}
+
+// Retryable reports whether failures of the given class should be retried.
+func (p Policy) Retryable(class checker.ErrorClass) bool {
+	return len(p.RetryOn) == 0 || slices.Contains(p.RetryOn, class)
+}
+
+// Wrap marks err as permanent when its class is not retryable, so the retry
+// loop gives up right away.
+func (p Policy) Wrap(err error) error {
+	if err == nil || p.Retryable(checker.ClassifyError(err)) {
+		return err
+	}
+
+	return backoff.Permanent(err)
+}
+
+// Options returns the backoff.Retry options implementing the policy.
+func (p Policy) Options() []backoff.RetryOption {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = p.InitialInterval
+	b.Multiplier = p.Multiplier
+	if !p.Jitter {
+		b.RandomizationFactor = 0
+	}
+
+	opts := []backoff.RetryOption{
+		backoff.WithBackOff(b),
+		backoff.WithMaxTries(p.MaxAttempts),
+	}
+	if p.MaxElapsedTime > 0 {
+		opts = append(opts, backoff.WithMaxElapsedTime(p.MaxElapsedTime))
+	}
+
+	return opts
+}
@@ -0,0 +1,146 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cenkalti/backoff/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openstatushq/openstatus/apps/checker/checker"
+	"github.com/openstatushq/openstatus/apps/checker/pkg/retry"
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestFromRequest(t *testing.T) {
+	noJitter := false
+
+	tests := []struct {
+		name   string
+		policy *request.RetryPolicy
+		want   retry.Policy
+		retry  int64
+	}{
+		{name: "defaults", want: retry.Default()},
+		{
+			name:  "legacy retry field",
+			retry: 1,
+			want: retry.Policy{
+				InitialInterval: retry.DefaultInitialInterval,
+				Multiplier:      retry.DefaultMultiplier,
+				MaxAttempts:     2,
+				Jitter:          true,
+			},
+		},
+		{
+			name:  "policy wins over legacy retry",
+			retry: 5,
+			policy: &request.RetryPolicy{
+				MaxAttempts:     2,
+				InitialInterval: 100,
+				Multiplier:      2,
+				MaxElapsedTime:  3000,
+				Jitter:          &noJitter,
+			},
+			want: retry.Policy{
+				InitialInterval: 100 * time.Millisecond,
+				MaxElapsedTime:  3 * time.Second,
+				Multiplier:      2,
+				MaxAttempts:     2,
+			},
+		},
+		{
+			name:   "invalid values are ignored",
+			policy: &request.RetryPolicy{MaxAttempts: -1, Multiplier: 0.5, InitialInterval: -10},
+			want:   retry.Default(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, retry.FromRequest(tt.policy, tt.retry))
+		})
+	}
+}
+
+func TestPolicyOptions(t *testing.T) {
+	policy := retry.Policy{InitialInterval: time.Millisecond, Multiplier: 1, MaxAttempts: 3}
+
+	var called int
+	_, err := backoff.Retry(context.Background(), func() (struct{}, error) {
+		called++
+		return struct{}{}, errors.New("boom")
+	}, policy.Options()...)
+
+	assert.Error(t, err)
+	assert.Equal(t, 3, called)
+	assert.True(t, policy.IsLastAttempt(called))
+}
+
+func TestWithDeadline(t *testing.T) {
+	policy := retry.Policy{InitialInterval: 50 * time.Millisecond, Multiplier: 1, MaxAttempts: 100}
+
+	ctx, cancel := retry.WithDeadline(context.Background(), 120)
+	defer cancel()
+
+	var called int
+	start := time.Now()
+	_, err := backoff.Retry(ctx, func() (struct{}, error) {
+		called++
+		return struct{}{}, errors.New("boom")
+	}, policy.Options()...)
+
+	assert.ErrorIs(t, err, retry.ErrDeadlineExceeded)
+	assert.Less(t, called, 5)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestPolicyRetryOn(t *testing.T) {
+	policy := retry.FromRequest(&request.RetryPolicy{RetryOn: []string{"timeout", "http_5xx"}}, 3)
+
+	assert.True(t, policy.Retryable(checker.ErrorClassTimeout))
+	assert.True(t, policy.Retryable(checker.ErrorClassHTTP5xx))
+	assert.False(t, policy.Retryable(checker.ErrorClassTLS))
+	assert.True(t, retry.Default().Retryable(checker.ErrorClassTLS))
+
+	var called int
+	_, err := backoff.Retry(context.Background(), func() (struct{}, error) {
+		called++
+		return struct{}{}, policy.Wrap(&checker.ClassifiedError{Class: checker.ErrorClassConnectionRefused, Err: errors.New("connection refused")})
+	}, policy.Options()...)
+
+	assert.EqualError(t, err, "connection refused")
+	assert.Equal(t, 1, called)
+}
+
+func TestFromRequest_DefaultRetries(t *testing.T) {
+	attempts := func(policy retry.Policy) int {
+		policy.InitialInterval = time.Millisecond
+		var called int
+		_, _ = backoff.Retry(context.Background(), func() (struct{}, error) {
+			called++
+			return struct{}{}, errors.New("boom")
+		}, policy.Options()...)
+
+		return called
+	}
+
+	assert.Equal(t, 4, attempts(retry.FromRequest(nil, 0)), "3 retries after the first attempt")
+	assert.Equal(t, attempts(retry.FromRequest(nil, retry.DefaultRetries)), attempts(retry.FromRequest(nil, 0)))
+}
+
+func TestFromRequest_LegacyRetryAttempts(t *testing.T) {
+	policy := retry.FromRequest(nil, 2)
+	policy.InitialInterval = time.Millisecond
+
+	var called int
+	_, err := backoff.Retry(context.Background(), func() (struct{}, error) {
+		called++
+		return struct{}{}, errors.New("boom")
+	}, policy.Options()...)
+
+	assert.Error(t, err)
+	assert.Equal(t, 3, called, "2 retries after the first attempt")
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"connectrpc.com/connect"
@@ -30,6 +31,40 @@ type MonitorManager struct {
 	Scheduler *tasks.Scheduler
 	mu        sync.Mutex
 	configs   map[string][]byte
+	// running counts the checks in progress, and lastIngest is the unix
+	// milliseconds of the last result accepted by the control plane.
+	running    atomic.Int64
+	lastIngest atomic.Int64
+}
+
+// Stats describes the work of the agent, for its heartbeats.
+type Stats struct {
+	LastIngest time.Time
+	Monitors   int
+	Running    int
+}
+
+// Stats returns the monitors scheduled, the checks in progress and the time
+// of the last ingested result, zero when none was yet.
+func (mm *MonitorManager) Stats() Stats {
+	stats := Stats{Monitors: len(mm.Scheduler.Tasks()), Running: int(mm.running.Load())}
+	if ms := mm.lastIngest.Load(); ms != 0 {
+		stats.LastIngest = time.UnixMilli(ms)
+	}
+
+	return stats
+}
+
+// track counts a check in progress until the returned func is called.
+func (mm *MonitorManager) track() func() {
+	mm.running.Add(1)
+
+	return func() { mm.running.Add(-1) }
+}
+
+// ingested records that the control plane accepted a result.
+func (mm *MonitorManager) ingested() {
+	mm.lastIngest.Store(time.Now().UnixMilli())
 }
 
 // shouldSchedule reports whether a task has to be created for the monitor, and
@@ -73,10 +108,16 @@ func (mm *MonitorManager) UpdateMonitors(ctx context.Context) {
 		return
 	}
 
+	mm.Apply(res.Msg)
+}
+
+// Apply starts the jobs of the new or changed monitors of msg, and stops the
+// ones of the monitors it does not list anymore.
+func (mm *MonitorManager) Apply(msg *v1.MonitorsResponse) {
 	currentIDs := make(map[string]struct{})
 
 	// HTTP monitors: start jobs for new monitors
-	for _, m := range res.Msg.HttpMonitors {
+	for _, m := range msg.HttpMonitors {
 		currentIDs[m.Id] = struct{}{}
 		if !mm.shouldSchedule(m.Id, m) {
 			continue
@@ -94,8 +135,9 @@ func (mm *MonitorManager) UpdateMonitors(ctx context.Context) {
 			FuncWithTaskContext: func(ctx tasks.TaskContext) error {
 				monitor := m
 				c := context.Background()
+				defer mm.track()()
 				log.Printf("Starting job for monitor %s (%s)", monitor.Id, monitor.Url)
-				data, err := mm.JobRunner.HTTPJob(c, monitor, res.Msg.Region)
+				data, err := mm.JobRunner.HTTPJob(c, monitor, msg.Region)
 
 				if err != nil {
 					log.Printf("Monitor check failed for %s (%s): %v", monitor.Id, monitor.Url, err)
@@ -122,6 +164,7 @@ func (mm *MonitorManager) UpdateMonitors(ctx context.Context) {
 					log.Printf("Failed to ingest HTTP result for %s (%s): %v", monitor.Id, monitor.Url, ingestErr)
 					return ingestErr
 				}
+				mm.ingested()
 				log.Printf("Monitor check for %s (%s) ingested with status %q (code %d), ingest response: %v", monitor.Id, monitor.Url, data.RequestStatus, data.StatusCode, resp)
 				return nil
 			},
@@ -135,7 +178,7 @@ func (mm *MonitorManager) UpdateMonitors(ctx context.Context) {
 	}
 
 	// TCP monitors: start jobs for new monitors
-	for _, m := range res.Msg.TcpMonitors {
+	for _, m := range msg.TcpMonitors {
 		currentIDs[m.Id] = struct{}{}
 		if !mm.shouldSchedule(m.Id, m) {
 			continue
@@ -151,8 +194,9 @@ func (mm *MonitorManager) UpdateMonitors(ctx context.Context) {
 
 				monitor := m
 				c := context.Background()
+				defer mm.track()()
 				log.Printf("Starting TCP job for monitor %s (%s)", monitor.Id, monitor.Uri)
-				data, err := mm.JobRunner.TCPJob(c, monitor, res.Msg.Region)
+				data, err := mm.JobRunner.TCPJob(c, monitor, msg.Region)
 				if err != nil {
 					log.Printf("TCP monitor check failed for %s (%s): %v", monitor.Id, monitor.Uri, err)
 					return err
@@ -174,6 +218,7 @@ func (mm *MonitorManager) UpdateMonitors(ctx context.Context) {
 					log.Printf("Failed to ingest TCP result for %s (%s): %v", monitor.Id, monitor.Uri, ingestErr)
 					return ingestErr
 				}
+				mm.ingested()
 				log.Printf("TCP monitor check for %s (%s) ingested with status %q, ingest response: %v", monitor.Id, monitor.Uri, data.RequestStatus, resp)
 
 				return nil
@@ -187,7 +232,7 @@ func (mm *MonitorManager) UpdateMonitors(ctx context.Context) {
 		log.Printf("Started TCP monitoring job for %s (%s)", m.Id, m.Uri)
 	}
 
-	for _, m := range res.Msg.DnsMonitors {
+	for _, m := range msg.DnsMonitors {
 		currentIDs[m.Id] = struct{}{}
 		if !mm.shouldSchedule(m.Id, m) {
 			continue
@@ -203,6 +248,7 @@ func (mm *MonitorManager) UpdateMonitors(ctx context.Context) {
 
 				monitor := m
 				c := context.Background()
+				defer mm.track()()
 				log.Printf("Starting DNS job for monitor %s (%s)", monitor.Id, monitor.Uri)
 				_, err := mm.JobRunner.DNSJob(c, monitor)
 				if err != nil {
@@ -227,6 +273,7 @@ func (mm *MonitorManager) UpdateMonitors(ctx context.Context) {
 					log.Printf("Failed to ingest DNS result for %s (%s): %v", monitor.Id, monitor.Uri, ingestErr)
 					return ingestErr
 				}
+				mm.ingested()
 				log.Printf("DNS monitor check for %s (%s) ingested, ingest response: %v", monitor.Id, monitor.Uri, resp)
 
 				return nil
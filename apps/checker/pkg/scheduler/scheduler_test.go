@@ -88,6 +88,17 @@ func (m *mockClient) IngestDNS(ctx context.Context, req *connect.Request[v1.Inge
 	return m.IngestDNSFunc(ctx, req)
 }
 
+// The agent RPCs are not used by the scheduler.
+func (m *mockClient) Register(ctx context.Context, req *connect.Request[v1.RegisterRequest]) (*connect.Response[v1.RegisterResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, nil)
+}
+func (m *mockClient) Heartbeat(ctx context.Context, req *connect.Request[v1.HeartbeatRequest]) (*connect.Response[v1.HeartbeatResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, nil)
+}
+func (m *mockClient) Config(ctx context.Context, req *connect.Request[v1.ConfigRequest]) (*connect.Response[v1.ConfigResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, nil)
+}
+
 func TestMonitorManager_StartAndStopJobs_WithJobRunner(t *testing.T) {
 	ctx := t.Context()
 
@@ -238,3 +249,34 @@ func TestMonitorManager_ReschedulesOnConfigChange(t *testing.T) {
 		t.Errorf("expected the added header to reach the job, got %v", got.Headers)
 	}
 }
+
+func TestMonitorManager_Stats(t *testing.T) {
+	client := &mockClient{
+		MonitorsFunc: func(ctx context.Context, req *connect.Request[v1.MonitorsRequest]) (*connect.Response[v1.MonitorsResponse], error) {
+			return connect.NewResponse(&v1.MonitorsResponse{
+				HttpMonitors: []*v1.HTTPMonitor{{Id: "http1", Url: "https://openstat.us", Periodicity: "1h"}},
+			}), nil
+		},
+		IngestHTTPFunc: func(ctx context.Context, req *connect.Request[v1.IngestHTTPRequest]) (*connect.Response[v1.IngestHTTPResponse], error) {
+			return connect.NewResponse(&v1.IngestHTTPResponse{}), nil
+		},
+	}
+
+	s := tasks.New()
+	defer s.Stop()
+
+	mm := &scheduler.MonitorManager{Client: client, JobRunner: &mockJobRunner{}, Scheduler: s}
+	mm.UpdateMonitors(t.Context())
+	if stats := mm.Stats(); stats.Monitors != 1 || !stats.LastIngest.IsZero() {
+		t.Fatalf("expected 1 monitor and no ingest yet, got %+v", stats)
+	}
+
+	runScheduledTask(t, mm.Scheduler, "http1")
+	stats := mm.Stats()
+	if stats.LastIngest.IsZero() || time.Since(stats.LastIngest) > time.Minute {
+		t.Errorf("expected the last ingest to be recorded, got %v", stats.LastIngest)
+	}
+	if stats.Running != 0 {
+		t.Errorf("expected no check in progress, got %d", stats.Running)
+	}
+}
@@ -0,0 +1,318 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/madflojo/tasks"
+	"github.com/rs/zerolog/log"
+)
+
+// The check types of a standalone monitor.
+const (
+	TypeHTTP     = "http"
+	TypeTCP      = "tcp"
+	TypeDNS      = "dns"
+	TypeContent  = "content"
+	TypeCrawl    = "crawl"
+	TypeGRPC     = "grpc"
+	TypeSMTP     = "smtp"
+	TypeEmail    = "email"
+	TypePorts    = "ports"
+	TypeOIDC     = "oidc"
+	TypeDownload = "download"
+)
+
+// Monitor is a check scheduled by the checker itself. Request is the body
+// an external dispatcher would POST to /checker/<type>, its monitorId and
+// cronTimestamp are set for every run.
+type Monitor struct {
+	ID       string          `json:"id"`
+	Type     string          `json:"type"`
+	Interval string          `json:"interval"`
+	Regions  []string        `json:"regions,omitempty"`
+	Request  json.RawMessage `json:"request"`
+}
+
+type Config struct {
+	Monitors []Monitor `json:"monitors"`
+}
+
+// LoadConfig reads the monitors from source, a file path or an http(s) URL
+// of the API serving them.
+func LoadConfig(ctx context.Context, client *http.Client, source string) (Config, error) {
+	var data []byte
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+		if err != nil {
+			return Config{}, fmt.Errorf("unable to create config request: %w", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return Config{}, fmt.Errorf("unable to fetch config: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return Config{}, fmt.Errorf("unable to fetch config: unexpected status code %d", resp.StatusCode)
+		}
+		if data, err = io.ReadAll(io.LimitReader(resp.Body, 10<<20)); err != nil {
+			return Config{}, fmt.Errorf("unable to read config: %w", err)
+		}
+	} else {
+		var err error
+		if data, err = os.ReadFile(source); err != nil {
+			return Config{}, fmt.Errorf("unable to read config: %w", err)
+		}
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("unable to decode config: %w", err)
+	}
+
+	seen := make(map[string]struct{}, len(cfg.Monitors))
+	for _, m := range cfg.Monitors {
+		if m.ID == "" {
+			return Config{}, fmt.Errorf("monitor without id")
+		}
+		if _, ok := seen[m.ID]; ok {
+			return Config{}, fmt.Errorf("duplicate monitor %s", m.ID)
+		}
+		seen[m.ID] = struct{}{}
+		switch m.Type {
+		case TypeHTTP, TypeTCP, TypeDNS, TypeContent, TypeCrawl, TypeGRPC, TypeSMTP, TypeEmail, TypePorts, TypeOIDC, TypeDownload:
+		default:
+			return Config{}, fmt.Errorf("monitor %s: unsupported type %q", m.ID, m.Type)
+		}
+		if _, err := m.interval(); err != nil {
+			return Config{}, fmt.Errorf("monitor %s: %w", m.ID, err)
+		}
+		if !json.Valid(m.Request) {
+			return Config{}, fmt.Errorf("monitor %s: invalid request", m.ID)
+		}
+	}
+
+	return cfg, nil
+}
+
+// interval accepts the periodicities of openstatus, e.g. 1m, and any Go
+// duration of at least a second.
+func (m Monitor) interval() (time.Duration, error) {
+	if seconds := intervalToSecond(m.Interval); seconds > 0 {
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	d, err := time.ParseDuration(m.Interval)
+	if err != nil || d < time.Second {
+		return 0, fmt.Errorf("invalid interval %q", m.Interval)
+	}
+
+	return d, nil
+}
+
+// jitter is the offset of the runs of the monitor within its interval,
+// derived from its id so it does not move across restarts and reloads.
+func (m Monitor) jitter(interval time.Duration) time.Duration {
+	h := fnv.New64a()
+	h.Write([]byte(m.ID))
+
+	return time.Duration(h.Sum64() % uint64(interval))
+}
+
+// body is the request of a run at t, stamped with the monitor id, the start
+// of its interval like the dispatcher does, the time it was scheduled at:
+// the start of the interval plus the jitter of the monitor, and status, the
+// last one reported, so the check only updates it when it changes. Before
+// the first check, the status is the one of the request, active by default.
+func (m Monitor) body(t time.Time, interval, jitter time.Duration, status string) ([]byte, error) {
+	var payload map[string]any
+	if err := json.Unmarshal(m.Request, &payload); err != nil {
+		return nil, err
+	}
+	if payload == nil {
+		payload = make(map[string]any)
+	}
+	// Round, the timer of a run may fire a bit early or late.
+	tick := t.Add(-jitter).Round(interval)
+	payload["monitorId"] = m.ID
+	payload["cronTimestamp"] = tick.UnixMilli()
+	payload["scheduledAt"] = tick.Add(jitter).UnixMilli()
+	if _, ok := payload["trigger"]; !ok {
+		payload["trigger"] = "cron"
+	}
+	if status != "" {
+		payload["status"] = status
+	} else if _, ok := payload["status"]; !ok {
+		payload["status"] = "active"
+	}
+
+	return json.Marshal(payload)
+}
+
+// Dispatch runs the check of a monitor, with the body of its request, and
+// returns the status of the monitor it reported: active, degraded or error,
+// empty when the check had no outcome, e.g. its circuit being open.
+type Dispatch func(ctx context.Context, checkType string, body []byte) (string, error)
+
+// Standalone schedules the monitors of a Config, so a single checker can run
+// without an external dispatcher. Monitors restricted to other regions are
+// left to the checkers of those regions.
+//
+// The runs are aligned on their interval, e.g. on the minute, and spread
+// across it by a per monitor jitter when Jitter is set, so the checks of a
+// tick do not all start at once.
+type Standalone struct {
+	Region    string
+	Dispatch  Dispatch
+	Scheduler *tasks.Scheduler
+	Jitter    bool
+	mu        sync.Mutex
+	configs   map[string][]byte
+	// statuses are the last status reported of each monitor.
+	statuses map[string]string
+	running  sync.WaitGroup
+	stopping bool
+}
+
+// Stop stops scheduling checks, and waits for the ones running.
+func (s *Standalone) Stop() {
+	// No run starts once stopping is set, so running is not added to while
+	// it is waited for.
+	s.mu.Lock()
+	s.stopping = true
+	s.mu.Unlock()
+
+	s.Scheduler.Stop()
+	s.running.Wait()
+}
+
+// start registers a run of monitor id, returning false once stopping, and
+// the last status reported of the monitor.
+func (s *Standalone) start(id string) (bool, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopping {
+		return false, ""
+	}
+	s.running.Add(1)
+
+	return true, s.statuses[id]
+}
+
+// reported keeps status as the last one of monitor id, unless the check had
+// no outcome or the monitor was removed meanwhile.
+func (s *Standalone) reported(id, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.configs[id]; ok && status != "" {
+		s.statuses[id] = status
+	}
+}
+
+// Apply starts the tasks of new and changed monitors, and stops those of the
+// monitors no longer in cfg.
+func (s *Standalone) Apply(cfg Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.configs == nil {
+		s.configs = make(map[string][]byte)
+		s.statuses = make(map[string]string)
+	}
+
+	current := make(map[string]struct{}, len(cfg.Monitors))
+	for _, m := range cfg.Monitors {
+		if len(m.Regions) > 0 && !slices.Contains(m.Regions, s.Region) {
+			continue
+		}
+		current[m.ID] = struct{}{}
+
+		config, err := json.Marshal(m)
+		if err != nil {
+			log.Error().Err(err).Str("monitor_id", m.ID).Msg("unable to encode monitor")
+			continue
+		}
+		if _, err := s.Scheduler.Lookup(m.ID); err == nil {
+			if bytes.Equal(s.configs[m.ID], config) {
+				continue
+			}
+			log.Info().Str("monitor_id", m.ID).Msg("config changed, rescheduling")
+			s.Scheduler.Del(m.ID)
+		}
+
+		interval, err := m.interval()
+		if err != nil {
+			log.Error().Err(err).Str("monitor_id", m.ID).Msg("unable to schedule monitor")
+			continue
+		}
+
+		var jitter time.Duration
+		if s.Jitter {
+			jitter = m.jitter(interval)
+		}
+
+		task := tasks.Task{
+			Interval: interval,
+			// The runs happen every interval after StartAfter, which
+			// must not be in the past to keep them aligned.
+			StartAfter:        nextRun(time.Now(), interval, jitter),
+			RunSingleInstance: true,
+			ErrFunc: func(err error) {
+				log.Error().Err(err).Str("monitor_id", m.ID).Str("type", m.Type).Msg("scheduled check failed")
+			},
+			TaskFunc: func() error {
+				ok, status := s.start(m.ID)
+				if !ok {
+					return nil
+				}
+				defer s.running.Done()
+
+				body, err := m.body(time.Now(), interval, jitter, status)
+				if err != nil {
+					return fmt.Errorf("unable to build request: %w", err)
+				}
+
+				status, err = s.Dispatch(context.Background(), m.Type, body)
+				s.reported(m.ID, status)
+
+				return err
+			},
+		}
+		if err := s.Scheduler.AddWithID(m.ID, &task); err != nil {
+			log.Error().Err(err).Str("monitor_id", m.ID).Msg("unable to schedule monitor")
+			continue
+		}
+		s.configs[m.ID] = config
+	}
+
+	for id := range s.configs {
+		if _, ok := current[id]; !ok {
+			s.Scheduler.Del(id)
+			delete(s.configs, id)
+			delete(s.statuses, id)
+		}
+	}
+}
+
+// nextRun is the first time after now that is jitter past a multiple of
+// interval.
+func nextRun(now time.Time, interval, jitter time.Duration) time.Time {
+	next := now.Truncate(interval).Add(jitter)
+	if !next.After(now) {
+		next = next.Add(interval)
+	}
+
+	return next
+}
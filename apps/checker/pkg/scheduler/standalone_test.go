@@ -0,0 +1,166 @@
+package scheduler_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/madflojo/tasks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/scheduler"
+)
+
+func TestLoadConfig(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "monitors.json")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	cfg, err := scheduler.LoadConfig(t.Context(), nil, write(t, `{"monitors": [
+		{"id": "1", "type": "http", "interval": "1m", "regions": ["ams"], "request": {"url": "https://openstat.us"}},
+		{"id": "2", "type": "tcp", "interval": "45s", "request": {"uri": "openstat.us:443"}}
+	]}`))
+	require.NoError(t, err)
+	assert.Len(t, cfg.Monitors, 2)
+
+	for name, content := range map[string]string{
+		"type":      `{"monitors": [{"id": "1", "type": "icmp", "interval": "1m", "request": {}}]}`,
+		"interval":  `{"monitors": [{"id": "1", "type": "http", "interval": "100ms", "request": {}}]}`,
+		"duplicate": `{"monitors": [{"id": "1", "type": "http", "interval": "1m", "request": {}}, {"id": "1", "type": "dns", "interval": "1m", "request": {}}]}`,
+		"id":        `{"monitors": [{"type": "http", "interval": "1m", "request": {}}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := scheduler.LoadConfig(t.Context(), nil, write(t, content))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestStandalone_Apply(t *testing.T) {
+	s := tasks.New()
+	defer s.Stop()
+
+	bodies := make(map[string]map[string]any)
+	standalone := &scheduler.Standalone{
+		Region:    "ams",
+		Scheduler: s,
+		Dispatch: func(_ context.Context, checkType string, body []byte) (string, error) {
+			var payload map[string]any
+			if err := json.Unmarshal(body, &payload); err != nil {
+				return "", err
+			}
+			bodies[checkType] = payload
+			return "error", nil
+		},
+	}
+
+	standalone.Apply(scheduler.Config{Monitors: []scheduler.Monitor{
+		{ID: "1", Type: scheduler.TypeHTTP, Interval: "1m", Regions: []string{"ams", "iad"}, Request: json.RawMessage(`{"url": "https://openstat.us"}`)},
+		{ID: "2", Type: scheduler.TypeTCP, Interval: "1m", Regions: []string{"iad"}, Request: json.RawMessage(`{"uri": "openstat.us:443"}`)},
+	}})
+	assert.Len(t, s.Tasks(), 1, "monitors of other regions are not scheduled")
+
+	task, err := s.Lookup("1")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, task.Interval)
+	assert.Equal(t, task.StartAfter, task.StartAfter.Truncate(time.Minute), "runs are aligned on the interval")
+	require.NoError(t, task.TaskFunc())
+
+	payload := bodies[scheduler.TypeHTTP]
+	assert.Equal(t, "https://openstat.us", payload["url"])
+	assert.Equal(t, "1", payload["monitorId"])
+	assert.Equal(t, "cron", payload["trigger"])
+	assert.Equal(t, payload["cronTimestamp"], payload["scheduledAt"])
+	assert.Zero(t, int64(payload["cronTimestamp"].(float64))%time.Minute.Milliseconds())
+	assert.Equal(t, "active", payload["status"])
+
+	require.NoError(t, task.TaskFunc())
+	assert.Equal(t, "error", bodies[scheduler.TypeHTTP]["status"], "the last status reported is sent")
+
+	standalone.Apply(scheduler.Config{})
+	assert.Empty(t, s.Tasks(), "removed monitors are stopped")
+}
+
+func TestStandalone_Jitter(t *testing.T) {
+	s := tasks.New()
+	defer s.Stop()
+
+	var payload map[string]any
+	standalone := &scheduler.Standalone{
+		Scheduler: s,
+		Jitter:    true,
+		Dispatch: func(_ context.Context, _ string, body []byte) (string, error) {
+			return "", json.Unmarshal(body, &payload)
+		},
+	}
+	monitors := make([]scheduler.Monitor, 20)
+	for i := range monitors {
+		monitors[i] = scheduler.Monitor{ID: strconv.Itoa(i), Type: scheduler.TypeHTTP, Interval: "1m", Request: json.RawMessage(`{}`)}
+	}
+	standalone.Apply(scheduler.Config{Monitors: monitors})
+
+	offsets := make(map[time.Duration]struct{})
+	for id, task := range s.Tasks() {
+		offset := task.StartAfter.Sub(task.StartAfter.Truncate(time.Minute))
+		offsets[offset] = struct{}{}
+
+		require.NoError(t, task.TaskFunc())
+		scheduled := time.UnixMilli(int64(payload["scheduledAt"].(float64)))
+		cron := time.UnixMilli(int64(payload["cronTimestamp"].(float64)))
+		assert.Equal(t, offset.Truncate(time.Millisecond), scheduled.Sub(cron), "monitor %s", id)
+	}
+	assert.Greater(t, len(offsets), 10, "the runs are spread across the interval")
+
+	standalone.Apply(scheduler.Config{})
+	standalone.Apply(scheduler.Config{Monitors: monitors[:1]})
+	task, err := s.Lookup("0")
+	require.NoError(t, err)
+	assert.Contains(t, offsets, task.StartAfter.Sub(task.StartAfter.Truncate(time.Minute)), "the jitter of a monitor does not change")
+}
+
+func TestStandalone_Stop(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	standalone := &scheduler.Standalone{
+		Scheduler: tasks.New(),
+		Dispatch: func(context.Context, string, []byte) (string, error) {
+			close(started)
+			<-release
+			return "", nil
+		},
+	}
+	standalone.Apply(scheduler.Config{Monitors: []scheduler.Monitor{
+		{ID: "1", Type: scheduler.TypeHTTP, Interval: "1m", Request: json.RawMessage(`{}`)},
+	}})
+	task, err := standalone.Scheduler.Lookup("1")
+	require.NoError(t, err)
+	go func() { _ = task.TaskFunc() }()
+	<-started
+
+	stopped := make(chan struct{})
+	go func() {
+		standalone.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		t.Fatal("stopped before the running check finished")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("not stopped once the running check finished")
+	}
+
+	// started would be closed twice.
+	assert.NoError(t, task.TaskFunc(), "no check runs once stopped")
+}
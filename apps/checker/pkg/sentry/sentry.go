@@ -0,0 +1,295 @@
+// Package sentry reports the panics and the errors logged by the checker to
+// Sentry, or to any service accepting its envelopes such as GlitchTip, so the
+// bugs of a region are known before they show up as gaps in the data.
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// queueSize is how many events wait to be sent, the next ones being
+	// dropped.
+	queueSize = 100
+	// maxPerMinute caps the events sent, so an error logged on every check
+	// does not flood the project.
+	maxPerMinute = 60
+)
+
+// Options are attached to every event.
+type Options struct {
+	// Tags index the events, e.g. region and provider.
+	Tags        map[string]string
+	Release     string
+	Environment string
+}
+
+// Event is an error reported to Sentry.
+type Event struct {
+	Level   zerolog.Level
+	Message string
+	// Type and Value describe the exception, e.g. panic and its value.
+	Type  string
+	Value string
+	Extra map[string]any
+}
+
+// Client sends the events in the background.
+//
+// A nil *Client is valid and reports nothing.
+type Client struct {
+	opts   Options
+	server string
+	http   *http.Client
+	events chan Event
+	done   chan struct{}
+
+	mu       sync.Mutex
+	endpoint string
+	auth     string
+	dsn      string
+	window   time.Time
+	sent     int
+	closed   bool
+}
+
+// New returns a client of the project of dsn, e.g.
+// https://key@o0.ingest.sentry.io/42.
+func New(dsn string, opts Options) (*Client, error) {
+	server, _ := os.Hostname()
+	c := &Client{
+		opts:   opts,
+		server: server,
+		http:   &http.Client{Timeout: 10 * time.Second},
+		events: make(chan Event, queueSize),
+		done:   make(chan struct{}),
+	}
+	if err := c.SetDSN(dsn); err != nil {
+		return nil, err
+	}
+	go c.run()
+
+	return c, nil
+}
+
+// SetDSN replaces the project and key of the next events, to rotate the key
+// without a restart.
+func (c *Client) SetDSN(dsn string) error {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return err
+	}
+	project := path.Base(u.Path)
+	if u.User == nil || u.User.Username() == "" || project == "/" || project == "." || u.Host == "" {
+		return errors.New("dsn must be scheme://key@host/project")
+	}
+
+	endpoint := *u
+	endpoint.User = nil
+	endpoint.Path = path.Join(path.Dir(u.Path), "api", project, "envelope") + "/"
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.endpoint = endpoint.String()
+	c.auth = fmt.Sprintf("Sentry sentry_version=7, sentry_client=openstatus-checker/%s, sentry_key=%s", c.opts.Release, u.User.Username())
+	c.dsn = dsn
+
+	return nil
+}
+
+// Capture queues e, dropping it when the queue is full or too many events
+// were sent in the last minute.
+func (c *Client) Capture(e Event) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	if now := time.Now(); now.Sub(c.window) >= time.Minute {
+		c.window, c.sent = now, 0
+	}
+	if c.sent >= maxPerMinute {
+		return
+	}
+	select {
+	case c.events <- e:
+		c.sent++
+	default:
+	}
+}
+
+// Close sends the queued events, waiting for them at most timeout.
+func (c *Client) Close(timeout time.Duration) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.events)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-c.done:
+	case <-time.After(timeout):
+	}
+}
+
+func (c *Client) run() {
+	defer close(c.done)
+	for e := range c.events {
+		if err := c.send(context.Background(), e); err != nil {
+			// Not logged at the error level, it would be captured again.
+			fmt.Fprintf(os.Stderr, "failed to send event to sentry: %v\n", err)
+		}
+	}
+}
+
+func (c *Client) send(ctx context.Context, e Event) error {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	eventID := hex.EncodeToString(id)
+	c.mu.Lock()
+	endpoint, auth, dsn := c.endpoint, c.auth, c.dsn
+	c.mu.Unlock()
+
+	event := map[string]any{
+		"event_id":    eventID,
+		"timestamp":   time.Now().UTC().Format(time.RFC3339Nano),
+		"level":       level(e.Level),
+		"platform":    "go",
+		"logger":      "checker",
+		"server_name": c.server,
+		"message":     map[string]string{"formatted": e.Message},
+		"tags":        c.opts.Tags,
+		"extra":       e.Extra,
+	}
+	if c.opts.Release != "" {
+		event["release"] = c.opts.Release
+	}
+	if c.opts.Environment != "" {
+		event["environment"] = c.opts.Environment
+	}
+	if e.Type != "" || e.Value != "" {
+		event["exception"] = map[string]any{"values": []map[string]string{{"type": e.Type, "value": e.Value}}}
+	}
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	_ = enc.Encode(map[string]string{"event_id": eventID, "dsn": dsn, "sent_at": time.Now().UTC().Format(time.RFC3339Nano)})
+	_ = enc.Encode(map[string]string{"type": "event"})
+	if err := enc.Encode(event); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", auth)
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+func level(l zerolog.Level) string {
+	switch l {
+	case zerolog.FatalLevel, zerolog.PanicLevel:
+		return "fatal"
+	case zerolog.WarnLevel:
+		return "warning"
+	case zerolog.InfoLevel:
+		return "info"
+	case zerolog.DebugLevel, zerolog.TraceLevel:
+		return "debug"
+	default:
+		return "error"
+	}
+}
+
+// Writer returns the zerolog writer capturing the records at the error level
+// and above, to be combined with the usual output.
+func (c *Client) Writer() zerolog.LevelWriter {
+	return writer{c}
+}
+
+type writer struct {
+	c *Client
+}
+
+func (w writer) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (w writer) WriteLevel(l zerolog.Level, p []byte) (int, error) {
+	if l < zerolog.ErrorLevel || l == zerolog.NoLevel {
+		return len(p), nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(p, &fields); err != nil {
+		return len(p), nil
+	}
+	e := Event{Level: l, Extra: fields}
+	e.Message, _ = fields[zerolog.MessageFieldName].(string)
+	if err, ok := fields[zerolog.ErrorFieldName].(string); ok {
+		e.Type, e.Value = "error", err
+	}
+	for _, key := range []string{zerolog.LevelFieldName, zerolog.MessageFieldName, zerolog.ErrorFieldName, zerolog.TimestampFieldName} {
+		delete(fields, key)
+	}
+	w.c.Capture(e)
+
+	return len(p), nil
+}
+
+// Recover captures the panics of the handlers, with their stack, and panics
+// again for the recovery middleware before it to answer.
+func (c *Client) Recover() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				c.Capture(Event{
+					Level:   zerolog.PanicLevel,
+					Message: fmt.Sprintf("panic in %s %s", ctx.Request.Method, ctx.FullPath()),
+					Type:    "panic",
+					Value:   fmt.Sprint(r),
+					Extra:   map[string]any{"stack": strings.TrimSpace(string(debug.Stack()))},
+				})
+				panic(r)
+			}
+		}()
+		ctx.Next()
+	}
+}
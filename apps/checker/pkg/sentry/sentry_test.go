@@ -0,0 +1,147 @@
+package sentry_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/sentry"
+)
+
+// ingest is a Sentry project keeping the events of the envelopes it receives.
+type ingest struct {
+	*httptest.Server
+	mu     sync.Mutex
+	events []map[string]any
+}
+
+func newIngest(t *testing.T) *ingest {
+	t.Helper()
+
+	i := &ingest{}
+	i.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/42/envelope/", r.URL.Path)
+		assert.Contains(t, r.Header.Get("X-Sentry-Auth"), "sentry_key=public")
+
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(nil, 1<<20)
+		var lines []string
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		if !assert.Len(t, lines, 3) {
+			return
+		}
+		assert.JSONEq(t, `{"type":"event"}`, lines[1])
+		var event map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(lines[2]), &event))
+		i.mu.Lock()
+		i.events = append(i.events, event)
+		i.mu.Unlock()
+	}))
+	t.Cleanup(i.Close)
+
+	return i
+}
+
+func (i *ingest) received() []map[string]any {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return append([]map[string]any(nil), i.events...)
+}
+
+func newClient(t *testing.T, i *ingest) *sentry.Client {
+	t.Helper()
+
+	dsn := strings.Replace(i.URL, "http://", "http://public@", 1) + "/42"
+	c, err := sentry.New(dsn, sentry.Options{Tags: map[string]string{"region": "ams", "provider": "fly"}, Release: "v1"})
+	require.NoError(t, err)
+
+	return c
+}
+
+func TestNew_InvalidDSN(t *testing.T) {
+	for _, dsn := range []string{"", "https://o0.ingest.sentry.io/42", "https://key@o0.ingest.sentry.io"} {
+		_, err := sentry.New(dsn, sentry.Options{})
+		assert.Error(t, err, dsn)
+	}
+}
+
+func TestClient_SetDSN(t *testing.T) {
+	i := newIngest(t)
+	c, err := sentry.New("https://revoked@o0.ingest.sentry.io/1", sentry.Options{})
+	require.NoError(t, err)
+
+	assert.Error(t, c.SetDSN("https://o0.ingest.sentry.io/42"))
+	require.NoError(t, c.SetDSN(strings.Replace(i.URL, "http://", "http://public@", 1)+"/42"))
+	c.Capture(sentry.Event{Message: "rotated"})
+	c.Close(5 * time.Second)
+
+	events := i.received()
+	require.Len(t, events, 1)
+	assert.Equal(t, map[string]any{"formatted": "rotated"}, events[0]["message"])
+}
+
+func TestWriter(t *testing.T) {
+	i := newIngest(t)
+	c := newClient(t, i)
+
+	logger := zerolog.New(c.Writer())
+	logger.Info().Msg("check done")
+	logger.Error().Err(errors.New("connection reset")).Str("monitor", "1").Msg("failed to send event to tinybird")
+	c.Close(5 * time.Second)
+
+	events := i.received()
+	require.Len(t, events, 1)
+	event := events[0]
+	assert.Equal(t, "error", event["level"])
+	assert.Equal(t, map[string]any{"formatted": "failed to send event to tinybird"}, event["message"])
+	assert.Equal(t, map[string]any{"region": "ams", "provider": "fly"}, event["tags"])
+	assert.Equal(t, "v1", event["release"])
+	assert.Equal(t, map[string]any{"monitor": "1"}, event["extra"])
+	assert.Equal(t, map[string]any{"values": []any{map[string]any{"type": "error", "value": "connection reset"}}}, event["exception"])
+}
+
+func TestRecover(t *testing.T) {
+	i := newIngest(t)
+	c := newClient(t, i)
+
+	router := gin.New()
+	router.Use(gin.Recovery(), c.Recover())
+	router.GET("/boom", func(*gin.Context) { panic("boom") })
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/boom", nil))
+	c.Close(5 * time.Second)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	events := i.received()
+	require.Len(t, events, 1)
+	assert.Equal(t, "fatal", events[0]["level"])
+	assert.Equal(t, map[string]any{"formatted": "panic in GET /boom"}, events[0]["message"])
+	assert.Contains(t, events[0]["extra"].(map[string]any)["stack"], "sentry_test.TestRecover")
+}
+
+func TestClient_Nil(t *testing.T) {
+	var c *sentry.Client
+
+	c.Capture(sentry.Event{Message: "ignored"})
+	c.Close(time.Second)
+	router := gin.New()
+	router.Use(gin.Recovery(), c.Recover())
+	router.GET("/boom", func(*gin.Context) { panic("boom") })
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/boom", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
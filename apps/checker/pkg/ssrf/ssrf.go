@@ -0,0 +1,203 @@
+// Package ssrf keeps the checks from reaching the network of the checker
+// itself: loopback, private, link-local and cloud metadata addresses.
+package ssrf
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var ErrBlocked = errors.New("target address is not allowed")
+
+// blocked lists the ranges not covered by the netip.Addr predicates.
+var blocked = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"), // carrier-grade NAT, Alibaba metadata
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	// NAT64, translated to any IPv4 address, the private ones included.
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// blockedHosts are names resolving to the checker or its metadata service.
+var blockedHosts = map[string]bool{
+	"localhost":                true,
+	"metadata":                 true,
+	"metadata.google.internal": true,
+}
+
+// Guard rejects the blocked addresses that are not in its allowlist. A nil
+// *Guard is valid and allows everything.
+type Guard struct {
+	allow []netip.Prefix
+	mu    sync.RWMutex
+}
+
+// New returns a guard letting the checks reach the allow ranges, for
+// self-hosted checkers monitoring internal services.
+func New(allow []netip.Prefix) *Guard {
+	return &Guard{allow: allow}
+}
+
+// SetAllowlist replaces the allowlist of the guard, when the configuration
+// of the checker is reloaded.
+func (g *Guard) SetAllowlist(allow []netip.Prefix) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.allow = allow
+}
+
+func (g *Guard) allowlist() []netip.Prefix {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.allow
+}
+
+// ParseAllowlist parses a comma separated list of CIDRs or addresses.
+func ParseAllowlist(s string) ([]netip.Prefix, error) {
+	var allow []netip.Prefix
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address %q: %w", v, err)
+			}
+			allow = append(allow, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q: %w", v, err)
+		}
+		allow = append(allow, prefix.Masked())
+	}
+
+	return allow, nil
+}
+
+// CheckAddr reports whether the checks may dial addr.
+func (g *Guard) CheckAddr(addr netip.Addr) error {
+	if g == nil {
+		return nil
+	}
+
+	addr = addr.Unmap()
+	for _, p := range g.allowlist() {
+		if p.Contains(addr) {
+			return nil
+		}
+	}
+
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return fmt.Errorf("%w: %s", ErrBlocked, addr)
+	}
+	for _, p := range blocked {
+		if p.Contains(addr) {
+			return fmt.Errorf("%w: %s", ErrBlocked, addr)
+		}
+	}
+
+	return nil
+}
+
+// CheckHost rejects a target before it is resolved, when it is a blocked
+// address or a name of the checker itself. Names are checked again once
+// resolved, by the dialers of the guard.
+func (g *Guard) CheckHost(host string) error {
+	if g == nil {
+		return nil
+	}
+
+	host = strings.TrimSuffix(strings.ToLower(strings.Trim(host, "[]")), ".")
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return g.CheckAddr(addr)
+	}
+
+	// With an allowlist, the names are left to the dialers: they may resolve
+	// to allowed addresses.
+	if (blockedHosts[host] || strings.HasSuffix(host, ".localhost")) && len(g.allowlist()) == 0 {
+		return fmt.Errorf("%w: %s", ErrBlocked, host)
+	}
+
+	return nil
+}
+
+// CheckURL is CheckHost for the host of an HTTP target.
+func (g *Guard) CheckURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+
+	return g.CheckHost(u.Hostname())
+}
+
+// CheckHostPort is CheckHost for the host:port target of a TCP check.
+func (g *Guard) CheckHostPort(hostPort string) error {
+	host, _, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return err
+	}
+
+	return g.CheckHost(host)
+}
+
+// CheckAddress is CheckHost for a host with an optional port.
+func (g *Guard) CheckAddress(address string) error {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return g.CheckHost(host)
+	}
+
+	return g.CheckHost(address)
+}
+
+// Control checks the resolved address of every connection, redirects and
+// DNS rebinding included. It is meant for net.Dialer.Control.
+func (g *Guard) Control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlocked, address)
+	}
+
+	return g.CheckAddr(addrPort.Addr())
+}
+
+// Dialer returns a dialer checking the addresses it connects to.
+func (g *Guard) Dialer(timeout time.Duration) *net.Dialer {
+	d := &net.Dialer{Timeout: timeout}
+	if g != nil {
+		d.Control = g.Control
+	}
+
+	return d
+}
+
+// Transport returns a copy of the default transport dialing through the
+// guard.
+func (g *Guard) Transport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	d := g.Dialer(30 * time.Second)
+	d.KeepAlive = 30 * time.Second
+	t.DialContext = d.DialContext
+
+	return t
+}
@@ -0,0 +1,63 @@
+package ssrf
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuard_CheckHost(t *testing.T) {
+	g := New(nil)
+
+	for _, host := range []string{
+		"127.0.0.1", "[::1]", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"100.100.100.200", "0.0.0.0", "fd00:ec2::254", "::ffff:127.0.0.1", "localhost",
+		"64:ff9b::a9fe:a9fe", "64:ff9b:1::a00:1", "api.localhost", "metadata.google.internal.",
+	} {
+		assert.ErrorIs(t, g.CheckHost(host), ErrBlocked, host)
+	}
+
+	for _, host := range []string{"openstat.us", "1.1.1.1", "2606:4700:4700::1111"} {
+		assert.NoError(t, g.CheckHost(host), host)
+	}
+
+	var disabled *Guard
+	assert.NoError(t, disabled.CheckHost("127.0.0.1"))
+}
+
+func TestGuard_Allowlist(t *testing.T) {
+	allow, err := ParseAllowlist("10.0.0.0/8, 192.168.1.1")
+	require.NoError(t, err)
+
+	g := New(allow)
+	assert.NoError(t, g.CheckHost("10.2.3.4"))
+	assert.NoError(t, g.CheckHost("192.168.1.1"))
+	assert.ErrorIs(t, g.CheckHost("192.168.1.2"), ErrBlocked)
+	assert.ErrorIs(t, g.CheckAddr(netip.MustParseAddr("127.0.0.1")), ErrBlocked)
+
+	_, err = ParseAllowlist("10.0.0.0/33")
+	assert.Error(t, err)
+
+	g.SetAllowlist(nil)
+	assert.ErrorIs(t, g.CheckHost("10.2.3.4"), ErrBlocked)
+}
+
+func TestGuard_Transport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer server.Close()
+
+	// Checked once resolved, whatever the name of the target.
+	client := &http.Client{Transport: New(nil).Transport()}
+	_, err := client.Get(server.URL)
+	assert.ErrorIs(t, err, ErrBlocked)
+
+	allow, _ := ParseAllowlist("127.0.0.1")
+	client = &http.Client{Transport: New(allow).Transport()}
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
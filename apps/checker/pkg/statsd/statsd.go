@@ -0,0 +1,80 @@
+// Package statsd emits the metrics of the checks over UDP in the DogStatsD
+// format, for the Datadog agents, Telegraf or statsd_exporter listening next
+// to the checker without an OTLP collector.
+package statsd
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Client sends the metrics, prefixed, with its tags on top of the ones of
+// each metric. Sending never blocks and errors are dropped, as UDP would.
+//
+// A nil *Client is valid and sends nothing.
+type Client struct {
+	conn   net.Conn
+	prefix string
+	tags   []string
+}
+
+// New returns a client sending to addr, e.g. localhost:8125. Tags are
+// name:value pairs.
+func New(addr, prefix string, tags ...string) (*Client, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, ".") {
+		prefix += "."
+	}
+
+	return &Client{conn: conn, prefix: prefix, tags: tags}, nil
+}
+
+// Timing sends a duration in milliseconds.
+func (c *Client) Timing(name string, ms int64, tags ...string) {
+	c.send(name, strconv.FormatInt(ms, 10), "ms", tags)
+}
+
+// Count adds n to a counter.
+func (c *Client) Count(name string, n int64, tags ...string) {
+	c.send(name, strconv.FormatInt(n, 10), "c", tags)
+}
+
+func (c *Client) send(name, value, kind string, tags []string) {
+	if c == nil {
+		return
+	}
+
+	var b strings.Builder
+	b.WriteString(c.prefix)
+	b.WriteString(sanitize(name))
+	b.WriteByte(':')
+	b.WriteString(value)
+	b.WriteByte('|')
+	b.WriteString(kind)
+	for i, tag := range append(c.tags[:len(c.tags):len(c.tags)], tags...) {
+		if i == 0 {
+			b.WriteString("|#")
+		} else {
+			b.WriteByte(',')
+		}
+		b.WriteString(sanitize(tag))
+	}
+
+	_, _ = c.conn.Write([]byte(b.String()))
+}
+
+// sanitize replaces the characters of the protocol in names and tags.
+var sanitize = strings.NewReplacer("|", "_", "#", "_", ",", "_", "\n", "_", "@", "_").Replace
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	if c == nil {
+		return nil
+	}
+
+	return c.conn.Close()
+}
@@ -0,0 +1,40 @@
+package statsd
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	c, err := New(conn.LocalAddr().String(), "openstatus", "provider:fly")
+	require.NoError(t, err)
+	defer c.Close()
+
+	read := func() string {
+		buf := make([]byte, 1024)
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		return string(buf[:n])
+	}
+
+	c.Timing("check.latency", 42, "monitor:1", "region:ams")
+	require.Equal(t, "openstatus.check.latency:42|ms|#provider:fly,monitor:1,region:ams", read())
+
+	c.Count("check.error", 1, "code:a|b,c")
+	require.Equal(t, "openstatus.check.error:1|c|#provider:fly,code:a_b_c", read())
+}
+
+func TestNilClient(t *testing.T) {
+	var c *Client
+	c.Timing("check.latency", 1)
+	c.Count("check.count", 1)
+	require.NoError(t, c.Close())
+}
@@ -0,0 +1,131 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sasValidity is how long the shared access signature of a request is valid.
+const sasValidity = time.Hour
+
+// EventHubs publishes the records to an event hub with its REST API, as
+// batches of messages.
+type EventHubs struct {
+	endpoint string
+	resource string
+	http     *http.Client
+
+	mu      sync.RWMutex
+	keyName string
+	key     string
+}
+
+// NewEventHubs returns the publisher of the event hub of connectionString,
+// e.g. Endpoint=sb://ns.servicebus.windows.net/;SharedAccessKeyName=send;
+// SharedAccessKey=...;EntityPath=checks.
+func NewEventHubs(connectionString string) (*EventHubs, error) {
+	fields := make(map[string]string)
+	for part := range strings.SplitSeq(connectionString, ";") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(part), "="); ok {
+			fields[strings.ToLower(k)] = v
+		}
+	}
+	endpoint, err := url.Parse(fields["endpoint"])
+	if err != nil {
+		return nil, err
+	}
+	hub := fields["entitypath"]
+	if endpoint.Host == "" || hub == "" || fields["sharedaccesskeyname"] == "" || fields["sharedaccesskey"] == "" {
+		return nil, errors.New("connection string must set Endpoint, SharedAccessKeyName, SharedAccessKey and EntityPath")
+	}
+
+	resource := "https://" + endpoint.Host + "/" + hub
+	return &EventHubs{
+		endpoint: resource + "/messages",
+		resource: resource,
+		keyName:  fields["sharedaccesskeyname"],
+		key:      fields["sharedaccesskey"],
+		http:     &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// SetConnectionString replaces the shared access key of the next requests
+// with the one of connectionString, which must be of the same event hub.
+func (e *EventHubs) SetConnectionString(connectionString string) error {
+	next, err := NewEventHubs(connectionString)
+	if err != nil {
+		return err
+	}
+	if next.resource != e.resource {
+		return errors.New("connection string of another event hub")
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.keyName, e.key = next.keyName, next.key
+
+	return nil
+}
+
+type message struct {
+	Body             string            `json:"Body"`
+	BrokerProperties map[string]string `json:"BrokerProperties"`
+}
+
+func (e *EventHubs) Publish(ctx context.Context, records []Record) error {
+	messages := make([]message, len(records))
+	for i, r := range records {
+		messages[i] = message{Body: string(r.Data), BrokerProperties: map[string]string{"PartitionKey": r.PartitionKey}}
+	}
+	body, err := json.Marshal(messages)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.microsoft.servicebus.json")
+	req.Header.Set("Authorization", e.signature(time.Now()))
+
+	res, err := e.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// signature returns the shared access signature of the event hub, valid for
+// sasValidity from now.
+func (e *EventHubs) signature(now time.Time) string {
+	e.mu.RLock()
+	keyName, key := e.keyName, e.key
+	e.mu.RUnlock()
+
+	resource := url.QueryEscape(e.resource)
+	expiry := strconv.FormatInt(now.Add(sasValidity).Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(resource + "\n" + expiry))
+	sig := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	return fmt.Sprintf("SharedAccessSignature sr=%s&sig=%s&se=%s&skn=%s", resource, url.QueryEscape(sig), expiry, url.QueryEscape(keyName))
+}
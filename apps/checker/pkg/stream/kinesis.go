@@ -0,0 +1,177 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials sign the requests to AWS.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set for temporary credentials.
+	SessionToken string
+}
+
+// Kinesis publishes the records to a Kinesis data stream with PutRecords,
+// signed with Signature Version 4.
+type Kinesis struct {
+	stream   string
+	region   string
+	endpoint string
+	http     *http.Client
+
+	mu    sync.RWMutex
+	creds Credentials
+}
+
+func NewKinesis(stream, region string, creds Credentials) (*Kinesis, error) {
+	if stream == "" || region == "" {
+		return nil, errors.New("stream and region are required")
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, errors.New("missing AWS credentials")
+	}
+
+	return &Kinesis{
+		stream:   stream,
+		region:   region,
+		creds:    creds,
+		endpoint: "https://kinesis." + region + ".amazonaws.com/",
+		http:     &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// SetCredentials replaces the credentials the next requests are signed with,
+// e.g. once temporary ones are renewed.
+func (k *Kinesis) SetCredentials(creds Credentials) error {
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return errors.New("missing AWS credentials")
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.creds = creds
+
+	return nil
+}
+
+type putRecordsEntry struct {
+	// Data is encoded in base64 as a []byte.
+	Data         []byte `json:"Data"`
+	PartitionKey string `json:"PartitionKey"`
+}
+
+func (k *Kinesis) Publish(ctx context.Context, records []Record) error {
+	entries := make([]putRecordsEntry, len(records))
+	for i, r := range records {
+		entries[i] = putRecordsEntry{Data: r.Data, PartitionKey: r.PartitionKey}
+	}
+	body, err := json.Marshal(map[string]any{"StreamName": k.stream, "Records": entries})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "Kinesis_20131202.PutRecords")
+	k.sign(req, body, time.Now())
+
+	res, err := k.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("unexpected status code %d: %s", res.StatusCode, bytes.TrimSpace(msg))
+	}
+	var out struct {
+		FailedRecordCount int `json:"FailedRecordCount"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return fmt.Errorf("unable to decode response: %w", err)
+	}
+	if out.FailedRecordCount > 0 {
+		return fmt.Errorf("%d of %d records failed", out.FailedRecordCount, len(records))
+	}
+
+	return nil
+}
+
+// sign sets the Signature Version 4 headers of req, whose body is body.
+func (k *Kinesis) sign(req *http.Request, body []byte, now time.Time) {
+	const service = "kinesis"
+	k.mu.RLock()
+	creds := k.creds
+	k.mu.RUnlock()
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	// The signed headers, in order.
+	names := []string{"content-type", "host", "x-amz-date", "x-amz-security-token", "x-amz-target"}
+	var canonicalHeaders strings.Builder
+	var signed []string
+	for _, name := range names {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.URL.Host
+		}
+		if value == "" {
+			continue
+		}
+		canonicalHeaders.WriteString(name + ":" + value + "\n")
+		signed = append(signed, name)
+	}
+	signedHeaders := strings.Join(signed, ";")
+	canonicalRequest := req.Method + "\n/\n\n" + canonicalHeaders.String() + "\n" + signedHeaders + "\n" + payloadHash
+
+	scope := date + "/" + k.region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	signature := hex.EncodeToString(hmacSHA256(signingKey(creds.SecretAccessKey, date, k.region, service), stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// signingKey derives the key of the signatures of date, region and service.
+func signingKey(secret, date, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+
+	return hmacSHA256(key, "aws4_request")
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,171 @@
+// Package stream ships the events of the checks to the streaming services
+// the data platforms of enterprises are built on, Azure Event Hubs and AWS
+// Kinesis, next to Tinybird.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// queueSize is how many records wait to be published, the next ones
+	// being dropped.
+	queueSize = 1000
+	// batchSize is how many records are published at once at most.
+	batchSize = 100
+	// maxBatchBytes bounds the data of a batch below the 1 MB of an Event
+	// Hubs batch and the 5 MB of a Kinesis one.
+	maxBatchBytes = 900 << 10
+	// flushInterval is how long a record waits for its batch to fill.
+	flushInterval = time.Second
+)
+
+// ErrQueueFull is returned for the events sent while the queue is full.
+var ErrQueueFull = errors.New("stream queue full, event dropped")
+
+// Record is an event as published: Data is the JSON of the event and its
+// source, the Tinybird data source, and the events of a monitor share their
+// PartitionKey so they are read in order.
+type Record struct {
+	PartitionKey string
+	Data         []byte
+}
+
+// Publisher publishes a batch of records to a service.
+type Publisher interface {
+	Publish(ctx context.Context, records []Record) error
+}
+
+// Sink publishes the events with its Publisher in the background, batched.
+// It is a tinybird.Client.
+//
+// A nil *Sink is valid and publishes nothing.
+type Sink struct {
+	name      string
+	publisher Publisher
+	records   chan Record
+	done      chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// NewSink returns a sink publishing with p, name naming it in the logs.
+func NewSink(name string, p Publisher) *Sink {
+	s := &Sink{
+		name:      name,
+		publisher: p,
+		records:   make(chan Record, queueSize),
+		done:      make(chan struct{}),
+	}
+	go s.run()
+
+	return s
+}
+
+// SendEvent queues event.
+func (s *Sink) SendEvent(_ context.Context, event any, dataSourceName string) error {
+	if s == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(struct {
+		Source string `json:"source"`
+		Event  any    `json:"event"`
+	}{dataSourceName, event})
+	if err != nil {
+		return fmt.Errorf("unable to encode event: %w", err)
+	}
+	r := Record{PartitionKey: partitionKey(data, dataSourceName), Data: data}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	select {
+	case s.records <- r:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// partitionKey returns the monitor of an event, its source for the events of
+// no monitor.
+func partitionKey(data []byte, source string) string {
+	var fields struct {
+		Event struct {
+			MonitorID json.Number `json:"monitorId"`
+		} `json:"event"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil || fields.Event.MonitorID == "" {
+		return source
+	}
+
+	return fields.Event.MonitorID.String()
+}
+
+// Close publishes the queued records, waiting for them at most timeout.
+func (s *Sink) Close(timeout time.Duration) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.records)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+	case <-time.After(timeout):
+	}
+}
+
+func (s *Sink) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	var (
+		batch []Record
+		size  int
+	)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := s.publisher.Publish(context.Background(), batch); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to publish %d events to %s: %v\n", len(batch), s.name, err)
+		}
+		batch, size = nil, 0
+	}
+	for {
+		select {
+		case r, ok := <-s.records:
+			if !ok {
+				flush()
+				return
+			}
+			if size+len(r.Data) > maxBatchBytes {
+				flush()
+			}
+			batch, size = append(batch, r), size+len(r.Data)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package stream
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakePublisher struct {
+	mu      sync.Mutex
+	batches [][]Record
+}
+
+func (p *fakePublisher) Publish(_ context.Context, records []Record) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.batches = append(p.batches, records)
+	return nil
+}
+
+func TestSink(t *testing.T) {
+	p := &fakePublisher{}
+	s := NewSink("fake", p)
+
+	ctx := context.Background()
+	require.NoError(t, s.SendEvent(ctx, map[string]any{"monitorId": 42, "region": "ams"}, "ping_response__v8"))
+	require.NoError(t, s.SendEvent(ctx, map[string]any{"region": "ams"}, "heartbeat__v0"))
+	s.Close(5 * time.Second)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	require.Len(t, p.batches, 1)
+	require.Len(t, p.batches[0], 2)
+	require.Equal(t, "42", p.batches[0][0].PartitionKey)
+	require.JSONEq(t, `{"source":"ping_response__v8","event":{"monitorId":42,"region":"ams"}}`, string(p.batches[0][0].Data))
+	require.Equal(t, "heartbeat__v0", p.batches[0][1].PartitionKey)
+
+	// Closed, the events are dropped.
+	require.NoError(t, s.SendEvent(ctx, map[string]any{}, "ping_response__v8"))
+
+	var nilSink *Sink
+	require.NoError(t, nilSink.SendEvent(ctx, map[string]any{}, "ping_response__v8"))
+	nilSink.Close(time.Second)
+}
+
+func TestEventHubs(t *testing.T) {
+	_, err := NewEventHubs("Endpoint=sb://ns.servicebus.windows.net/;SharedAccessKeyName=send")
+	require.Error(t, err)
+
+	e, err := NewEventHubs("Endpoint=sb://ns.servicebus.windows.net/;SharedAccessKeyName=send;SharedAccessKey=c2VjcmV0;EntityPath=checks")
+	require.NoError(t, err)
+	require.Equal(t, "https://ns.servicebus.windows.net/checks/messages", e.endpoint)
+
+	sig := e.signature(time.Unix(1700000000, 0))
+	require.True(t, strings.HasPrefix(sig, "SharedAccessSignature sr=https%3A%2F%2Fns.servicebus.windows.net%2Fchecks&sig="), sig)
+	require.True(t, strings.HasSuffix(sig, "&se=1700003600&skn=send"), sig)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/vnd.microsoft.servicebus.json", r.Header.Get("Content-Type"))
+		require.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "SharedAccessSignature "))
+
+		var messages []message
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&messages))
+		require.Equal(t, []message{{Body: `{"a":1}`, BrokerProperties: map[string]string{"PartitionKey": "1"}}}, messages)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	e.endpoint = server.URL
+
+	require.NoError(t, e.Publish(context.Background(), []Record{{PartitionKey: "1", Data: []byte(`{"a":1}`)}}))
+
+	require.Error(t, e.SetConnectionString("Endpoint=sb://ns.servicebus.windows.net/;SharedAccessKeyName=send;SharedAccessKey=c2VjcmV0;EntityPath=other"))
+	require.NoError(t, e.SetConnectionString("Endpoint=sb://ns.servicebus.windows.net/;SharedAccessKeyName=rotated;SharedAccessKey=bmV3;EntityPath=checks"))
+	require.True(t, strings.HasSuffix(e.signature(time.Unix(1700000000, 0)), "&skn=rotated"))
+}
+
+func TestKinesis(t *testing.T) {
+	_, err := NewKinesis("checks", "eu-west-1", Credentials{})
+	require.Error(t, err)
+
+	k, err := NewKinesis("checks", "eu-west-1", Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token"})
+	require.NoError(t, err)
+	require.Equal(t, "https://kinesis.eu-west-1.amazonaws.com/", k.endpoint)
+
+	failed := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Kinesis_20131202.PutRecords", r.Header.Get("X-Amz-Target"))
+		require.Equal(t, "token", r.Header.Get("X-Amz-Security-Token"))
+		auth := r.Header.Get("Authorization")
+		require.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/"), auth)
+		require.Contains(t, auth, "/eu-west-1/kinesis/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target, Signature=")
+
+		var body struct {
+			StreamName string
+			Records    []struct {
+				Data         string
+				PartitionKey string
+			}
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		require.Equal(t, "checks", body.StreamName)
+		require.Equal(t, base64.StdEncoding.EncodeToString([]byte(`{"a":1}`)), body.Records[0].Data)
+		require.Equal(t, "1", body.Records[0].PartitionKey)
+		_ = json.NewEncoder(w).Encode(map[string]int{"FailedRecordCount": failed})
+	}))
+	defer server.Close()
+	k.endpoint = server.URL + "/"
+
+	records := []Record{{PartitionKey: "1", Data: []byte(`{"a":1}`)}}
+	require.NoError(t, k.Publish(context.Background(), records))
+
+	failed = 1
+	require.EqualError(t, k.Publish(context.Background(), records), "1 of 1 records failed")
+
+	require.Error(t, k.SetCredentials(Credentials{AccessKeyID: "AKID"}))
+	require.NoError(t, k.SetCredentials(Credentials{AccessKeyID: "AKID", SecretAccessKey: "rotated", SessionToken: "token"}))
+	failed = 0
+	require.NoError(t, k.Publish(context.Background(), records))
+}
+
+func TestSigningKey(t *testing.T) {
+	// The example of the AWS documentation.
+	key := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	require.Equal(t, "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d", hex.EncodeToString(key))
+}
@@ -0,0 +1,80 @@
+// Package templating renders the variables of the URL, headers and body of
+// HTTP checks when they run, so checks can reach APIs rejecting duplicate
+// payloads without hard-coding fresh values.
+package templating
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SecretPrefix is the prefix of the environment variables of the checker
+// readable as {{secret.NAME}}, the rest of the environment is not.
+const SecretPrefix = "CHECKER_SECRET_"
+
+var variable = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.]+)\s*\}\}`)
+
+// Renderer renders the variables of one execution of a check:
+//
+//   - {{timestamp}}: the Unix time in seconds
+//   - {{timestamp_ms}}: the Unix time in milliseconds
+//   - {{uuid}}: a random UUID, different for each occurrence
+//   - {{random_int}}: a random integer between 0 and 2^31-1, different for
+//     each occurrence
+//   - {{secret.NAME}}: the CHECKER_SECRET_NAME environment variable
+//
+// Any other {{...}} is left as is, bodies may legitimately hold some.
+type Renderer struct {
+	now       time.Time
+	lookupEnv func(string) (string, bool)
+}
+
+func New() Renderer {
+	return Renderer{now: time.Now(), lookupEnv: os.LookupEnv}
+}
+
+// Render returns s with its variables replaced, and fails on a secret the
+// checker does not have.
+func (r Renderer) Render(s string) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	var err error
+	rendered := variable.ReplaceAllStringFunc(s, func(match string) string {
+		name := variable.FindStringSubmatch(match)[1]
+		switch name {
+		case "timestamp":
+			return strconv.FormatInt(r.now.Unix(), 10)
+		case "timestamp_ms":
+			return strconv.FormatInt(r.now.UnixMilli(), 10)
+		case "uuid":
+			return uuid.NewString()
+		case "random_int":
+			return strconv.FormatInt(int64(rand.Int32()), 10)
+		}
+
+		secret, ok := strings.CutPrefix(name, "secret.")
+		if !ok {
+			return match
+		}
+		value, ok := r.lookupEnv(SecretPrefix + secret)
+		if !ok && err == nil {
+			err = fmt.Errorf("unknown secret %q", secret)
+		}
+
+		return value
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return rendered, nil
+}
@@ -0,0 +1,53 @@
+package templating_test
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/templating"
+)
+
+func TestRenderer_Render(t *testing.T) {
+	t.Setenv(templating.SecretPrefix+"API_KEY", "s3cret")
+	r := templating.New()
+
+	t.Run("variables", func(t *testing.T) {
+		got, err := r.Render(`{"id":"{{uuid}}","n":{{ random_int }},"at":{{timestamp}}}`)
+		require.NoError(t, err)
+		assert.Regexp(t, regexp.MustCompile(`^\{"id":"[0-9a-f-]{36}","n":\d+,"at":\d+\}$`), got)
+	})
+
+	t.Run("timestamp", func(t *testing.T) {
+		got, err := r.Render("{{timestamp_ms}}")
+		require.NoError(t, err)
+		ms, err := strconv.ParseInt(got, 10, 64)
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), time.UnixMilli(ms), time.Minute)
+	})
+
+	t.Run("fresh values", func(t *testing.T) {
+		got, err := r.Render("{{uuid}} {{uuid}}")
+		require.NoError(t, err)
+		assert.NotEqual(t, got[:36], got[37:])
+	})
+
+	t.Run("secret", func(t *testing.T) {
+		got, err := r.Render("Bearer {{secret.API_KEY}}")
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer s3cret", got)
+
+		_, err = r.Render("{{secret.MISSING}}")
+		assert.ErrorContains(t, err, `unknown secret "MISSING"`)
+	})
+
+	t.Run("other braces", func(t *testing.T) {
+		got, err := r.Render(`{"query":"{{ user.name }}"}`)
+		require.NoError(t, err)
+		assert.Equal(t, `{"query":"{{ user.name }}"}`, got)
+	})
+}
@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
+	"sync"
 
 	"github.com/rs/zerolog/log"
 )
@@ -77,3 +79,44 @@ func (c client) SendEvent(ctx context.Context, event any, dataSourceName string)
 
 	return nil
 }
+
+// Reloadable is a Client replaced when the configuration of the checker is
+// reloaded, e.g. with a new token.
+type Reloadable struct {
+	client Client
+	mu     sync.RWMutex
+}
+
+func NewReloadable(c Client) *Reloadable {
+	return &Reloadable{client: c}
+}
+
+// Set replaces the client the events are sent with.
+func (r *Reloadable) Set(c Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.client = c
+}
+
+func (r *Reloadable) SendEvent(ctx context.Context, event any, dataSourceName string) error {
+	r.mu.RLock()
+	c := r.client
+	r.mu.RUnlock()
+
+	return c.SendEvent(ctx, event, dataSourceName)
+}
+
+// Multi sends the events to every client, e.g. Tinybird and a log sink.
+type Multi []Client
+
+func (m Multi) SendEvent(ctx context.Context, event any, dataSourceName string) error {
+	var errs []error
+	for _, c := range m {
+		if err := c.SendEvent(ctx, event, dataSourceName); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
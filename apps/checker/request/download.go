@@ -0,0 +1,63 @@
+package request
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// The size caps of a download check, in bytes.
+const (
+	DefaultDownloadBytes = 100 << 20
+	MaxDownloadBytes     = 1 << 30
+)
+
+// DownloadCheckerRequest downloads the file at URL, e.g. a release artifact
+// or an installer, failing when its SHA-256 is not SHA256, the file being
+// corrupted or tampered with.
+type DownloadCheckerRequest struct {
+	Scheduled
+	Headers []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	} `json:"headers,omitempty"`
+	URL string `json:"url"`
+	// Timeout bounds the whole download, its body included.
+	Timeout int64 `json:"timeout"`
+	// SHA256 is the hex digest the file must have.
+	SHA256 string `json:"sha256"`
+	// MaxBytes caps the file, DefaultDownloadBytes by default and at most
+	// MaxDownloadBytes.
+	MaxBytes int64 `json:"maxBytes,omitempty"`
+}
+
+// Cap returns the size cap of the file, MaxBytes or its default.
+func (r DownloadCheckerRequest) Cap() int64 {
+	if r.MaxBytes <= 0 {
+		return DefaultDownloadBytes
+	}
+
+	return min(r.MaxBytes, MaxDownloadBytes)
+}
+
+// Validate reports every invalid field of a scheduled download check.
+func (r DownloadCheckerRequest) Validate() error {
+	var v ValidationError
+
+	v.id("workspaceId", r.WorkspaceID, true)
+	v.id("monitorId", r.MonitorID, true)
+	v.httpURL("url", r.URL)
+	v.status(r.Status)
+	v.timeout("download", r.Timeout)
+	if r.SHA256 == "" {
+		v.add("sha256", "is required", nil)
+	} else if b, err := hex.DecodeString(r.SHA256); err != nil || len(b) != 32 {
+		v.add("sha256", "must be the 64 hex digits of a SHA-256 digest", r.SHA256)
+	}
+	if r.MaxBytes < 0 || r.MaxBytes > MaxDownloadBytes {
+		v.add("maxBytes", fmt.Sprintf("must be between 0 and %d", MaxDownloadBytes), r.MaxBytes)
+	}
+	v.labels(r.Labels)
+	v.callbackURL(r.CallbackURL)
+
+	return v.err()
+}
@@ -0,0 +1,58 @@
+package request
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MaxDKIMSelectors caps the DKIM selectors of an email check.
+const MaxDKIMSelectors = 10
+
+// dmarcPolicies are the DMARC policies, weakest first.
+var dmarcPolicies = []string{"none", "quarantine", "reject"}
+
+// EmailCheckerRequest checks the email authentication records of the domain
+// URI: the syntax of its SPF record, the keys of its DKIM Selectors and its
+// DMARC policy, failing when one of them is missing or broken.
+type EmailCheckerRequest struct {
+	Scheduled
+	URI     string `json:"uri"`
+	Timeout int64  `json:"timeout"`
+	// Selectors are the DKIM selectors whose keys are checked, e.g. google
+	// for google._domainkey.
+	Selectors []string `json:"selectors,omitempty"`
+	// MinPolicy is the weakest DMARC policy accepted, none, quarantine or
+	// reject, any by default.
+	MinPolicy string `json:"minPolicy,omitempty"`
+}
+
+// Validate reports every invalid field of a scheduled email check.
+func (r EmailCheckerRequest) Validate() error {
+	var v ValidationError
+
+	v.id("workspaceId", r.WorkspaceID, true)
+	v.id("monitorId", r.MonitorID, true)
+	v.hostname("uri", r.URI, true)
+	v.status(r.Status)
+	v.timeout("email", r.Timeout)
+	if len(r.Selectors) > MaxDKIMSelectors {
+		v.add("selectors", fmt.Sprintf("must list at most %d selectors", MaxDKIMSelectors), len(r.Selectors))
+	}
+	for i, selector := range r.Selectors {
+		field := fmt.Sprintf("selectors[%d]", i)
+		switch {
+		case selector == "":
+			v.add(field, "is required", nil)
+		case strings.ContainsAny(selector, "/ ") || strings.HasSuffix(selector, "._domainkey"):
+			v.add(field, "must be the selector alone, e.g. google", selector)
+		}
+	}
+	if r.MinPolicy != "" && !slices.Contains(dmarcPolicies, r.MinPolicy) {
+		v.add("minPolicy", "must be one of none, quarantine or reject", r.MinPolicy)
+	}
+	v.labels(r.Labels)
+	v.callbackURL(r.CallbackURL)
+
+	return v.err()
+}
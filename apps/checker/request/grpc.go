@@ -0,0 +1,89 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// GRPCCheckerRequest invokes Method, a unary method of the server at URI,
+// discovered with its server reflection, with the JSON of Body, and asserts
+// on the status code and the JSON of the response. The assertions are
+// status (the code, 0 being OK), textBody and jsonBody, the call succeeding
+// with the OK code when there is none.
+type GRPCCheckerRequest struct {
+	Scheduled
+	URI     string `json:"uri"`
+	Timeout int64  `json:"timeout"`
+	// Method is the full name of the method, package.Service/Method.
+	Method string `json:"method"`
+	// Body is the JSON of the request message, empty for its default
+	// value.
+	Body json.RawMessage `json:"body,omitempty"`
+	// Metadata is sent with the call, e.g. an authorization token.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Plaintext calls the server without TLS.
+	Plaintext     bool              `json:"plaintext,omitempty"`
+	RawAssertions []json.RawMessage `json:"assertions,omitempty"`
+}
+
+// grpcAssertionTypes are the assertions of a gRPC check.
+var grpcAssertionTypes = map[AssertionType]bool{
+	AssertionStatus: true, AssertionTextBody: true, AssertionJsonBody: true,
+}
+
+// Address returns the host:port of the URI of the check, as validated.
+func (r GRPCCheckerRequest) Address() string {
+	address, err := HostPort(r.URI)
+	if err != nil {
+		return r.URI
+	}
+
+	return address
+}
+
+// Service returns the full names of the service and of the method of
+// Method, e.g. grpc.health.v1.Health and Check.
+func (r GRPCCheckerRequest) Service() (string, string, bool) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(r.Method, "/"), "/")
+
+	return service, method, ok && service != "" && method != "" && !strings.Contains(method, "/")
+}
+
+// Validate reports every invalid field of a scheduled gRPC check.
+func (r GRPCCheckerRequest) Validate() error {
+	var v ValidationError
+
+	v.id("workspaceId", r.WorkspaceID, true)
+	v.id("monitorId", r.MonitorID, true)
+	v.hostPort("uri", r.URI)
+	v.status(r.Status)
+	v.timeout("grpc", r.Timeout)
+	if _, _, ok := r.Service(); !ok {
+		v.add("method", "must be package.Service/Method", r.Method)
+	}
+	if len(r.Body) > 0 {
+		var body map[string]json.RawMessage
+		if json.Unmarshal(r.Body, &body) != nil {
+			v.add("body", "must be a JSON object", string(r.Body))
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(r.Metadata)) {
+		if key == "" || strings.HasPrefix(strings.ToLower(key), "grpc-") {
+			v.add("metadata."+key, "must not be empty nor start with grpc-", key)
+		}
+	}
+	v.assertions(r.RawAssertions)
+	for i, a := range r.RawAssertions {
+		var assertion Assertion
+		if json.Unmarshal(a, &assertion) == nil && assertionTypes[assertion.AssertionType] && !grpcAssertionTypes[assertion.AssertionType] {
+			v.add(fmt.Sprintf("assertions[%d].type", i), "must be one of status, textBody or jsonBody", assertion.AssertionType)
+		}
+	}
+	v.labels(r.Labels)
+	v.callbackURL(r.CallbackURL)
+
+	return v.err()
+}
@@ -0,0 +1,98 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultPorts are the ports of the schemes the URI of a TCP check may carry
+// instead of a port, e.g. smtp://mail.example.com for port 25.
+var DefaultPorts = map[string]string{
+	"ftp": "21", "ssh": "22", "smtp": "25", "http": "80", "imap": "143",
+	"ldap": "389", "https": "443", "smtps": "465", "submission": "587",
+	"ldaps": "636", "imaps": "993", "mysql": "3306", "postgres": "5432",
+	"postgresql": "5432", "redis": "6379", "mongodb": "27017",
+}
+
+// HostPort returns the host:port of uri, the target of a TCP check: either
+// host:port, with the IPv6 literals bracketed and their zone if any, e.g.
+// [fe80::1%eth0]:22, or scheme://host[:port] with a scheme of DefaultPorts.
+// Internationalized hosts are returned in their ASCII form. Its errors say
+// what is wrong with uri.
+func HostPort(uri string) (string, error) {
+	if scheme, _, ok := strings.Cut(uri, "://"); ok {
+		return schemeHostPort(uri, strings.ToLower(scheme))
+	}
+
+	host, port, err := net.SplitHostPort(uri)
+	if err != nil {
+		if strings.Count(uri, ":") > 1 {
+			return "", errors.New("IPv6 literals must be bracketed and followed by a port, as [::1]:443")
+		}
+		var addrErr *net.AddrError
+		if errors.As(err, &addrErr) && addrErr.Err == "missing port in address" {
+			return "", errors.New("missing port, as host:443")
+		}
+		return "", errors.New("must be host:port")
+	}
+
+	return joinHostPort(host, port)
+}
+
+func schemeHostPort(uri, scheme string) (string, error) {
+	defaultPort, ok := DefaultPorts[scheme]
+	if !ok {
+		return "", fmt.Errorf("unsupported scheme %q, use host:port or one of %s", scheme, strings.Join(slices.Sorted(maps.Keys(DefaultPorts)), ", "))
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", errors.New("invalid URI")
+	}
+	if u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return "", errors.New("must not have credentials, a path, a query or a fragment")
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = defaultPort
+	}
+
+	return joinHostPort(u.Hostname(), port)
+}
+
+func joinHostPort(host, port string) (string, error) {
+	if host == "" {
+		return "", errors.New("missing host")
+	}
+	if strings.Contains(host, ":") {
+		if _, err := netip.ParseAddr(host); err != nil {
+			return "", fmt.Errorf("invalid IPv6 literal %q", host)
+		}
+	}
+	if p, err := strconv.ParseUint(port, 10, 16); err != nil || p == 0 {
+		return "", fmt.Errorf("port must be between 1 and 65535, not %q", port)
+	}
+	host, err := ASCIIHost(host)
+	if err != nil {
+		return "", err
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
+
+// Address returns the host:port of the URI of the check, as validated.
+func (r TCPCheckerRequest) Address() string {
+	address, err := HostPort(r.URI)
+	if err != nil {
+		return r.URI
+	}
+
+	return address
+}
@@ -0,0 +1,46 @@
+package request_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestHostPort(t *testing.T) {
+	for uri, want := range map[string]string{
+		"openstat.us:443":             "openstat.us:443",
+		"[::1]:443":                   "[::1]:443",
+		"[fe80::1%eth0]:22":           "[fe80::1%eth0]:22",
+		"127.0.0.1:5432":              "127.0.0.1:5432",
+		"smtp://mail.openstat.us":     "mail.openstat.us:25",
+		"HTTPS://openstat.us/":        "openstat.us:443",
+		"postgres://db.internal:6543": "db.internal:6543",
+		"https://[2001:db8::1]":       "[2001:db8::1]:443",
+		"ssh://[fe80::1%25eth0]":      "[fe80::1%eth0]:22",
+	} {
+		got, err := request.HostPort(uri)
+		require.NoError(t, err, uri)
+		assert.Equal(t, want, got, uri)
+	}
+
+	for uri, want := range map[string]string{
+		"openstat.us":             "missing port, as host:443",
+		"::1:443":                 "IPv6 literals must be bracketed and followed by a port, as [::1]:443",
+		"::1":                     "IPv6 literals must be bracketed and followed by a port, as [::1]:443",
+		"[::1]":                   "IPv6 literals must be bracketed and followed by a port, as [::1]:443",
+		"[::zz]:443":              `invalid IPv6 literal "::zz"`,
+		":443":                    "missing host",
+		"openstat.us:99999":       `port must be between 1 and 65535, not "99999"`,
+		"openstat.us:0":           `port must be between 1 and 65535, not "0"`,
+		"https://openstat.us/api": "must not have credentials, a path, a query or a fragment",
+	} {
+		_, err := request.HostPort(uri)
+		assert.EqualError(t, err, want, uri)
+	}
+
+	_, err := request.HostPort("tcp://openstat.us:443")
+	assert.ErrorContains(t, err, `unsupported scheme "tcp"`)
+}
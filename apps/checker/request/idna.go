@@ -0,0 +1,52 @@
+package request
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// ASCIIHost returns host, an internationalized domain name, in the ASCII
+// form it is resolved with, e.g. xn--bcher-kva.example for bücher.example.
+// ASCII hosts, IP literals included, are returned as they are.
+func ASCIIHost(host string) (string, error) {
+	if isASCII(host) {
+		return host, nil
+	}
+
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return "", fmt.Errorf("invalid internationalized domain name %q: %w", host, err)
+	}
+
+	return ascii, nil
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// TargetHost returns the host of the target of a check: a URL, the URI of a
+// TCP check or a hostname.
+func TargetHost(target string) string {
+	if strings.Contains(target, "://") {
+		if u, err := url.Parse(target); err == nil {
+			return u.Hostname()
+		}
+		return target
+	}
+	if host, _, err := net.SplitHostPort(target); err == nil {
+		return host
+	}
+
+	return target
+}
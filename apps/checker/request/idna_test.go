@@ -0,0 +1,34 @@
+package request_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestASCIIHost(t *testing.T) {
+	for host, want := range map[string]string{
+		"bücher.example": "xn--bcher-kva.example",
+		"münchen.de":     "xn--mnchen-3ya.de",
+		"openstat.us":    "openstat.us",
+		"my_host.local":  "my_host.local",
+		"::1":            "::1",
+	} {
+		got, err := request.ASCIIHost(host)
+		require.NoError(t, err, host)
+		assert.Equal(t, want, got, host)
+	}
+
+	_, err := request.ASCIIHost("bü cher.example")
+	assert.Error(t, err)
+
+	address, err := request.HostPort("bücher.example:443")
+	require.NoError(t, err)
+	assert.Equal(t, "xn--bcher-kva.example:443", address)
+
+	assert.NoError(t, request.DNSCheckerRequest{URI: "bücher.example"}.Validate())
+	assert.NoError(t, request.HttpCheckerRequest{URL: "https://bücher.example/", Method: "GET"}.Validate())
+}
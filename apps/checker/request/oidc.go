@@ -0,0 +1,58 @@
+package request
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// The bounds of an OIDC check.
+const (
+	MaxOIDCKeyIDs       = 20
+	MaxOIDCValidityDays = 365
+)
+
+// OIDCCheckerRequest checks the OpenID Connect identity provider at URL, its
+// issuer: the structure of its discovery document and the keys of its JWKS,
+// failing when a field or a key is missing or broken, or when the
+// certificate of a key expires.
+type OIDCCheckerRequest struct {
+	Scheduled
+	URL string `json:"url"`
+	// Timeout bounds each request of the check.
+	Timeout int64 `json:"timeout"`
+	// KeyIDs are the kid of the keys the JWKS must hold, e.g. the ones
+	// pinned by a client.
+	KeyIDs []string `json:"keyIds,omitempty"`
+	// MinValidityDays is how many days the certificates of the keys must
+	// remain valid, until they expire by default.
+	MinValidityDays int `json:"minValidityDays,omitempty"`
+}
+
+// Validate reports every invalid field of a scheduled OIDC check.
+func (r OIDCCheckerRequest) Validate() error {
+	var v ValidationError
+
+	v.id("workspaceId", r.WorkspaceID, true)
+	v.id("monitorId", r.MonitorID, true)
+	v.httpURL("url", r.URL)
+	if u, err := url.Parse(r.URL); err == nil && (u.RawQuery != "" || u.Fragment != "") {
+		v.add("url", "must be the issuer, without query nor fragment", r.URL)
+	}
+	v.status(r.Status)
+	v.timeout("oidc", r.Timeout)
+	if len(r.KeyIDs) > MaxOIDCKeyIDs {
+		v.add("keyIds", fmt.Sprintf("must list at most %d key ids", MaxOIDCKeyIDs), len(r.KeyIDs))
+	}
+	for i, kid := range r.KeyIDs {
+		if kid == "" {
+			v.add(fmt.Sprintf("keyIds[%d]", i), "is required", nil)
+		}
+	}
+	if r.MinValidityDays < 0 || r.MinValidityDays > MaxOIDCValidityDays {
+		v.add("minValidityDays", fmt.Sprintf("must be between 0 and %d", MaxOIDCValidityDays), r.MinValidityDays)
+	}
+	v.labels(r.Labels)
+	v.callbackURL(r.CallbackURL)
+
+	return v.err()
+}
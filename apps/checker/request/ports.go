@@ -0,0 +1,116 @@
+package request
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// MaxPorts caps the ports of a ports check, its ranges expanded.
+const MaxPorts = 100
+
+// PortsCheckerRequest connects to the ports of the host URI in one check,
+// failing when a port of Open is closed, e.g. 443 and 8443 both expected up,
+// or a port of Closed is open, e.g. a database unexpectedly exposed. The
+// ports are written alone, 443, or as ranges, 8000-8010.
+type PortsCheckerRequest struct {
+	Scheduled
+	URI string `json:"uri"`
+	// Timeout is the one of the connection to each port.
+	Timeout int64    `json:"timeout"`
+	Open    []string `json:"open,omitempty"`
+	Closed  []string `json:"closed,omitempty"`
+}
+
+// Ports returns the ports expected open and the ones expected closed, their
+// ranges expanded, as validated.
+func (r PortsCheckerRequest) Ports() ([]int, []int) {
+	var open, closed []int
+	for _, spec := range r.Open {
+		lo, hi, _ := ParsePortRange(spec)
+		for port := lo; port <= hi; port++ {
+			open = append(open, port)
+		}
+	}
+	for _, spec := range r.Closed {
+		lo, hi, _ := ParsePortRange(spec)
+		for port := lo; port <= hi; port++ {
+			closed = append(closed, port)
+		}
+	}
+
+	return open, closed
+}
+
+// ParsePortRange parses a port, 443, or a range of ports, 8000-8010, into
+// its first and last ports.
+func ParsePortRange(spec string) (int, int, error) {
+	first, last, isRange := strings.Cut(spec, "-")
+	if !isRange {
+		last = first
+	}
+	lo, err := strconv.Atoi(first)
+	if err != nil || lo < 1 || lo > 65535 {
+		return 0, 0, fmt.Errorf("invalid port %q", first)
+	}
+	hi, err := strconv.Atoi(last)
+	if err != nil || hi < 1 || hi > 65535 {
+		return 0, 0, fmt.Errorf("invalid port %q", last)
+	}
+	if hi < lo {
+		return 0, 0, fmt.Errorf("range %s ends before it starts", spec)
+	}
+
+	return lo, hi, nil
+}
+
+// Validate reports every invalid field of a scheduled ports check.
+func (r PortsCheckerRequest) Validate() error {
+	var v ValidationError
+
+	v.id("workspaceId", r.WorkspaceID, true)
+	v.id("monitorId", r.MonitorID, true)
+	v.hostname("uri", r.URI, true)
+	if _, _, err := net.SplitHostPort(r.URI); err == nil {
+		v.add("uri", "must be a host without port, the ports being open and closed", r.URI)
+	}
+	v.status(r.Status)
+	v.timeout("ports", r.Timeout)
+
+	seen := map[int]string{}
+	for _, list := range []struct {
+		field string
+		specs []string
+	}{{"open", r.Open}, {"closed", r.Closed}} {
+		for i, spec := range list.specs {
+			field := fmt.Sprintf("%s[%d]", list.field, i)
+			lo, hi, err := ParsePortRange(spec)
+			if err != nil {
+				v.add(field, err.Error(), spec)
+				continue
+			}
+			if hi-lo >= MaxPorts {
+				v.add(field, fmt.Sprintf("must span at most %d ports", MaxPorts), spec)
+				continue
+			}
+			for port := lo; port <= hi; port++ {
+				if other, ok := seen[port]; ok {
+					v.add(field, fmt.Sprintf("port %d is already listed in %s", port, other), spec)
+					break
+				}
+				seen[port] = field
+			}
+		}
+	}
+	switch {
+	case len(seen) == 0:
+		v.add("open", "open or closed is required", nil)
+	case len(seen) > MaxPorts:
+		v.add("open", fmt.Sprintf("must list at most %d ports with closed", MaxPorts), len(seen))
+	}
+	v.labels(r.Labels)
+	v.callbackURL(r.CallbackURL)
+
+	return v.err()
+}
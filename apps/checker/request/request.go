@@ -1,7 +1,11 @@
 package request
 
 import (
+	"crypto/tls"
 	"encoding/json"
+	"net/url"
+	"slices"
+	"strings"
 )
 
 type AssertionType string
@@ -12,6 +16,15 @@ const (
 	AssertionStatus    AssertionType = "status"
 	AssertionJsonBody  AssertionType = "jsonBody"
 	AssertionDnsRecord AssertionType = "dnsRecord"
+	// AssertionTLSVersion compares the negotiated TLS version, e.g. 1.2,
+	// with a number comparator.
+	AssertionTLSVersion AssertionType = "tlsVersion"
+	// AssertionCacheStatus compares the normalized CDN cache status, e.g.
+	// HIT, with a string comparator.
+	AssertionCacheStatus AssertionType = "cacheStatus"
+	// AssertionSecurityGrade compares the grade of the security headers,
+	// e.g. B, with a number comparator, better grades being greater.
+	AssertionSecurityGrade AssertionType = "securityGrade"
 )
 
 type StringComparator string
@@ -60,52 +73,398 @@ const (
 	RecordTXT   Record = "TXT"
 )
 
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseTLSVersion parses a TLS version written 1.2 or TLS 1.2, as in the
+// tlsVersion assertions and the results of the checks.
+func ParseTLSVersion(s string) (uint16, bool) {
+	v, ok := tlsVersions[strings.TrimPrefix(s, "TLS ")]
+
+	return v, ok
+}
+
+// SecurityGrades are the grades of the security headers of a response, worst
+// first.
+var SecurityGrades = []string{"F", "E", "D", "C", "B", "A"}
+
+// ParseSecurityGrade returns the rank of a grade in SecurityGrades.
+func ParseSecurityGrade(s string) (int, bool) {
+	i := slices.Index(SecurityGrades, strings.ToUpper(strings.TrimSpace(s)))
+
+	return i, i >= 0
+}
+
 type Assertion struct {
 	AssertionType AssertionType   `json:"type"`
 	Comparator    json.RawMessage `json:"compare"`
 	RawTarget     json.RawMessage `json:"target"`
 }
 
+// RetryPolicy tunes how a failing check is retried. Durations are in
+// milliseconds and zero values fall back to the checker defaults. RetryOn
+// lists the error classes worth retrying (dns, connection_refused, timeout,
+// tls, http_5xx, http_4xx, assertion), all of them when empty.
+type RetryPolicy struct {
+	Jitter          *bool    `json:"jitter,omitempty"`
+	RetryOn         []string `json:"retryOn,omitempty"`
+	Multiplier      float64  `json:"multiplier,omitempty"`
+	MaxAttempts     int64    `json:"maxAttempts,omitempty"`
+	InitialInterval int64    `json:"initialInterval,omitempty"`
+	MaxElapsedTime  int64    `json:"maxElapsedTime,omitempty"`
+}
+
+// Confirm re-runs a failed check right away before reporting its monitor
+// down, which it only does when at least Failures of the Checks runs fail,
+// the first one included. Failures defaults to Checks.
+type Confirm struct {
+	Checks   int `json:"checks"`
+	Failures int `json:"failures,omitempty"`
+}
+
+// Quorum has the checkers of Regions run a failed check again before its
+// monitor goes down, which only happens once Failures of the regions, this
+// one included, fail. Failures defaults to a majority.
+type Quorum struct {
+	Regions  []string `json:"regions"`
+	Failures int      `json:"failures,omitempty"`
+}
+
+// Required returns the failed regions needed for the monitor to go down.
+func (q Quorum) Required() int {
+	if q.Failures > 0 {
+		return q.Failures
+	}
+
+	return (len(q.Regions)+1)/2 + 1
+}
+
+// MaxQuorumRegions caps the peer regions of a quorum.
+const MaxQuorumRegions = 10
+
+// MaxConfirmChecks caps the runs of a confirmed check.
+const MaxConfirmChecks = 10
+
+// MaxDegradedWindow caps the checks averaged by a degraded window.
+const MaxDegradedWindow = 20
+
+// PhaseThresholds degrade a successful check as soon as one of its phases
+// takes longer than its threshold, in milliseconds, even when its total
+// latency stays under DegradedAfter. The phases are compared check by check,
+// without the window and recovery of DegradedAfter. Unset ones are not
+// compared.
+type PhaseThresholds struct {
+	DNS       int64 `json:"dnsMs,omitempty"`
+	Connect   int64 `json:"connectMs,omitempty"`
+	TLS       int64 `json:"tlsMs,omitempty"`
+	FirstByte int64 `json:"firstByteMs,omitempty"`
+	Total     int64 `json:"totalMs,omitempty"`
+}
+
+// BodyCapture keeps the start of the response body in the event of a failed
+// or degraded HTTP check, or of every check with Always. MaxBytes defaults to
+// 4 KB and cannot exceed 64 KB.
+type BodyCapture struct {
+	MaxBytes int  `json:"maxBytes,omitempty"`
+	Always   bool `json:"always,omitempty"`
+}
+
+// ClientCertificate is a PEM encoded certificate and private key presented to
+// targets requiring mutual TLS. It is only held in memory for the duration
+// of the check: the key is redacted whenever the request is encoded.
+type ClientCertificate struct {
+	Certificate string `json:"certificate"`
+	PrivateKey  string `json:"privateKey"`
+}
+
+func (c ClientCertificate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Certificate string `json:"certificate"`
+		PrivateKey  string `json:"privateKey"`
+	}{Certificate: c.Certificate, PrivateKey: "[REDACTED]"})
+}
+
+func (c ClientCertificate) String() string {
+	return "ClientCertificate{[REDACTED]}"
+}
+
+// TLSConfig returns the TLS configuration presenting the certificate, nil
+// when there is none.
+func (c *ClientCertificate) TLSConfig() (*tls.Config, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	cert, err := tls.X509KeyPair([]byte(c.Certificate), []byte(c.PrivateKey))
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+}
+
+// Proxy routes an HTTP check through an HTTP(S) or SOCKS5 proxy. The
+// password is redacted whenever the request is encoded.
+type Proxy struct {
+	URL      string `json:"url"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+func (p Proxy) MarshalJSON() ([]byte, error) {
+	type proxy Proxy
+	if p.Password != "" {
+		p.Password = "[REDACTED]"
+	}
+
+	return json.Marshal(proxy(p))
+}
+
+// ProxyURL returns the URL of the proxy with its credentials, nil when there
+// is none.
+func (p *Proxy) ProxyURL() (*url.URL, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return nil, err
+	}
+	if p.Username != "" {
+		u.User = url.UserPassword(p.Username, p.Password)
+	}
+
+	return u, nil
+}
+
+// AuthOAuth2ClientCredentials is the only Auth type so far.
+const AuthOAuth2ClientCredentials = "oauth2_client_credentials"
+
+// Auth authenticates the requests of an HTTP check with a token the checker
+// fetches from TokenURL with the OAuth2 client credentials grant, and caches
+// until it expires. The secret is redacted whenever the request is encoded.
+type Auth struct {
+	Type         string   `json:"type"`
+	TokenURL     string   `json:"tokenUrl"`
+	ClientID     string   `json:"clientId"`
+	ClientSecret string   `json:"clientSecret"`
+	Scopes       []string `json:"scopes,omitempty"`
+}
+
+func (a Auth) MarshalJSON() ([]byte, error) {
+	type auth Auth
+	if a.ClientSecret != "" {
+		a.ClientSecret = "[REDACTED]"
+	}
+
+	return json.Marshal(auth(a))
+}
+
+// The IP families a check can be restricted to.
+const (
+	IPFamilyAny  = "any"
+	IPFamilyIPv4 = "ipv4"
+	IPFamilyIPv6 = "ipv6"
+)
+
+// The Revocation modes of a TLS check: warn records the OCSP status of the
+// certificate, fail also fails the check on a revoked certificate or on a
+// must-staple one without stapled response.
+const (
+	RevocationWarn = "warn"
+	RevocationFail = "fail"
+)
+
 type HttpCheckerRequest struct {
 	Headers []struct {
 		Key   string `json:"key"`
 		Value string `json:"value"`
 	} `json:"headers,omitempty"`
-	WorkspaceID     string            `json:"workspaceId"`
-	URL             string            `json:"url"`
-	MonitorID       string            `json:"monitorId"`
-	Method          string            `json:"method"`
-	Status          string            `json:"status"`
-	Body            string            `json:"body"`
-	Trigger         string            `json:"trigger,omitempty"`
-	RawAssertions   []json.RawMessage `json:"assertions,omitempty"`
-	CronTimestamp   int64             `json:"cronTimestamp"`
-	Timeout         int64             `json:"timeout"`
-	DegradedAfter   int64             `json:"degradedAfter,omitempty"`
-	Retry           int64             `json:"retry,omitempty"`
-	FollowRedirects bool              `json:"followRedirects,omitempty"`
-	OtelConfig      struct {
+	WorkspaceID   string             `json:"workspaceId"`
+	URL           string             `json:"url"`
+	MonitorID     string             `json:"monitorId"`
+	Method        string             `json:"method"`
+	Status        string             `json:"status"`
+	Body          string             `json:"body"`
+	Trigger       string             `json:"trigger,omitempty"`
+	RawAssertions []json.RawMessage  `json:"assertions,omitempty"`
+	CronTimestamp int64              `json:"cronTimestamp"`
+	ScheduledAt   int64              `json:"scheduledAt,omitempty"`
+	Timeout       int64              `json:"timeout"`
+	TotalDeadline int64              `json:"totalDeadline,omitempty"`
+	DegradedAfter int64              `json:"degradedAfter,omitempty"`
+	Retry         int64              `json:"retry,omitempty"`
+	RetryPolicy   *RetryPolicy       `json:"retryPolicy,omitempty"`
+	ClientCert    *ClientCertificate `json:"clientCertificate,omitempty"`
+	Proxy         *Proxy             `json:"proxy,omitempty"`
+	IPFamily      string             `json:"ipFamily,omitempty"`
+	// ConnectTo is the host[:port] dialed instead of the host of the URL, to
+	// probe an origin directly while presenting the production hostname.
+	ConnectTo string `json:"connectTo,omitempty"`
+	// ServerName overrides the SNI, the host of the URL by default.
+	ServerName      string `json:"serverName,omitempty"`
+	FollowRedirects bool   `json:"followRedirects,omitempty"`
+	// MaxRedirects caps the redirects followed, 10 when unset.
+	MaxRedirects int          `json:"maxRedirects,omitempty"`
+	CaptureBody  *BodyCapture `json:"captureBody,omitempty"`
+	// CaptureHeaders names the response headers kept with the result, e.g.
+	// x-request-id or cf-ray, to find the request in the logs of the origin.
+	CaptureHeaders []string `json:"captureHeaders,omitempty"`
+	Auth           *Auth    `json:"auth,omitempty"`
+	// Cookies are sent with the first request, the cookies set by the
+	// responses are kept across redirects.
+	Cookies map[string]string `json:"cookies,omitempty"`
+	// AllAddresses checks every A/AAAA record of the host on top of the URL.
+	AllAddresses bool `json:"allAddresses,omitempty"`
+	// DualStack races connections to the host over IPv4 and IPv6 on top of
+	// the check, failing it when the family of an address cannot connect.
+	DualStack bool `json:"dualStack,omitempty"`
+	// Conditional replays the request with the ETag and Last-Modified of
+	// the response, failing the check unless it is answered 304.
+	Conditional bool `json:"conditional,omitempty"`
+	// DependsOn lists the parent monitors, a check failing while one of them
+	// is down in the same tick is skipped instead of failed.
+	DependsOn []string `json:"dependsOn,omitempty"`
+	Confirm   *Confirm `json:"confirm,omitempty"`
+	Quorum    *Quorum  `json:"quorum,omitempty"`
+	// RecoverBelow is the latency a degraded monitor must go back under to
+	// be active again, DegradedAfter by default.
+	RecoverBelow int64 `json:"recoverBelow,omitempty"`
+	// DegradedWindow averages the latency of the last checks of the monitor
+	// before comparing it with DegradedAfter and RecoverBelow.
+	DegradedWindow int `json:"degradedWindow,omitempty"`
+	// DegradedPhases are thresholds on the phases of the check.
+	DegradedPhases *PhaseThresholds `json:"degradedPhases,omitempty"`
+	// Labels are stored with the events of the check and attached to its
+	// metrics, e.g. team or environment, to segment the results.
+	Labels map[string]string `json:"labels,omitempty"`
+	// CallbackURL is posted the result of the check once it completed, its
+	// retries included.
+	CallbackURL string `json:"callbackUrl,omitempty"`
+	OtelConfig  struct {
 		Endpoint string            `json:"endpoint"`
 		Headers  map[string]string `json:"headers,omitempty"`
 	} `json:"otelConfig"`
+	// Revocation checks the OCSP status of the certificate of the target,
+	// one of the Revocation modes, not at all when empty.
+	Revocation string `json:"revocation,omitempty"`
+	// SecurityHeaders grades the security headers of the response, HSTS,
+	// CSP and the like, which the securityGrade assertions do anyway.
+	SecurityHeaders bool `json:"securityHeaders,omitempty"`
+	// MaxBodyBytes caps the bytes of the body read, DefaultMaxBodyBytes when
+	// unset, the rest being left unread.
+	MaxBodyBytes int64 `json:"maxBodyBytes,omitempty"`
+}
+
+// The bounds of the bytes read from the body of a response, so a monitor
+// pointed at a large download cannot exhaust the memory of the checker.
+const (
+	DefaultMaxBodyBytes = 10 << 20
+	MaxBodyBytesLimit   = 100 << 20
+)
+
+// BodyLimit returns the bytes of the body read at most for maxBodyBytes,
+// DefaultMaxBodyBytes when it is unset.
+func BodyLimit(maxBodyBytes int64) int64 {
+	if maxBodyBytes <= 0 {
+		return DefaultMaxBodyBytes
+	}
+
+	return min(maxBodyBytes, MaxBodyBytesLimit)
 }
 
 type TCPCheckerRequest struct {
-	Status        string            `json:"status"`
-	WorkspaceID   string            `json:"workspaceId"`
-	URI           string            `json:"uri"`
-	MonitorID     string            `json:"monitorId"`
-	Trigger       string            `json:"trigger,omitempty"`
-	RawAssertions []json.RawMessage `json:"assertions,omitempty"`
-	RequestId     int64             `json:"requestId,omitempty"`
-	CronTimestamp int64             `json:"cronTimestamp"`
-	Timeout       int64             `json:"timeout"`
-	DegradedAfter int64             `json:"degradedAfter,omitempty"`
-	Retry         int64             `json:"retry,omitempty"`
-	OtelConfig    struct {
+	Status        string             `json:"status"`
+	WorkspaceID   string             `json:"workspaceId"`
+	URI           string             `json:"uri"`
+	MonitorID     string             `json:"monitorId"`
+	Trigger       string             `json:"trigger,omitempty"`
+	RawAssertions []json.RawMessage  `json:"assertions,omitempty"`
+	RequestId     int64              `json:"requestId,omitempty"`
+	CronTimestamp int64              `json:"cronTimestamp"`
+	ScheduledAt   int64              `json:"scheduledAt,omitempty"`
+	Timeout       int64              `json:"timeout"`
+	TotalDeadline int64              `json:"totalDeadline,omitempty"`
+	DegradedAfter int64              `json:"degradedAfter,omitempty"`
+	Retry         int64              `json:"retry,omitempty"`
+	RetryPolicy   *RetryPolicy       `json:"retryPolicy,omitempty"`
+	ClientCert    *ClientCertificate `json:"clientCertificate,omitempty"`
+	IPFamily      string             `json:"ipFamily,omitempty"`
+	// TLS performs a TLS handshake once connected, presenting ServerName or
+	// the host of the URI.
+	TLS        bool   `json:"tls,omitempty"`
+	ServerName string `json:"serverName,omitempty"`
+	// AllAddresses checks every A/AAAA record of the host on top of the URI.
+	AllAddresses bool `json:"allAddresses,omitempty"`
+	// DualStack is the one of HttpCheckerRequest.
+	DualStack bool     `json:"dualStack,omitempty"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+	Confirm   *Confirm `json:"confirm,omitempty"`
+	Quorum    *Quorum  `json:"quorum,omitempty"`
+	// RecoverBelow and DegradedWindow are the ones of HttpCheckerRequest.
+	RecoverBelow   int64 `json:"recoverBelow,omitempty"`
+	DegradedWindow int   `json:"degradedWindow,omitempty"`
+	// DegradedPhases are the ones of HttpCheckerRequest, a TCP check has no
+	// first byte.
+	DegradedPhases *PhaseThresholds `json:"degradedPhases,omitempty"`
+	// Labels are the ones of HttpCheckerRequest.
+	Labels map[string]string `json:"labels,omitempty"`
+	// CallbackURL is the one of HttpCheckerRequest.
+	CallbackURL string `json:"callbackUrl,omitempty"`
+	// CacheTTL is the one of PingRequest, for the on-demand checks.
+	CacheTTL   int64 `json:"cacheTtl,omitempty"`
+	OtelConfig struct {
 		Endpoint string            `json:"endpoint"`
 		Headers  map[string]string `json:"headers,omitempty"`
 	} `json:"otelConfig"`
+	// Revocation is the one of HttpCheckerRequest, for TLS checks.
+	Revocation string `json:"revocation,omitempty"`
+	// TLSScan enumerates the TLS versions and weak cipher suites the target
+	// accepts, for TLS checks, failing the check when a forbidden one is.
+	TLSScan *TLSScan `json:"tlsScan,omitempty"`
+	// Hold keeps the connection open and idle once established, reporting
+	// whether the target or an intermediary drops it.
+	Hold *Hold `json:"hold,omitempty"`
+}
+
+// MaxHold caps how long a TCP check holds its connection, in milliseconds.
+const MaxHold = 300000
+
+// Hold is how long a TCP check holds its connection, Duration milliseconds,
+// sending TCP keep-alive probes every KeepAlive milliseconds when set and
+// none otherwise. The check fails when the connection is dropped before
+// Duration, or, with ExpectDrop, when it is not, e.g. to verify the idle
+// timeout of a load balancer.
+type Hold struct {
+	Duration   int64 `json:"duration"`
+	KeepAlive  int64 `json:"keepAlive,omitempty"`
+	ExpectDrop bool  `json:"expectDrop,omitempty"`
+}
+
+// DefaultForbiddenTLSVersions are the TLS versions a TLS scan forbids by
+// default, deprecated by RFC 8996.
+var DefaultForbiddenTLSVersions = []string{"1.0", "1.1"}
+
+// TLSScan is what a TLS scan forbids: the TLS versions of ForbiddenVersions,
+// DefaultForbiddenTLSVersions when nil, and the weak cipher suites, RC4 and
+// 3DES, unless AllowWeakCiphers is set.
+type TLSScan struct {
+	ForbiddenVersions []string `json:"forbiddenVersions,omitempty"`
+	AllowWeakCiphers  bool     `json:"allowWeakCiphers,omitempty"`
+}
+
+// Forbidden returns the versions forbidden by s.
+func (s TLSScan) Forbidden() []string {
+	if s.ForbiddenVersions == nil {
+		return DefaultForbiddenTLSVersions
+	}
+
+	return s.ForbiddenVersions
 }
 
 type TCPRequest struct {
@@ -117,14 +476,57 @@ type TCPRequest struct {
 }
 
 type PingRequest struct {
-	Headers     map[string]string `json:"headers"`
-	URL         string            `json:"url"`
-	Method      string            `json:"method"`
-	Body        string            `json:"body"`
-	RequestId   int64             `json:"requestId"`
-	WorkspaceId int64             `json:"workspaceId"`
+	Headers     map[string]string  `json:"headers"`
+	URL         string             `json:"url"`
+	Method      string             `json:"method"`
+	Body        string             `json:"body"`
+	RetryPolicy *RetryPolicy       `json:"retryPolicy,omitempty"`
+	ClientCert  *ClientCertificate `json:"clientCertificate,omitempty"`
+	Proxy       *Proxy             `json:"proxy,omitempty"`
+	IPFamily    string             `json:"ipFamily,omitempty"`
+	ConnectTo   string             `json:"connectTo,omitempty"`
+	ServerName  string             `json:"serverName,omitempty"`
+	Cookies     map[string]string  `json:"cookies,omitempty"`
+	Auth        *Auth              `json:"auth,omitempty"`
+	RequestId   int64              `json:"requestId"`
+	WorkspaceId int64              `json:"workspaceId"`
+	// CacheTTL allows the check to be answered the result of an identical
+	// check of the last CacheTTL milliseconds, marked as cached, instead of
+	// checking the target again. 0 always runs the check.
+	CacheTTL int64 `json:"cacheTtl,omitempty"`
 }
 
+// MaxCacheTTL bounds the cacheTtl of the on-demand checks, in milliseconds.
+const MaxCacheTTL = 60_000
+
+// FanOutRequest runs the on-demand check of Request, a PingRequest,
+// TCPCheckerRequest or DNSCheckerRequest depending on Type, from each of
+// Regions.
+type FanOutRequest struct {
+	Type    string          `json:"type"`
+	Regions []string        `json:"regions"`
+	Request json.RawMessage `json:"request"`
+}
+
+// BatchRequest runs Checks, each the body of a /check request, as one
+// batch, e.g. the monitors of a cron tick.
+type BatchRequest struct {
+	Checks []json.RawMessage `json:"checks"`
+}
+
+// MaxBatchChecks caps the checks of a batch.
+const MaxBatchChecks = 5000
+
+// CheckRequest is the discriminator of the body of /check, the other fields
+// being the ones of the scheduled request of Type, e.g. an
+// HttpCheckerRequest for http.
+type CheckRequest struct {
+	Type string `json:"type"`
+}
+
+// MaxFanOutRegions caps the regions of a fan-out.
+const MaxFanOutRegions = 50
+
 type DNSCheckerRequest struct {
 	Status        string            `json:"status"`
 	WorkspaceID   string            `json:"workspaceId"`
@@ -134,11 +536,79 @@ type DNSCheckerRequest struct {
 	RawAssertions []json.RawMessage `json:"assertions,omitempty"`
 	RequestId     int64             `json:"requestId,omitempty"`
 	CronTimestamp int64             `json:"cronTimestamp"`
+	ScheduledAt   int64             `json:"scheduledAt,omitempty"`
 	Timeout       int64             `json:"timeout"`
+	TotalDeadline int64             `json:"totalDeadline,omitempty"`
 	DegradedAfter int64             `json:"degradedAfter,omitempty"`
 	Retry         int64             `json:"retry,omitempty"`
-	OtelConfig    struct {
+	RetryPolicy   *RetryPolicy      `json:"retryPolicy,omitempty"`
+	DependsOn     []string          `json:"dependsOn,omitempty"`
+	Confirm       *Confirm          `json:"confirm,omitempty"`
+	Quorum        *Quorum           `json:"quorum,omitempty"`
+	// RecoverBelow and DegradedWindow are the ones of HttpCheckerRequest.
+	RecoverBelow   int64 `json:"recoverBelow,omitempty"`
+	DegradedWindow int   `json:"degradedWindow,omitempty"`
+	// Labels are the ones of HttpCheckerRequest.
+	Labels map[string]string `json:"labels,omitempty"`
+	// CallbackURL is the one of HttpCheckerRequest.
+	CallbackURL string `json:"callbackUrl,omitempty"`
+	// CacheTTL is the one of PingRequest, for the on-demand checks.
+	CacheTTL   int64 `json:"cacheTtl,omitempty"`
+	OtelConfig struct {
 		Endpoint string            `json:"endpoint"`
 		Headers  map[string]string `json:"headers,omitempty"`
 	} `json:"otelConfig"`
 }
+
+// Scheduled holds the fields the scheduled checks of the types added after
+// HTTP, TCP and DNS share: the ids of the monitor, its last status, what
+// triggered the run and the time of its tick, with the Labels and the
+// CallbackURL of HttpCheckerRequest. The ids are required.
+type Scheduled struct {
+	WorkspaceID   string            `json:"workspaceId"`
+	MonitorID     string            `json:"monitorId"`
+	Status        string            `json:"status"`
+	Trigger       string            `json:"trigger,omitempty"`
+	CronTimestamp int64             `json:"cronTimestamp"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	CallbackURL   string            `json:"callbackUrl,omitempty"`
+}
+
+// ContentCheckerRequest watches the text of the elements of the page at URL
+// matching Selector, a CSS selector, for changes since the previous run.
+type ContentCheckerRequest struct {
+	Scheduled
+	Headers []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	} `json:"headers,omitempty"`
+	URL      string `json:"url"`
+	Selector string `json:"selector"`
+	Timeout  int64  `json:"timeout"`
+	// PreviousHash and PreviousContent are the hash and the text of the
+	// previous run, as stored by the control plane. Without them the text
+	// kept by this checker is compared, if any.
+	PreviousHash    string `json:"previousHash,omitempty"`
+	PreviousContent string `json:"previousContent,omitempty"`
+	// MaxBodyBytes is the one of HttpCheckerRequest.
+	MaxBodyBytes int64 `json:"maxBodyBytes,omitempty"`
+}
+
+// CrawlCheckerRequest checks the links of the page at URL, and of the pages of
+// its host it links to up to Depth levels, for broken ones.
+type CrawlCheckerRequest struct {
+	Scheduled
+	Headers []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	} `json:"headers,omitempty"`
+	URL string `json:"url"`
+	// Timeout bounds each request of the crawl.
+	Timeout int64 `json:"timeout"`
+	// Depth is how many levels of links are checked, 1 by default for the
+	// links of the page only, at most MaxCrawlDepth.
+	Depth int `json:"depth,omitempty"`
+	// MaxLinks caps the links checked, 100 by default and at most
+	// MaxCrawlLinks.
+	MaxLinks int `json:"maxLinks,omitempty"`
+}
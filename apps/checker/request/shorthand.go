@@ -0,0 +1,70 @@
+package request
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// shorthandOperators are the comparators of the assertion shorthand, the
+// two-character ones first so they win over their prefix.
+var shorthandOperators = []struct {
+	op, compare string
+}{
+	{"==", "eq"}, {"!=", "not_eq"}, {">=", "gte"}, {"<=", "lte"}, {"!~", "not_contains"},
+	{"=", "eq"}, {">", "gt"}, {"<", "lt"}, {"~", "contains"},
+}
+
+// ParseAssertion parses the shorthand of an assertion, <subject><op><target>,
+// e.g. status=200, header.content-type~json or record.A=1.2.3.4, into its
+// JSON form. The subject is status, body, header.<key>, tlsVersion,
+// cacheStatus, securityGrade or record.<type> for the DNS records, and the
+// operator one of = (or ==), !=, >, >=, <, <=, ~ (contains) and !~.
+func ParseAssertion(s string) (json.RawMessage, error) {
+	i := strings.IndexAny(s, "=!<>~")
+	if i <= 0 {
+		return nil, fmt.Errorf("invalid assertion %q, expected <subject><op><target> as status=200", s)
+	}
+	subject, rest := strings.TrimSpace(s[:i]), s[i:]
+
+	var compare, target string
+	for _, o := range shorthandOperators {
+		if strings.HasPrefix(rest, o.op) {
+			compare, target = o.compare, strings.TrimSpace(rest[len(o.op):])
+			break
+		}
+	}
+	if compare == "" {
+		return nil, fmt.Errorf("invalid operator in assertion %q", s)
+	}
+
+	assertion := map[string]any{"compare": compare, "target": target}
+	switch key, hasKey := cutSubject(subject); {
+	case subject == "status":
+		status, err := strconv.ParseInt(target, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status in assertion %q", s)
+		}
+		assertion["type"], assertion["target"] = AssertionStatus, status
+	case subject == "body":
+		assertion["type"] = AssertionTextBody
+	case hasKey && strings.HasPrefix(subject, "header."):
+		assertion["type"], assertion["key"] = AssertionHeader, key
+	case hasKey && strings.HasPrefix(subject, "record."):
+		assertion["type"], assertion["key"] = AssertionDnsRecord, strings.ToUpper(key)
+	case subject == string(AssertionTLSVersion), subject == string(AssertionCacheStatus), subject == string(AssertionSecurityGrade):
+		assertion["type"] = AssertionType(subject)
+	default:
+		return nil, fmt.Errorf("unknown subject %q in assertion %q", subject, s)
+	}
+
+	return json.Marshal(assertion)
+}
+
+// cutSubject returns the key of a header.<key> or record.<type> subject.
+func cutSubject(subject string) (string, bool) {
+	_, key, ok := strings.Cut(subject, ".")
+
+	return key, ok && key != ""
+}
@@ -0,0 +1,34 @@
+package request_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func TestParseAssertion(t *testing.T) {
+	for s, want := range map[string]string{
+		"status=200":               `{"compare":"eq","target":200,"type":"status"}`,
+		"status < 400":             `{"compare":"lt","target":400,"type":"status"}`,
+		"body~ok":                  `{"compare":"contains","target":"ok","type":"textBody"}`,
+		"body!~error":              `{"compare":"not_contains","target":"error","type":"textBody"}`,
+		"header.content-type~json": `{"compare":"contains","key":"content-type","target":"json","type":"header"}`,
+		"record.a==1.2.3.4":        `{"compare":"eq","key":"A","target":"1.2.3.4","type":"dnsRecord"}`,
+		"tlsVersion>=1.2":          `{"compare":"gte","target":"1.2","type":"tlsVersion"}`,
+		"securityGrade>=B":         `{"compare":"gte","target":"B","type":"securityGrade"}`,
+		"cacheStatus!=MISS":        `{"compare":"not_eq","target":"MISS","type":"cacheStatus"}`,
+		"header.x-request-id!=":    `{"compare":"not_eq","key":"x-request-id","target":"","type":"header"}`,
+	} {
+		got, err := request.ParseAssertion(s)
+		require.NoError(t, err, s)
+		assert.JSONEq(t, want, string(got), s)
+	}
+
+	for _, s := range []string{"status", "=200", "status=ok", "latency<500", "header.=json", "status!200"} {
+		_, err := request.ParseAssertion(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -0,0 +1,85 @@
+package request
+
+import (
+	"encoding/json"
+	"net/mail"
+)
+
+// The TLS modes of an SMTP check.
+const (
+	SMTPStartTLS = "starttls"
+	SMTPImplicit = "implicit"
+	SMTPNone     = "none"
+)
+
+// SMTPCheckerRequest sends a message through the SMTP server at URI and
+// waits for it in the mailbox of the checker, within Timeout: the server may
+// accept the message and still not deliver it.
+type SMTPCheckerRequest struct {
+	Scheduled
+	URI string `json:"uri"`
+	// Timeout bounds the round trip, from the connection to the server to
+	// the delivery of the message.
+	Timeout int64 `json:"timeout"`
+	// DegradedAfter is the round trip above which the monitor is degraded.
+	DegradedAfter int64 `json:"degradedAfter,omitempty"`
+	// From is the sender of the message, e.g. monitoring@example.com.
+	From string `json:"from"`
+	// Username and Password authenticate to the server with PLAIN, when
+	// set. The password is redacted whenever the request is encoded.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// TLS is starttls by default, implicit for the servers speaking TLS from
+	// the connection, e.g. on port 465, or none.
+	TLS string `json:"tls,omitempty"`
+}
+
+func (r SMTPCheckerRequest) MarshalJSON() ([]byte, error) {
+	type smtpRequest SMTPCheckerRequest
+	if r.Password != "" {
+		r.Password = "[REDACTED]"
+	}
+
+	return json.Marshal(smtpRequest(r))
+}
+
+// Address returns the host:port of the URI of the check, as validated.
+func (r SMTPCheckerRequest) Address() string {
+	address, err := HostPort(r.URI)
+	if err != nil {
+		return r.URI
+	}
+
+	return address
+}
+
+// Validate reports every invalid field of a scheduled SMTP check.
+func (r SMTPCheckerRequest) Validate() error {
+	var v ValidationError
+
+	v.id("workspaceId", r.WorkspaceID, true)
+	v.id("monitorId", r.MonitorID, true)
+	v.hostPort("uri", r.URI)
+	v.status(r.Status)
+	v.timeout("smtp", r.Timeout)
+	if r.DegradedAfter < 0 {
+		v.add("degradedAfter", "must not be negative", r.DegradedAfter)
+	}
+	if r.From == "" {
+		v.add("from", "is required", nil)
+	} else if address, err := mail.ParseAddress(r.From); err != nil || address.Name != "" {
+		v.add("from", "must be an email address", r.From)
+	}
+	if r.Password != "" && r.Username == "" {
+		v.add("username", "is required with password", nil)
+	}
+	switch r.TLS {
+	case "", SMTPStartTLS, SMTPImplicit, SMTPNone:
+	default:
+		v.add("tls", "must be one of starttls, implicit or none", r.TLS)
+	}
+	v.labels(r.Labels)
+	v.callbackURL(r.CallbackURL)
+
+	return v.err()
+}
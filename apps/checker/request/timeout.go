@@ -0,0 +1,48 @@
+package request
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeoutBounds are the default and the bounds of the timeout of a check
+// type, in milliseconds.
+type TimeoutBounds struct {
+	Default  int64
+	Min, Max int64
+}
+
+// Timeouts holds the TimeoutBounds of each check type. The timeout of every
+// check is in milliseconds, TCP checks included.
+var Timeouts = map[string]TimeoutBounds{
+	"http":     {Default: 45000, Min: 100, Max: 120000},
+	"content":  {Default: 45000, Min: 100, Max: 120000},
+	"crawl":    {Default: 10000, Min: 100, Max: 60000},
+	"tcp":      {Default: 10000, Min: 100, Max: 120000},
+	"dns":      {Default: 5000, Min: 100, Max: 60000},
+	"grpc":     {Default: 10000, Min: 100, Max: 60000},
+	"smtp":     {Default: 60000, Min: 1000, Max: 300000},
+	"email":    {Default: 5000, Min: 100, Max: 60000},
+	"ports":    {Default: 5000, Min: 100, Max: 30000},
+	"oidc":     {Default: 10000, Min: 100, Max: 60000},
+	"download": {Default: 60000, Min: 1000, Max: 300000},
+}
+
+// Timeout returns the timeout of a check of checkType for value, the default
+// of the type when unset, clamped to its bounds otherwise: the checks run by
+// the private locations are not validated.
+func Timeout(checkType string, value int64) time.Duration {
+	b := Timeouts[checkType]
+	if value <= 0 {
+		value = b.Default
+	}
+
+	return time.Duration(min(max(value, b.Min), b.Max)) * time.Millisecond
+}
+
+func (v *ValidationError) timeout(checkType string, value int64) {
+	b := Timeouts[checkType]
+	if value != 0 && (value < b.Min || value > b.Max) {
+		v.add("timeout", fmt.Sprintf("must be between %d and %d ms, %d ms when unset", b.Min, b.Max, b.Default), value)
+	}
+}
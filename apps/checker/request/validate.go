@@ -0,0 +1,752 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/openstatushq/openstatus/apps/checker/pkg/content"
+)
+
+// FieldError describes why a field of a check request was rejected. Field is
+// the JSON path of the field, empty when the body itself is malformed.
+type FieldError struct {
+	Value  any    `json:"value,omitempty"`
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// ValidationError lists every rejected field of a check request.
+type ValidationError []FieldError
+
+func (v ValidationError) Error() string {
+	messages := make([]string, 0, len(v))
+	for _, f := range v {
+		if f.Field == "" {
+			messages = append(messages, f.Reason)
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("%s: %s", f.Field, f.Reason))
+	}
+
+	return "invalid request: " + strings.Join(messages, "; ")
+}
+
+func (v *ValidationError) add(field, reason string, value any) {
+	*v = append(*v, FieldError{Field: field, Reason: reason, Value: value})
+}
+
+func (v ValidationError) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+
+	return v
+}
+
+// DecodeError turns an error of the JSON decoder into a ValidationError
+// pointing at the offending field when the decoder knows it.
+func DecodeError(err error) ValidationError {
+	var (
+		typeErr   *json.UnmarshalTypeError
+		syntaxErr *json.SyntaxError
+	)
+
+	switch {
+	case errors.As(err, &typeErr):
+		return ValidationError{{
+			Field:  typeErr.Field,
+			Reason: fmt.Sprintf("expected %s", typeErr.Type),
+			Value:  typeErr.Value,
+		}}
+	case errors.As(err, &syntaxErr):
+		return ValidationError{{Reason: fmt.Sprintf("malformed JSON at offset %d: %s", syntaxErr.Offset, syntaxErr)}}
+	default:
+		return ValidationError{{Reason: err.Error()}}
+	}
+}
+
+var statuses = map[string]bool{"": true, "active": true, "error": true, "degraded": true}
+
+var methods = map[string]bool{
+	"": true, "GET": true, "HEAD": true, "POST": true, "PUT": true,
+	"PATCH": true, "DELETE": true, "OPTIONS": true,
+}
+
+var assertionTypes = map[AssertionType]bool{
+	AssertionHeader: true, AssertionTextBody: true, AssertionStatus: true,
+	AssertionJsonBody: true, AssertionDnsRecord: true, AssertionTLSVersion: true,
+	AssertionCacheStatus: true, AssertionSecurityGrade: true,
+}
+
+// Validate reports every invalid field of a scheduled HTTP check. The ids are
+// optional for HTTP checks but must be numeric when set.
+func (r HttpCheckerRequest) Validate() error {
+	var v ValidationError
+
+	v.id("workspaceId", r.WorkspaceID, false)
+	v.id("monitorId", r.MonitorID, false)
+	v.httpURL("url", r.URL)
+	v.method("method", r.Method)
+	v.status(r.Status)
+	v.timeout("http", r.Timeout)
+	v.durations(r.TotalDeadline, r.DegradedAfter, r.Retry)
+	v.degraded(r.DegradedAfter, r.RecoverBelow, r.DegradedWindow)
+	v.degradedPhases(r.DegradedPhases, true)
+	v.retryPolicy(r.RetryPolicy)
+	v.assertions(r.RawAssertions)
+	v.clientCertificate(r.ClientCert)
+	v.proxy(r.Proxy)
+	v.ipFamily(r.IPFamily)
+	v.connectTo(r.ConnectTo, r.ServerName, r.Proxy, r.AllAddresses)
+	if r.MaxRedirects < 0 {
+		v.add("maxRedirects", "must not be negative", r.MaxRedirects)
+	}
+	v.cookies(r.Cookies)
+	v.auth(r.Auth)
+	v.captureHeaders(r.CaptureHeaders)
+	v.dependsOn(r.MonitorID, r.DependsOn)
+	v.confirm(r.Confirm)
+	v.quorum(r.Quorum)
+	v.labels(r.Labels)
+	v.callbackURL(r.CallbackURL)
+	v.revocation(r.Revocation)
+	v.maxBodyBytes(r.MaxBodyBytes)
+	if r.CaptureBody != nil && (r.CaptureBody.MaxBytes < 0 || r.CaptureBody.MaxBytes > 64<<10) {
+		v.add("captureBody.maxBytes", "must be between 0 and 65536", r.CaptureBody.MaxBytes)
+	}
+	if r.AllAddresses && r.Proxy != nil {
+		v.add("allAddresses", "cannot be used with a proxy", nil)
+	}
+	if r.Conditional && !strings.EqualFold(r.Method, http.MethodGet) && !strings.EqualFold(r.Method, http.MethodHead) {
+		v.add("conditional", "requires the GET or HEAD method", r.Method)
+	}
+	v.dualStack(r.DualStack, r.IPFamily)
+	if r.DualStack && r.Proxy != nil {
+		v.add("dualStack", "cannot be used with a proxy", nil)
+	}
+
+	return v.err()
+}
+
+// Validate reports every invalid field of an on-demand TCP check, the ids
+// are optional but must be numeric when set.
+func (r TCPCheckerRequest) Validate() error {
+	return r.validate(false)
+}
+
+// ValidateScheduled is Validate for scheduled checks, which must carry the
+// ids of their monitor.
+func (r TCPCheckerRequest) ValidateScheduled() error {
+	return r.validate(true)
+}
+
+func (r TCPCheckerRequest) validate(requireIDs bool) error {
+	var v ValidationError
+
+	v.id("workspaceId", r.WorkspaceID, requireIDs)
+	v.id("monitorId", r.MonitorID, requireIDs)
+	v.hostPort("uri", r.URI)
+	v.status(r.Status)
+	v.timeout("tcp", r.Timeout)
+	v.durations(r.TotalDeadline, r.DegradedAfter, r.Retry)
+	v.degraded(r.DegradedAfter, r.RecoverBelow, r.DegradedWindow)
+	v.degradedPhases(r.DegradedPhases, false)
+	v.retryPolicy(r.RetryPolicy)
+	v.assertions(r.RawAssertions)
+	v.clientCertificate(r.ClientCert)
+	v.ipFamily(r.IPFamily)
+	if r.ClientCert != nil && !r.TLS {
+		v.add("clientCertificate", "requires tls", nil)
+	}
+	if r.ServerName != "" && !r.TLS {
+		v.add("serverName", "requires tls", r.ServerName)
+	}
+	v.hostname("serverName", r.ServerName, false)
+	v.revocation(r.Revocation)
+	if r.Revocation != "" && !r.TLS {
+		v.add("revocation", "requires tls", r.Revocation)
+	}
+	v.tlsScan(r.TLSScan, r.TLS)
+	v.dualStack(r.DualStack, r.IPFamily)
+	v.hold(r.Hold, r.TotalDeadline)
+	v.dependsOn(r.MonitorID, r.DependsOn)
+	v.confirm(r.Confirm)
+	v.quorum(r.Quorum)
+	v.labels(r.Labels)
+	v.callbackURL(r.CallbackURL)
+	v.cacheTTL(r.CacheTTL)
+
+	return v.err()
+}
+
+// Validate reports every invalid field of an on-demand DNS check, the ids
+// are optional but must be numeric when set.
+func (r DNSCheckerRequest) Validate() error {
+	return r.validate(false)
+}
+
+// ValidateScheduled is Validate for scheduled checks, which must carry the
+// ids of their monitor.
+func (r DNSCheckerRequest) ValidateScheduled() error {
+	return r.validate(true)
+}
+
+func (r DNSCheckerRequest) validate(requireIDs bool) error {
+	var v ValidationError
+
+	v.id("workspaceId", r.WorkspaceID, requireIDs)
+	v.id("monitorId", r.MonitorID, requireIDs)
+	v.hostname("uri", r.URI, true)
+	v.status(r.Status)
+	v.timeout("dns", r.Timeout)
+	v.durations(r.TotalDeadline, r.DegradedAfter, r.Retry)
+	v.degraded(r.DegradedAfter, r.RecoverBelow, r.DegradedWindow)
+	v.retryPolicy(r.RetryPolicy)
+	v.assertions(r.RawAssertions)
+	v.dependsOn(r.MonitorID, r.DependsOn)
+	v.confirm(r.Confirm)
+	v.quorum(r.Quorum)
+	v.labels(r.Labels)
+	v.callbackURL(r.CallbackURL)
+	v.cacheTTL(r.CacheTTL)
+
+	return v.err()
+}
+
+// Validate reports every invalid field of a content check.
+func (r ContentCheckerRequest) Validate() error {
+	var v ValidationError
+
+	v.id("workspaceId", r.WorkspaceID, true)
+	v.id("monitorId", r.MonitorID, true)
+	v.httpURL("url", r.URL)
+	if r.Selector == "" {
+		v.add("selector", "is required", nil)
+	} else if _, err := content.ParseSelector(r.Selector); err != nil {
+		v.add("selector", err.Error(), r.Selector)
+	}
+	v.status(r.Status)
+	v.timeout("content", r.Timeout)
+	v.labels(r.Labels)
+	v.callbackURL(r.CallbackURL)
+	v.maxBodyBytes(r.MaxBodyBytes)
+
+	return v.err()
+}
+
+// The bounds of a crawl check, so a single run stays within its region's
+// means.
+const (
+	MaxCrawlDepth = 3
+	MaxCrawlLinks = 1000
+)
+
+// Validate reports every invalid field of a crawl check.
+func (r CrawlCheckerRequest) Validate() error {
+	var v ValidationError
+
+	v.id("workspaceId", r.WorkspaceID, true)
+	v.id("monitorId", r.MonitorID, true)
+	v.httpURL("url", r.URL)
+	v.status(r.Status)
+	v.timeout("crawl", r.Timeout)
+	if r.Depth < 0 || r.Depth > MaxCrawlDepth {
+		v.add("depth", fmt.Sprintf("must be between 0 and %d", MaxCrawlDepth), r.Depth)
+	}
+	if r.MaxLinks < 0 || r.MaxLinks > MaxCrawlLinks {
+		v.add("maxLinks", fmt.Sprintf("must be between 0 and %d", MaxCrawlLinks), r.MaxLinks)
+	}
+	v.labels(r.Labels)
+	v.callbackURL(r.CallbackURL)
+
+	return v.err()
+}
+
+// Validate reports every invalid field of an on-demand HTTP check.
+func (r PingRequest) Validate() error {
+	var v ValidationError
+
+	v.httpURL("url", r.URL)
+	v.method("method", r.Method)
+	v.retryPolicy(r.RetryPolicy)
+	v.clientCertificate(r.ClientCert)
+	v.proxy(r.Proxy)
+	v.ipFamily(r.IPFamily)
+	v.connectTo(r.ConnectTo, r.ServerName, r.Proxy, false)
+	v.cookies(r.Cookies)
+	v.auth(r.Auth)
+	v.cacheTTL(r.CacheTTL)
+
+	return v.err()
+}
+
+// Validate reports whether the batch has between 1 and MaxBatchChecks
+// checks, each of them being validated by the handler of its type.
+func (r BatchRequest) Validate() error {
+	var v ValidationError
+
+	if len(r.Checks) == 0 || len(r.Checks) > MaxBatchChecks {
+		v.add("checks", fmt.Sprintf("must list between 1 and %d checks", MaxBatchChecks), len(r.Checks))
+	}
+
+	return v.err()
+}
+
+// Validate reports every invalid field of a fan-out, its request being
+// validated by the checker of each region.
+func (r FanOutRequest) Validate() error {
+	var v ValidationError
+
+	switch r.Type {
+	case "http", "tcp", "dns":
+	default:
+		v.add("type", "must be one of http, tcp, dns", r.Type)
+	}
+	if len(r.Regions) == 0 || len(r.Regions) > MaxFanOutRegions {
+		v.add("regions", fmt.Sprintf("must list between 1 and %d regions", MaxFanOutRegions), len(r.Regions))
+	}
+	for i, region := range r.Regions {
+		field := fmt.Sprintf("regions[%d]", i)
+		if region == "" {
+			v.add(field, "is required", nil)
+		} else if slices.Contains(r.Regions[:i], region) {
+			v.add(field, "is listed twice", region)
+		}
+	}
+	if len(r.Request) == 0 || string(r.Request) == "null" {
+		v.add("request", "is required", nil)
+	}
+
+	return v.err()
+}
+
+func (v *ValidationError) id(field, value string, required bool) {
+	if value == "" {
+		if required {
+			v.add(field, "is required", nil)
+		}
+		return
+	}
+
+	if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+		v.add(field, "must be numeric", value)
+	}
+}
+
+// callbackURL checks the optional callback of a check.
+func (v *ValidationError) callbackURL(value string) {
+	if value != "" {
+		v.httpURL("callbackUrl", value)
+	}
+}
+
+func (v *ValidationError) cacheTTL(ttl int64) {
+	if ttl < 0 || ttl > MaxCacheTTL {
+		v.add("cacheTtl", fmt.Sprintf("must be between 0 and %d", MaxCacheTTL), ttl)
+	}
+}
+
+func (v *ValidationError) httpURL(field, value string) {
+	if value == "" {
+		v.add(field, "is required", nil)
+		return
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		v.add(field, "is not a valid URL", value)
+		return
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		v.add(field, fmt.Sprintf("unsupported scheme %q, expected http or https", u.Scheme), value)
+		return
+	}
+	if u.Host == "" {
+		v.add(field, "is missing a host", value)
+	} else if _, err := ASCIIHost(u.Hostname()); err != nil {
+		v.add(field, err.Error(), value)
+	}
+}
+
+func (v *ValidationError) hostPort(field, value string) {
+	if value == "" {
+		v.add(field, "is required", nil)
+		return
+	}
+
+	if _, err := HostPort(value); err != nil {
+		v.add(field, err.Error(), value)
+	}
+}
+
+func (v *ValidationError) hostname(field, value string, required bool) {
+	if value == "" {
+		if required {
+			v.add(field, "is required", nil)
+		}
+		return
+	}
+
+	if strings.Contains(value, "://") || strings.ContainsAny(value, "/ ") {
+		v.add(field, "must be a hostname without scheme or path", value)
+	} else if _, err := ASCIIHost(value); err != nil {
+		v.add(field, err.Error(), value)
+	}
+}
+
+func (v *ValidationError) method(field, value string) {
+	if !methods[strings.ToUpper(value)] {
+		v.add(field, "unsupported HTTP method", value)
+	}
+}
+
+func (v *ValidationError) status(value string) {
+	if !statuses[value] {
+		v.add("status", "must be one of active, error or degraded", value)
+	}
+}
+
+func (v *ValidationError) durations(totalDeadline, degradedAfter, retry int64) {
+	for _, d := range []struct {
+		field string
+		value int64
+	}{
+		{"totalDeadline", totalDeadline},
+		{"degradedAfter", degradedAfter},
+		{"retry", retry},
+	} {
+		if d.value < 0 {
+			v.add(d.field, "must not be negative", d.value)
+		}
+	}
+}
+
+func (v *ValidationError) quorum(q *Quorum) {
+	if q == nil {
+		return
+	}
+
+	if len(q.Regions) == 0 || len(q.Regions) > MaxQuorumRegions {
+		v.add("quorum.regions", fmt.Sprintf("must list between 1 and %d regions", MaxQuorumRegions), len(q.Regions))
+	}
+	for i, region := range q.Regions {
+		field := fmt.Sprintf("quorum.regions[%d]", i)
+		if region == "" {
+			v.add(field, "is required", nil)
+		} else if slices.Contains(q.Regions[:i], region) {
+			v.add(field, "is listed twice", region)
+		}
+	}
+	if q.Failures < 0 || q.Failures > len(q.Regions)+1 {
+		v.add("quorum.failures", "must be between 1 and the number of regions, this one included", q.Failures)
+	}
+}
+
+func (v *ValidationError) degraded(degradedAfter, recoverBelow int64, window int) {
+	if recoverBelow < 0 || recoverBelow > degradedAfter {
+		v.add("recoverBelow", "must be between 0 and degradedAfter", recoverBelow)
+	}
+	if window < 0 || window > MaxDegradedWindow {
+		v.add("degradedWindow", fmt.Sprintf("must be between 0 and %d", MaxDegradedWindow), window)
+	}
+	if window > 1 && degradedAfter == 0 {
+		v.add("degradedWindow", "requires degradedAfter", window)
+	}
+}
+
+// degradedPhases rejects negative thresholds, and the first byte one of the
+// checks without a response.
+func (v *ValidationError) degradedPhases(p *PhaseThresholds, firstByte bool) {
+	if p == nil {
+		return
+	}
+
+	for _, t := range []struct {
+		field string
+		value int64
+	}{
+		{"degradedPhases.dnsMs", p.DNS},
+		{"degradedPhases.connectMs", p.Connect},
+		{"degradedPhases.tlsMs", p.TLS},
+		{"degradedPhases.firstByteMs", p.FirstByte},
+		{"degradedPhases.totalMs", p.Total},
+	} {
+		if t.value < 0 {
+			v.add(t.field, "must not be negative", t.value)
+		}
+	}
+	if p.FirstByte > 0 && !firstByte {
+		v.add("degradedPhases.firstByteMs", "the check has no first byte", p.FirstByte)
+	}
+}
+
+func (v *ValidationError) retryPolicy(p *RetryPolicy) {
+	if p == nil {
+		return
+	}
+
+	if p.MaxAttempts < 0 {
+		v.add("retryPolicy.maxAttempts", "must not be negative", p.MaxAttempts)
+	}
+	if p.InitialInterval < 0 {
+		v.add("retryPolicy.initialInterval", "must not be negative", p.InitialInterval)
+	}
+	if p.MaxElapsedTime < 0 {
+		v.add("retryPolicy.maxElapsedTime", "must not be negative", p.MaxElapsedTime)
+	}
+	if p.Multiplier != 0 && p.Multiplier < 1 {
+		v.add("retryPolicy.multiplier", "must be at least 1", p.Multiplier)
+	}
+}
+
+func (v *ValidationError) confirm(c *Confirm) {
+	if c == nil {
+		return
+	}
+
+	if c.Checks < 2 || c.Checks > MaxConfirmChecks {
+		v.add("confirm.checks", fmt.Sprintf("must be between 2 and %d", MaxConfirmChecks), c.Checks)
+	}
+	if c.Failures < 0 || c.Failures > c.Checks {
+		v.add("confirm.failures", "must be between 1 and checks", c.Failures)
+	}
+}
+
+func (v *ValidationError) assertions(raw []json.RawMessage) {
+	for i, a := range raw {
+		field := fmt.Sprintf("assertions[%d]", i)
+
+		var assertion Assertion
+		if err := json.Unmarshal(a, &assertion); err != nil {
+			v.add(field, "is not a valid assertion", string(a))
+			continue
+		}
+		if !assertionTypes[assertion.AssertionType] {
+			v.add(field+".type", "unknown assertion type", assertion.AssertionType)
+		}
+		if assertion.AssertionType == AssertionTLSVersion {
+			var target string
+			_ = json.Unmarshal(assertion.RawTarget, &target)
+			if _, ok := ParseTLSVersion(target); !ok {
+				v.add(field+".target", "must be one of 1.0, 1.1, 1.2 or 1.3", string(assertion.RawTarget))
+			}
+		}
+		if assertion.AssertionType == AssertionSecurityGrade {
+			var target string
+			_ = json.Unmarshal(assertion.RawTarget, &target)
+			if _, ok := ParseSecurityGrade(target); !ok {
+				v.add(field+".target", "must be one of A, B, C, D, E or F", string(assertion.RawTarget))
+			}
+		}
+	}
+}
+
+// clientCertificate never reports the value of the field, it holds a key.
+func (v *ValidationError) clientCertificate(c *ClientCertificate) {
+	if c == nil {
+		return
+	}
+
+	if _, err := c.TLSConfig(); err != nil {
+		v.add("clientCertificate", "must be a PEM encoded certificate and matching private key", nil)
+	}
+}
+
+var proxySchemes = map[string]bool{"http": true, "https": true, "socks5": true, "socks5h": true}
+
+func (v *ValidationError) proxy(p *Proxy) {
+	if p == nil {
+		return
+	}
+
+	u, err := p.ProxyURL()
+	switch {
+	case p.URL == "":
+		v.add("proxy.url", "is required", nil)
+	case err != nil:
+		v.add("proxy.url", "is not a valid URL", p.URL)
+	case !proxySchemes[u.Scheme]:
+		v.add("proxy.url", fmt.Sprintf("unsupported scheme %q, expected http, https, socks5 or socks5h", u.Scheme), p.URL)
+	case u.Host == "":
+		v.add("proxy.url", "is missing a host", p.URL)
+	}
+}
+
+var ipFamilies = map[string]bool{"": true, IPFamilyAny: true, IPFamilyIPv4: true, IPFamilyIPv6: true}
+
+func (v *ValidationError) ipFamily(value string) {
+	if !ipFamilies[value] {
+		v.add("ipFamily", "must be one of any, ipv4 or ipv6", value)
+	}
+}
+
+func (v *ValidationError) revocation(value string) {
+	if value != "" && value != RevocationWarn && value != RevocationFail {
+		v.add("revocation", "must be warn or fail", value)
+	}
+}
+
+func (v *ValidationError) dualStack(dualStack bool, family string) {
+	if dualStack && family != "" && family != IPFamilyAny {
+		v.add("dualStack", "requires the any ipFamily", family)
+	}
+}
+
+func (v *ValidationError) tlsScan(scan *TLSScan, tls bool) {
+	if scan == nil {
+		return
+	}
+	if !tls {
+		v.add("tlsScan", "requires tls", nil)
+	}
+	for i, version := range scan.ForbiddenVersions {
+		if _, ok := ParseTLSVersion(version); !ok {
+			v.add(fmt.Sprintf("tlsScan.forbiddenVersions[%d]", i), "must be one of 1.0, 1.1, 1.2 or 1.3", version)
+		}
+	}
+}
+
+func (v *ValidationError) hold(hold *Hold, totalDeadline int64) {
+	if hold == nil {
+		return
+	}
+	if hold.Duration < 1000 || hold.Duration > MaxHold {
+		v.add("hold.duration", fmt.Sprintf("must be between 1000 and %d", MaxHold), hold.Duration)
+	} else if totalDeadline > 0 && totalDeadline <= hold.Duration {
+		v.add("hold.duration", "must be shorter than totalDeadline", hold.Duration)
+	}
+	if hold.KeepAlive != 0 && (hold.KeepAlive < 1000 || hold.KeepAlive > MaxHold) {
+		v.add("hold.keepAlive", fmt.Sprintf("must be between 1000 and %d", MaxHold), hold.KeepAlive)
+	}
+}
+
+func (v *ValidationError) maxBodyBytes(value int64) {
+	if value < 0 || value > MaxBodyBytesLimit {
+		v.add("maxBodyBytes", fmt.Sprintf("must be between 0 and %d", MaxBodyBytesLimit), value)
+	}
+}
+
+func (v *ValidationError) connectTo(connectTo, serverName string, proxy *Proxy, allAddresses bool) {
+	v.hostname("serverName", serverName, false)
+	if connectTo == "" {
+		return
+	}
+
+	host := connectTo
+	if h, _, err := net.SplitHostPort(connectTo); err == nil {
+		host = h
+	}
+	v.hostname("connectTo", strings.Trim(host, "[]"), true)
+
+	if proxy != nil {
+		v.add("connectTo", "cannot be used with a proxy", connectTo)
+	}
+	if allAddresses {
+		v.add("connectTo", "cannot be used with allAddresses", connectTo)
+	}
+}
+
+func (v *ValidationError) cookies(cookies map[string]string) {
+	for _, name := range slices.Sorted(maps.Keys(cookies)) {
+		if err := (&http.Cookie{Name: name, Value: cookies[name]}).Valid(); err != nil {
+			v.add("cookies."+name, "is not a valid cookie", cookies[name])
+		}
+	}
+}
+
+// auth never reports the client secret.
+func (v *ValidationError) auth(a *Auth) {
+	if a == nil {
+		return
+	}
+
+	if a.Type != AuthOAuth2ClientCredentials {
+		v.add("auth.type", fmt.Sprintf("must be %s", AuthOAuth2ClientCredentials), a.Type)
+	}
+	v.httpURL("auth.tokenUrl", a.TokenURL)
+	if a.ClientID == "" {
+		v.add("auth.clientId", "is required", nil)
+	}
+	if a.ClientSecret == "" {
+		v.add("auth.clientSecret", "is required", nil)
+	}
+}
+
+// maxCaptureHeaders bounds the headers kept with each result.
+const maxCaptureHeaders = 20
+
+// maxLabels caps the labels of a check, each of them being a metric
+// attribute.
+const maxLabels = 20
+
+// The longest label names and values.
+const (
+	maxLabelName  = 63
+	maxLabelValue = 256
+)
+
+func (v *ValidationError) labels(labels map[string]string) {
+	if len(labels) > maxLabels {
+		v.add("labels", fmt.Sprintf("must not hold more than %d labels", maxLabels), len(labels))
+	}
+	for _, name := range slices.Sorted(maps.Keys(labels)) {
+		field := "labels." + name
+		if !validLabelName(name) {
+			v.add(field, fmt.Sprintf("name must be at most %d letters, digits, '_', '-' or '.', starting with a letter", maxLabelName), name)
+		}
+		if len(labels[name]) > maxLabelValue {
+			v.add(field, fmt.Sprintf("must be at most %d bytes", maxLabelValue), len(labels[name]))
+		}
+	}
+}
+
+func validLabelName(name string) bool {
+	if name == "" || len(name) > maxLabelName {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '_' || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// maxDependsOn caps the parents of a monitor.
+const maxDependsOn = 10
+
+func (v *ValidationError) dependsOn(monitorID string, parents []string) {
+	if len(parents) > maxDependsOn {
+		v.add("dependsOn", fmt.Sprintf("must not list more than %d monitors", maxDependsOn), len(parents))
+	}
+	for i, parent := range parents {
+		field := fmt.Sprintf("dependsOn[%d]", i)
+		v.id(field, parent, true)
+		if parent == monitorID {
+			v.add(field, "must not be the monitor itself", parent)
+		}
+	}
+}
+
+func (v *ValidationError) captureHeaders(names []string) {
+	if len(names) > maxCaptureHeaders {
+		v.add("captureHeaders", fmt.Sprintf("must not name more than %d headers", maxCaptureHeaders), len(names))
+	}
+	for i, name := range names {
+		if name == "" || strings.ContainsAny(name, " :\r\n") {
+			v.add(fmt.Sprintf("captureHeaders[%d]", i), "is not a valid header name", name)
+		}
+	}
+}
@@ -0,0 +1,325 @@
+package request_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openstatushq/openstatus/apps/checker/request"
+)
+
+func fields(t *testing.T, err error) []string {
+	t.Helper()
+
+	var v request.ValidationError
+	require.ErrorAs(t, err, &v)
+
+	names := make([]string, 0, len(v))
+	for _, f := range v {
+		names = append(names, f.Field)
+	}
+
+	return names
+}
+
+func TestHttpCheckerRequestValidate(t *testing.T) {
+	assert.NoError(t, request.HttpCheckerRequest{URL: "https://openstat.us", Method: "get"}.Validate())
+
+	err := request.HttpCheckerRequest{
+		URL:           "ftp://openstat.us",
+		Method:        "FETCH",
+		MonitorID:     "abc",
+		Status:        "paused",
+		Timeout:       -1,
+		RawAssertions: []json.RawMessage{json.RawMessage(`{"type":"nope"}`)},
+	}.Validate()
+	assert.Equal(t, []string{"monitorId", "url", "method", "status", "timeout", "assertions[0].type"}, fields(t, err))
+}
+
+func TestTCPCheckerRequestValidate(t *testing.T) {
+	assert.NoError(t, request.TCPCheckerRequest{URI: "openstat.us:443"}.Validate())
+	assert.Equal(t, []string{"workspaceId", "monitorId"}, fields(t, request.TCPCheckerRequest{URI: "openstat.us:443"}.ValidateScheduled()))
+	assert.Equal(t, []string{"uri"}, fields(t, request.TCPCheckerRequest{URI: "tcp://openstat.us:443"}.Validate()))
+	assert.Equal(t, []string{"uri"}, fields(t, request.TCPCheckerRequest{URI: "openstat.us:99999"}.Validate()))
+}
+
+func TestDNSCheckerRequestValidate(t *testing.T) {
+	assert.NoError(t, request.DNSCheckerRequest{URI: "openstat.us", WorkspaceID: "1", MonitorID: "2"}.ValidateScheduled())
+	assert.Equal(t, []string{"uri"}, fields(t, request.DNSCheckerRequest{URI: "https://openstat.us/"}.Validate()))
+}
+
+func TestConfirm(t *testing.T) {
+	valid := request.TCPCheckerRequest{URI: "openstat.us:443", Confirm: &request.Confirm{Checks: 3, Failures: 2}}
+	assert.NoError(t, valid.Validate())
+
+	invalid := request.TCPCheckerRequest{URI: "openstat.us:443", Confirm: &request.Confirm{Checks: 1, Failures: 2}}
+	assert.Equal(t, []string{"confirm.checks", "confirm.failures"}, fields(t, invalid.Validate()))
+}
+
+func TestQuorum(t *testing.T) {
+	valid := request.TCPCheckerRequest{URI: "openstat.us:443", Quorum: &request.Quorum{Regions: []string{"iad", "syd"}}}
+	assert.NoError(t, valid.Validate())
+	assert.Equal(t, 2, valid.Quorum.Required())
+
+	invalid := request.TCPCheckerRequest{URI: "openstat.us:443", Quorum: &request.Quorum{Regions: []string{"iad", "iad"}, Failures: 4}}
+	assert.Equal(t, []string{"quorum.regions[1]", "quorum.failures"}, fields(t, invalid.Validate()))
+}
+
+func TestLabels(t *testing.T) {
+	valid := request.DNSCheckerRequest{URI: "openstat.us", Labels: map[string]string{"team": "platform", "k8s.env": "prod"}}
+	assert.NoError(t, valid.Validate())
+
+	invalid := request.DNSCheckerRequest{URI: "openstat.us", Labels: map[string]string{"1team": "platform", "env": strings.Repeat("a", 257)}}
+	assert.Equal(t, []string{"labels.1team", "labels.env"}, fields(t, invalid.Validate()))
+}
+
+func TestCallbackURL(t *testing.T) {
+	valid := request.DNSCheckerRequest{URI: "openstat.us", CallbackURL: "https://example.com/hooks/openstatus"}
+	assert.NoError(t, valid.Validate())
+
+	invalid := request.DNSCheckerRequest{URI: "openstat.us", CallbackURL: "example.com/hooks"}
+	assert.Equal(t, []string{"callbackUrl"}, fields(t, invalid.Validate()))
+}
+
+func TestCacheTTL(t *testing.T) {
+	assert.NoError(t, request.PingRequest{URL: "https://openstat.us", Method: "GET", CacheTTL: 5000}.Validate())
+	assert.Equal(t, []string{"cacheTtl"}, fields(t, request.PingRequest{URL: "https://openstat.us", Method: "GET", CacheTTL: request.MaxCacheTTL + 1}.Validate()))
+}
+
+func TestDegradedThresholds(t *testing.T) {
+	valid := request.DNSCheckerRequest{URI: "openstat.us", DegradedAfter: 400, RecoverBelow: 300, DegradedWindow: 5}
+	assert.NoError(t, valid.Validate())
+
+	invalid := request.DNSCheckerRequest{URI: "openstat.us", RecoverBelow: 300, DegradedWindow: 5}
+	assert.Equal(t, []string{"recoverBelow", "degradedWindow"}, fields(t, invalid.Validate()))
+}
+
+func TestContentCheckerRequestValidate(t *testing.T) {
+	assert.NoError(t, request.ContentCheckerRequest{URL: "https://openstat.us", Selector: "main > h1", Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "2"}}.Validate())
+	assert.Equal(t, []string{"workspaceId", "monitorId", "selector"}, fields(t, request.ContentCheckerRequest{URL: "https://openstat.us"}.Validate()))
+	assert.Equal(t, []string{"url", "selector"}, fields(t, request.ContentCheckerRequest{URL: "openstat.us", Selector: "p:first-child", Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "2"}}.Validate()))
+}
+
+func TestCrawlCheckerRequestValidate(t *testing.T) {
+	assert.NoError(t, request.CrawlCheckerRequest{URL: "https://openstat.us", Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "2"}, Depth: 2}.Validate())
+	assert.Equal(t, []string{"depth", "maxLinks"}, fields(t, request.CrawlCheckerRequest{URL: "https://openstat.us", Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "2"}, Depth: 4, MaxLinks: -1}.Validate()))
+}
+
+func TestGRPCCheckerRequestValidate(t *testing.T) {
+	valid := request.GRPCCheckerRequest{URI: "openstat.us:443", Method: "/grpc.health.v1.Health/Check", Body: json.RawMessage(`{"service":""}`), Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "2"}}
+	assert.NoError(t, valid.Validate())
+
+	invalid := request.GRPCCheckerRequest{
+		URI:           "openstat.us:443",
+		Method:        "grpc.health.v1.Health",
+		Body:          json.RawMessage(`[]`),
+		Metadata:      map[string]string{"grpc-timeout": "1S"},
+		RawAssertions: []json.RawMessage{json.RawMessage(`{"type":"header","compare":"eq","key":"server","target":"envoy"}`)},
+		Scheduled:     request.Scheduled{WorkspaceID: "1", MonitorID: "2"},
+	}
+	assert.Equal(t, []string{"method", "body", "metadata.grpc-timeout", "assertions[0].type"}, fields(t, invalid.Validate()))
+}
+
+func TestSMTPCheckerRequestValidate(t *testing.T) {
+	valid := request.SMTPCheckerRequest{URI: "smtp.openstat.us:587", From: "monitor@openstat.us", Username: "monitor", Password: "secret", Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "2"}}
+	assert.NoError(t, valid.Validate())
+
+	b, err := json.Marshal(valid)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "secret")
+
+	invalid := request.SMTPCheckerRequest{URI: "smtp.openstat.us:587", From: "Monitor <monitor@openstat.us>", Password: "secret", TLS: "ssl", Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "2"}}
+	assert.Equal(t, []string{"from", "username", "tls"}, fields(t, invalid.Validate()))
+}
+
+func TestEmailCheckerRequestValidate(t *testing.T) {
+	assert.NoError(t, request.EmailCheckerRequest{URI: "openstat.us", Selectors: []string{"google"}, MinPolicy: "quarantine", Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "2"}}.Validate())
+	assert.Equal(t, []string{"uri", "selectors[0]", "selectors[1]", "minPolicy"}, fields(t, request.EmailCheckerRequest{
+		URI:       "https://openstat.us",
+		Selectors: []string{"", "google._domainkey"},
+		MinPolicy: "block",
+		Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "2"},
+	}.Validate()))
+}
+
+func TestPortsCheckerRequestValidate(t *testing.T) {
+	req := request.PortsCheckerRequest{URI: "openstat.us", Open: []string{"443", "8000-8002"}, Closed: []string{"5432"}, Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "2"}}
+	assert.NoError(t, req.Validate())
+	open, closed := req.Ports()
+	assert.Equal(t, []int{443, 8000, 8001, 8002}, open)
+	assert.Equal(t, []int{5432}, closed)
+
+	assert.Equal(t, []string{"open"}, fields(t, request.PortsCheckerRequest{URI: "openstat.us", Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "2"}}.Validate()))
+	assert.Equal(t, []string{"uri", "open[0]", "open[1]", "open[2]", "closed[1]"}, fields(t, request.PortsCheckerRequest{
+		URI:       "openstat.us:443",
+		Open:      []string{"0", "90-80", "1-1000"},
+		Closed:    []string{"443-444", "444"},
+		Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "2"},
+	}.Validate()))
+}
+
+func TestOIDCCheckerRequestValidate(t *testing.T) {
+	assert.NoError(t, request.OIDCCheckerRequest{URL: "https://accounts.openstat.us", KeyIDs: []string{"2026-10"}, MinValidityDays: 30, Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "2"}}.Validate())
+	assert.Equal(t, []string{"url", "keyIds[0]", "minValidityDays"}, fields(t, request.OIDCCheckerRequest{
+		URL:             "https://accounts.openstat.us?tenant=1",
+		KeyIDs:          []string{""},
+		MinValidityDays: 400,
+		Scheduled:       request.Scheduled{WorkspaceID: "1", MonitorID: "2"},
+	}.Validate()))
+}
+
+func TestDownloadCheckerRequestValidate(t *testing.T) {
+	req := request.DownloadCheckerRequest{URL: "https://openstat.us/checker.tar.gz", SHA256: strings.Repeat("ab", 32), Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "2"}}
+	assert.NoError(t, req.Validate())
+	assert.Equal(t, int64(request.DefaultDownloadBytes), req.Cap())
+
+	assert.Equal(t, []string{"sha256"}, fields(t, request.DownloadCheckerRequest{URL: "https://openstat.us/checker.tar.gz", Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "2"}}.Validate()))
+	assert.Equal(t, []string{"timeout", "sha256", "maxBytes"}, fields(t, request.DownloadCheckerRequest{
+		URL:       "https://openstat.us/checker.tar.gz",
+		Timeout:   10,
+		SHA256:    "sha256:" + strings.Repeat("ab", 32),
+		MaxBytes:  2 << 30,
+		Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "2"},
+	}.Validate()))
+}
+
+func TestRevocation(t *testing.T) {
+	assert.NoError(t, request.HttpCheckerRequest{URL: "https://openstat.us", Method: "GET", Revocation: request.RevocationFail}.Validate())
+	assert.Equal(t, []string{"revocation"}, fields(t, request.HttpCheckerRequest{URL: "https://openstat.us", Method: "GET", Revocation: "strict"}.Validate()))
+	assert.NoError(t, request.TCPCheckerRequest{URI: "openstat.us:443", TLS: true, Revocation: request.RevocationWarn}.Validate())
+	assert.Equal(t, []string{"revocation"}, fields(t, request.TCPCheckerRequest{URI: "openstat.us:443", Revocation: request.RevocationWarn}.Validate()))
+}
+
+func TestTLSScan(t *testing.T) {
+	assert.NoError(t, request.TCPCheckerRequest{URI: "openstat.us:443", TLS: true, TLSScan: &request.TLSScan{}}.Validate())
+	assert.NoError(t, request.TCPCheckerRequest{URI: "openstat.us:443", TLS: true, TLSScan: &request.TLSScan{ForbiddenVersions: []string{"TLS 1.0", "1.1", "1.2"}}}.Validate())
+	assert.Equal(t, []string{"tlsScan"}, fields(t, request.TCPCheckerRequest{URI: "openstat.us:443", TLSScan: &request.TLSScan{}}.Validate()))
+	assert.Equal(t, []string{"tlsScan.forbiddenVersions[1]"}, fields(t, request.TCPCheckerRequest{URI: "openstat.us:443", TLS: true, TLSScan: &request.TLSScan{ForbiddenVersions: []string{"1.0", "SSL 3.0"}}}.Validate()))
+
+	assert.Equal(t, request.DefaultForbiddenTLSVersions, request.TLSScan{}.Forbidden())
+	assert.Empty(t, request.TLSScan{ForbiddenVersions: []string{}}.Forbidden())
+}
+
+func TestDualStack(t *testing.T) {
+	assert.NoError(t, request.TCPCheckerRequest{URI: "openstat.us:443", DualStack: true}.Validate())
+	assert.Equal(t, []string{"dualStack"}, fields(t, request.TCPCheckerRequest{URI: "openstat.us:443", DualStack: true, IPFamily: request.IPFamilyIPv6}.Validate()))
+	assert.NoError(t, request.HttpCheckerRequest{URL: "https://openstat.us", Method: "GET", DualStack: true, IPFamily: request.IPFamilyAny}.Validate())
+	assert.Equal(t, []string{"dualStack"}, fields(t, request.HttpCheckerRequest{URL: "https://openstat.us", Method: "GET", DualStack: true, Proxy: &request.Proxy{URL: "http://proxy.openstat.us:3128"}}.Validate()))
+}
+
+func TestConditional(t *testing.T) {
+	assert.NoError(t, request.HttpCheckerRequest{URL: "https://openstat.us", Method: "GET", Conditional: true}.Validate())
+	assert.Equal(t, []string{"conditional"}, fields(t, request.HttpCheckerRequest{URL: "https://openstat.us", Method: "POST", Conditional: true}.Validate()))
+}
+
+func TestHold(t *testing.T) {
+	assert.NoError(t, request.TCPCheckerRequest{URI: "openstat.us:443", Hold: &request.Hold{Duration: 90000, KeepAlive: 30000}}.Validate())
+	assert.Equal(t, []string{"hold.duration", "hold.keepAlive"}, fields(t, request.TCPCheckerRequest{URI: "openstat.us:443", Hold: &request.Hold{Duration: 500, KeepAlive: -1}}.Validate()))
+	assert.Equal(t, []string{"hold.duration"}, fields(t, request.TCPCheckerRequest{URI: "openstat.us:443", TotalDeadline: 60000, Hold: &request.Hold{Duration: 90000}}.Validate()))
+}
+
+func TestTimeout(t *testing.T) {
+	assert.NoError(t, request.TCPCheckerRequest{URI: "openstat.us:443", Timeout: 5000}.Validate())
+	assert.Equal(t, []string{"timeout"}, fields(t, request.TCPCheckerRequest{URI: "openstat.us:443", Timeout: 50}.Validate()))
+	assert.Equal(t, []string{"timeout"}, fields(t, request.DNSCheckerRequest{URI: "openstat.us", Timeout: 60001}.Validate()))
+	assert.Equal(t, []string{"timeout"}, fields(t, request.CrawlCheckerRequest{URL: "https://openstat.us", Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "2"}, Timeout: 120000}.Validate()))
+
+	assert.Equal(t, 45*time.Second, request.Timeout("http", 0))
+	assert.Equal(t, 5*time.Second, request.Timeout("dns", 0))
+	assert.Equal(t, 100*time.Millisecond, request.Timeout("tcp", 1), "clamped to the minimum")
+	assert.Equal(t, 2*time.Minute, request.Timeout("tcp", 45000000), "clamped to the maximum")
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	assert.NoError(t, request.HttpCheckerRequest{URL: "https://openstat.us", Method: "GET", MaxBodyBytes: 1 << 20}.Validate())
+	assert.Equal(t, []string{"maxBodyBytes"}, fields(t, request.HttpCheckerRequest{URL: "https://openstat.us", Method: "GET", MaxBodyBytes: request.MaxBodyBytesLimit + 1}.Validate()))
+	assert.Equal(t, []string{"maxBodyBytes"}, fields(t, request.ContentCheckerRequest{URL: "https://openstat.us", Scheduled: request.Scheduled{WorkspaceID: "1", MonitorID: "2"}, Selector: "main", MaxBodyBytes: -1}.Validate()))
+
+	assert.Equal(t, int64(request.DefaultMaxBodyBytes), request.BodyLimit(0))
+	assert.Equal(t, int64(1024), request.BodyLimit(1024))
+}
+
+func TestSecurityGradeAssertion(t *testing.T) {
+	valid := request.HttpCheckerRequest{URL: "https://openstat.us", Method: "GET", RawAssertions: []json.RawMessage{json.RawMessage(`{"type":"securityGrade","compare":"gte","target":"B"}`)}}
+	assert.NoError(t, valid.Validate())
+
+	invalid := request.HttpCheckerRequest{URL: "https://openstat.us", Method: "GET", RawAssertions: []json.RawMessage{json.RawMessage(`{"type":"securityGrade","compare":"gte","target":"A+"}`)}}
+	assert.Equal(t, []string{"assertions[0].target"}, fields(t, invalid.Validate()))
+}
+
+func TestFanOutRequestValidate(t *testing.T) {
+	valid := request.FanOutRequest{Type: "http", Regions: []string{"ams", "iad"}, Request: json.RawMessage(`{"url": "https://openstat.us"}`)}
+	assert.NoError(t, valid.Validate())
+
+	invalid := request.FanOutRequest{Type: "icmp", Regions: []string{"ams", "ams"}}
+	assert.Equal(t, []string{"type", "regions[1]", "request"}, fields(t, invalid.Validate()))
+}
+
+func TestDecodeError(t *testing.T) {
+	var req request.HttpCheckerRequest
+	err := json.Unmarshal([]byte(`{"url":"https://openstat.us","timeout":"10s"}`), &req)
+
+	v := request.DecodeError(err)
+	require.Len(t, v, 1)
+	assert.Equal(t, "timeout", v[0].Field)
+	assert.Equal(t, "expected int64", v[0].Reason)
+}
+
+func TestClientCertificate(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	cert := &request.ClientCertificate{
+		Certificate: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})),
+	}
+
+	req := request.HttpCheckerRequest{URL: "https://openstat.us", ClientCert: cert}
+	assert.NoError(t, req.Validate())
+
+	b, err := json.Marshal(req)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), cert.PrivateKey)
+	assert.Contains(t, string(b), `"privateKey":"[REDACTED]"`)
+
+	err = request.TCPCheckerRequest{URI: "openstat.us:443", ClientCert: cert}.Validate()
+	assert.Equal(t, []string{"clientCertificate"}, fields(t, err), "requires tls")
+
+	req.ClientCert = &request.ClientCertificate{Certificate: cert.Certificate, PrivateKey: "nope"}
+	var v request.ValidationError
+	require.ErrorAs(t, req.Validate(), &v)
+	assert.Equal(t, request.ValidationError{{Field: "clientCertificate", Reason: "must be a PEM encoded certificate and matching private key"}}, v)
+}
+
+func TestProxy(t *testing.T) {
+	req := request.PingRequest{URL: "https://openstat.us", Proxy: &request.Proxy{URL: "socks5://proxy:1080", Username: "user", Password: "secret"}}
+	assert.NoError(t, req.Validate())
+
+	b, err := json.Marshal(req)
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "secret")
+
+	req.Proxy.URL = "ftp://proxy"
+	assert.Equal(t, []string{"proxy.url"}, fields(t, req.Validate()))
+}
+
+func TestDegradedPhases(t *testing.T) {
+	assert.NoError(t, request.HttpCheckerRequest{URL: "https://openstat.us", Method: "GET", DegradedPhases: &request.PhaseThresholds{TLS: 200, FirstByte: 500}}.Validate())
+	assert.Equal(t, []string{"degradedPhases.dnsMs"}, fields(t, request.HttpCheckerRequest{URL: "https://openstat.us", Method: "GET", DegradedPhases: &request.PhaseThresholds{DNS: -1}}.Validate()))
+	assert.Equal(t, []string{"degradedPhases.firstByteMs"}, fields(t, request.TCPCheckerRequest{URI: "openstat.us:443", DegradedPhases: &request.PhaseThresholds{FirstByte: 500}}.Validate()))
+}
@@ -41,6 +41,7 @@ type Monitor struct {
 }
 
 type PrivateLocation struct {
-	ID   int    `db:"id"`
-	Name string `db:"name"`
+	ID       int            `db:"id"`
+	Name     string         `db:"name"`
+	Metadata sql.NullString `db:"metadata"`
 }
@@ -0,0 +1,234 @@
+package server
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openstatushq/openstatus/apps/private-location/internal/database"
+	private_locationv1 "github.com/openstatushq/openstatus/apps/private-location/proto/private_location/v1"
+)
+
+// signatureHeader carries the ed25519 signature of the message of an agent
+// request, deterministically encoded.
+const signatureHeader = "openstatus-signature"
+
+// The metadata keys of the agent of a private location.
+const (
+	metadataRegion       = "region"
+	metadataVersion      = "version"
+	metadataCapabilities = "capabilities"
+	metadataPublicKey    = "public_key"
+	// metadataLabelPrefix prefixes the keys of the labels of the agent.
+	metadataLabelPrefix = "label."
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrNotRegistered    = errors.New("agent not registered")
+	ErrConfigDisabled   = errors.New("agent config not enabled")
+)
+
+// ParseSigningKey parses the base64 ed25519 seed the configs of the agents
+// are signed with.
+func ParseSigningKey(s string) (ed25519.PrivateKey, error) {
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, errors.New("expected a base64 ed25519 seed")
+	}
+
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// verifySignature checks that msg, the message of req, is signed with key.
+func verifySignature(req connect.AnyRequest, msg proto.Message, key []byte) error {
+	if len(key) != ed25519.PublicKeySize {
+		return connect.NewError(connect.CodeUnauthenticated, ErrInvalidSignature)
+	}
+	signature, err := base64.StdEncoding.DecodeString(req.Header().Get(signatureHeader))
+	if err != nil {
+		return connect.NewError(connect.CodeUnauthenticated, ErrInvalidSignature)
+	}
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return connect.NewError(connect.CodeInternal, err)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), data, signature) {
+		return connect.NewError(connect.CodeUnauthenticated, ErrInvalidSignature)
+	}
+
+	return nil
+}
+
+// agentLocation returns the private location of the token of req.
+func (h *privateLocationHandler) agentLocation(req connect.AnyRequest) (database.PrivateLocation, map[string]string, error) {
+	var location database.PrivateLocation
+	token := req.Header().Get("openstatus-token")
+	if token == "" {
+		return location, nil, connect.NewError(connect.CodeUnauthenticated, ErrMissingToken)
+	}
+
+	if err := h.db.Get(&location, "SELECT id, name, metadata FROM private_location WHERE token = ?", token); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return location, nil, connect.NewError(connect.CodeUnauthenticated, ErrPrivateLocationNotFound)
+		}
+		return location, nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	metadata := make(map[string]string)
+	if location.Metadata.Valid && location.Metadata.String != "" {
+		if err := json.Unmarshal([]byte(location.Metadata.String), &metadata); err != nil {
+			return location, nil, connect.NewError(connect.CodeInternal, err)
+		}
+	}
+
+	return location, metadata, nil
+}
+
+// registeredLocation returns the private location of the token of req, once
+// its agent registered and req is signed with the key of the agent.
+func (h *privateLocationHandler) registeredLocation(req connect.AnyRequest, msg proto.Message) (database.PrivateLocation, error) {
+	location, metadata, err := h.agentLocation(req)
+	if err != nil {
+		return location, err
+	}
+
+	key, err := base64.StdEncoding.DecodeString(metadata[metadataPublicKey])
+	if err != nil || len(key) == 0 {
+		return location, connect.NewError(connect.CodeFailedPrecondition, ErrNotRegistered)
+	}
+
+	return location, verifySignature(req, msg, key)
+}
+
+func (h *privateLocationHandler) Register(ctx context.Context, req *connect.Request[private_locationv1.RegisterRequest]) (*connect.Response[private_locationv1.RegisterResponse], error) {
+	location, metadata, err := h.agentLocation(req)
+	if err != nil {
+		return nil, err
+	}
+	// The agent proves it holds the key its next requests are signed with.
+	if err := verifySignature(req, req.Msg, req.Msg.GetPublicKey()); err != nil {
+		return nil, err
+	}
+
+	// The last agent registered replaces the previous one.
+	for k := range metadata {
+		if strings.HasPrefix(k, metadataLabelPrefix) {
+			delete(metadata, k)
+		}
+	}
+	for k, v := range req.Msg.GetLabels() {
+		metadata[metadataLabelPrefix+k] = v
+	}
+	capabilities := slices.Clone(req.Msg.GetCapabilities())
+	slices.Sort(capabilities)
+	metadata[metadataRegion] = req.Msg.GetRegion()
+	metadata[metadataVersion] = req.Msg.GetVersion()
+	metadata[metadataCapabilities] = strings.Join(capabilities, ",")
+	metadata[metadataPublicKey] = base64.StdEncoding.EncodeToString(req.Msg.GetPublicKey())
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	now := time.Now().Unix()
+	_, err = h.db.NamedExec("UPDATE private_location SET metadata = :metadata, status = 'active', last_seen_at = :last_seen_at, updated_at = :updated_at WHERE id = :id", map[string]any{
+		"metadata":     string(data),
+		"last_seen_at": now,
+		"updated_at":   now,
+		"id":           location.ID,
+	})
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	// Enrich wide event with the agent registered
+	if holder := GetEvent(ctx); holder != nil {
+		holder.Event["agent"] = map[string]any{
+			"location_id":  location.ID,
+			"region":       req.Msg.GetRegion(),
+			"version":      req.Msg.GetVersion(),
+			"capabilities": capabilities,
+		}
+	}
+
+	return connect.NewResponse(&private_locationv1.RegisterResponse{
+		LocationId: strconv.Itoa(location.ID),
+	}), nil
+}
+
+func (h *privateLocationHandler) Heartbeat(ctx context.Context, req *connect.Request[private_locationv1.HeartbeatRequest]) (*connect.Response[private_locationv1.HeartbeatResponse], error) {
+	location, err := h.registeredLocation(req, req.Msg)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = h.db.NamedExec("UPDATE private_location SET status = 'active', last_seen_at = :last_seen_at WHERE id = :id", map[string]any{
+		"last_seen_at": time.Now().Unix(),
+		"id":           location.ID,
+	})
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	// Enrich wide event with the liveness of the agent
+	if holder := GetEvent(ctx); holder != nil {
+		holder.Event["agent"] = map[string]any{
+			"location_id":    location.ID,
+			"region":         req.Msg.GetRegion(),
+			"version":        req.Msg.GetVersion(),
+			"last_ingest":    req.Msg.GetLastIngest(),
+			"uptime_seconds": req.Msg.GetUptimeSeconds(),
+			"queue_depth":    req.Msg.GetQueueDepth(),
+			"monitors":       req.Msg.GetMonitors(),
+		}
+	}
+
+	return connect.NewResponse(&private_locationv1.HeartbeatResponse{}), nil
+}
+
+func (h *privateLocationHandler) Config(ctx context.Context, req *connect.Request[private_locationv1.ConfigRequest]) (*connect.Response[private_locationv1.ConfigResponse], error) {
+	if h.ConfigKey == nil {
+		return nil, connect.NewError(connect.CodeUnimplemented, ErrConfigDisabled)
+	}
+	location, err := h.registeredLocation(req, req.Msg)
+	if err != nil {
+		return nil, err
+	}
+
+	monitors, err := h.monitors(ctx, req.Header().Get("openstatus-token"), location)
+	if err != nil {
+		return nil, err
+	}
+	config, err := proto.MarshalOptions{Deterministic: true}.Marshal(&private_locationv1.AgentConfig{
+		Monitors:        monitors,
+		RefreshInterval: h.RefreshInterval,
+	})
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+	sum := sha256.Sum256(config)
+	etag := hex.EncodeToString(sum[:])
+
+	if req.Msg.GetEtag() == etag {
+		return connect.NewResponse(&private_locationv1.ConfigResponse{NotModified: true}), nil
+	}
+
+	return connect.NewResponse(&private_locationv1.ConfigResponse{
+		Etag:      etag,
+		Config:    config,
+		Signature: ed25519.Sign(h.ConfigKey, config),
+	}), nil
+}
@@ -0,0 +1,167 @@
+package server_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openstatushq/openstatus/apps/private-location/internal/server"
+	"github.com/openstatushq/openstatus/apps/private-location/internal/tinybird"
+	private_locationv1 "github.com/openstatushq/openstatus/apps/private-location/proto/private_location/v1"
+)
+
+// signedRequest returns the request of msg for the test private location,
+// signed with key.
+func signedRequest[T any, M interface {
+	*T
+	proto.Message
+}](t *testing.T, key ed25519.PrivateKey, msg M) *connect.Request[T] {
+	t.Helper()
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := connect.NewRequest((*T)(msg))
+	req.Header().Set("openstatus-token", "my-secret-key")
+	req.Header().Set("openstatus-signature", base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)))
+
+	return req
+}
+
+func newKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func register(t *testing.T, h private_locationv1.PrivateLocationServiceHandler, key ed25519.PrivateKey) {
+	t.Helper()
+	_, err := h.Register(context.Background(), signedRequest(t, key, &private_locationv1.RegisterRequest{
+		Region:    "home",
+		PublicKey: key.Public().(ed25519.PublicKey),
+	}))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	db := testDB()
+	h := server.NewPrivateLocationServer(db, tinybird.NewClient(http.DefaultClient, ""))
+	key := newKey(t)
+
+	_, err := h.Register(context.Background(), connect.NewRequest(&private_locationv1.RegisterRequest{}))
+	if connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Errorf("expected unauthenticated code for missing token, got %v", connect.CodeOf(err))
+	}
+
+	req := signedRequest(t, newKey(t), &private_locationv1.RegisterRequest{
+		Region:    "home",
+		PublicKey: key.Public().(ed25519.PublicKey),
+	})
+	_, err = h.Register(context.Background(), req)
+	if connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Errorf("expected unauthenticated code for a request not signed with the public key, got %v", connect.CodeOf(err))
+	}
+
+	resp, err := h.Register(context.Background(), signedRequest(t, key, &private_locationv1.RegisterRequest{
+		Region:       "home",
+		Labels:       map[string]string{"site": "basement"},
+		Capabilities: []string{"tcp", "http"},
+		PublicKey:    key.Public().(ed25519.PublicKey),
+		Version:      "v1.0.0",
+	}))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if resp.Msg.LocationId != "1" {
+		t.Errorf("expected location 1, got %q", resp.Msg.LocationId)
+	}
+
+	var status, metadata string
+	if err := db.QueryRow("SELECT status, metadata FROM private_location WHERE id = 1").Scan(&status, &metadata); err != nil {
+		t.Fatal(err)
+	}
+	if status != "active" {
+		t.Errorf("expected status active, got %q", status)
+	}
+	want := `{"capabilities":"http,tcp","label.site":"basement","public_key":"` + base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)) + `","region":"home","version":"v1.0.0"}`
+	if metadata != want {
+		t.Errorf("expected metadata %s, got %s", want, metadata)
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	h := server.NewPrivateLocationServer(testDB(), tinybird.NewClient(http.DefaultClient, ""))
+	key := newKey(t)
+
+	_, err := h.Heartbeat(context.Background(), signedRequest(t, key, &private_locationv1.HeartbeatRequest{Region: "home"}))
+	if connect.CodeOf(err) != connect.CodeFailedPrecondition {
+		t.Errorf("expected failed precondition code before registration, got %v", connect.CodeOf(err))
+	}
+
+	register(t, h, key)
+
+	_, err = h.Heartbeat(context.Background(), signedRequest(t, newKey(t), &private_locationv1.HeartbeatRequest{Region: "home"}))
+	if connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Errorf("expected unauthenticated code for another key, got %v", connect.CodeOf(err))
+	}
+
+	_, err = h.Heartbeat(context.Background(), signedRequest(t, key, &private_locationv1.HeartbeatRequest{Region: "home", Monitors: 3}))
+	if err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	h := server.NewPrivateLocationServer(testDB(), tinybird.NewClient(http.DefaultClient, ""))
+	key := newKey(t)
+	register(t, h, key)
+
+	_, err := h.Config(context.Background(), signedRequest(t, key, &private_locationv1.ConfigRequest{}))
+	if connect.CodeOf(err) != connect.CodeUnimplemented {
+		t.Errorf("expected unimplemented code without a signing key, got %v", connect.CodeOf(err))
+	}
+
+	signingKey, err := server.ParseSigningKey(base64.StdEncoding.EncodeToString(make([]byte, ed25519.SeedSize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.ConfigKey = signingKey
+	h.RefreshInterval = "1m"
+
+	resp, err := h.Config(context.Background(), signedRequest(t, key, &private_locationv1.ConfigRequest{}))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !ed25519.Verify(signingKey.Public().(ed25519.PublicKey), resp.Msg.Config, resp.Msg.Signature) {
+		t.Fatal("expected the config to be signed")
+	}
+	var config private_locationv1.AgentConfig
+	if err := proto.Unmarshal(resp.Msg.Config, &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.RefreshInterval != "1m" {
+		t.Errorf("expected refresh interval 1m, got %q", config.RefreshInterval)
+	}
+	if config.Monitors.GetRegion() != "My Home" {
+		t.Errorf("expected region My Home, got %q", config.Monitors.GetRegion())
+	}
+
+	resp, err = h.Config(context.Background(), signedRequest(t, key, &private_locationv1.ConfigRequest{Etag: resp.Msg.Etag}))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !resp.Msg.NotModified {
+		t.Error("expected the config not to be modified")
+	}
+}
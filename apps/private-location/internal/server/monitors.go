@@ -185,6 +185,16 @@ func (h *privateLocationHandler) Monitors(ctx context.Context, req *connect.Requ
 		return nil, connect.NewError(connect.CodeInternal, err)
 	}
 
+	res, err := h.monitors(ctx, token, location)
+	if err != nil {
+		return nil, err
+	}
+
+	return connect.NewResponse(res), nil
+}
+
+// monitors returns the active monitors of the private location of token.
+func (h *privateLocationHandler) monitors(ctx context.Context, token string, location database.PrivateLocation) (*private_locationv1.MonitorsResponse, error) {
 	var monitors []database.Monitor
 	err := h.db.Select(&monitors, "SELECT monitor.id, monitor.job_type, monitor.url, monitor.periodicity, monitor.method, monitor.body, monitor.timeout, monitor.degraded_after, monitor.follow_redirects, monitor.headers, monitor.assertions, monitor.workspace_id, monitor.retry, monitor.otel_endpoint, monitor.otel_headers FROM monitor JOIN private_location_to_monitor a ON monitor.id = a.monitor_id JOIN private_location b ON a.private_location_id = b.id WHERE b.token = ? AND monitor.deleted_at IS NULL and monitor.active = 1", token)
 	if err != nil {
@@ -203,12 +213,12 @@ func (h *privateLocationHandler) Monitors(ctx context.Context, req *connect.Requ
 		}
 	}
 
-	return connect.NewResponse(&private_locationv1.MonitorsResponse{
+	return &private_locationv1.MonitorsResponse{
 		HttpMonitors: httpMonitors,
 		TcpMonitors:  tcpMonitors,
 		DnsMonitors:  dnsMonitors,
 		Region:       location.Name,
-	}), nil
+	}, nil
 }
 
 func mapMonitors(ctx context.Context, monitors []database.Monitor) (
@@ -2,6 +2,8 @@ package server
 
 import (
 	"context"
+	"crypto/ed25519"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
@@ -133,6 +135,12 @@ type privateLocationHandler struct {
 	db              *sqlx.DB
 	TbClient        tinybird.Client
 	WorkflowsClient workflows.Client
+	// ConfigKey signs the configs of the agents, nil when the Config RPC is
+	// disabled.
+	ConfigKey ed25519.PrivateKey
+	// RefreshInterval is how often the agents fetch their config, e.g. 1m,
+	// empty for their default.
+	RefreshInterval string
 }
 
 func NewPrivateLocationServer(db *sqlx.DB, tbClient tinybird.Client) *privateLocationHandler {
@@ -160,6 +168,15 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 	privateLocationServer := NewPrivateLocationServer(s.db, tinybirdClient)
 	privateLocationServer.WorkflowsClient = workflows.NewClient(httpClient, os.Getenv("CRON_SECRET"))
+	privateLocationServer.RefreshInterval = os.Getenv("AGENT_REFRESH_INTERVAL")
+	if seed := os.Getenv("AGENT_CONFIG_SIGNING_KEY"); seed != "" {
+		key, err := ParseSigningKey(seed)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid AGENT_CONFIG_SIGNING_KEY: %v\n", err)
+			os.Exit(1)
+		}
+		privateLocationServer.ConfigKey = key
+	}
 	path, handler := v1.NewPrivateLocationServiceHandler(privateLocationServer)
 
 	r.Group(func(r chi.Router) {
@@ -44,6 +44,15 @@ const (
 	// PrivateLocationServiceIngestDNSProcedure is the fully-qualified name of the
 	// PrivateLocationService's IngestDNS RPC.
 	PrivateLocationServiceIngestDNSProcedure = "/private_location.v1.PrivateLocationService/IngestDNS"
+	// PrivateLocationServiceRegisterProcedure is the fully-qualified name of the
+	// PrivateLocationService's Register RPC.
+	PrivateLocationServiceRegisterProcedure = "/private_location.v1.PrivateLocationService/Register"
+	// PrivateLocationServiceHeartbeatProcedure is the fully-qualified name of the
+	// PrivateLocationService's Heartbeat RPC.
+	PrivateLocationServiceHeartbeatProcedure = "/private_location.v1.PrivateLocationService/Heartbeat"
+	// PrivateLocationServiceConfigProcedure is the fully-qualified name of the PrivateLocationService's
+	// Config RPC.
+	PrivateLocationServiceConfigProcedure = "/private_location.v1.PrivateLocationService/Config"
 )
 
 // PrivateLocationServiceClient is a client for the private_location.v1.PrivateLocationService
@@ -53,6 +62,15 @@ type PrivateLocationServiceClient interface {
 	IngestTCP(context.Context, *connect.Request[IngestTCPRequest]) (*connect.Response[IngestTCPResponse], error)
 	IngestHTTP(context.Context, *connect.Request[IngestHTTPRequest]) (*connect.Response[IngestHTTPResponse], error)
 	IngestDNS(context.Context, *connect.Request[IngestDNSRequest]) (*connect.Response[IngestDNSResponse], error)
+	// Register announces an agent, so it shows up as a selectable private
+	// location without manual provisioning.
+	Register(context.Context, *connect.Request[RegisterRequest]) (*connect.Response[RegisterResponse], error)
+	// Heartbeat reports the liveness of an agent, for the stale or wedged ones
+	// to be excluded from scheduling.
+	Heartbeat(context.Context, *connect.Request[HeartbeatRequest]) (*connect.Response[HeartbeatResponse], error)
+	// Config returns the monitors and settings of an agent, signed by the
+	// control plane.
+	Config(context.Context, *connect.Request[ConfigRequest]) (*connect.Response[ConfigResponse], error)
 }
 
 // NewPrivateLocationServiceClient constructs a client for the
@@ -90,6 +108,24 @@ func NewPrivateLocationServiceClient(httpClient connect.HTTPClient, baseURL stri
 			connect.WithSchema(privateLocationServiceMethods.ByName("IngestDNS")),
 			connect.WithClientOptions(opts...),
 		),
+		register: connect.NewClient[RegisterRequest, RegisterResponse](
+			httpClient,
+			baseURL+PrivateLocationServiceRegisterProcedure,
+			connect.WithSchema(privateLocationServiceMethods.ByName("Register")),
+			connect.WithClientOptions(opts...),
+		),
+		heartbeat: connect.NewClient[HeartbeatRequest, HeartbeatResponse](
+			httpClient,
+			baseURL+PrivateLocationServiceHeartbeatProcedure,
+			connect.WithSchema(privateLocationServiceMethods.ByName("Heartbeat")),
+			connect.WithClientOptions(opts...),
+		),
+		config: connect.NewClient[ConfigRequest, ConfigResponse](
+			httpClient,
+			baseURL+PrivateLocationServiceConfigProcedure,
+			connect.WithSchema(privateLocationServiceMethods.ByName("Config")),
+			connect.WithClientOptions(opts...),
+		),
 	}
 }
 
@@ -99,6 +135,9 @@ type privateLocationServiceClient struct {
 	ingestTCP  *connect.Client[IngestTCPRequest, IngestTCPResponse]
 	ingestHTTP *connect.Client[IngestHTTPRequest, IngestHTTPResponse]
 	ingestDNS  *connect.Client[IngestDNSRequest, IngestDNSResponse]
+	register   *connect.Client[RegisterRequest, RegisterResponse]
+	heartbeat  *connect.Client[HeartbeatRequest, HeartbeatResponse]
+	config     *connect.Client[ConfigRequest, ConfigResponse]
 }
 
 // Monitors calls private_location.v1.PrivateLocationService.Monitors.
@@ -121,6 +160,21 @@ func (c *privateLocationServiceClient) IngestDNS(ctx context.Context, req *conne
 	return c.ingestDNS.CallUnary(ctx, req)
 }
 
+// Register calls private_location.v1.PrivateLocationService.Register.
+func (c *privateLocationServiceClient) Register(ctx context.Context, req *connect.Request[RegisterRequest]) (*connect.Response[RegisterResponse], error) {
+	return c.register.CallUnary(ctx, req)
+}
+
+// Heartbeat calls private_location.v1.PrivateLocationService.Heartbeat.
+func (c *privateLocationServiceClient) Heartbeat(ctx context.Context, req *connect.Request[HeartbeatRequest]) (*connect.Response[HeartbeatResponse], error) {
+	return c.heartbeat.CallUnary(ctx, req)
+}
+
+// Config calls private_location.v1.PrivateLocationService.Config.
+func (c *privateLocationServiceClient) Config(ctx context.Context, req *connect.Request[ConfigRequest]) (*connect.Response[ConfigResponse], error) {
+	return c.config.CallUnary(ctx, req)
+}
+
 // PrivateLocationServiceHandler is an implementation of the
 // private_location.v1.PrivateLocationService service.
 type PrivateLocationServiceHandler interface {
@@ -128,6 +182,15 @@ type PrivateLocationServiceHandler interface {
 	IngestTCP(context.Context, *connect.Request[IngestTCPRequest]) (*connect.Response[IngestTCPResponse], error)
 	IngestHTTP(context.Context, *connect.Request[IngestHTTPRequest]) (*connect.Response[IngestHTTPResponse], error)
 	IngestDNS(context.Context, *connect.Request[IngestDNSRequest]) (*connect.Response[IngestDNSResponse], error)
+	// Register announces an agent, so it shows up as a selectable private
+	// location without manual provisioning.
+	Register(context.Context, *connect.Request[RegisterRequest]) (*connect.Response[RegisterResponse], error)
+	// Heartbeat reports the liveness of an agent, for the stale or wedged ones
+	// to be excluded from scheduling.
+	Heartbeat(context.Context, *connect.Request[HeartbeatRequest]) (*connect.Response[HeartbeatResponse], error)
+	// Config returns the monitors and settings of an agent, signed by the
+	// control plane.
+	Config(context.Context, *connect.Request[ConfigRequest]) (*connect.Response[ConfigResponse], error)
 }
 
 // NewPrivateLocationServiceHandler builds an HTTP handler from the service implementation. It
@@ -161,6 +224,24 @@ func NewPrivateLocationServiceHandler(svc PrivateLocationServiceHandler, opts ..
 		connect.WithSchema(privateLocationServiceMethods.ByName("IngestDNS")),
 		connect.WithHandlerOptions(opts...),
 	)
+	privateLocationServiceRegisterHandler := connect.NewUnaryHandler(
+		PrivateLocationServiceRegisterProcedure,
+		svc.Register,
+		connect.WithSchema(privateLocationServiceMethods.ByName("Register")),
+		connect.WithHandlerOptions(opts...),
+	)
+	privateLocationServiceHeartbeatHandler := connect.NewUnaryHandler(
+		PrivateLocationServiceHeartbeatProcedure,
+		svc.Heartbeat,
+		connect.WithSchema(privateLocationServiceMethods.ByName("Heartbeat")),
+		connect.WithHandlerOptions(opts...),
+	)
+	privateLocationServiceConfigHandler := connect.NewUnaryHandler(
+		PrivateLocationServiceConfigProcedure,
+		svc.Config,
+		connect.WithSchema(privateLocationServiceMethods.ByName("Config")),
+		connect.WithHandlerOptions(opts...),
+	)
 	return "/private_location.v1.PrivateLocationService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case PrivateLocationServiceMonitorsProcedure:
@@ -171,6 +252,12 @@ func NewPrivateLocationServiceHandler(svc PrivateLocationServiceHandler, opts ..
 			privateLocationServiceIngestHTTPHandler.ServeHTTP(w, r)
 		case PrivateLocationServiceIngestDNSProcedure:
 			privateLocationServiceIngestDNSHandler.ServeHTTP(w, r)
+		case PrivateLocationServiceRegisterProcedure:
+			privateLocationServiceRegisterHandler.ServeHTTP(w, r)
+		case PrivateLocationServiceHeartbeatProcedure:
+			privateLocationServiceHeartbeatHandler.ServeHTTP(w, r)
+		case PrivateLocationServiceConfigProcedure:
+			privateLocationServiceConfigHandler.ServeHTTP(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -195,3 +282,15 @@ func (UnimplementedPrivateLocationServiceHandler) IngestHTTP(context.Context, *c
 func (UnimplementedPrivateLocationServiceHandler) IngestDNS(context.Context, *connect.Request[IngestDNSRequest]) (*connect.Response[IngestDNSResponse], error) {
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("private_location.v1.PrivateLocationService.IngestDNS is not implemented"))
 }
+
+func (UnimplementedPrivateLocationServiceHandler) Register(context.Context, *connect.Request[RegisterRequest]) (*connect.Response[RegisterResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("private_location.v1.PrivateLocationService.Register is not implemented"))
+}
+
+func (UnimplementedPrivateLocationServiceHandler) Heartbeat(context.Context, *connect.Request[HeartbeatRequest]) (*connect.Response[HeartbeatResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("private_location.v1.PrivateLocationService.Heartbeat is not implemented"))
+}
+
+func (UnimplementedPrivateLocationServiceHandler) Config(context.Context, *connect.Request[ConfigRequest]) (*connect.Response[ConfigResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("private_location.v1.PrivateLocationService.Config is not implemented"))
+}